@@ -12,21 +12,32 @@ import (
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/infrastructure/currency_api"
 	"github.com/AsaHero/e-wallet/internal/infrastructure/dictionary"
+	"github.com/AsaHero/e-wallet/internal/infrastructure/llm_chain"
 	"github.com/AsaHero/e-wallet/internal/infrastructure/ocr_service"
 	"github.com/AsaHero/e-wallet/internal/infrastructure/openai"
+	"github.com/AsaHero/e-wallet/internal/infrastructure/parse_cache"
+	"github.com/AsaHero/e-wallet/internal/infrastructure/rate_limiter"
 	"github.com/AsaHero/e-wallet/internal/infrastructure/repository"
+	"github.com/AsaHero/e-wallet/internal/infrastructure/stats_cache"
 	"github.com/AsaHero/e-wallet/internal/infrastructure/telegram_bot_service"
 	"github.com/AsaHero/e-wallet/internal/usecase/accounts"
 	"github.com/AsaHero/e-wallet/internal/usecase/categories"
+	"github.com/AsaHero/e-wallet/internal/usecase/drafts"
+	"github.com/AsaHero/e-wallet/internal/usecase/imports"
 	"github.com/AsaHero/e-wallet/internal/usecase/notifications"
 	"github.com/AsaHero/e-wallet/internal/usecase/parser"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/internal/usecase/reports"
+	"github.com/AsaHero/e-wallet/internal/usecase/rollups"
 	"github.com/AsaHero/e-wallet/internal/usecase/transactions"
+	"github.com/AsaHero/e-wallet/internal/usecase/usage"
 	"github.com/AsaHero/e-wallet/internal/usecase/users"
 	"github.com/AsaHero/e-wallet/pkg/app"
 	"github.com/AsaHero/e-wallet/pkg/config"
 	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/pdf"
 	"github.com/AsaHero/e-wallet/pkg/redis"
 	"github.com/hibiken/asynq"
 	"github.com/uptrace/bun"
@@ -38,6 +49,7 @@ type App struct {
 	server       *http.Server
 	db           *bun.DB
 	taskWorker   *asynq.Server
+	scheduler    *asynq.Scheduler
 	taskQueue    *asynq.Client
 	redis        *redis.RedisClient
 	shutdownOTLP func(ctx context.Context) error
@@ -100,9 +112,9 @@ func (a *App) Run() error {
 	txManager := postgres.NewTxManager(a.db)
 
 	// init provider
-	openaiProvider, err := openai.New(a.config)
+	llmProvider, err := a.newLLMProvider()
 	if err != nil {
-		return fmt.Errorf("failed to create openai provider: %w", err)
+		return fmt.Errorf("failed to create llm provider: %w", err)
 	}
 
 	telegramBotService, err := telegram_bot_service.New(a.config)
@@ -120,25 +132,82 @@ func (a *App) Run() error {
 		return fmt.Errorf("failed to create currency api client: %w", err)
 	}
 
+	// Reports still render without the font, in Latin script only.
+	reportFont, err := pdf.LoadFont(a.config.Report.FontPath)
+	if err != nil {
+		a.logger.WarnContext(context.Background(), "failed to load report font", "path", a.config.Report.FontPath, "error", err)
+	}
+
+	statsCache := stats_cache.Disabled()
+	if a.config.StatsCache.Enabled {
+		statsCache = stats_cache.New(a.redis, a.config.StatsCache.TTL)
+	}
+
+	parseCache := parse_cache.Disabled()
+	if a.config.ParseCache.Enabled {
+		parseCache = parse_cache.New(a.redis, a.config.ParseCache.TTL)
+	}
+
+	rateLimiter := rate_limiter.Disabled()
+	if a.config.RateLimit.Enabled {
+		rateLimiter = rate_limiter.New(a.redis)
+	}
+
 	// init dictionary
+	languagesDict := dictionary.NewLanguagesDict(a.db)
 	categoriesDict := dictionary.NewCategoriesDict(a.db)
 	subcategoriesDict := dictionary.NewSubcategoriesDict(a.db)
 
+	languages, err := languagesDict.FindAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to load languages: %w", err)
+	}
+
+	if err := entities.RegisterLanguages(languages); err != nil {
+		return fmt.Errorf("failed to register languages: %w", err)
+	}
+
 	// init repository
 	usersRepo := repository.NewUsersRepo(a.db)
 	accountsRepo := repository.NewAccountsRepo(a.db)
 	transactionsRepo := repository.NewTransactionsRepo(a.db, categoriesDict, subcategoriesDict)
+	notificationSettingsRepo := repository.NewNotificationSettingsRepo(a.db)
+	importSessionsRepo := repository.NewImportSessionsRepo(a.db)
+	digestSchedulesRepo := repository.NewDigestSchedulesRepo(a.db)
+	rollupsRepo := repository.NewRollupsRepo(a.db)
+	draftsRepo := repository.NewTransactionDraftsRepo(a.db)
+	llmUsageRepo := repository.NewLLMUsageRepo(a.db)
 
 	// domain services
 	accountsDomainService := entities.NewAccountsService(accountsRepo)
 
+	llmModels := parser.Models{
+		Classification: a.config.LLM.Models.Classification,
+		Details:        a.config.LLM.Models.Details,
+		OCRCleanup:     a.config.LLM.Models.OCRCleanup,
+		Transcription:  a.config.LLM.Models.Transcription,
+	}
+
+	quotas := make(map[entities.Plan]entities.PlanQuota, len(a.config.Quotas))
+	for plan, quota := range a.config.Quotas {
+		quotas[entities.Plan(plan)] = entities.PlanQuota{
+			Daily:   entities.UsageLimits(quota.Daily),
+			Monthly: entities.UsageLimits(quota.Monthly),
+		}
+	}
+
 	// init usecases
-	usersUsecase := users.NewModule(a.config.Context.Timeout, a.logger, usersRepo)
-	accountsUsecase := accounts.NewModule(a.config.Context.Timeout, a.logger, usersRepo, accountsRepo, accountsDomainService, transactionsRepo, categoriesDict)
-	transactionsUsecase := transactions.NewModule(a.config.Context.Timeout, a.logger, txManager, usersRepo, accountsRepo, transactionsRepo, categoriesDict, subcategoriesDict)
-	categoriesUsecase := categories.NewModule(a.config.Context.Timeout, a.logger, categoriesDict, subcategoriesDict, usersRepo)
-	parserUsecase := parser.NewModule(a.logger, openaiProvider, ocrProvider, usersRepo, accountsRepo, categoriesDict, subcategoriesDict, currencyApiClient)
-	notificationsUsecase := notifications.NewModule(a.logger, transactionsRepo, usersRepo, a.taskQueue, telegramBotService)
+	usersUsecase := users.NewModule(a.config.Context.Timeout, a.logger, usersRepo, notificationSettingsRepo, digestSchedulesRepo, a.taskQueue, statsCache)
+	accountsUsecase := accounts.NewModule(a.config.Context.Timeout, a.logger, usersRepo, accountsRepo, accountsDomainService, transactionsRepo, categoriesDict, statsCache)
+	transactionsUsecase := transactions.NewModule(a.config.Context.Timeout, a.logger, txManager, usersRepo, accountsRepo, transactionsRepo, categoriesDict, subcategoriesDict, a.taskQueue, statsCache)
+	categoriesUsecase := categories.NewModule(a.config.Context.Timeout, a.logger, txManager, categoriesDict, subcategoriesDict, usersRepo, transactionsRepo, statsCache)
+	parserUsecase := parser.NewModule(a.logger, txManager, llmProvider, llmModels, ocrProvider, usersRepo, accountsRepo, categoriesDict, subcategoriesDict, currencyApiClient, draftsRepo, parseCache, a.config.ParseCache.MinConfidence, llmUsageRepo, quotas)
+	draftsUsecase := drafts.NewModule(a.config.Context.Timeout, a.logger, txManager, usersRepo, accountsRepo, transactionsRepo, categoriesDict, subcategoriesDict, draftsRepo, a.taskQueue, statsCache)
+	importsUsecase := imports.NewModule(a.config.Context.Timeout, a.logger, txManager, usersRepo, accountsRepo, transactionsRepo, categoriesDict, subcategoriesDict, importSessionsRepo, currencyApiClient, statsCache)
+	reportsUsecase := reports.NewModule(a.config.Context.Timeout, a.logger, usersRepo, accountsRepo, transactionsRepo, a.taskQueue, telegramBotService, reportFont)
+	rollupsUsecase := rollups.NewModule(a.logger, usersRepo, rollupsRepo, a.taskQueue, statsCache)
+	usageUsecase := usage.NewModule(a.config.Context.Timeout, a.logger, usersRepo, llmUsageRepo, quotas)
+	notificationsUsecase := notifications.NewModule(a.logger, transactionsRepo, usersRepo, notificationSettingsRepo, digestSchedulesRepo, a.taskQueue, telegramBotService)
 
 	// init handlers
 	opts := &delivery.Options{
@@ -150,13 +219,27 @@ func (a *App) Run() error {
 		TransactionsUsecase: transactionsUsecase,
 		CategoriesUsecase:   categoriesUsecase,
 		ParserUsecase:       parserUsecase,
+		DraftsUsecase:       draftsUsecase,
+		ImportsUsecase:      importsUsecase,
 		NotificationUsecase: notificationsUsecase,
+		ReportsUsecase:      reportsUsecase,
+		RollupsUsecase:      rollupsUsecase,
+		UsageUsecase:        usageUsecase,
+		RateLimiter:         rateLimiter,
 	}
 
 	mux := worker.NewRouter(opts)
 	a.taskWorker = worker.NewWorker(a.config)
 	go a.taskWorker.Run(mux)
 
+	a.scheduler, err = worker.NewScheduler(a.config)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduler: %w", err)
+	}
+	if err := a.scheduler.Start(); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+
 	router := api.NewRouter(opts)
 	a.server = api.NewServer(a.config, router)
 
@@ -173,6 +256,10 @@ func (a *App) Stop() error {
 		a.db.Close()
 	}
 
+	if a.scheduler != nil {
+		a.scheduler.Shutdown()
+	}
+
 	if a.taskWorker != nil {
 		a.taskWorker.Stop()
 	}
@@ -191,3 +278,28 @@ func (a *App) Stop() error {
 
 	return nil
 }
+
+// newLLMProvider chains the configured providers, the first one that answers
+// wins.
+func (a *App) newLLMProvider() (ports.LLMProvider, error) {
+	var providers []llm_chain.Provider
+	for _, name := range a.config.LLM.Providers {
+		var provider ports.LLMProvider
+		var err error
+		switch name {
+		case "openai":
+			provider, err = openai.New(a.config)
+		case "compatible":
+			provider, err = openai.NewCompatible(a.config)
+		default:
+			err = fmt.Errorf("unknown provider")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		providers = append(providers, llm_chain.Provider{Name: name, LLMProvider: provider})
+	}
+
+	return llm_chain.New(a.logger, a.config.LLM.Timeout, providers...)
+}
@@ -30,6 +30,9 @@ func Handle(c *gin.Context, err error, opts ...Option) {
 				Code:    m.Code,
 				Message: m.Message,
 			}
+			if len(m.Details) > 0 {
+				resp.Details = m.Details
+			}
 		}
 	}
 
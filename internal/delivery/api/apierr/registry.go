@@ -100,6 +100,29 @@ func NewRegistry() *Registry {
 		},
 	)
 
+	r.RegisterMatch(func(err error) bool { return errors.Is(err, inerr.ErrQuotaExceeded{}) },
+		func(err error) Mapping {
+			var quotaErr inerr.ErrQuotaExceeded
+			if !errors.As(err, &quotaErr) {
+				return Mapping{
+					HTTPStatus: http.StatusTooManyRequests,
+					Code:       CodeTooManyRequests,
+				}
+			}
+
+			return Mapping{
+				HTTPStatus: http.StatusTooManyRequests,
+				Code:       CodeTooManyRequests,
+				Message:    quotaErr.Error(),
+				Details: map[string]any{
+					"period":   quotaErr.Period,
+					"metric":   quotaErr.Metric,
+					"reset_at": quotaErr.ResetAt,
+				},
+			}
+		},
+	)
+
 	r.RegisterMatch(func(err error) bool { return errors.Is(err, inerr.ErrorPermissionDenied) },
 		func(err error) Mapping {
 			return Mapping{
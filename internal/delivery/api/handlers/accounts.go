@@ -8,6 +8,7 @@ import (
 	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/usecase/accounts/command"
+	"github.com/AsaHero/e-wallet/internal/usecase/accounts/query"
 	"github.com/gin-gonic/gin"
 	"github.com/shogo82148/pointer"
 )
@@ -192,3 +193,44 @@ func (h *Handlers) DeleteAccount(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// GetCashFlowForecast godoc
+// @Summary      Projects daily account balances
+// @Description  Repeats salary-like recurring flows, averages weekday spending over the last 120 days and applies future-dated transactions. Days with a negative projected balance are flagged.
+// @Tags         Accounts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days       query int    false "Forecast horizon in days, 1-90 (default 30)"
+// @Param        account_id query string false "Account ID"
+// @Success      200 {object} query.CashFlowForecastView
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /accounts/forecast [get]
+func (h *Handlers) GetCashFlowForecast(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.CashFlowForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	response, err := h.AccountsUsecase.Query.GetCashFlowForecast(ctx, &query.GetCashFlowForecastQuery{
+		UserID:    userID,
+		AccountID: req.AccountID,
+		Days:      req.Days,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
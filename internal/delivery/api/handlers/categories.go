@@ -7,6 +7,7 @@ import (
 	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/usecase/categories/command"
 	"github.com/AsaHero/e-wallet/internal/usecase/categories/query"
 	"github.com/gin-gonic/gin"
@@ -94,6 +95,7 @@ func (h *Handlers) CreateCategory(c *gin.Context) {
 	response, err := h.CategoriesUsecase.Command.CreateCategory(ctx, &command.CreateCategoryCommand{
 		UserID: userID,
 		Name:   req.Name,
+		Names:  req.Names,
 		Emoji:  req.Emoji,
 	})
 	if err != nil {
@@ -133,6 +135,7 @@ func (h *Handlers) CreateSubcategory(c *gin.Context) {
 		UserID:     userID,
 		CategoryID: req.CategoryID,
 		Name:       req.Name,
+		Names:      req.Names,
 		Emoji:      req.Emoji,
 	})
 	if err != nil {
@@ -145,10 +148,12 @@ func (h *Handlers) CreateSubcategory(c *gin.Context) {
 
 // DeleteCategory godoc
 // @Summary      Delete a category
+// @Description  Transactions and subcategories are moved to target_id (defaults to "Other") before the category is removed.
 // @Tags         Categories
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path int true "Category ID"
+// @Param        target_id query int false "Category receiving the transactions"
 // @Success      204
 // @Failure      401 {object} apierr.Response
 // @Failure      404 {object} apierr.Response
@@ -174,9 +179,16 @@ func (h *Handlers) DeleteCategory(c *gin.Context) {
 		return
 	}
 
+	var target models.CategoryTargetQuery
+	if err := c.ShouldBindQuery(&target); err != nil {
+		apierr.BadRequest(c, "invalid target category id", err.Error())
+		return
+	}
+
 	err := h.CategoriesUsecase.Command.DeleteCategory(ctx, &command.DeleteCategoryCommand{
-		UserID:     userID,
-		CategoryID: categoryIDInt,
+		UserID:           userID,
+		CategoryID:       categoryIDInt,
+		TargetCategoryID: target.TargetCategoryID,
 	})
 	if err != nil {
 		apierr.Handle(c, err)
@@ -228,3 +240,396 @@ func (h *Handlers) DeleteSubcategory(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// UpdateCategory godoc
+// @Summary      Update a user category
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Category ID"
+// @Param        request body models.UpdateCategoryRequest true "request"
+// @Success      200 {object} models.Category
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      403 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /categories/{id} [put]
+func (h *Handlers) UpdateCategory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var categoryIDInt int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &categoryIDInt); err != nil {
+		apierr.BadRequest(c, "invalid category id", err.Error())
+		return
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	response, err := h.CategoriesUsecase.Command.UpdateCategory(ctx, &command.UpdateCategoryCommand{
+		UserID:     userID,
+		CategoryID: categoryIDInt,
+		Name:       req.Name,
+		Names:      req.Names,
+		Emoji:      req.Emoji,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateSubcategory godoc
+// @Summary      Update a user subcategory
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Subcategory ID"
+// @Param        request body models.UpdateCategoryRequest true "request"
+// @Success      200 {object} models.Subcategory
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      403 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /subcategories/{id} [put]
+func (h *Handlers) UpdateSubcategory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var subcategoryIDInt int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &subcategoryIDInt); err != nil {
+		apierr.BadRequest(c, "invalid subcategory id", err.Error())
+		return
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	response, err := h.CategoriesUsecase.Command.UpdateSubcategory(ctx, &command.UpdateSubcategoryCommand{
+		UserID:        userID,
+		SubcategoryID: subcategoryIDInt,
+		Name:          req.Name,
+		Names:         req.Names,
+		Emoji:         req.Emoji,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ReorderCategories godoc
+// @Summary      Bulk update category positions for the user
+// @Tags         Categories
+// @Accept       json
+// @Security     BearerAuth
+// @Param        request body models.ReorderRequest true "request"
+// @Success      204
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /categories/order [put]
+func (h *Handlers) ReorderCategories(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	items := make([]command.ReorderItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, command.ReorderItem{ID: item.ID, Position: item.Position})
+	}
+
+	err := h.CategoriesUsecase.Command.ReorderCategories(ctx, &command.ReorderCategoriesCommand{
+		UserID: userID,
+		Items:  items,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReorderSubcategories godoc
+// @Summary      Bulk update subcategory positions for the user
+// @Tags         Categories
+// @Accept       json
+// @Security     BearerAuth
+// @Param        request body models.ReorderRequest true "request"
+// @Success      204
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /subcategories/order [put]
+func (h *Handlers) ReorderSubcategories(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	items := make([]command.ReorderItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, command.ReorderItem{ID: item.ID, Position: item.Position})
+	}
+
+	err := h.CategoriesUsecase.Command.ReorderSubcategories(ctx, &command.ReorderSubcategoriesCommand{
+		UserID: userID,
+		Items:  items,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HideCategory godoc
+// @Summary      Hide a category for the user
+// @Description  Hidden categories stay listed with hidden=true but are left out of AI classification.
+// @Tags         Categories
+// @Security     BearerAuth
+// @Param        id path int true "Category ID"
+// @Success      204
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /categories/{id}/hide [post]
+func (h *Handlers) HideCategory(c *gin.Context) {
+	h.setCategoryHidden(c, true)
+}
+
+// UnhideCategory godoc
+// @Summary      Unhide a category for the user
+// @Tags         Categories
+// @Security     BearerAuth
+// @Param        id path int true "Category ID"
+// @Success      204
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /categories/{id}/unhide [post]
+func (h *Handlers) UnhideCategory(c *gin.Context) {
+	h.setCategoryHidden(c, false)
+}
+
+func (h *Handlers) setCategoryHidden(c *gin.Context, hidden bool) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var categoryIDInt int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &categoryIDInt); err != nil {
+		apierr.BadRequest(c, "invalid category id", err.Error())
+		return
+	}
+
+	err := h.CategoriesUsecase.Command.SetCategoryHidden(ctx, &command.SetCategoryHiddenCommand{
+		UserID:     userID,
+		CategoryID: categoryIDInt,
+		Hidden:     hidden,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HideSubcategory godoc
+// @Summary      Hide a subcategory for the user
+// @Tags         Categories
+// @Security     BearerAuth
+// @Param        id path int true "Subcategory ID"
+// @Success      204
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /subcategories/{id}/hide [post]
+func (h *Handlers) HideSubcategory(c *gin.Context) {
+	h.setSubcategoryHidden(c, true)
+}
+
+// UnhideSubcategory godoc
+// @Summary      Unhide a subcategory for the user
+// @Tags         Categories
+// @Security     BearerAuth
+// @Param        id path int true "Subcategory ID"
+// @Success      204
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /subcategories/{id}/unhide [post]
+func (h *Handlers) UnhideSubcategory(c *gin.Context) {
+	h.setSubcategoryHidden(c, false)
+}
+
+func (h *Handlers) setSubcategoryHidden(c *gin.Context, hidden bool) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var subcategoryIDInt int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &subcategoryIDInt); err != nil {
+		apierr.BadRequest(c, "invalid subcategory id", err.Error())
+		return
+	}
+
+	err := h.CategoriesUsecase.Command.SetSubcategoryHidden(ctx, &command.SetSubcategoryHiddenCommand{
+		UserID:        userID,
+		SubcategoryID: subcategoryIDInt,
+		Hidden:        hidden,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetCategoryMergePreview godoc
+// @Summary      Preview what merging a category would move
+// @Tags         Categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Source category ID"
+// @Param        target_id query int false "Target category ID, defaults to Other"
+// @Success      200 {object} query.CategoryMergePreview
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /categories/{id}/merge-preview [get]
+func (h *Handlers) GetCategoryMergePreview(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var categoryIDInt int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &categoryIDInt); err != nil {
+		apierr.BadRequest(c, "invalid category id", err.Error())
+		return
+	}
+
+	var target models.CategoryTargetQuery
+	if err := c.ShouldBindQuery(&target); err != nil {
+		apierr.BadRequest(c, "invalid target category id", err.Error())
+		return
+	}
+
+	targetID := entities.OtherCategory.Int()
+	if target.TargetCategoryID != nil {
+		targetID = *target.TargetCategoryID
+	}
+
+	preview, err := h.CategoriesUsecase.Query.GetCategoryMergePreview(ctx, userID, categoryIDInt, targetID)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// MergeCategory godoc
+// @Summary      Merge a user category into another one
+// @Description  Moves transactions and subcategories to the target and deletes the source, in one database transaction.
+// @Tags         Categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Source category ID"
+// @Param        request body models.MergeCategoryRequest true "request"
+// @Success      200 {object} command.MergeCategoriesResult
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      403 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /categories/{id}/merge [post]
+func (h *Handlers) MergeCategory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var categoryIDInt int
+	if _, err := fmt.Sscanf(c.Param("id"), "%d", &categoryIDInt); err != nil {
+		apierr.BadRequest(c, "invalid category id", err.Error())
+		return
+	}
+
+	var req models.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	result, err := h.CategoriesUsecase.Command.MergeCategories(ctx, &command.MergeCategoriesCommand{
+		UserID:           userID,
+		SourceCategoryID: categoryIDInt,
+		TargetCategoryID: req.TargetCategoryID,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
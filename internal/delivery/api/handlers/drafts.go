@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/drafts/command"
+	"github.com/gin-gonic/gin"
+	"github.com/shogo82148/pointer"
+)
+
+// GetDrafts godoc
+// @Summary      Lists pending drafts
+// @Description  Drafts are saved by the parse endpoints with save_draft and kept for a day
+// @Tags         Drafts
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.TransactionDraft
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /drafts [get]
+func (h *Handlers) GetDrafts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	drafts, err := h.DraftsUsecase.Query.GetDrafts(ctx, userID)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	response := make([]models.TransactionDraft, 0, len(drafts))
+	for _, draft := range drafts {
+		response = append(response, toTransactionDraft(draft))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateDraft godoc
+// @Summary      Edits a pending draft
+// @Description  Only the fields sent are changed. A new amount is taken in the user's currency.
+// @Tags         Drafts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "draft id"
+// @Param        request body models.UpdateDraftRequest true "request"
+// @Success      200 {object} models.TransactionDraft
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Failure      409 {object} apierr.Response
+// @Router       /drafts/{id} [patch]
+func (h *Handlers) UpdateDraft(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	draftID := c.Param("id")
+	if draftID == "" {
+		apierr.BadRequest(c, "draft id is missing")
+		return
+	}
+
+	var req models.UpdateDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	draft, err := h.DraftsUsecase.Command.UpdateDraft(ctx, &command.UpdateDraftCommand{
+		UserID:        userID,
+		DraftID:       draftID,
+		AccountID:     req.AccountID,
+		CategoryID:    req.CategoryID,
+		SubcategoryID: req.SubcategoryID,
+		Type:          req.Type,
+		Amount:        req.Amount,
+		Note:          req.Note,
+		Merchant:      req.Merchant,
+		PerformedAt:   req.PerformedAt,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTransactionDraft(draft))
+}
+
+// ConfirmDraft godoc
+// @Summary      Confirms a draft
+// @Description  Creates the transaction and applies it to the balance. Drafts without an account go to the default one. Entries that likely record the same expense are listed in possible_duplicates
+// @Tags         Drafts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "draft id"
+// @Success      201 {object} models.Transaction
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Failure      409 {object} apierr.Response
+// @Router       /drafts/{id}/confirm [post]
+func (h *Handlers) ConfirmDraft(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	draftID := c.Param("id")
+	if draftID == "" {
+		apierr.BadRequest(c, "draft id is missing")
+		return
+	}
+
+	result, err := h.DraftsUsecase.Command.ConfirmDraft(ctx, userID, draftID)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	transaction := toTransaction(result.Transaction)
+	transaction.PossibleDuplicates = toPossibleDuplicates(result.Duplicates)
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// RejectDraft godoc
+// @Summary      Rejects a draft
+// @Tags         Drafts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "draft id"
+// @Success      204
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Failure      409 {object} apierr.Response
+// @Router       /drafts/{id}/reject [post]
+func (h *Handlers) RejectDraft(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	draftID := c.Param("id")
+	if draftID == "" {
+		apierr.BadRequest(c, "draft id is missing")
+		return
+	}
+
+	if err := h.DraftsUsecase.Command.RejectDraft(ctx, userID, draftID); err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toTransactionDraft(draft *entities.TransactionDraft) models.TransactionDraft {
+	model := models.TransactionDraft{
+		ID:            draft.ID.String(),
+		CategoryID:    draft.CategoryID,
+		SubcategoryID: draft.SubcategoryID,
+		Type:          draft.Type.String(),
+		Status:        draft.Status.String(),
+		Source:        draft.Source.String(),
+		Amount:        draft.AmountMajor(),
+		CurrencyCode:  draft.CurrencyCode.String(),
+		Note:          draft.Note,
+		Merchant:      draft.Merchant,
+		Confidence:    draft.Confidence,
+		PerformedAt:   pointer.TimeOrNil(draft.PerformedAt),
+		ExpiresAt:     draft.ExpiresAt,
+		CreatedAt:     draft.CreatedAt,
+	}
+
+	if draft.AccountID != nil {
+		model.AccountID = pointer.String(draft.AccountID.String())
+	}
+
+	if draft.OriginalAmount > 0 {
+		model.OriginalAmount = pointer.Float64(draft.OriginalAmountMajor())
+		model.OriginalCurrencyCode = pointer.String(draft.OriginalCurrencyCode.String())
+		model.FxRate = pointer.Float64(draft.FxRate)
+	}
+
+	if draft.TransactionID != nil {
+		model.TransactionID = pointer.String(draft.TransactionID.String())
+	}
+
+	return model
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/imports/command"
+	"github.com/AsaHero/e-wallet/internal/usecase/imports/query"
+	"github.com/gin-gonic/gin"
+	"github.com/shogo82148/pointer"
+)
+
+// maxImportFileSize is the largest statement accepted for upload.
+const maxImportFileSize = 5 << 20
+
+// UploadImport godoc
+// @Summary      Uploads a bank statement
+// @Description  Parses a CSV export, detecting encoding, delimiter, date and number formats, and suggests a column mapping. OFX, QIF and CAMT.053 statements are recognised by content or extension and need no mapping. Nothing is imported until commit.
+// @Tags         Imports
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        account_id formData string true "account the transactions go to"
+// @Param        file formData file true "CSV, OFX, QIF or CAMT.053 statement, up to 5 MB"
+// @Success      201 {object} models.ImportSession
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /imports [post]
+func (h *Handlers) UploadImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.UploadImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		apierr.BadRequest(c, "file is missing", err.Error())
+		return
+	}
+
+	if fileHeader.Size > maxImportFileSize {
+		apierr.BadRequest(c, "file is too large")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		apierr.BadRequest(c, "failed to read file", err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		apierr.BadRequest(c, "failed to read file", err.Error())
+		return
+	}
+
+	session, err := h.ImportsUsecase.Command.UploadImport(ctx, &command.UploadImportCommand{
+		UserID:    userID,
+		AccountID: req.AccountID,
+		Filename:  fileHeader.Filename,
+		Data:      data,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toImportSession(session))
+}
+
+// GetImport godoc
+// @Summary      Returns an import session
+// @Tags         Imports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "import session id"
+// @Success      200 {object} models.ImportSession
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /imports/{id} [get]
+func (h *Handlers) GetImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		apierr.BadRequest(c, "import id is missing")
+		return
+	}
+
+	session, err := h.ImportsUsecase.Query.GetImport(ctx, userID, sessionID)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toImportSession(session))
+}
+
+// SetImportMapping godoc
+// @Summary      Maps file columns to transaction fields
+// @Description  Fields are date, amount, income, expense, currency, description, merchant and external_id, e.g. {"mapping": {"date": 0, "amount": 3}}. A date and either amount or income/expense are required.
+// @Tags         Imports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "import session id"
+// @Param        request body models.SetImportMappingRequest true "request"
+// @Success      200 {object} models.ImportSession
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      409 {object} apierr.Response
+// @Router       /imports/{id}/mapping [put]
+func (h *Handlers) SetImportMapping(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		apierr.BadRequest(c, "import id is missing")
+		return
+	}
+
+	var req models.SetImportMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	session, err := h.ImportsUsecase.Command.SetImportMapping(ctx, &command.SetImportMappingCommand{
+		UserID:           userID,
+		SessionID:        sessionID,
+		Mapping:          req.Mapping,
+		DateFormat:       req.DateFormat,
+		DecimalSeparator: req.DecimalSeparator,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toImportSession(session))
+}
+
+// PreviewImport godoc
+// @Summary      Previews imported rows
+// @Description  Shows a page of rows as the transactions they would become, with the suggested category and per-row errors
+// @Tags         Imports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "import session id"
+// @Param        request query models.PreviewImportRequest false "request"
+// @Success      200 {object} query.ImportPreviewView
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /imports/{id}/preview [get]
+func (h *Handlers) PreviewImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		apierr.BadRequest(c, "import id is missing")
+		return
+	}
+
+	var req models.PreviewImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	response, err := h.ImportsUsecase.Query.PreviewImport(ctx, &query.PreviewImportQuery{
+		UserID:    userID,
+		SessionID: sessionID,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CommitImport godoc
+// @Summary      Imports the rows as transactions
+// @Description  Creates the transactions and updates the account balance in one go. Rows that cannot be read or whose bank reference is already on the account are skipped and listed. Imported rows that look like transactions recorded by hand are listed as possible duplicates.
+// @Tags         Imports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "import session id"
+// @Success      200 {object} models.CommitImportResponse
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      409 {object} apierr.Response
+// @Router       /imports/{id}/commit [post]
+func (h *Handlers) CommitImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		apierr.BadRequest(c, "import id is missing")
+		return
+	}
+
+	result, err := h.ImportsUsecase.Command.CommitImport(ctx, &command.CommitImportCommand{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	response := models.CommitImportResponse{
+		Session:            toImportSession(result.Session),
+		Skipped:            make([]models.ImportSkippedRow, 0, len(result.Skipped)),
+		PossibleDuplicates: make([]models.ImportDuplicateRow, 0, len(result.Duplicates)),
+	}
+	for _, record := range result.Skipped {
+		response.Skipped = append(response.Skipped, models.ImportSkippedRow{
+			Line:  record.Line,
+			Error: record.Error,
+		})
+	}
+	for _, duplicate := range result.Duplicates {
+		response.PossibleDuplicates = append(response.PossibleDuplicates, models.ImportDuplicateRow{
+			Line:          duplicate.Line,
+			TransactionID: duplicate.Transaction.ID.String(),
+			DuplicateOf:   duplicate.DuplicateOf.ID.String(),
+			Score:         duplicate.Score,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RollbackImport godoc
+// @Summary      Rolls back a committed import
+// @Description  Deletes the transactions the import created and reverts the balance
+// @Tags         Imports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "import session id"
+// @Success      200 {object} models.ImportSession
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      409 {object} apierr.Response
+// @Router       /imports/{id}/rollback [post]
+func (h *Handlers) RollbackImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		apierr.BadRequest(c, "import id is missing")
+		return
+	}
+
+	session, err := h.ImportsUsecase.Command.RollbackImport(ctx, &command.RollbackImportCommand{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toImportSession(session))
+}
+
+func toImportSession(session *entities.ImportSession) models.ImportSession {
+	mapping := make(map[string]int, len(session.Mapping))
+	for field, column := range session.Mapping {
+		mapping[field.String()] = column
+	}
+
+	return models.ImportSession{
+		ID:               session.ID.String(),
+		AccountID:        session.AccountID.String(),
+		Source:           session.Source,
+		Filename:         session.Filename,
+		Status:           session.Status.String(),
+		Encoding:         session.Format.Encoding,
+		Delimiter:        session.Format.Delimiter,
+		DateFormat:       session.Format.DateFormat,
+		DecimalSeparator: session.Format.DecimalSeparator,
+		Header:           session.Header,
+		Mapping:          mapping,
+		RowCount:         session.RowCount,
+		ImportedCount:    session.ImportedCount,
+		DateFormats:      entities.ImportDateFormats(),
+		CreatedAt:        session.CreatedAt,
+		CommittedAt:      pointer.TimeOrNil(session.CommittedAt),
+	}
+}
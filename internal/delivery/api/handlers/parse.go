@@ -12,13 +12,13 @@ import (
 
 // ParseText godoc
 // @Summary Parse text
-// @Description Parse text
+// @Description Parse every transaction mentioned in a message
 // @Tags Parse
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.ParseTextRequest true "Parse transaction request"
-// @Success      200 {object} parser.ParseTextView
+// @Success      200 {object} parser.ParseView
 // @Failure      400 {object} apierr.Response
 // @Failure      401 {object} apierr.Response
 // @Router       /parse/text [post]
@@ -37,8 +37,8 @@ func (h *Handlers) ParseText(c *gin.Context) {
 		return
 	}
 
-	var response *parser.ParseTextView
-	response, err := h.ParserUsecase.Command.ParseText(ctx, userID, req.Content)
+	var response *parser.ParseView
+	response, err := h.ParserUsecase.Command.ParseText(ctx, userID, req.Content, req.SaveDraft)
 	if err != nil {
 		apierr.Handle(c, err)
 		return
@@ -55,7 +55,7 @@ func (h *Handlers) ParseText(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        request body models.ParseAudioRequest true "Parse transaction request"
-// @Success      200 {object} parser.ParseAudioView
+// @Success      200 {object} parser.ParseView
 // @Failure      400 {object} apierr.Response
 // @Failure      401 {object} apierr.Response
 // @Router       /parse/voice [post]
@@ -74,8 +74,8 @@ func (h *Handlers) ParseVoice(c *gin.Context) {
 		return
 	}
 
-	var response *parser.ParseAudioView
-	response, err := h.ParserUsecase.Command.ParseAudio(ctx, userID, req.FileURL)
+	var response *parser.ParseView
+	response, err := h.ParserUsecase.Command.ParseAudio(ctx, userID, req.FileURL, req.SaveDraft)
 	if err != nil {
 		apierr.Handle(c, err)
 		return
@@ -92,7 +92,7 @@ func (h *Handlers) ParseVoice(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        request body models.ParseImageRequest true "Parse transaction request"
-// @Success      200 {object} parser.ParseImageView
+// @Success      200 {object} parser.ParseView
 // @Failure      400 {object} apierr.Response
 // @Failure      401 {object} apierr.Response
 // @Router       /parse/image [post]
@@ -111,8 +111,8 @@ func (h *Handlers) ParseImage(c *gin.Context) {
 		return
 	}
 
-	var response *parser.ParseImageView
-	response, err := h.ParserUsecase.Command.ParseImage(ctx, userID, req.ImageURL)
+	var response *parser.ParseView
+	response, err := h.ParserUsecase.Command.ParseImage(ctx, userID, req.ImageURL, req.SaveDraft)
 	if err != nil {
 		apierr.Handle(c, err)
 		return
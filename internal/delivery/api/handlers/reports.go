@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMonthlyReport godoc
+// @Summary      Returns the monthly report as PDF
+// @Description  Totals, expenses by category, top merchants, account balances at the start and end of the month, spending compared with the previous months and a daily chart, in the user's language and time zone
+// @Tags         Reports
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        month query string false "Month (YYYY-MM), the previous month by default"
+// @Success      200 {file} file
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /reports/monthly [get]
+func (h *Handlers) GetMonthlyReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.MonthlyReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	report, err := h.ReportsUsecase.GetMonthlyReport(ctx, userID, req.Month)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, report.Filename))
+	c.Data(http.StatusOK, "application/pdf", report.Data)
+}
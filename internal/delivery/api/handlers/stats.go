@@ -5,6 +5,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
 	"github.com/AsaHero/e-wallet/internal/usecase/transactions/query"
 	"github.com/gin-gonic/gin"
 )
@@ -14,8 +15,9 @@ import (
 // @Tags         Stats
 // @Produce      json
 // @Security     BearerAuth
-// @Param        from query string false "From Date"
-// @Param        to query string false "To Date"
+// @Param        period query string false "Named period, e.g. this_month or last_7_days"
+// @Param        from query string false "From Date (YYYY-MM-DD)"
+// @Param        to query string false "To Date (YYYY-MM-DD), inclusive"
 // @Param        account_id query string false "Account ID"
 // @Success      200 {object} query.GetStatsView
 // @Failure      401 {object} apierr.Response
@@ -29,12 +31,105 @@ func (h *Handlers) GetStats(c *gin.Context) {
 		return
 	}
 
+	period := c.Query("period")
 	from := c.Query("from")
 	to := c.Query("to")
 	accountID := c.Query("account_id")
 
 	var response *query.GetStatsView
-	response, err := h.TransactionsUsecase.Query.GetStats(ctx, userID, accountID, from, to)
+	response, err := h.TransactionsUsecase.Query.GetStats(ctx, userID, accountID, period, from, to)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTimeseries godoc
+// @Summary      Returns income, expense and net per time bucket
+// @Description  Buckets are computed in the user's timezone; empty buckets are zero-filled.
+// @Tags         Stats
+// @Produce      json
+// @Security     BearerAuth
+// @Param        bucket      query string false "day, week or month (default day)"
+// @Param        period      query string false "Named period, e.g. this_month or last_7_days"
+// @Param        from        query string false "From Date (YYYY-MM-DD)"
+// @Param        to          query string false "To Date (YYYY-MM-DD), inclusive"
+// @Param        account_id  query string false "Account ID"
+// @Param        by_category query bool   false "Split the series by category"
+// @Success      200 {object} query.TimeseriesView
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /stats/timeseries [get]
+func (h *Handlers) GetTimeseries(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.TimeseriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	response, err := h.TransactionsUsecase.Query.GetTimeseries(ctx, &query.GetTimeseriesQuery{
+		UserID:     userID,
+		AccountID:  req.AccountID,
+		Bucket:     req.Bucket,
+		Period:     req.Period,
+		From:       req.From,
+		To:         req.To,
+		ByCategory: req.ByCategory,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetStatsComparison godoc
+// @Summary      Compares a period with the previous equivalent one
+// @Description  Returns totals and per-category deltas against the previous period of the same length. Periods starting on the first of a month are compared month to month. Dates are resolved in the user's timezone; without dates the current month to date is used.
+// @Tags         Stats
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period     query string false "Named period, e.g. this_month or last_7_days"
+// @Param        from       query string false "From Date (YYYY-MM-DD)"
+// @Param        to         query string false "To Date (YYYY-MM-DD), inclusive"
+// @Param        account_id query string false "Account ID"
+// @Success      200 {object} query.StatsComparisonView
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /stats/compare [get]
+func (h *Handlers) GetStatsComparison(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.StatsComparisonRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	response, err := h.TransactionsUsecase.Query.GetStatsComparison(ctx, &query.GetStatsComparisonQuery{
+		UserID:    userID,
+		AccountID: req.AccountID,
+		Period:    req.Period,
+		From:      req.From,
+		To:        req.To,
+	})
 	if err != nil {
 		apierr.Handle(c, err)
 		return
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/usecase/transactions/command"
 	"github.com/AsaHero/e-wallet/internal/usecase/transactions/query"
 	"github.com/gin-gonic/gin"
@@ -14,6 +17,7 @@ import (
 
 // CreateTransaction godoc
 // @Summary      Creates a new transaction
+// @Description  Entries that likely record the same expense are listed in possible_duplicates
 // @Tags         Transactions
 // @Accept       json
 // @Produce      json
@@ -38,7 +42,7 @@ func (h *Handlers) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	trn, err := h.TransactionsUsecase.Command.CreateTransaction(ctx, &command.CreateTransactionCommand{
+	result, err := h.TransactionsUsecase.Command.CreateTransaction(ctx, &command.CreateTransactionCommand{
 		UserID:               userID,
 		AccountID:            req.AccountID,
 		CategoryID:           req.CategoryID,
@@ -49,6 +53,7 @@ func (h *Handlers) CreateTransaction(c *gin.Context) {
 		OriginalCurrencyCode: req.OriginalCurrencyCode,
 		FxRate:               req.FxRate,
 		Note:                 req.Note,
+		Merchant:             req.Merchant,
 		PerformedAt:          req.PerformedAt,
 	})
 	if err != nil {
@@ -56,6 +61,7 @@ func (h *Handlers) CreateTransaction(c *gin.Context) {
 		return
 	}
 
+	trn := result.Transaction
 	transaction := models.Transaction{
 		ID:                   trn.ID.String(),
 		UserID:               trn.UserID.String(),
@@ -68,6 +74,7 @@ func (h *Handlers) CreateTransaction(c *gin.Context) {
 		OriginalCurrencyCode: pointer.String(trn.OriginalCurrencyCode.String()),
 		FxRate:               pointer.Float64(trn.FxRate),
 		Note:                 trn.RowText,
+		Merchant:             trn.Merchant,
 		PerformedAt:          pointer.TimeOrNil(trn.PerformedAt),
 		RejectedAt:           pointer.TimeOrNil(trn.RejectedAt),
 		CreatedAt:            trn.CreatedAt,
@@ -81,6 +88,8 @@ func (h *Handlers) CreateTransaction(c *gin.Context) {
 		transaction.SubcategoryID = pointer.IntOrNil(trn.Subcategory.ID)
 	}
 
+	transaction.PossibleDuplicates = toPossibleDuplicates(result.Duplicates)
+
 	c.JSON(http.StatusCreated, transaction)
 }
 
@@ -146,6 +155,7 @@ func (h *Handlers) GetTransactions(c *gin.Context) {
 			OriginalCurrencyCode: pointer.String(trn.OriginalCurrencyCode.String()),
 			FxRate:               pointer.Float64(trn.FxRate),
 			Note:                 trn.RowText,
+			Merchant:             trn.Merchant,
 			PerformedAt:          pointer.TimeOrNil(trn.PerformedAt),
 			RejectedAt:           pointer.TimeOrNil(trn.RejectedAt),
 			CreatedAt:            trn.CreatedAt,
@@ -165,6 +175,98 @@ func (h *Handlers) GetTransactions(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// SearchTransactions godoc
+// @Summary      Full-text search over transaction notes, merchants and categories
+// @Tags         Transactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q      query    string true  "search text"
+// @Param        limit  query    int    false "limit"
+// @Param        offset query    int    false "offset"
+// @Success      200 {object} models.TransactionSearchResponse
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /transactions/search [get]
+func (h *Handlers) SearchTransactions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.TransactionSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid search params", err.Error())
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	hits, total, err := h.TransactionsUsecase.Query.Search(ctx, &query.SearchQuery{
+		UserID: userID,
+		Text:   req.Query,
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	resp := models.TransactionSearchResponse{
+		Items: make([]models.TransactionSearchItem, 0, len(hits)),
+		Pagination: models.PaginationResponse{
+			Limit:  req.Limit,
+			Offset: req.Offset,
+			Total:  int64(total),
+		},
+	}
+
+	for _, hit := range hits {
+		trn := hit.Transaction
+		item := models.TransactionSearchItem{
+			Transaction: models.Transaction{
+				ID:                   trn.ID.String(),
+				UserID:               trn.UserID.String(),
+				AccountID:            trn.AccountID.String(),
+				Type:                 trn.Type.String(),
+				Status:               trn.Status.String(),
+				Amount:               trn.AmountMajor(),
+				CurrencyCode:         trn.CurrencyCode.String(),
+				OriginalAmount:       pointer.Float64(trn.OriginalAmountMajor()),
+				OriginalCurrencyCode: pointer.String(trn.OriginalCurrencyCode.String()),
+				FxRate:               pointer.Float64(trn.FxRate),
+				Note:                 trn.RowText,
+				Merchant:             trn.Merchant,
+				PerformedAt:          pointer.TimeOrNil(trn.PerformedAt),
+				RejectedAt:           pointer.TimeOrNil(trn.RejectedAt),
+				CreatedAt:            trn.CreatedAt,
+			},
+			Rank: hit.Rank,
+			Highlights: models.TransactionSearchHighlights{
+				Note:     hit.NoteHighlight,
+				Merchant: hit.MerchantHighlight,
+			},
+		}
+
+		if trn.Category != nil {
+			item.CategoryID = pointer.IntOrNil(trn.Category.ID.Int())
+		}
+
+		if trn.Subcategory != nil {
+			item.SubcategoryID = pointer.IntOrNil(trn.Subcategory.ID)
+		}
+
+		resp.Items = append(resp.Items, item)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetTransaction godoc
 // @Summary      Returns transaction by ID
 // @Tags         Transactions
@@ -209,6 +311,7 @@ func (h *Handlers) GetTransaction(c *gin.Context) {
 		OriginalCurrencyCode: pointer.String(trn.OriginalCurrencyCode.String()),
 		FxRate:               pointer.Float64(trn.FxRate),
 		Note:                 trn.RowText,
+		Merchant:             trn.Merchant,
 		PerformedAt:          pointer.TimeOrNil(trn.PerformedAt),
 		RejectedAt:           pointer.TimeOrNil(trn.RejectedAt),
 		CreatedAt:            trn.CreatedAt,
@@ -307,6 +410,7 @@ func (h *Handlers) UpdateTransaction(c *gin.Context) {
 		OriginalCurrencyCode: req.OriginalCurrencyCode,
 		FxRate:               req.FxRate,
 		Note:                 req.Note,
+		Merchant:             req.Merchant,
 		PerformedAt:          req.PerformedAt,
 	})
 	if err != nil {
@@ -326,6 +430,7 @@ func (h *Handlers) UpdateTransaction(c *gin.Context) {
 		OriginalCurrencyCode: pointer.String(trn.OriginalCurrencyCode.String()),
 		FxRate:               pointer.Float64(trn.FxRate),
 		Note:                 trn.RowText,
+		Merchant:             trn.Merchant,
 		PerformedAt:          pointer.TimeOrNil(trn.PerformedAt),
 		RejectedAt:           pointer.TimeOrNil(trn.RejectedAt),
 		CreatedAt:            trn.CreatedAt,
@@ -341,3 +446,211 @@ func (h *Handlers) UpdateTransaction(c *gin.Context) {
 
 	c.JSON(http.StatusOK, transaction)
 }
+
+// ExportTransactions godoc
+// @Summary      Exports transactions as CSV or XLSX
+// @Description  Streams every matching transaction. Names are in the user's language, dates in the user's timezone and amounts use the currency's decimal scale.
+// @Tags         Transactions
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security     BearerAuth
+// @Param        format     query string false "csv or xlsx (default csv)"
+// @Param        columns    query string false "Comma separated columns: date,type,status,account,category,subcategory,amount,currency,original_amount,original_currency,fx_rate,merchant,note"
+// @Param        account_id query string false "Account ID"
+// @Param        type       query string false "Transaction type"
+// @Param        period     query string false "Named period, e.g. this_month or last_7_days"
+// @Param        from       query string false "From Date (YYYY-MM-DD)"
+// @Param        to         query string false "To Date (YYYY-MM-DD), inclusive"
+// @Success      200 {file} file
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /transactions/export [get]
+func (h *Handlers) ExportTransactions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.ExportTransactionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	var columns []string
+	if req.Columns != "" {
+		columns = strings.Split(req.Columns, ",")
+	}
+
+	export, err := h.TransactionsUsecase.Query.ExportTransactions(ctx, &query.ExportTransactionsQuery{
+		UserID:    userID,
+		Format:    req.Format,
+		Columns:   columns,
+		AccountID: req.AccountID,
+		Type:      req.Type,
+		Period:    req.Period,
+		From:      req.From,
+		To:        req.To,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Status(http.StatusOK)
+
+	// The status is already sent, a failure can only cut the file short.
+	if err := export.Write(ctx, c.Writer); err != nil {
+		h.Logger.ErrorContext(ctx, "failed to write transactions export", err)
+	}
+}
+
+// GetDuplicateTransactions godoc
+// @Summary      Lists possible duplicate transactions
+// @Description  Pairs of entries on the same account with close amounts and times and a similar merchant or note, best match first. Defaults to the last 30 days.
+// @Tags         Transactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        account_id query string false "Account ID"
+// @Param        period     query string false "Named period, e.g. this_month or last_7_days"
+// @Param        from       query string false "From Date (YYYY-MM-DD)"
+// @Param        to         query string false "To Date (YYYY-MM-DD), inclusive"
+// @Success      200 {object} models.DuplicateTransactionsResponse
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /transactions/duplicates [get]
+func (h *Handlers) GetDuplicateTransactions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.DuplicateTransactionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	pairs, err := h.TransactionsUsecase.Query.GetDuplicates(ctx, &query.GetDuplicatesQuery{
+		UserID:    userID,
+		AccountID: req.AccountID,
+		Period:    req.Period,
+		From:      req.From,
+		To:        req.To,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	response := models.DuplicateTransactionsResponse{
+		Items: make([]models.DuplicatePair, 0, len(pairs)),
+	}
+	for _, pair := range pairs {
+		response.Items = append(response.Items, models.DuplicatePair{
+			Original:  toTransaction(pair.Original),
+			Duplicate: toTransaction(pair.Duplicate),
+			Score:     pair.Score,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MergeTransactions godoc
+// @Summary      Merges a duplicate into a transaction
+// @Description  Keeps the transaction, deletes the duplicate and reverts its effect on the account balance. Blank merchant, note and category are filled from the duplicate.
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path string                          true "transaction id to keep"
+// @Param        request body models.MergeTransactionsRequest true "request"
+// @Success      200 {object} models.Transaction
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      404 {object} apierr.Response
+// @Router       /transactions/{id}/merge [post]
+func (h *Handlers) MergeTransactions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	trnID := c.Param("id")
+	if trnID == "" {
+		apierr.BadRequest(c, "transaction id is missing")
+		return
+	}
+
+	var req models.MergeTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	trn, err := h.TransactionsUsecase.Command.MergeTransactions(ctx, &command.MergeTransactionsCommand{
+		UserID:        userID,
+		TransactionID: trnID,
+		DuplicateID:   req.DuplicateID,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTransaction(trn))
+}
+
+func toTransaction(trn *entities.Transaction) models.Transaction {
+	transaction := models.Transaction{
+		ID:                   trn.ID.String(),
+		UserID:               trn.UserID.String(),
+		AccountID:            trn.AccountID.String(),
+		Type:                 trn.Type.String(),
+		Status:               trn.Status.String(),
+		Amount:               trn.AmountMajor(),
+		CurrencyCode:         trn.CurrencyCode.String(),
+		OriginalAmount:       pointer.Float64(trn.OriginalAmountMajor()),
+		OriginalCurrencyCode: pointer.String(trn.OriginalCurrencyCode.String()),
+		FxRate:               pointer.Float64(trn.FxRate),
+		Note:                 trn.RowText,
+		Merchant:             trn.Merchant,
+		PerformedAt:          pointer.TimeOrNil(trn.PerformedAt),
+		RejectedAt:           pointer.TimeOrNil(trn.RejectedAt),
+		CreatedAt:            trn.CreatedAt,
+	}
+
+	if trn.Category != nil {
+		transaction.CategoryID = pointer.IntOrNil(trn.Category.ID.Int())
+	}
+
+	if trn.Subcategory != nil {
+		transaction.SubcategoryID = pointer.IntOrNil(trn.Subcategory.ID)
+	}
+
+	return transaction
+}
+
+func toPossibleDuplicates(matches []entities.DuplicateMatch) []models.PossibleDuplicate {
+	var duplicates []models.PossibleDuplicate
+	for _, match := range matches {
+		duplicates = append(duplicates, models.PossibleDuplicate{
+			TransactionID: match.Transaction.ID.String(),
+			Score:         match.Score,
+		})
+	}
+
+	return duplicates
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
+	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/usage/query"
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsage godoc
+// @Summary      Returns the user's LLM usage
+// @Description  Usage of the parse endpoints in the user's current day and month, with the limits of their plan. A zero limit means unlimited.
+// @Tags         Usage
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.Usage
+// @Failure      401 {object} apierr.Response
+// @Router       /usage [get]
+func (h *Handlers) GetUsage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	view, err := h.UsageUsecase.Query.GetUsage(ctx, userID)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.Usage{
+		Plan:  view.Plan.String(),
+		Day:   toPeriodUsage(view.Day),
+		Month: toPeriodUsage(view.Month),
+	})
+}
+
+// GetUsageReport godoc
+// @Summary      Returns LLM usage per user
+// @Description  Sums usage per user over the range, the heaviest users first. Defaults to the current month in UTC.
+// @Tags         Admin
+// @Produce      json
+// @Security     BasicAuth
+// @Param        from  query string false "From Date (YYYY-MM-DD)"
+// @Param        to    query string false "To Date (YYYY-MM-DD), inclusive"
+// @Param        limit query int    false "Max users (default 50)"
+// @Success      200 {array} models.UsageReportRow
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Failure      403 {object} apierr.Response
+// @Router       /admin/usage [get]
+func (h *Handlers) GetUsageReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req models.UsageReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierr.BadRequest(c, "invalid query params", err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	from, to := entities.UsageMonth(now)
+
+	if req.From != "" {
+		date, err := time.Parse(time.DateOnly, req.From)
+		if err != nil {
+			apierr.BadRequest(c, "invalid from date", err.Error())
+			return
+		}
+		from = date
+	}
+
+	if req.To != "" {
+		date, err := time.Parse(time.DateOnly, req.To)
+		if err != nil {
+			apierr.BadRequest(c, "invalid to date", err.Error())
+			return
+		}
+		to = date.AddDate(0, 0, 1)
+	}
+
+	rows, err := h.UsageUsecase.Query.GetUsageReport(ctx, from, to, req.Limit)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	response := make([]models.UsageReportRow, 0, len(rows))
+	for _, row := range rows {
+		response = append(response, models.UsageReportRow{
+			UserID: row.UserID.String(),
+			Plan:   row.Plan.String(),
+			Usage:  toUsageTotals(row.UsageTotals),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func toPeriodUsage(p query.PeriodUsage) models.PeriodUsage {
+	return models.PeriodUsage{
+		From: p.From,
+		To:   p.To,
+		Used: toUsageTotals(p.Totals),
+		Limits: models.UsageLimits{
+			Tokens:       p.Limits.Tokens,
+			AudioSeconds: p.Limits.AudioSeconds,
+			OCRCalls:     p.Limits.OCRCalls,
+		},
+	}
+}
+
+func toUsageTotals(t entities.UsageTotals) models.UsageTotals {
+	return models.UsageTotals{
+		Requests:         t.Requests,
+		PromptTokens:     t.PromptTokens,
+		CompletionTokens: t.CompletionTokens,
+		Tokens:           t.Tokens(),
+		AudioSeconds:     t.AudioSeconds,
+		OCRCalls:         t.OCRCalls,
+	}
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/usecase/users/command"
 	"github.com/gin-gonic/gin"
 	"github.com/shogo82148/pointer"
@@ -103,3 +105,154 @@ func (h *Handlers) UpdateMe(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetNotificationSettings godoc
+// @Summary      Returns notification settings
+// @Description  Lists every alert type with whether it is enabled for the user
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.NotificationSettings
+// @Failure      401 {object} apierr.Response
+// @Router       /users/me/notifications [get]
+func (h *Handlers) GetNotificationSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	settings, err := h.UsersUsecase.Query.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toNotificationSettings(settings))
+}
+
+// UpdateNotificationSettings godoc
+// @Summary      Turns notifications on or off
+// @Description  Only the listed types are changed, e.g. {"settings": {"new_merchant": false}}
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.UpdateNotificationSettingsRequest true "request"
+// @Success      200 {object} models.NotificationSettings
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /users/me/notifications [put]
+func (h *Handlers) UpdateNotificationSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	settings, err := h.UsersUsecase.Command.UpdateNotificationSettings(ctx, &command.UpdateNotificationSettingsCommand{
+		UserID:  userID,
+		Enabled: req.Settings,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toNotificationSettings(settings))
+}
+
+func toNotificationSettings(settings *entities.NotificationSettings) models.NotificationSettings {
+	response := models.NotificationSettings{
+		Settings: make(map[string]bool),
+	}
+	for _, t := range entities.NotificationTypes() {
+		response.Settings[t.String()] = settings.IsEnabled(t)
+	}
+
+	return response
+}
+
+// GetDigestSchedule godoc
+// @Summary      Returns the digest schedule
+// @Description  Weekday and hour, in the user's time zone, the weekly and monthly digests are sent at
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.DigestSchedule
+// @Failure      401 {object} apierr.Response
+// @Router       /users/me/digest [get]
+func (h *Handlers) GetDigestSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	schedule, err := h.UsersUsecase.Query.GetDigestSchedule(ctx, userID)
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDigestSchedule(schedule))
+}
+
+// UpdateDigestSchedule godoc
+// @Summary      Changes the digest schedule
+// @Description  Only the given fields are changed, e.g. {"weekday": "sunday", "hour": 20}. Digests are turned on or off in the notification settings.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.UpdateDigestScheduleRequest true "request"
+// @Success      200 {object} models.DigestSchedule
+// @Failure      400 {object} apierr.Response
+// @Failure      401 {object} apierr.Response
+// @Router       /users/me/digest [put]
+func (h *Handlers) UpdateDigestSchedule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierr.Unauthorized(c, "user context is missing")
+		return
+	}
+
+	var req models.UpdateDigestScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierr.BadRequest(c, "invalid request payload", err.Error())
+		return
+	}
+
+	schedule, err := h.UsersUsecase.Command.UpdateDigestSchedule(ctx, &command.UpdateDigestScheduleCommand{
+		UserID:  userID,
+		Weekday: req.Weekday,
+		Hour:    req.Hour,
+	})
+	if err != nil {
+		apierr.Handle(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDigestSchedule(schedule))
+}
+
+func toDigestSchedule(schedule *entities.DigestSchedule) models.DigestSchedule {
+	return models.DigestSchedule{
+		Weekday: strings.ToLower(schedule.Weekday.String()),
+		Hour:    schedule.Hour,
+	}
+}
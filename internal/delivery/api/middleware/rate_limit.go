@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/delivery/api/apierr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey tells whose budget a request is counted against.
+type RateLimitKey func(c *gin.Context) string
+
+// ByIP counts requests per client address, X-Forwarded-For is only taken
+// from the trusted proxies.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser counts requests per authenticated user, per client address when
+// there is none. It must run after AuthMiddleware.
+func ByUser(c *gin.Context) string {
+	if userID := GetUserID(c); userID != "" {
+		return "user:" + userID
+	}
+	return ByIP(c)
+}
+
+// RateLimit limits the requests of a route group, each group has a budget
+// of its own. Allowed requests get the RateLimit-* headers, denied ones a
+// 429 with Retry-After. Requests pass when the limiter fails, an outage of
+// redis must not take the API down.
+func RateLimit(limiter ports.RateLimiter, group string, limit ports.RateLimit, key RateLimitKey) gin.HandlerFunc {
+	policy := strconv.Itoa(limit.Limit) + ";w=" + strconv.Itoa(seconds(limit.Window))
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		result, err := limiter.Allow(ctx, group+":"+key(c), limit)
+		if err != nil {
+			slog.WarnContext(ctx, "rate limiter failed",
+				slog.String("group", group),
+				slog.String("error.message", err.Error()),
+			)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+
+		if !result.Allowed {
+			retryAfter := seconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			apierr.Handle(c, nil,
+				apierr.WithStatus(http.StatusTooManyRequests),
+				apierr.WithCode(apierr.CodeTooManyRequests),
+				apierr.WithMessage("Too many requests, try again later"),
+				apierr.WithDetail("group", group),
+				apierr.WithDetail("retry_after", retryAfter),
+			)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds rounds up, a client waiting for the rounded down value would be
+// denied again.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
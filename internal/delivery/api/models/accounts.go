@@ -23,3 +23,8 @@ type UpdateAccountRequest struct {
 	Name      *string `json:"name"`
 	IsDefault *bool   `json:"is_default"`
 }
+
+type CashFlowForecastRequest struct {
+	AccountID string `form:"account_id"`
+	Days      int    `form:"days"`
+}
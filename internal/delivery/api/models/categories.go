@@ -22,12 +22,39 @@ type Subcategory struct {
 }
 
 type CreateCategoryRequest struct {
-	Name  string `json:"name"`
-	Emoji string `json:"emoji"`
+	Name  string            `json:"name"`
+	Names map[string]string `json:"names"`
+	Emoji string            `json:"emoji"`
 }
 
 type CreateSubcategoryRequest struct {
-	CategoryID int    `json:"category_id"`
-	Name       string `json:"name"`
-	Emoji      string `json:"emoji"`
+	CategoryID int               `json:"category_id"`
+	Name       string            `json:"name"`
+	Names      map[string]string `json:"names"`
+	Emoji      string            `json:"emoji"`
+}
+
+// UpdateCategoryRequest renames a user category. Name sets every language,
+// names overrides single ones, keyed by language code.
+type UpdateCategoryRequest struct {
+	Name  string            `json:"name"`
+	Names map[string]string `json:"names"`
+	Emoji string            `json:"emoji"`
+}
+
+type ReorderItem struct {
+	ID       int `json:"id" binding:"required"`
+	Position int `json:"position"`
+}
+
+type ReorderRequest struct {
+	Items []ReorderItem `json:"items" binding:"required,min=1,dive"`
+}
+
+type MergeCategoryRequest struct {
+	TargetCategoryID int `json:"target_category_id" binding:"required"`
+}
+
+type CategoryTargetQuery struct {
+	TargetCategoryID *int `form:"target_id"`
 }
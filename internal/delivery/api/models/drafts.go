@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// TransactionDraft is a parsed transaction waiting to be confirmed. It does
+// not affect the balance until then.
+type TransactionDraft struct {
+	ID                   string     `json:"id"`
+	AccountID            *string    `json:"account_id,omitempty"`
+	CategoryID           *int       `json:"category_id,omitempty"`
+	SubcategoryID        *int       `json:"subcategory_id,omitempty"`
+	Type                 string     `json:"type"`
+	Status               string     `json:"status"`
+	Source               string     `json:"source"`
+	Amount               float64    `json:"amount"`
+	CurrencyCode         string     `json:"currency_code"`
+	OriginalAmount       *float64   `json:"original_amount,omitempty"`
+	OriginalCurrencyCode *string    `json:"original_currency_code,omitempty"`
+	FxRate               *float64   `json:"fx_rate,omitempty"`
+	Note                 string     `json:"note,omitempty"`
+	Merchant             string     `json:"merchant,omitempty"`
+	Confidence           float64    `json:"confidence"`
+	PerformedAt          *time.Time `json:"performed_at,omitempty"`
+	TransactionID        *string    `json:"transaction_id,omitempty"`
+	ExpiresAt            time.Time  `json:"expires_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+// UpdateDraftRequest only changes the fields that are sent. Amount is in the
+// user's currency and drops the converted original amount.
+type UpdateDraftRequest struct {
+	AccountID     *string    `json:"account_id"`
+	CategoryID    *int       `json:"category_id"`
+	SubcategoryID *int       `json:"subcategory_id"`
+	Type          *string    `json:"type" binding:"omitempty,oneof=deposit withdrawal"`
+	Amount        *float64   `json:"amount" binding:"omitempty,gt=0"`
+	Note          *string    `json:"note"`
+	Merchant      *string    `json:"merchant"`
+	PerformedAt   *time.Time `json:"performed_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// ImportSession is an uploaded statement with its detected format and
+// column mapping. Mapping values are zero-based column indexes.
+type ImportSession struct {
+	ID               string         `json:"id"`
+	AccountID        string         `json:"account_id"`
+	Source           string         `json:"source"`
+	Filename         string         `json:"filename,omitempty"`
+	Status           string         `json:"status"`
+	Encoding         string         `json:"encoding"`
+	Delimiter        string         `json:"delimiter"`
+	DateFormat       string         `json:"date_format,omitempty"`
+	DecimalSeparator string         `json:"decimal_separator"`
+	Header           []string       `json:"header"`
+	Mapping          map[string]int `json:"mapping"`
+	RowCount         int            `json:"row_count"`
+	ImportedCount    int            `json:"imported_count"`
+	DateFormats      []string       `json:"date_formats"`
+	CreatedAt        time.Time      `json:"created_at"`
+	CommittedAt      *time.Time     `json:"committed_at,omitempty"`
+}
+
+type UploadImportRequest struct {
+	AccountID string `form:"account_id" binding:"required"`
+}
+
+type SetImportMappingRequest struct {
+	Mapping          map[string]int `json:"mapping" binding:"required"`
+	DateFormat       string         `json:"date_format"`
+	DecimalSeparator string         `json:"decimal_separator"`
+}
+
+type PreviewImportRequest struct {
+	Limit  int `form:"limit"`
+	Offset int `form:"offset"`
+}
+
+type CommitImportResponse struct {
+	Session            ImportSession        `json:"session"`
+	Skipped            []ImportSkippedRow   `json:"skipped"`
+	PossibleDuplicates []ImportDuplicateRow `json:"possible_duplicates"`
+}
+
+// ImportDuplicateRow is an imported row that looks like a transaction the
+// user recorded before, they can be merged.
+type ImportDuplicateRow struct {
+	Line          int     `json:"line"`
+	TransactionID string  `json:"transaction_id"`
+	DuplicateOf   string  `json:"duplicate_of"`
+	Score         float64 `json:"score"`
+}
+
+type ImportSkippedRow struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
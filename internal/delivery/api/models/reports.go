@@ -0,0 +1,5 @@
+package models
+
+type MonthlyReportRequest struct {
+	Month string `form:"month"`
+}
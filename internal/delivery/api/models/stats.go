@@ -0,0 +1,17 @@
+package models
+
+type TimeseriesRequest struct {
+	Bucket     string `form:"bucket"`
+	Period     string `form:"period"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+	AccountID  string `form:"account_id"`
+	ByCategory bool   `form:"by_category"`
+}
+
+type StatsComparisonRequest struct {
+	Period    string `form:"period"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+	AccountID string `form:"account_id"`
+}
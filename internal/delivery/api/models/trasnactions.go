@@ -17,22 +17,55 @@ type Transaction struct {
 	OriginalCurrencyCode *string    `json:"original_currency_code,omitempty"`
 	FxRate               *float64   `json:"fx_rate,omitempty"`
 	Note                 string     `json:"note,omitempty"`
+	Merchant             string     `json:"merchant,omitempty"`
 	PerformedAt          *time.Time `json:"performed_at,omitempty"`
 	RejectedAt           *time.Time `json:"rejected_at,omitempty"`
 	CreatedAt            time.Time  `json:"created_at"`
+	// PossibleDuplicates is only set right after the transaction is created.
+	PossibleDuplicates []PossibleDuplicate `json:"possible_duplicates,omitempty"`
 }
 
-// ParseTransactionRequest represents payload that is parsed by AI
+type PossibleDuplicate struct {
+	TransactionID string  `json:"transaction_id"`
+	Score         float64 `json:"score"`
+}
+
+type DuplicateTransactionsRequest struct {
+	AccountID string `form:"account_id"`
+	Period    string `form:"period"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+}
+
+type DuplicatePair struct {
+	Original  Transaction `json:"original"`
+	Duplicate Transaction `json:"duplicate"`
+	Score     float64     `json:"score"`
+}
+
+type DuplicateTransactionsResponse struct {
+	Items []DuplicatePair `json:"items"`
+}
+
+type MergeTransactionsRequest struct {
+	DuplicateID string `json:"duplicate_id" binding:"required"`
+}
+
+// ParseTransactionRequest represents payload that is parsed by AI. With
+// SaveDraft the parsed transactions are also kept as drafts to confirm later.
 type ParseTextRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content   string `json:"content" binding:"required"`
+	SaveDraft bool   `json:"save_draft"`
 }
 
 type ParseAudioRequest struct {
-	FileURL string `json:"file_url" binding:"required"`
+	FileURL   string `json:"file_url" binding:"required"`
+	SaveDraft bool   `json:"save_draft"`
 }
 
 type ParseImageRequest struct {
-	ImageURL string `json:"image_url" binding:"required"`
+	ImageURL  string `json:"image_url" binding:"required"`
+	SaveDraft bool   `json:"save_draft"`
 }
 
 type CreateTransactionRequest struct {
@@ -45,6 +78,7 @@ type CreateTransactionRequest struct {
 	OriginalCurrencyCode *string    `json:"original_currency_code,omitempty"`
 	FxRate               *float64   `json:"fx_rate,omitempty"`
 	Note                 string     `json:"note"`
+	Merchant             string     `json:"merchant"`
 	PerformedAt          *time.Time `json:"performed_at"`
 }
 
@@ -59,6 +93,7 @@ type UpdateTransactionRequest struct {
 	OriginalCurrencyCode *string    `json:"original_currency_code,omitempty"`
 	FxRate               *float64   `json:"fx_rate,omitempty"`
 	Note                 string     `json:"note"`
+	Merchant             string     `json:"merchant"`
 	PerformedAt          *time.Time `json:"performed_at"`
 }
 
@@ -66,3 +101,35 @@ type TransactionsResponse struct {
 	Items      []Transaction      `json:"items"`
 	Pagination PaginationResponse `json:"pagination"`
 }
+
+type TransactionSearchHighlights struct {
+	Note     string `json:"note,omitempty"`
+	Merchant string `json:"merchant,omitempty"`
+}
+
+// TransactionSearchItem is a transaction matched by full-text search
+type TransactionSearchItem struct {
+	Transaction
+	Rank       float64                     `json:"rank"`
+	Highlights TransactionSearchHighlights `json:"highlights"`
+}
+
+type TransactionSearchRequest struct {
+	Query string `form:"q" binding:"required"`
+	PaginationRequest
+}
+
+type TransactionSearchResponse struct {
+	Items      []TransactionSearchItem `json:"items"`
+	Pagination PaginationResponse      `json:"pagination"`
+}
+
+type ExportTransactionsRequest struct {
+	Format    string `form:"format"`
+	Columns   string `form:"columns"`
+	AccountID string `form:"account_id"`
+	Type      string `form:"type"`
+	Period    string `form:"period"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type UsageTotals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Tokens           int64   `json:"tokens"`
+	AudioSeconds     float64 `json:"audio_seconds"`
+	OCRCalls         int64   `json:"ocr_calls"`
+}
+
+// UsageLimits caps usage over a period, zero means unlimited.
+type UsageLimits struct {
+	Tokens       int64 `json:"tokens"`
+	AudioSeconds int64 `json:"audio_seconds"`
+	OCRCalls     int64 `json:"ocr_calls"`
+}
+
+type PeriodUsage struct {
+	From   time.Time   `json:"from"`
+	To     time.Time   `json:"to"`
+	Used   UsageTotals `json:"used"`
+	Limits UsageLimits `json:"limits"`
+}
+
+type Usage struct {
+	Plan  string      `json:"plan"`
+	Day   PeriodUsage `json:"day"`
+	Month PeriodUsage `json:"month"`
+}
+
+type UsageReportRequest struct {
+	From  string `form:"from"`
+	To    string `form:"to"`
+	Limit int    `form:"limit" binding:"omitempty,gt=0"`
+}
+
+type UsageReportRow struct {
+	UserID string      `json:"user_id"`
+	Plan   string      `json:"plan"`
+	Usage  UsageTotals `json:"usage"`
+}
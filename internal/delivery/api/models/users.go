@@ -20,3 +20,24 @@ type UpdateUserRequest struct {
 	LanguageCode *string `json:"language_code"`
 	Timezone     *string `json:"timezone"`
 }
+
+// NotificationSettings maps every notification type to whether it is on.
+type NotificationSettings struct {
+	Settings map[string]bool `json:"settings"`
+}
+
+type UpdateNotificationSettingsRequest struct {
+	Settings map[string]bool `json:"settings" binding:"required"`
+}
+
+// DigestSchedule is when digests are sent in the user's time zone. The
+// weekly digest goes out on Weekday, the monthly one on the 1st.
+type DigestSchedule struct {
+	Weekday string `json:"weekday"`
+	Hour    int    `json:"hour"`
+}
+
+type UpdateDigestScheduleRequest struct {
+	Weekday *string `json:"weekday"`
+	Hour    *int    `json:"hour"`
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/AsaHero/e-wallet/internal/delivery/api/handlers"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/middleware"
 	"github.com/AsaHero/e-wallet/internal/delivery/api/validation"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
@@ -33,6 +35,12 @@ import (
 func NewRouter(opts *delivery.Options) *gin.Engine {
 	router := gin.New()
 
+	// X-Forwarded-For is only read from these, the rate limits count
+	// clients by the address it yields
+	if err := router.SetTrustedProxies(opts.Config.Server.TrustedProxies); err != nil {
+		opts.Logger.ErrorContext(context.Background(), "failed to set trusted proxies", err)
+	}
+
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
 
@@ -70,39 +78,72 @@ func NewRouter(opts *delivery.Options) *gin.Engine {
 		TransactionsUsecase: opts.TransactionsUsecase,
 		CategoriesUsecase:   opts.CategoriesUsecase,
 		ParserUsecase:       opts.ParserUsecase,
+		DraftsUsecase:       opts.DraftsUsecase,
+		ImportsUsecase:      opts.ImportsUsecase,
+		ReportsUsecase:      opts.ReportsUsecase,
+		UsageUsecase:        opts.UsageUsecase,
 	}
 
+	limits := opts.Config.RateLimit
 	// API routes
 	api := router.Group("/api")
 	{
 		// Authentication (no auth required)
-		api.POST("/auth/telegram", h.AuthTelegram)
+		api.POST("/auth/telegram", middleware.RateLimit(opts.RateLimiter, "auth", ports.RateLimit(limits.Auth), middleware.ByIP), h.AuthTelegram)
 
 		// Protected routes
 		protected := api.Group("")
 		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.RateLimit(opts.RateLimiter, "api", ports.RateLimit(limits.API), middleware.ByUser))
 		{
 			// User routes
 			protected.GET("/users/me", h.GetMe)
 			protected.PATCH("/users/me", h.UpdateMe)
+			protected.GET("/users/me/notifications", h.GetNotificationSettings)
+			protected.PUT("/users/me/notifications", h.UpdateNotificationSettings)
+			protected.GET("/users/me/digest", h.GetDigestSchedule)
+			protected.PUT("/users/me/digest", h.UpdateDigestSchedule)
 
 			// Account routes
 			protected.GET("/accounts", h.GetAccounts)
 			protected.POST("/accounts", h.CreateAccount)
+			protected.GET("/accounts/forecast", h.GetCashFlowForecast)
 			protected.PATCH("/accounts/:id", h.UpdateAccount)
 			protected.DELETE("/accounts/:id", h.DeleteAccount)
 
-			// Parsers routes
-			protected.POST("/parse/text", h.ParseText)
-			protected.POST("/parse/voice", h.ParseVoice)
-			protected.POST("/parse/image", h.ParseImage)
+			// Parsers routes, each call costs a model request
+			parse := protected.Group("/parse")
+			parse.Use(middleware.RateLimit(opts.RateLimiter, "parse", ports.RateLimit(limits.Parse), middleware.ByUser))
+			{
+				parse.POST("/text", h.ParseText)
+				parse.POST("/voice", h.ParseVoice)
+				parse.POST("/image", h.ParseImage)
+			}
+
+			// Draft routes
+			protected.GET("/drafts", h.GetDrafts)
+			protected.PATCH("/drafts/:id", h.UpdateDraft)
+			protected.POST("/drafts/:id/confirm", h.ConfirmDraft)
+			protected.POST("/drafts/:id/reject", h.RejectDraft)
 
 			// Transaction routes
 			protected.POST("/transactions", h.CreateTransaction)
 			protected.GET("/transactions", h.GetTransactions)
+			protected.GET("/transactions/search", h.SearchTransactions)
+			protected.GET("/transactions/export", h.ExportTransactions)
+			protected.GET("/transactions/duplicates", h.GetDuplicateTransactions)
 			protected.GET("/transactions/:id", h.GetTransaction)
 			protected.PUT("/transactions/:id", h.UpdateTransaction)
 			protected.DELETE("/transactions/:id", h.DeleteTransaction)
+			protected.POST("/transactions/:id/merge", h.MergeTransactions)
+
+			// Import routes
+			protected.POST("/imports", h.UploadImport)
+			protected.GET("/imports/:id", h.GetImport)
+			protected.PUT("/imports/:id/mapping", h.SetImportMapping)
+			protected.GET("/imports/:id/preview", h.PreviewImport)
+			protected.POST("/imports/:id/commit", h.CommitImport)
+			protected.POST("/imports/:id/rollback", h.RollbackImport)
 
 			// Category routes
 			protected.GET("/categories", h.GetCategories)
@@ -111,9 +152,38 @@ func NewRouter(opts *delivery.Options) *gin.Engine {
 			protected.POST("/subcategories", h.CreateSubcategory)
 			protected.DELETE("/categories/:id", h.DeleteCategory)
 			protected.DELETE("/subcategories/:id", h.DeleteSubcategory)
+			protected.PUT("/categories/order", h.ReorderCategories)
+			protected.PUT("/subcategories/order", h.ReorderSubcategories)
+			protected.PUT("/categories/:id", h.UpdateCategory)
+			protected.PUT("/subcategories/:id", h.UpdateSubcategory)
+			protected.POST("/categories/:id/hide", h.HideCategory)
+			protected.POST("/categories/:id/unhide", h.UnhideCategory)
+			protected.POST("/subcategories/:id/hide", h.HideSubcategory)
+			protected.POST("/subcategories/:id/unhide", h.UnhideSubcategory)
+			protected.GET("/categories/:id/merge-preview", h.GetCategoryMergePreview)
+			protected.POST("/categories/:id/merge", h.MergeCategory)
 
 			// Stats routes
 			protected.GET("/stats/summary", h.GetStats)
+			protected.GET("/stats/timeseries", h.GetTimeseries)
+			protected.GET("/stats/compare", h.GetStatsComparison)
+
+			// Report routes
+			protected.GET("/reports/monthly", h.GetMonthlyReport)
+
+			// Usage routes
+			protected.GET("/usage", h.GetUsage)
+		}
+
+		// Admin routes, served only once a password is configured
+		if opts.Config.Admin.Password != "" {
+			admin := api.Group("/admin")
+			// credentials are checked after, guessing them counts too
+			admin.Use(middleware.RateLimit(opts.RateLimiter, "admin", ports.RateLimit(limits.Admin), middleware.ByIP))
+			admin.Use(middleware.AdminAuthorizer(opts.Config.Admin.Username, opts.Config.Admin.Password))
+			{
+				admin.GET("/usage", h.GetUsageReport)
+			}
 		}
 	}
 
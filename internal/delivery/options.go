@@ -4,9 +4,15 @@ import (
 	"github.com/AsaHero/e-wallet/internal/delivery/api/validation"
 	"github.com/AsaHero/e-wallet/internal/usecase/accounts"
 	"github.com/AsaHero/e-wallet/internal/usecase/categories"
+	"github.com/AsaHero/e-wallet/internal/usecase/drafts"
+	"github.com/AsaHero/e-wallet/internal/usecase/imports"
 	"github.com/AsaHero/e-wallet/internal/usecase/notifications"
 	"github.com/AsaHero/e-wallet/internal/usecase/parser"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/internal/usecase/reports"
+	"github.com/AsaHero/e-wallet/internal/usecase/rollups"
 	"github.com/AsaHero/e-wallet/internal/usecase/transactions"
+	"github.com/AsaHero/e-wallet/internal/usecase/usage"
 	"github.com/AsaHero/e-wallet/internal/usecase/users"
 	"github.com/AsaHero/e-wallet/pkg/config"
 	"github.com/AsaHero/e-wallet/pkg/logger"
@@ -21,5 +27,11 @@ type Options struct {
 	TransactionsUsecase *transactions.Module
 	CategoriesUsecase   *categories.Module
 	ParserUsecase       *parser.Module
+	DraftsUsecase       *drafts.Module
+	ImportsUsecase      *imports.Module
+	ReportsUsecase      *reports.Module
+	RollupsUsecase      *rollups.Module
 	NotificationUsecase *notifications.Module
+	UsageUsecase        *usage.Module
+	RateLimiter         ports.RateLimiter
 }
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (h *Handler) AnomalyDetect(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "AnomalyDetect", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	var payload tasks.AnomalyDetectPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return err
+	}
+
+	err := h.NotificationUsecase.AnomalyDetect(ctx, payload.UserID, payload.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (h *Handler) DigestSchedule(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "DigestSchedule", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	return h.NotificationUsecase.DigestSchedule(ctx)
+}
+
+func (h *Handler) DigestSend(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "DigestSend", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	var payload tasks.DigestSendPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return err
+	}
+
+	err := h.NotificationUsecase.DigestSend(ctx, payload.UserID, payload.Period, payload.Date)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (h *Handler) DraftsCleanup(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "DraftsCleanup", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	return h.DraftsUsecase.Command.CleanupDrafts(ctx)
+}
@@ -1,7 +1,15 @@
 package handlers
 
-import "github.com/AsaHero/e-wallet/internal/usecase/notifications"
+import (
+	"github.com/AsaHero/e-wallet/internal/usecase/drafts"
+	"github.com/AsaHero/e-wallet/internal/usecase/notifications"
+	"github.com/AsaHero/e-wallet/internal/usecase/reports"
+	"github.com/AsaHero/e-wallet/internal/usecase/rollups"
+)
 
 type Handler struct {
 	NotificationUsecase *notifications.Module
+	ReportsUsecase      *reports.Module
+	RollupsUsecase      *rollups.Module
+	DraftsUsecase       *drafts.Module
 }
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (h *Handler) MonthlyReportSchedule(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "MonthlyReportSchedule", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	return h.ReportsUsecase.ScheduleMonthlyReports(ctx)
+}
+
+func (h *Handler) MonthlyReportSend(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "MonthlyReportSend", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	var payload tasks.MonthlyReportSendPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return err
+	}
+
+	err := h.ReportsUsecase.SendMonthlyReport(ctx, payload.UserID, payload.Month)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
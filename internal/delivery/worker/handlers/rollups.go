@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func (h *Handler) RollupsVerify(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "RollupsVerify", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	return h.RollupsUsecase.VerifyRollups(ctx)
+}
+
+func (h *Handler) RollupsRebuild(ctx context.Context, task *asynq.Task) error {
+	ctx, end := otlp.Start(ctx, otel.Tracer("worker"), "RollupsRebuild", attribute.String("task_type", task.Type()))
+	defer func() { end(nil) }()
+
+	var payload tasks.RollupsRebuildPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return err
+	}
+
+	return h.RollupsUsecase.RebuildRollups(ctx, payload.UserID)
+}
@@ -10,11 +10,22 @@ import (
 func NewRouter(opts *delivery.Options) *asynq.ServeMux {
 	handler := handlers.Handler{
 		NotificationUsecase: opts.NotificationUsecase,
+		ReportsUsecase:      opts.ReportsUsecase,
+		RollupsUsecase:      opts.RollupsUsecase,
+		DraftsUsecase:       opts.DraftsUsecase,
 	}
 
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(tasks.RecordReminderCalculateTaskName, handler.RecordReminderCalculate)
 	mux.HandleFunc(tasks.RecordReminderSendTaskName, handler.RecordReminderSend)
+	mux.HandleFunc(tasks.AnomalyDetectTaskName, handler.AnomalyDetect)
+	mux.HandleFunc(tasks.DigestScheduleTaskName, handler.DigestSchedule)
+	mux.HandleFunc(tasks.DigestSendTaskName, handler.DigestSend)
+	mux.HandleFunc(tasks.MonthlyReportScheduleTaskName, handler.MonthlyReportSchedule)
+	mux.HandleFunc(tasks.MonthlyReportSendTaskName, handler.MonthlyReportSend)
+	mux.HandleFunc(tasks.RollupsVerifyTaskName, handler.RollupsVerify)
+	mux.HandleFunc(tasks.RollupsRebuildTaskName, handler.RollupsRebuild)
+	mux.HandleFunc(tasks.DraftsCleanupTaskName, handler.DraftsCleanup)
 
 	return mux
 }
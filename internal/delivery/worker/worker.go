@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"github.com/AsaHero/e-wallet/internal/tasks"
 	"github.com/AsaHero/e-wallet/pkg/config"
 	"github.com/hibiken/asynq"
 )
@@ -23,3 +24,33 @@ func NewWorker(cfg *config.Config) *asynq.Server {
 
 	return server
 }
+
+// NewScheduler registers the periodic tasks. Every replica runs one, the
+// tasks are unique so a period is only enqueued once.
+func NewScheduler(cfg *config.Config) (*asynq.Scheduler, error) {
+	scheduler := asynq.NewScheduler(
+		asynq.RedisClientOpt{
+			Addr:     cfg.Redis.Host + ":" + cfg.Redis.Port,
+			Password: cfg.Redis.Password,
+		},
+		nil,
+	)
+
+	if _, err := scheduler.Register(tasks.MonthlyReportScheduleCron, tasks.NewMonthlyReportScheduleTask()); err != nil {
+		return nil, err
+	}
+
+	if _, err := scheduler.Register(tasks.DigestScheduleCron, tasks.NewDigestScheduleTask()); err != nil {
+		return nil, err
+	}
+
+	if _, err := scheduler.Register(tasks.RollupsVerifyCron, tasks.NewRollupsVerifyTask()); err != nil {
+		return nil, err
+	}
+
+	if _, err := scheduler.Register(tasks.DraftsCleanupCron, tasks.NewDraftsCleanupTask()); err != nil {
+		return nil, err
+	}
+
+	return scheduler, nil
+}
@@ -0,0 +1,172 @@
+package entities
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// anomalyMinSamples is how many comparable charges are needed before an
+	// amount can be called unusual.
+	anomalyMinSamples = 5
+	// anomalyMaxScore is the modified z-score above which a charge is
+	// unusual, the usual cut-off for median absolute deviation.
+	anomalyMaxScore = 3.5
+	// anomalyMinRatio keeps small absolute jumps quiet: a charge must also
+	// be at least this many times the median.
+	anomalyMinRatio = 2.0
+	// DuplicateWindow is how close two identical charges must be to look
+	// like a duplicate.
+	DuplicateWindow = 30 * time.Minute
+	// newMerchantMinHistory is how many past expenses are needed to judge
+	// whether a first purchase somewhere is large.
+	newMerchantMinHistory = 10
+	// newMerchantMinRatio is how many times the median expense a first
+	// purchase at a merchant must be to be reported.
+	newMerchantMinRatio = 3.0
+)
+
+// Anomaly describes why a transaction looks unusual.
+type Anomaly struct {
+	Type NotificationType
+	// Baseline is the median of the comparable charges, zero for duplicates.
+	Baseline int64
+	Ratio    float64
+	// Duplicate is the earlier charge the transaction repeats.
+	Duplicate *Transaction
+}
+
+// DetectAnomalies compares an expense with the user's history. The history
+// may contain the transaction itself, it is skipped.
+func DetectAnomalies(t *Transaction, history []*Transaction) []Anomaly {
+	if t.Type != Withdrawal {
+		return nil
+	}
+
+	var (
+		duplicate  *Transaction
+		byMerchant []int64
+		byCategory []int64
+		all        []int64
+		seenBefore bool
+		anomalies  []Anomaly
+	)
+	merchant := normalizeMerchant(t.Merchant)
+
+	for _, h := range history {
+		if h.ID == t.ID || h.Type != Withdrawal {
+			continue
+		}
+
+		if duplicate == nil && isDuplicateOf(t, h) {
+			duplicate = h
+			anomalies = append(anomalies, Anomaly{Type: AlertDuplicate, Duplicate: h})
+		}
+
+		// Only earlier charges count as a baseline.
+		if !h.OccurredAt().Before(t.OccurredAt()) {
+			continue
+		}
+
+		all = append(all, h.AmountMinor())
+		if merchant != "" && normalizeMerchant(h.Merchant) == merchant {
+			byMerchant = append(byMerchant, h.AmountMinor())
+			seenBefore = true
+		}
+		if sameCategory(t, h) {
+			byCategory = append(byCategory, h.AmountMinor())
+		}
+	}
+
+	samples := byMerchant
+	if len(samples) < anomalyMinSamples {
+		samples = byCategory
+	}
+	if len(samples) >= anomalyMinSamples {
+		median, score := robustScore(t.AmountMinor(), samples)
+		if median > 0 {
+			ratio := float64(t.AmountMinor()) / float64(median)
+			if score > anomalyMaxScore && ratio >= anomalyMinRatio {
+				anomalies = append(anomalies, Anomaly{Type: AlertUnusualAmount, Baseline: median, Ratio: ratio})
+			}
+		}
+	}
+
+	if merchant != "" && !seenBefore && len(all) >= newMerchantMinHistory {
+		median := medianOf(all)
+		if median > 0 {
+			ratio := float64(t.AmountMinor()) / float64(median)
+			if ratio >= newMerchantMinRatio {
+				anomalies = append(anomalies, Anomaly{Type: AlertNewMerchant, Baseline: median, Ratio: ratio})
+			}
+		}
+	}
+
+	return anomalies
+}
+
+func isDuplicateOf(t, h *Transaction) bool {
+	if h.AccountID != t.AccountID || h.AmountMinor() != t.AmountMinor() {
+		return false
+	}
+	if normalizeMerchant(h.Merchant) != normalizeMerchant(t.Merchant) || !sameCategory(t, h) {
+		return false
+	}
+
+	diff := t.OccurredAt().Sub(h.OccurredAt())
+	return diff.Abs() <= DuplicateWindow
+}
+
+func sameCategory(a, b *Transaction) bool {
+	if a.Category == nil || b.Category == nil {
+		return a.Category == nil && b.Category == nil
+	}
+	return a.Category.ID == b.Category.ID
+}
+
+func normalizeMerchant(merchant string) string {
+	return strings.ToLower(strings.TrimSpace(merchant))
+}
+
+// robustScore returns the median of samples and the modified z-score of x,
+// based on the median absolute deviation. When most samples are equal the
+// deviation is zero and any larger amount scores as infinitely unusual.
+func robustScore(x int64, samples []int64) (int64, float64) {
+	median := medianOf(samples)
+
+	deviations := make([]int64, len(samples))
+	for i, s := range samples {
+		d := s - median
+		if d < 0 {
+			d = -d
+		}
+		deviations[i] = d
+	}
+	mad := medianOf(deviations)
+
+	if mad == 0 {
+		if x > median {
+			return median, math.Inf(1)
+		}
+		return median, 0
+	}
+
+	return median, 0.6745 * float64(x-median) / float64(mad)
+}
+
+func medianOf(values []int64) int64 {
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,10 +23,9 @@ type Category struct {
 	ID        CategoryID
 	UserID    uuid.UUID
 	Position  int
-	NameEN    string
-	NameRU    string
-	NameUZ    string
+	Names     Translations
 	Emoji     string
+	Hidden    bool // per user, resolved from preferences like Position
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -44,24 +44,78 @@ func NewUserCategory(
 
 	return &Category{
 		UserID: userID,
-		NameEN: name,
-		NameRU: name,
-		NameUZ: name,
+		Names:  Translations{DefaultLanguage: name},
 		Emoji:  emoji,
 	}, nil
 }
 
 func (c *Category) GetName(lang Language) string {
-	switch lang {
-	case EN:
-		return c.NameEN
-	case RU:
-		return c.NameRU
-	case UZ:
-		return c.NameUZ
-	default:
-		return c.NameEN
+	return c.Names.Get(lang)
+}
+
+// IsSystem reports whether the category is a seeded one shared by all users.
+func (c *Category) IsSystem() bool {
+	return c.UserID == uuid.Nil
+}
+
+// IsVisibleTo reports whether the user can see the category: either a system
+// one or one the user created.
+func (c *Category) IsVisibleTo(userID uuid.UUID) bool {
+	return c.IsSystem() || c.UserID == userID
+}
+
+// Update renames the category and changes its emoji. A non-empty name replaces
+// every translation, the same way a new user category is created, while
+// localized names only replace the language they belong to. An empty emoji
+// keeps the current one.
+func (c *Category) Update(name string, localized map[Language]string, emoji string) error {
+	if c.IsSystem() {
+		return errors.New("system category cannot be modified")
+	}
+
+	if name = strings.TrimSpace(name); name != "" {
+		c.Names = Translations{DefaultLanguage: name}
+	}
+
+	if err := c.Localize(localized); err != nil {
+		return err
+	}
+
+	if emoji != "" {
+		c.Emoji = emoji
 	}
+
+	c.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// Localize replaces the names of the given languages, skipping empty ones.
+func (c *Category) Localize(names map[Language]string) error {
+	for lang, value := range names {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !lang.IsSupported() {
+			return errors.New("unsupported language")
+		}
+
+		if c.Names == nil {
+			c.Names = make(Translations)
+		}
+		c.Names[lang] = value
+	}
+
+	return nil
+}
+
+// CategoryPosition is a single entry of a user's bulk reorder request. It is
+// shared by categories and subcategories.
+type CategoryPosition struct {
+	ID       int
+	Position int
 }
 
 // Repository
@@ -70,4 +124,6 @@ type CategoryRepository interface {
 	FindAll(ctx context.Context, userID uuid.UUID) ([]*Category, error)
 	FindByID(ctx context.Context, id int) (*Category, error)
 	Delete(ctx context.Context, userID uuid.UUID, id int) error
+	SetPositions(ctx context.Context, userID uuid.UUID, positions []CategoryPosition) error
+	SetHidden(ctx context.Context, userID uuid.UUID, id int, hidden bool) error
 }
@@ -0,0 +1,214 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// digestTopCategories is how many categories a digest lists.
+const digestTopCategories = 3
+
+type DigestPeriod string
+
+const (
+	DigestPeriodWeekly  DigestPeriod = "weekly"
+	DigestPeriodMonthly DigestPeriod = "monthly"
+)
+
+func (p DigestPeriod) String() string {
+	return string(p)
+}
+
+func ParseDigestPeriod(s string) (DigestPeriod, error) {
+	switch DigestPeriod(s) {
+	case DigestPeriodWeekly, DigestPeriodMonthly:
+		return DigestPeriod(s), nil
+	}
+	return "", errors.New("unknown digest period")
+}
+
+// NotificationType is the setting that turns the digest on or off.
+func (p DigestPeriod) NotificationType() NotificationType {
+	if p == DigestPeriodMonthly {
+		return DigestMonthly
+	}
+	return DigestWeekly
+}
+
+// Bounds returns the period that ends at the start of the local day of to,
+// and the one before it to compare with. A monthly digest sent on the 1st
+// covers the previous calendar month.
+func (p DigestPeriod) Bounds(to time.Time) (from, previousFrom, end time.Time) {
+	end = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location())
+	if p == DigestPeriodMonthly {
+		end = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location())
+		from = end.AddDate(0, -1, 0)
+		return from, from.AddDate(0, -1, 0), end
+	}
+	from = end.AddDate(0, 0, -7)
+	return from, from.AddDate(0, 0, -7), end
+}
+
+// DigestSchedule is when the user wants their digests, in their own time
+// zone. The weekly digest goes out on Weekday, the monthly one on the 1st,
+// both at Hour.
+type DigestSchedule struct {
+	UserID    uuid.UUID
+	Weekday   time.Weekday
+	Hour      int
+	UpdatedAt time.Time
+}
+
+// NewDigestSchedule returns the default, Monday morning.
+func NewDigestSchedule(userID uuid.UUID) *DigestSchedule {
+	return &DigestSchedule{
+		UserID:  userID,
+		Weekday: time.Monday,
+		Hour:    9,
+	}
+}
+
+func (s *DigestSchedule) Update(weekday time.Weekday, hour int) error {
+	if weekday < time.Sunday || weekday > time.Saturday {
+		return errors.New("weekday must be between 0 and 6")
+	}
+	if hour < 0 || hour > 23 {
+		return errors.New("hour must be between 0 and 23")
+	}
+
+	s.Weekday = weekday
+	s.Hour = hour
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Due lists the digests to send at the local time now. now is expected to
+// be in the user's location.
+func (s *DigestSchedule) Due(now time.Time) []DigestPeriod {
+	if now.Hour() != s.Hour {
+		return nil
+	}
+
+	var periods []DigestPeriod
+	if now.Weekday() == s.Weekday {
+		periods = append(periods, DigestPeriodWeekly)
+	}
+	if now.Day() == 1 {
+		periods = append(periods, DigestPeriodMonthly)
+	}
+	return periods
+}
+
+var weekdays = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+
+// ParseWeekday reads an English weekday name such as "monday".
+func ParseWeekday(s string) (time.Weekday, error) {
+	for _, d := range weekdays {
+		if strings.EqualFold(d.String(), s) {
+			return d, nil
+		}
+	}
+	return 0, errors.New("unknown weekday")
+}
+
+type DigestCategory struct {
+	Category *Category
+	Total    int64
+}
+
+// Digest sums up the expenses of [From, To) and compares them with the
+// period before.
+type Digest struct {
+	Period          DigestPeriod
+	From            time.Time
+	To              time.Time
+	Expense         int64
+	Income          int64
+	Count           int
+	PreviousExpense int64
+	TopCategories   []DigestCategory
+	BiggestPurchase *Transaction
+}
+
+// Change is the relative change of the expenses against the previous
+// period. It is false when there is nothing to compare with.
+func (d *Digest) Change() (float64, bool) {
+	if d.PreviousExpense == 0 {
+		return 0, false
+	}
+	return float64(d.Expense-d.PreviousExpense) / float64(d.PreviousExpense), true
+}
+
+// BuildDigest aggregates the completed transactions performed from
+// previousFrom to to, of which [from, to) is the digest period.
+func BuildDigest(period DigestPeriod, previousFrom, from, to time.Time, transactions []*Transaction) *Digest {
+	digest := &Digest{
+		Period: period,
+		From:   from,
+		To:     to,
+	}
+
+	categories := make(map[int]*DigestCategory)
+	for _, t := range transactions {
+		occurredAt := t.OccurredAt()
+		if occurredAt.Before(previousFrom) || !occurredAt.Before(to) {
+			continue
+		}
+
+		if occurredAt.Before(from) {
+			if t.Type == Withdrawal {
+				digest.PreviousExpense += t.AmountMinor()
+			}
+			continue
+		}
+
+		digest.Count++
+		switch t.Type {
+		case Deposit:
+			digest.Income += t.AmountMinor()
+		case Withdrawal:
+			digest.Expense += t.AmountMinor()
+
+			categoryID := 0
+			if t.Category != nil {
+				categoryID = t.Category.ID.Int()
+			}
+			category := categories[categoryID]
+			if category == nil {
+				category = &DigestCategory{Category: t.Category}
+				categories[categoryID] = category
+			}
+			category.Total += t.AmountMinor()
+
+			if digest.BiggestPurchase == nil || t.AmountMinor() > digest.BiggestPurchase.AmountMinor() {
+				digest.BiggestPurchase = t
+			}
+		}
+	}
+
+	for _, category := range categories {
+		digest.TopCategories = append(digest.TopCategories, *category)
+	}
+	sort.Slice(digest.TopCategories, func(i, j int) bool {
+		return digest.TopCategories[i].Total > digest.TopCategories[j].Total
+	})
+	if len(digest.TopCategories) > digestTopCategories {
+		digest.TopCategories = digest.TopCategories[:digestTopCategories]
+	}
+
+	return digest
+}
+
+// Repository
+type DigestScheduleRepository interface {
+	// FindByUserID returns the defaults when the user never changed them.
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*DigestSchedule, error)
+	// FindAll returns only the schedules users have saved.
+	FindAll(ctx context.Context) ([]*DigestSchedule, error)
+	Save(ctx context.Context, schedule *DigestSchedule) error
+}
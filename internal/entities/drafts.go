@@ -0,0 +1,204 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DraftTTL is how long a parsed transaction waits for the user to confirm
+// it before it is dropped.
+const DraftTTL = 24 * time.Hour
+
+type DraftStatus string
+
+const (
+	DraftPending   DraftStatus = "pending"
+	DraftConfirmed DraftStatus = "confirmed"
+	DraftRejected  DraftStatus = "rejected"
+)
+
+func (s DraftStatus) String() string {
+	return string(s)
+}
+
+// DraftSource is what the draft was parsed from.
+type DraftSource string
+
+const (
+	DraftSourceText  DraftSource = "text"
+	DraftSourceVoice DraftSource = "voice"
+	DraftSourceImage DraftSource = "image"
+)
+
+func (s DraftSource) String() string {
+	return string(s)
+}
+
+var (
+	ErrDraftClosed  = errors.New("draft is already confirmed or rejected")
+	ErrDraftExpired = errors.New("draft has expired")
+)
+
+// TransactionDraft is a parsed transaction waiting for the user. It does not
+// touch the balance until confirmed, which creates the real transaction.
+// Amounts are in minor units of the user's currency like on transactions,
+// OriginalAmount keeps what was said in another one.
+type TransactionDraft struct {
+	ID                   uuid.UUID
+	UserID               uuid.UUID
+	AccountID            *uuid.UUID
+	CategoryID           *int
+	SubcategoryID        *int
+	Type                 TrnType
+	Status               DraftStatus
+	Source               DraftSource
+	Amount               int64
+	CurrencyCode         Currency
+	OriginalAmount       int64
+	OriginalCurrencyCode Currency
+	FxRate               float64
+	Note                 string
+	Merchant             string
+	Confidence           float64
+	PerformedAt          time.Time
+	// TransactionID is the transaction the draft was confirmed into.
+	TransactionID *uuid.UUID
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func NewTransactionDraft(userID uuid.UUID, source DraftSource, trnType TrnType) (*TransactionDraft, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("invalid user id")
+	}
+	if trnType != Deposit && trnType != Withdrawal {
+		return nil, errors.New("invalid transaction type")
+	}
+
+	now := time.Now()
+	return &TransactionDraft{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Type:      trnType,
+		Status:    DraftPending,
+		Source:    source,
+		ExpiresAt: now.Add(DraftTTL),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Open reports why the draft can no longer be edited or closed, nil while
+// it is pending and not expired.
+func (d *TransactionDraft) Open(now time.Time) error {
+	if d.Status != DraftPending {
+		return ErrDraftClosed
+	}
+	if !now.Before(d.ExpiresAt) {
+		return ErrDraftExpired
+	}
+	return nil
+}
+
+func (d *TransactionDraft) SetAmountMajor(major float64, currency Currency) error {
+	if major <= 0 {
+		return errors.New("amount must be > 0")
+	}
+	if currency == "" {
+		return errors.New("currency code must not be empty")
+	}
+
+	d.Amount = MinorFromMajor(major, currency.Scale())
+	d.CurrencyCode = currency
+	return nil
+}
+
+// SetOriginalAmountMajor records the amount as said in another currency and
+// the rate it was converted at.
+func (d *TransactionDraft) SetOriginalAmountMajor(major float64, currency Currency, fxRate float64) error {
+	if major <= 0 {
+		return errors.New("original amount must be > 0")
+	}
+	if fxRate <= 0 {
+		return errors.New("fx rate must be > 0")
+	}
+
+	d.OriginalAmount = MinorFromMajor(major, currency.Scale())
+	d.OriginalCurrencyCode = currency
+	d.FxRate = fxRate
+	return nil
+}
+
+// ClearOriginalAmount drops the conversion, an amount the user typed in is
+// taken as final.
+func (d *TransactionDraft) ClearOriginalAmount() {
+	d.OriginalAmount = 0
+	d.OriginalCurrencyCode = ""
+	d.FxRate = 0
+}
+
+func (d *TransactionDraft) SetType(trnType TrnType) error {
+	if trnType != Deposit && trnType != Withdrawal {
+		return errors.New("invalid transaction type")
+	}
+	d.Type = trnType
+	return nil
+}
+
+func (d *TransactionDraft) SetMerchant(merchant string) {
+	d.Merchant = strings.TrimSpace(merchant)
+}
+
+func (d *TransactionDraft) AmountMajor() float64 {
+	return MajorFromMinor(d.Amount, d.CurrencyCode.Scale())
+}
+
+func (d *TransactionDraft) OriginalAmountMajor() float64 {
+	return MajorFromMinor(d.OriginalAmount, d.OriginalCurrencyCode.Scale())
+}
+
+func (d *TransactionDraft) Touch() {
+	d.UpdatedAt = time.Now()
+}
+
+func (d *TransactionDraft) Confirm(transactionID uuid.UUID) error {
+	if err := d.Open(time.Now()); err != nil {
+		return err
+	}
+
+	d.Status = DraftConfirmed
+	d.TransactionID = &transactionID
+	d.Touch()
+	return nil
+}
+
+func (d *TransactionDraft) Reject() error {
+	if err := d.Open(time.Now()); err != nil {
+		return err
+	}
+
+	d.Status = DraftRejected
+	d.Touch()
+	return nil
+}
+
+// Repository
+
+type TransactionDraftRepository interface {
+	Save(ctx context.Context, draft *TransactionDraft) error
+	SaveBatch(ctx context.Context, drafts []*TransactionDraft) error
+	FindByID(ctx context.Context, id uuid.UUID) (*TransactionDraft, error)
+	// FindByIDForUpdate locks the draft until the transaction ends, so it is
+	// confirmed once.
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*TransactionDraft, error)
+	// GetPending lists the user's drafts still open at now, oldest first.
+	GetPending(ctx context.Context, userID uuid.UUID, now time.Time) ([]*TransactionDraft, error)
+	// DeleteExpired removes drafts that expired before the given time,
+	// confirmed and rejected ones included.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
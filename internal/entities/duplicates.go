@@ -0,0 +1,204 @@
+package entities
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// DuplicateMaxGap is how far apart two entries of the same expense can
+	// be. Wide enough for a bank import that only has the date next to an
+	// entry recorded in the evening.
+	DuplicateMaxGap = 24 * time.Hour
+	// duplicateAmountTolerance is the relative difference allowed between
+	// the amounts, a spoken "about fifty thousand" against the receipt.
+	duplicateAmountTolerance = 0.03
+	// duplicateMinTextSimilarity is the share of words the shorter text
+	// must have in common with the other.
+	duplicateMinTextSimilarity = 0.5
+)
+
+// DuplicateMatch is a transaction that likely records the same money
+// movement as another one. Score is between 0 and 1, higher is closer.
+type DuplicateMatch struct {
+	Transaction *Transaction
+	Score       float64
+}
+
+// DuplicatePair is two transactions that likely are the same. Original is
+// the one recorded first.
+type DuplicatePair struct {
+	Original  *Transaction
+	Duplicate *Transaction
+	Score     float64
+}
+
+// FindDuplicates returns the candidates that look like t, best match first.
+// The candidates may contain t itself, it is skipped.
+func FindDuplicates(t *Transaction, candidates []*Transaction) []DuplicateMatch {
+	var matches []DuplicateMatch
+	for _, c := range candidates {
+		if c.ID == t.ID {
+			continue
+		}
+		if score, ok := duplicateScore(t, c); ok {
+			matches = append(matches, DuplicateMatch{Transaction: c, Score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
+
+// FindDuplicatePairs looks for likely duplicates among transactions, best
+// match first. Every pair is listed once.
+func FindDuplicatePairs(transactions []*Transaction) []DuplicatePair {
+	sorted := make([]*Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].OccurredAt().Before(sorted[j].OccurredAt())
+	})
+
+	var pairs []DuplicatePair
+	for i, a := range sorted {
+		for _, b := range sorted[i+1:] {
+			if b.OccurredAt().Sub(a.OccurredAt()) > DuplicateMaxGap {
+				break
+			}
+
+			score, ok := duplicateScore(a, b)
+			if !ok {
+				continue
+			}
+
+			pair := DuplicatePair{Original: a, Duplicate: b, Score: score}
+			if b.CreatedAt.Before(a.CreatedAt) {
+				pair.Original, pair.Duplicate = b, a
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Score > pairs[j].Score
+	})
+
+	return pairs
+}
+
+// duplicateScore reports whether b likely records the same money movement
+// as a. Both must be completed, on the same account, of the same type, with
+// close amounts and times, and describe the same thing. When one of them has
+// no merchant and no note, the same category stands in for the text.
+func duplicateScore(a, b *Transaction) (float64, bool) {
+	if a.AccountID != b.AccountID || a.Type != b.Type {
+		return 0, false
+	}
+	if a.Status != Completed || b.Status != Completed {
+		return 0, false
+	}
+	// Two entries from the same bank statement are different operations.
+	if a.ExternalID != "" && b.ExternalID != "" {
+		return 0, false
+	}
+
+	larger := max(a.AmountMinor(), b.AmountMinor())
+	if larger == 0 {
+		return 0, false
+	}
+	amountDiff := float64(a.AmountMinor()-b.AmountMinor()) / float64(larger)
+	if amountDiff < 0 {
+		amountDiff = -amountDiff
+	}
+	if amountDiff > duplicateAmountTolerance {
+		return 0, false
+	}
+
+	gap := a.OccurredAt().Sub(b.OccurredAt()).Abs()
+	if gap > DuplicateMaxGap {
+		return 0, false
+	}
+
+	var text float64
+	wordsA, wordsB := duplicateWords(a), duplicateWords(b)
+	switch {
+	case len(wordsA) == 0 || len(wordsB) == 0:
+		if a.Category == nil || b.Category == nil || a.Category.ID != b.Category.ID {
+			return 0, false
+		}
+		text = duplicateMinTextSimilarity
+	default:
+		text = wordSimilarity(wordsA, wordsB)
+		if text < duplicateMinTextSimilarity {
+			return 0, false
+		}
+	}
+
+	amount := 1 - amountDiff/duplicateAmountTolerance
+	closeness := 1 - float64(gap)/float64(DuplicateMaxGap)
+
+	return (amount + closeness + text) / 3, true
+}
+
+// duplicateWords are the distinct words of the merchant and the note.
+// Words shorter than three letters are mostly units and prepositions.
+func duplicateWords(t *Transaction) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(t.Merchant+" "+t.RowText), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) >= 3 {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+// wordSimilarity is the share of the smaller set found in the larger one. A
+// word also matches one it starts, "korzinka" matches "korzinkauz".
+func wordSimilarity(a, b map[string]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+			continue
+		}
+		for other := range b {
+			if len([]rune(word)) >= 4 && len([]rune(other)) >= 4 &&
+				(strings.HasPrefix(other, word) || strings.HasPrefix(word, other)) {
+				common++
+				break
+			}
+		}
+	}
+
+	return float64(common) / float64(len(a))
+}
+
+// Absorb fills what the transaction is missing from a duplicate about to be
+// removed, so merging a voice entry into a receipt keeps the note of one
+// and the merchant of the other.
+func (t *Transaction) Absorb(duplicate *Transaction) {
+	if t.Merchant == "" {
+		t.SetMerchant(duplicate.Merchant)
+	}
+	if t.RowText == "" {
+		t.RowText = duplicate.RowText
+	}
+	if t.Category == nil {
+		t.Category = duplicate.Category
+		t.Subcategory = duplicate.Subcategory
+	}
+	if t.ExternalID == "" && t.AccountID == duplicate.AccountID {
+		t.ExternalID = duplicate.ExternalID
+	}
+}
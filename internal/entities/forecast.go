@@ -0,0 +1,168 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// forecastRecurring finds the flows worth projecting forward. Weekly habits
+// can hit the same date in two months around February, never in three, and
+// the spread keeps groceries that happen to fall on the 5th from being
+// mistaken for rent.
+var forecastRecurring = RecurringDetector{
+	MinMonths: 3,
+	MaxSpread: 1.25,
+}
+
+// ForecastInput is everything the cash-flow model needs. History holds
+// completed transactions before Start and Scheduled the ones dated on or
+// after it, which are treated as known upcoming bills and incomes.
+type ForecastInput struct {
+	Accounts    []*Account
+	History     []*Transaction
+	Scheduled   []*Transaction
+	Start       time.Time
+	Days        int
+	HistoryDays int
+}
+
+type ForecastDay struct {
+	Date      time.Time
+	Income    int64
+	Expense   int64
+	Balance   int64
+	BelowZero bool
+}
+
+type AccountForecast struct {
+	AccountID      uuid.UUID
+	StartBalance   int64
+	MinBalance     int64
+	FirstBelowZero *time.Time
+	Recurring      []RecurringFlow
+	Days           []ForecastDay
+}
+
+// ForecastCashFlow projects the daily balance of every account for
+// input.Days days starting at input.Start, whose location is used for
+// calendar math. The model is deterministic: recurring day-of-month flows
+// are repeated, the remaining withdrawals are averaged per weekday over the
+// history window and scheduled transactions are applied on their dates.
+func ForecastCashFlow(input ForecastInput) []*AccountForecast {
+	loc := input.Start.Location()
+	start := time.Date(input.Start.Year(), input.Start.Month(), input.Start.Day(), 0, 0, 0, 0, loc)
+	historyFrom := start.AddDate(0, 0, -input.HistoryDays)
+
+	history := make(map[uuid.UUID][]*Transaction)
+	for _, t := range input.History {
+		history[t.AccountID] = append(history[t.AccountID], t)
+	}
+
+	scheduled := make(map[uuid.UUID]map[string]int64)
+	for _, t := range input.Scheduled {
+		if scheduled[t.AccountID] == nil {
+			scheduled[t.AccountID] = make(map[string]int64)
+		}
+		scheduled[t.AccountID][t.OccurredAt().In(loc).Format(time.DateOnly)] += signedAmount(t)
+	}
+
+	result := make([]*AccountForecast, 0, len(input.Accounts))
+	for _, account := range input.Accounts {
+		recurring, rest := forecastRecurring.Detect(history[account.ID], loc)
+		weekday := weekdaySpending(rest, historyFrom, start, loc)
+
+		// Scheduled transactions are already part of the balance, take them
+		// out so they land on the day they are dated instead.
+		balance := account.Balance
+		for _, amount := range scheduled[account.ID] {
+			balance -= amount
+		}
+
+		forecast := &AccountForecast{
+			AccountID:    account.ID,
+			StartBalance: balance,
+			MinBalance:   balance,
+			Recurring:    recurring,
+			Days:         make([]ForecastDay, 0, input.Days),
+		}
+
+		for i := 0; i < input.Days; i++ {
+			date := start.AddDate(0, 0, i)
+			day := ForecastDay{Date: date}
+
+			day.Expense += weekday[date.Weekday()]
+			for _, flow := range recurring {
+				if !flow.DueOn(date) {
+					continue
+				}
+				if flow.Type == Deposit {
+					day.Income += flow.Amount
+				} else {
+					day.Expense += flow.Amount
+				}
+			}
+
+			if amount, ok := scheduled[account.ID][date.Format(time.DateOnly)]; ok {
+				if amount > 0 {
+					day.Income += amount
+				} else {
+					day.Expense -= amount
+				}
+			}
+
+			balance += day.Income - day.Expense
+			day.Balance = balance
+			day.BelowZero = balance < 0
+
+			if balance < forecast.MinBalance {
+				forecast.MinBalance = balance
+			}
+			if day.BelowZero && forecast.FirstBelowZero == nil {
+				forecast.FirstBelowZero = &day.Date
+			}
+
+			forecast.Days = append(forecast.Days, day)
+		}
+
+		result = append(result, forecast)
+	}
+
+	return result
+}
+
+// weekdaySpending averages the withdrawals of every weekday over the
+// occurrences of that weekday in [from, to).
+func weekdaySpending(transactions []*Transaction, from, to time.Time, loc *time.Location) map[time.Weekday]int64 {
+	occurrences := make(map[time.Weekday]int64)
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		occurrences[d.Weekday()]++
+	}
+
+	totals := make(map[time.Weekday]int64)
+	for _, t := range transactions {
+		if t.Type != Withdrawal {
+			continue
+		}
+		totals[t.OccurredAt().In(loc).Weekday()] += t.AmountMinor()
+	}
+
+	result := make(map[time.Weekday]int64, len(totals))
+	for weekday, total := range totals {
+		if occurrences[weekday] > 0 {
+			result[weekday] = total / occurrences[weekday]
+		}
+	}
+
+	return result
+}
+
+func signedAmount(t *Transaction) int64 {
+	switch t.Type {
+	case Deposit:
+		return t.AmountMinor()
+	case Withdrawal:
+		return -t.AmountMinor()
+	}
+	return 0
+}
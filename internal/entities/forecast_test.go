@@ -0,0 +1,150 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func fixtureTransaction(accountID uuid.UUID, trnType TrnType, amount int64, date string) *Transaction {
+	performedAt, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Transaction{
+		ID:          uuid.New(),
+		AccountID:   accountID,
+		Type:        trnType,
+		Status:      Completed,
+		Amount:      amount,
+		PerformedAt: performedAt.Add(12 * time.Hour),
+	}
+}
+
+func TestRecurringDetectorDetect(t *testing.T) {
+	accountID := uuid.New()
+	history := []*Transaction{
+		// salary on the 25th, amounts within the spread
+		fixtureTransaction(accountID, Deposit, 100_000, "2025-02-25"),
+		fixtureTransaction(accountID, Deposit, 105_000, "2025-03-25"),
+		fixtureTransaction(accountID, Deposit, 100_000, "2025-04-25"),
+		// rent on the 1st
+		fixtureTransaction(accountID, Withdrawal, 50_000, "2025-03-01"),
+		fixtureTransaction(accountID, Withdrawal, 50_000, "2025-04-01"),
+		fixtureTransaction(accountID, Withdrawal, 50_000, "2025-05-01"),
+		// groceries on the 5th, amounts too far apart to be a bill
+		fixtureTransaction(accountID, Withdrawal, 1_000, "2025-03-05"),
+		fixtureTransaction(accountID, Withdrawal, 5_000, "2025-04-05"),
+		fixtureTransaction(accountID, Withdrawal, 2_000, "2025-05-05"),
+		// seen in two months only
+		fixtureTransaction(accountID, Withdrawal, 9_000, "2025-04-17"),
+		fixtureTransaction(accountID, Withdrawal, 9_000, "2025-05-17"),
+	}
+
+	flows, rest := forecastRecurring.Detect(history, time.UTC)
+
+	if len(flows) != 2 {
+		t.Fatalf("got %d flows, want 2: %+v", len(flows), flows)
+	}
+
+	rent, salary := flows[0], flows[1]
+	if rent.Type != Withdrawal || rent.DayOfMonth != 1 || rent.Amount != 50_000 || len(rent.Transactions) != 3 {
+		t.Errorf("unexpected rent flow: %+v", rent)
+	}
+	if salary.Type != Deposit || salary.DayOfMonth != 25 || salary.Amount != 101_666 {
+		t.Errorf("unexpected salary flow: %+v", salary)
+	}
+
+	if len(rest) != 5 {
+		t.Errorf("got %d transactions outside of flows, want 5", len(rest))
+	}
+
+	// without a spread bound and with two months the rest qualifies too
+	flows, _ = RecurringDetector{MinMonths: 2}.Detect(history, time.UTC)
+	if len(flows) != 4 {
+		t.Errorf("got %d flows without a spread bound, want 4", len(flows))
+	}
+}
+
+func TestRecurringFlowDueOn(t *testing.T) {
+	flow := RecurringFlow{Type: Deposit, DayOfMonth: 31}
+
+	tests := []struct {
+		date string
+		want bool
+	}{
+		{date: "2025-01-31", want: true},
+		{date: "2025-01-30", want: false},
+		{date: "2025-04-30", want: true},
+		{date: "2025-02-28", want: true},
+		{date: "2024-02-28", want: false},
+		{date: "2024-02-29", want: true},
+	}
+
+	for _, tt := range tests {
+		date, _ := time.Parse(time.DateOnly, tt.date)
+		if got := flow.DueOn(date); got != tt.want {
+			t.Errorf("DueOn(%s) = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestForecastCashFlow(t *testing.T) {
+	accountID := uuid.New()
+	start, _ := time.Parse(time.DateOnly, "2025-05-10")
+
+	history := []*Transaction{
+		fixtureTransaction(accountID, Withdrawal, 50_000, "2025-03-01"),
+		fixtureTransaction(accountID, Withdrawal, 50_000, "2025-04-01"),
+		fixtureTransaction(accountID, Withdrawal, 50_000, "2025-05-01"),
+	}
+	scheduled := []*Transaction{
+		fixtureTransaction(accountID, Withdrawal, 5_000, "2025-05-12"),
+	}
+
+	forecasts := ForecastCashFlow(ForecastInput{
+		// the balance already has the scheduled withdrawal taken out
+		Accounts:    []*Account{{ID: accountID, Balance: 30_000}},
+		History:     history,
+		Scheduled:   scheduled,
+		Start:       start,
+		Days:        30,
+		HistoryDays: 90,
+	})
+
+	if len(forecasts) != 1 {
+		t.Fatalf("got %d forecasts, want 1", len(forecasts))
+	}
+
+	forecast := forecasts[0]
+	if forecast.StartBalance != 35_000 {
+		t.Errorf("StartBalance = %d, want 35000", forecast.StartBalance)
+	}
+	if len(forecast.Days) != 30 {
+		t.Fatalf("got %d days, want 30", len(forecast.Days))
+	}
+	if len(forecast.Recurring) != 1 {
+		t.Fatalf("got %d recurring flows, want 1", len(forecast.Recurring))
+	}
+
+	byDate := make(map[string]ForecastDay)
+	for _, day := range forecast.Days {
+		byDate[day.Date.Format(time.DateOnly)] = day
+	}
+
+	if day := byDate["2025-05-12"]; day.Expense != 5_000 || day.Balance != 30_000 {
+		t.Errorf("scheduled day = %+v, want expense 5000 and balance 30000", day)
+	}
+	if day := byDate["2025-06-01"]; day.Expense != 50_000 || day.Balance != -20_000 || !day.BelowZero {
+		t.Errorf("rent day = %+v, want expense 50000 and balance -20000", day)
+	}
+
+	if forecast.MinBalance != -20_000 {
+		t.Errorf("MinBalance = %d, want -20000", forecast.MinBalance)
+	}
+	if forecast.FirstBelowZero == nil || forecast.FirstBelowZero.Format(time.DateOnly) != "2025-06-01" {
+		t.Errorf("FirstBelowZero = %v, want 2025-06-01", forecast.FirstBelowZero)
+	}
+}
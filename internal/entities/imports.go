@@ -0,0 +1,834 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const ImportSourceCSV = "csv"
+
+type ImportStatus string
+
+const (
+	ImportUploaded   ImportStatus = "uploaded"
+	ImportMapped     ImportStatus = "mapped"
+	ImportCommitted  ImportStatus = "committed"
+	ImportRolledBack ImportStatus = "rolled_back"
+)
+
+func (s ImportStatus) String() string {
+	return string(s)
+}
+
+var (
+	ErrImportNotMapped    = errors.New("import columns are not mapped")
+	ErrImportCommitted    = errors.New("import is already committed")
+	ErrImportNotCommitted = errors.New("import is not committed")
+)
+
+// ImportField is a transaction attribute a file column can be mapped to.
+type ImportField string
+
+const (
+	ImportDate        ImportField = "date"
+	ImportAmount      ImportField = "amount"
+	ImportIncome      ImportField = "income"
+	ImportExpense     ImportField = "expense"
+	ImportCurrency    ImportField = "currency"
+	ImportDescription ImportField = "description"
+	ImportMerchant    ImportField = "merchant"
+	// ImportExternalID is the bank's own reference of the entry, rows whose
+	// reference is already on the account are not imported again.
+	ImportExternalID ImportField = "external_id"
+)
+
+func (f ImportField) String() string {
+	return string(f)
+}
+
+func ImportFields() []ImportField {
+	return []ImportField{ImportDate, ImportAmount, ImportIncome, ImportExpense, ImportCurrency, ImportDescription, ImportMerchant, ImportExternalID}
+}
+
+func ParseImportField(s string) (ImportField, error) {
+	for _, f := range ImportFields() {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", errors.New("unknown import field")
+}
+
+// ImportMapping points fields at zero-based columns of the file. A signed
+// amount column and separate income/expense columns are both supported.
+type ImportMapping map[ImportField]int
+
+func (m ImportMapping) Validate(columns int) error {
+	for field, column := range m {
+		if column < 0 || column >= columns {
+			return fmt.Errorf("column %d of %s is out of range", column, field)
+		}
+	}
+
+	if _, ok := m[ImportDate]; !ok {
+		return errors.New("date column is required")
+	}
+
+	_, amount := m[ImportAmount]
+	_, income := m[ImportIncome]
+	_, expense := m[ImportExpense]
+	if !amount && !income && !expense {
+		return errors.New("amount or income and expense columns are required")
+	}
+
+	return nil
+}
+
+// importDateFormats are tried in order. Day-first formats come before the
+// month-first one, so 03/04/2025 is read the way local banks write it.
+var importDateFormats = []struct {
+	name   string
+	layout string
+}{
+	{"DD.MM.YYYY HH:mm:ss", "2.1.2006 15:04:05"},
+	{"DD.MM.YYYY HH:mm", "2.1.2006 15:04"},
+	{"DD.MM.YYYY", "2.1.2006"},
+	{"DD.MM.YY", "2.1.06"},
+	{"YYYY-MM-DD HH:mm:ss", "2006-01-02 15:04:05"},
+	{"YYYY-MM-DDTHH:mm:ss", "2006-01-02T15:04:05"},
+	{"YYYY-MM-DD HH:mm", "2006-01-02 15:04"},
+	{"YYYY-MM-DD", "2006-01-02"},
+	{"DD/MM/YYYY HH:mm", "2/1/2006 15:04"},
+	{"DD/MM/YYYY", "2/1/2006"},
+	{"MM/DD/YYYY", "1/2/2006"},
+	{"DD-MM-YYYY", "2-1-2006"},
+	{"YYYY.MM.DD", "2006.1.2"},
+	{"YYYY/MM/DD", "2006/1/2"},
+}
+
+// ImportDateFormats lists the date formats a user can pick.
+func ImportDateFormats() []string {
+	names := make([]string, len(importDateFormats))
+	for i, f := range importDateFormats {
+		names[i] = f.name
+	}
+	return names
+}
+
+func importDateLayout(name string) (string, bool) {
+	for _, f := range importDateFormats {
+		if f.name == name {
+			return f.layout, true
+		}
+	}
+	return "", false
+}
+
+// ImportFormat is how the file is written, detected on upload and
+// adjustable by the user.
+type ImportFormat struct {
+	Encoding         string
+	Delimiter        string
+	DateFormat       string
+	DecimalSeparator string
+}
+
+// ImportSession is an uploaded statement on its way to becoming
+// transactions. The raw rows are kept so the session can be remapped and
+// committed later.
+type ImportSession struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	AccountID     uuid.UUID
+	Source        string
+	Filename      string
+	Status        ImportStatus
+	Format        ImportFormat
+	Header        []string
+	Mapping       ImportMapping
+	RowCount      int
+	ImportedCount int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CommittedAt   time.Time
+}
+
+// NewImportSession starts a session. header has one value per column, empty
+// when the file has no header row.
+func NewImportSession(
+	userID uuid.UUID,
+	accountID uuid.UUID,
+	source string,
+	filename string,
+	format ImportFormat,
+	header []string,
+	mapping ImportMapping,
+	rowCount int,
+) (*ImportSession, error) {
+	if userID == uuid.Nil {
+		return nil, errors.New("invalid user id")
+	}
+	if accountID == uuid.Nil {
+		return nil, errors.New("invalid account id")
+	}
+	if rowCount == 0 {
+		return nil, errors.New("file has no rows")
+	}
+
+	if mapping == nil {
+		mapping = make(ImportMapping)
+	}
+
+	session := &ImportSession{
+		ID:        uuid.New(),
+		UserID:    userID,
+		AccountID: accountID,
+		Source:    source,
+		Filename:  filename,
+		Status:    ImportUploaded,
+		Format:    format,
+		Header:    header,
+		Mapping:   mapping,
+		RowCount:  rowCount,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	// A complete suggestion needs no user input before the preview.
+	if _, ok := importDateLayout(format.DateFormat); ok && mapping.Validate(len(header)) == nil {
+		session.Status = ImportMapped
+	}
+
+	return session, nil
+}
+
+// SetMapping replaces the column mapping. Empty dateFormat and
+// decimalSeparator keep the detected ones.
+func (s *ImportSession) SetMapping(mapping ImportMapping, dateFormat, decimalSeparator string) error {
+	if s.Status == ImportCommitted {
+		return ErrImportCommitted
+	}
+
+	if err := mapping.Validate(len(s.Header)); err != nil {
+		return err
+	}
+
+	if dateFormat != "" {
+		if _, ok := importDateLayout(dateFormat); !ok {
+			return errors.New("unknown date format")
+		}
+		s.Format.DateFormat = dateFormat
+	}
+	if _, ok := importDateLayout(s.Format.DateFormat); !ok {
+		return errors.New("date format is required")
+	}
+
+	if decimalSeparator != "" {
+		if decimalSeparator != "." && decimalSeparator != "," {
+			return errors.New("decimal separator must be . or ,")
+		}
+		s.Format.DecimalSeparator = decimalSeparator
+	}
+
+	s.Mapping = mapping
+	s.Status = ImportMapped
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// CanCommit reports why the session cannot be committed yet.
+func (s *ImportSession) CanCommit() error {
+	switch s.Status {
+	case ImportMapped, ImportRolledBack:
+		return nil
+	case ImportCommitted:
+		return ErrImportCommitted
+	default:
+		return ErrImportNotMapped
+	}
+}
+
+func (s *ImportSession) Committed(imported int) {
+	s.Status = ImportCommitted
+	s.ImportedCount = imported
+	s.CommittedAt = time.Now()
+	s.UpdatedAt = time.Now()
+}
+
+func (s *ImportSession) RollBack() error {
+	if s.Status != ImportCommitted {
+		return ErrImportNotCommitted
+	}
+
+	s.Status = ImportRolledBack
+	s.ImportedCount = 0
+	s.CommittedAt = time.Time{}
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// ImportRow is a raw line of the file, Line counts from one and skips the
+// header.
+type ImportRow struct {
+	Line   int
+	Values []string
+}
+
+// ImportRecord is a row read through the session mapping. Error is set when
+// the row cannot become a transaction.
+type ImportRecord struct {
+	Line        int
+	Type        TrnType
+	Amount      float64
+	Currency    Currency
+	PerformedAt time.Time
+	Description string
+	Merchant    string
+	ExternalID  string
+	Error       string
+}
+
+func (s *ImportSession) ParseRow(row ImportRow, loc *time.Location) ImportRecord {
+	value := func(field ImportField) string {
+		column, ok := s.Mapping[field]
+		if !ok || column >= len(row.Values) {
+			return ""
+		}
+		return strings.TrimSpace(row.Values[column])
+	}
+
+	record := ImportRecord{
+		Line:        row.Line,
+		Description: value(ImportDescription),
+		Merchant:    value(ImportMerchant),
+		ExternalID:  value(ImportExternalID),
+	}
+
+	if currency := strings.ToUpper(value(ImportCurrency)); len(currency) == 3 {
+		record.Currency = Currency(currency)
+	}
+
+	layout, ok := importDateLayout(s.Format.DateFormat)
+	if !ok {
+		record.Error = "date format is not set"
+		return record
+	}
+	performedAt, err := time.ParseInLocation(layout, value(ImportDate), loc)
+	if err != nil {
+		record.Error = "invalid date"
+		return record
+	}
+	record.PerformedAt = performedAt
+
+	amount, err := s.parseAmount(value)
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	record.Type = Deposit
+	if amount < 0 {
+		record.Type = Withdrawal
+	}
+	record.Amount = math.Abs(amount)
+
+	return record
+}
+
+// Transaction builds the performed transaction for a record without error.
+// rate converts the record currency into currency and is only used when
+// they differ.
+func (r ImportRecord) Transaction(session *ImportSession, currency Currency, rate float64) (*Transaction, error) {
+	if r.Error != "" {
+		return nil, errors.New(r.Error)
+	}
+
+	transaction, err := NewTransaction(session.UserID, session.AccountID, r.Type, r.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Currency == "" || r.Currency == currency {
+		err = transaction.SetAmountMajor(r.Amount, currency)
+	} else {
+		err = transaction.SetOriginalAmountMajor(r.Amount, r.Currency)
+		if err == nil {
+			err = transaction.SetAmountMajor(r.Amount*rate, currency)
+		}
+		if err == nil {
+			err = transaction.SetFxRate(rate)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.SetMerchant(r.Merchant)
+	transaction.ExternalID = r.ExternalID
+	transaction.Performed(r.PerformedAt)
+	transaction.Imported(session.ID)
+
+	return transaction, nil
+}
+
+// parseAmount returns a signed amount, negative for expenses.
+func (s *ImportSession) parseAmount(value func(ImportField) string) (float64, error) {
+	separator := s.Format.DecimalSeparator
+
+	if _, ok := s.Mapping[ImportAmount]; ok {
+		raw := value(ImportAmount)
+		if raw == "" {
+			return 0, errors.New("empty amount")
+		}
+		amount, err := ParseImportAmount(raw, separator)
+		if err != nil {
+			return 0, err
+		}
+		if amount == 0 {
+			return 0, errors.New("zero amount")
+		}
+		return amount, nil
+	}
+
+	if raw := value(ImportIncome); raw != "" {
+		amount, err := ParseImportAmount(raw, separator)
+		if err != nil {
+			return 0, err
+		}
+		if amount != 0 {
+			return math.Abs(amount), nil
+		}
+	}
+
+	if raw := value(ImportExpense); raw != "" {
+		amount, err := ParseImportAmount(raw, separator)
+		if err != nil {
+			return 0, err
+		}
+		if amount != 0 {
+			return -math.Abs(amount), nil
+		}
+	}
+
+	return 0, errors.New("empty amount")
+}
+
+var (
+	importAmountPattern  = regexp.MustCompile(`^[-+−(]?\s*[0-9][0-9\s\x{00a0}'.,]*\)?\s*[\p{L}$€₽]*\.?$`)
+	importCurrencySuffix = regexp.MustCompile(`[\p{L}$€₽.]+$`)
+)
+
+func looksLikeAmount(s string) bool {
+	return importAmountPattern.MatchString(strings.TrimSpace(s))
+}
+
+// ParseImportAmount reads amounts such as "-1 250 000,50", "(12.30)" or
+// "1'000.00 UZS". Thousands separators, spaces and a trailing currency are
+// dropped, parentheses and a leading minus make the amount negative.
+func ParseImportAmount(s string, decimalSeparator string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if !looksLikeAmount(s) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	s = strings.TrimSpace(importCurrencySuffix.ReplaceAllString(s, ""))
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '-' || r == '−':
+			negative = true
+		case string(r) == decimalSeparator:
+			b.WriteRune('.')
+		}
+	}
+
+	amount, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+// DetectDateFormat returns the first format that reads every non-empty
+// value, or an empty string.
+func DetectDateFormat(values []string) string {
+	for _, f := range importDateFormats {
+		parsed := 0
+		for _, v := range values {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if _, err := time.Parse(f.layout, v); err != nil {
+				parsed = -1
+				break
+			}
+			parsed++
+		}
+		if parsed > 0 {
+			return f.name
+		}
+	}
+	return ""
+}
+
+// DetectDecimalSeparator votes over the values: the last of two different
+// separators is the decimal one, and a lone separator followed by other
+// than three digits is too. Defaults to a dot.
+func DetectDecimalSeparator(values []string) string {
+	votes := map[string]int{}
+	for _, v := range values {
+		v = strings.TrimSpace(importCurrencySuffix.ReplaceAllString(strings.TrimSpace(v), ""))
+		dot, comma := strings.LastIndex(v, "."), strings.LastIndex(v, ",")
+
+		switch {
+		case dot >= 0 && comma >= 0:
+			if dot > comma {
+				votes["."]++
+			} else {
+				votes[","]++
+			}
+		case comma >= 0 && strings.Count(v, ",") == 1 && len(strings.TrimRight(v[comma+1:], ")")) != 3:
+			votes[","]++
+		case dot >= 0 && strings.Count(v, ".") == 1 && len(strings.TrimRight(v[dot+1:], ")")) != 3:
+			votes["."]++
+		}
+	}
+
+	if votes[","] > votes["."] {
+		return ","
+	}
+	return "."
+}
+
+// importFieldKeywords match header cells of Uzbek bank exports in Russian,
+// Uzbek and English. Fields are tried in this order, so "Сумма прихода" is
+// income rather than amount and "Номер операции" is not a description.
+var importFieldKeywords = []struct {
+	field    ImportField
+	keywords []string
+}{
+	{ImportExternalID, []string{"id операции", "id транзакции", "номер операции", "референс", "tranzaksiya id", "transaction id", "reference", "fitid"}},
+	{ImportIncome, []string{"приход", "поступлен", "зачислен", "кредит", "kirim", "tushum", "income", "credit"}},
+	{ImportExpense, []string{"расход", "списан", "дебет", "chiqim", "expense", "debit"}},
+	{ImportDate, []string{"дата", "sana", "date", "время", "vaqt", "time"}},
+	{ImportAmount, []string{"сумма", "summa", "miqdor", "amount", "sum"}},
+	{ImportCurrency, []string{"валют", "valyuta", "currency"}},
+	{ImportMerchant, []string{"получател", "контрагент", "мерчант", "торгов", "qabul qiluvchi", "merchant", "payee", "recipient"}},
+	{ImportDescription, []string{"назначени", "описани", "коммент", "детал", "izoh", "tavsif", "description", "details", "purpose", "comment"}},
+}
+
+// SuggestImportMapping maps header cells by keyword, the first column wins
+// when several match.
+func SuggestImportMapping(header []string) ImportMapping {
+	mapping := make(ImportMapping)
+
+	for column, cell := range header {
+		cell = strings.ToLower(strings.TrimSpace(cell))
+		if cell == "" {
+			continue
+		}
+
+	fields:
+		for _, f := range importFieldKeywords {
+			for _, keyword := range f.keywords {
+				if !strings.Contains(cell, keyword) {
+					continue
+				}
+				if _, taken := mapping[f.field]; !taken {
+					mapping[f.field] = column
+				}
+				break fields
+			}
+		}
+	}
+
+	return mapping
+}
+
+// importSampleRows is how many rows are looked at to detect formats.
+const importSampleRows = 50
+
+// ImportAnalysis is what could be guessed about a parsed file.
+type ImportAnalysis struct {
+	Header           []string
+	Rows             [][]string
+	DateFormat       string
+	DecimalSeparator string
+	Mapping          ImportMapping
+}
+
+// AnalyzeImport splits off the header, when the first record looks like
+// one, and guesses the formats and the mapping. Columns the header does not
+// name are found from their values.
+func AnalyzeImport(records [][]string) *ImportAnalysis {
+	columns := 0
+	for _, record := range records {
+		columns = max(columns, len(record))
+	}
+
+	analysis := &ImportAnalysis{
+		Header:           make([]string, columns),
+		Rows:             records,
+		DecimalSeparator: ".",
+	}
+	if len(records) > 0 && looksLikeHeader(records[0]) {
+		copy(analysis.Header, records[0])
+		analysis.Rows = records[1:]
+	}
+
+	sample := analysis.Rows[:min(len(analysis.Rows), importSampleRows)]
+	columnValues := func(column int) []string {
+		values := make([]string, 0, len(sample))
+		for _, row := range sample {
+			if column < len(row) {
+				values = append(values, row[column])
+			}
+		}
+		return values
+	}
+
+	mapping := SuggestImportMapping(analysis.Header)
+
+	if column, ok := mapping[ImportDate]; ok {
+		analysis.DateFormat = DetectDateFormat(columnValues(column))
+	}
+	if analysis.DateFormat == "" {
+		delete(mapping, ImportDate)
+		for column := 0; column < columns; column++ {
+			if format := DetectDateFormat(columnValues(column)); format != "" {
+				mapping[ImportDate] = column
+				analysis.DateFormat = format
+				break
+			}
+		}
+	}
+
+	_, amount := mapping[ImportAmount]
+	_, income := mapping[ImportIncome]
+	_, expense := mapping[ImportExpense]
+	if !amount && !income && !expense {
+		for column := 0; column < columns; column++ {
+			if date, ok := mapping[ImportDate]; ok && date == column {
+				continue
+			}
+			if isAmountColumn(columnValues(column)) {
+				mapping[ImportAmount] = column
+				break
+			}
+		}
+	}
+
+	var amounts []string
+	for _, field := range []ImportField{ImportAmount, ImportIncome, ImportExpense} {
+		if column, ok := mapping[field]; ok {
+			amounts = append(amounts, columnValues(column)...)
+		}
+	}
+	analysis.DecimalSeparator = DetectDecimalSeparator(amounts)
+	analysis.Mapping = mapping
+
+	return analysis
+}
+
+// looksLikeHeader is true when no cell reads as a date or an amount.
+func looksLikeHeader(record []string) bool {
+	for _, cell := range record {
+		if cell == "" {
+			continue
+		}
+		if looksLikeAmount(cell) || DetectDateFormat([]string{cell}) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isAmountColumn(values []string) bool {
+	found := false
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		if !looksLikeAmount(v) || DetectDateFormat([]string{v}) != "" {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// Categorizer guesses categories for imported rows: first from how the user
+// categorised the same merchant before, then from category and subcategory
+// names found in the merchant or description.
+type Categorizer struct {
+	merchants     map[string]categoryGuess
+	merchantKeys  []string
+	categories    map[int]*Category
+	subcategories []*Subcategory
+	names         []categoryName
+}
+
+type categoryGuess struct {
+	category    *Category
+	subcategory *Subcategory
+}
+
+type categoryName struct {
+	name  string
+	guess categoryGuess
+}
+
+// categorizerMinMatch keeps short names and merchants from matching inside
+// unrelated words.
+const categorizerMinMatch = 4
+
+func NewCategorizer(history []*Transaction, categories []*Category, subcategories []*Subcategory) *Categorizer {
+	c := &Categorizer{
+		merchants:  make(map[string]categoryGuess),
+		categories: make(map[int]*Category, len(categories)),
+	}
+
+	for _, category := range categories {
+		c.categories[category.ID.Int()] = category
+	}
+
+	type choice struct {
+		categoryID    int
+		subcategoryID int
+	}
+	counts := make(map[string]map[choice]int)
+	guesses := make(map[choice]categoryGuess)
+	for _, t := range history {
+		merchant := normalizeMerchant(t.Merchant)
+		if merchant == "" || t.Category == nil {
+			continue
+		}
+
+		key := choice{categoryID: t.Category.ID.Int()}
+		if t.Subcategory != nil {
+			key.subcategoryID = t.Subcategory.ID
+		}
+		if counts[merchant] == nil {
+			counts[merchant] = make(map[choice]int)
+		}
+		counts[merchant][key]++
+		guesses[key] = categoryGuess{category: t.Category, subcategory: t.Subcategory}
+	}
+
+	for merchant, choices := range counts {
+		var best choice
+		bestCount := 0
+		for key, count := range choices {
+			if count > bestCount || count == bestCount && (key.categoryID < best.categoryID ||
+				key.categoryID == best.categoryID && key.subcategoryID < best.subcategoryID) {
+				best, bestCount = key, count
+			}
+		}
+		c.merchants[merchant] = guesses[best]
+		if len([]rune(merchant)) >= categorizerMinMatch {
+			c.merchantKeys = append(c.merchantKeys, merchant)
+		}
+	}
+	sortLongestFirst(c.merchantKeys)
+
+	for _, subcategory := range subcategories {
+		category := c.categories[subcategory.CategoryID]
+		if subcategory.Hidden || category == nil || category.Hidden {
+			continue
+		}
+		for _, name := range subcategory.Names {
+			c.addName(name, categoryGuess{category: category, subcategory: subcategory})
+		}
+	}
+	for _, category := range categories {
+		if category.Hidden {
+			continue
+		}
+		for _, name := range category.Names {
+			c.addName(name, categoryGuess{category: category})
+		}
+	}
+	sort.SliceStable(c.names, func(i, j int) bool {
+		return len([]rune(c.names[i].name)) > len([]rune(c.names[j].name))
+	})
+
+	return c
+}
+
+func (c *Categorizer) addName(name string, guess categoryGuess) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len([]rune(name)) < categorizerMinMatch {
+		return
+	}
+	c.names = append(c.names, categoryName{name: name, guess: guess})
+}
+
+// Categorize returns nil when nothing matches.
+func (c *Categorizer) Categorize(merchant, description string) (*Category, *Subcategory) {
+	if guess, ok := c.merchants[normalizeMerchant(merchant)]; ok {
+		return guess.category, guess.subcategory
+	}
+
+	text := strings.ToLower(merchant + " " + description)
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	for _, key := range c.merchantKeys {
+		if strings.Contains(text, key) {
+			guess := c.merchants[key]
+			return guess.category, guess.subcategory
+		}
+	}
+
+	for _, n := range c.names {
+		if strings.Contains(text, n.name) {
+			return n.guess.category, n.guess.subcategory
+		}
+	}
+
+	return nil, nil
+}
+
+func sortLongestFirst(values []string) {
+	sort.Slice(values, func(i, j int) bool {
+		li, lj := len([]rune(values[i])), len([]rune(values[j]))
+		if li != lj {
+			return li > lj
+		}
+		return values[i] < values[j]
+	})
+}
+
+// Repository
+type ImportSessionRepository interface {
+	Save(ctx context.Context, session *ImportSession) error
+	FindByID(ctx context.Context, id uuid.UUID) (*ImportSession, error)
+	// FindByIDForUpdate locks the session until the transaction carried by
+	// ctx ends, commits and rollbacks of one session run one at a time.
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*ImportSession, error)
+	SaveRows(ctx context.Context, sessionID uuid.UUID, rows []ImportRow) error
+	// GetRows returns rows ordered by line, all of them when limit is zero.
+	GetRows(ctx context.Context, sessionID uuid.UUID, limit, offset int) ([]ImportRow, error)
+}
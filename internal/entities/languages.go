@@ -0,0 +1,179 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the root of every fallback chain. Names given without a
+// locale are stored under it.
+const DefaultLanguage = EN
+
+// LanguageInfo describes a locale users can pick. The list lives in the
+// languages table and is registered on startup with RegisterLanguages.
+type LanguageInfo struct {
+	Code         Language
+	Name         string
+	Fallback     Language
+	SearchConfig string
+	Position     int
+}
+
+type languageRegistry struct {
+	mu     sync.RWMutex
+	byCode map[Language]LanguageInfo
+	list   []LanguageInfo
+}
+
+// languages starts with the locales the bot shipped with, so everything keeps
+// working before the registry is loaded from the database.
+var languages = newLanguageRegistry([]LanguageInfo{
+	{Code: EN, Name: "English", SearchConfig: "english", Position: 1},
+	{Code: RU, Name: "Русский", Fallback: EN, SearchConfig: "russian", Position: 2},
+	{Code: UZ, Name: "O‘zbekcha", Fallback: RU, SearchConfig: "simple", Position: 3},
+})
+
+func newLanguageRegistry(list []LanguageInfo) *languageRegistry {
+	r := &languageRegistry{}
+	r.set(list)
+	return r
+}
+
+func (r *languageRegistry) set(list []LanguageInfo) {
+	byCode := make(map[Language]LanguageInfo, len(list))
+	for _, info := range list {
+		byCode[info.Code] = info
+	}
+
+	sorted := append([]LanguageInfo(nil), list...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	r.mu.Lock()
+	r.byCode = byCode
+	r.list = sorted
+	r.mu.Unlock()
+}
+
+// RegisterLanguages replaces the set of supported languages.
+func RegisterLanguages(list []*LanguageInfo) error {
+	var hasDefault bool
+	infos := make([]LanguageInfo, 0, len(list))
+	for _, info := range list {
+		if info.Code == DefaultLanguage {
+			hasDefault = true
+		}
+		infos = append(infos, *info)
+	}
+
+	if !hasDefault {
+		return errors.New("default language is not registered")
+	}
+
+	languages.set(infos)
+
+	return nil
+}
+
+// SupportedLanguages returns the registered languages in display order.
+func SupportedLanguages() []LanguageInfo {
+	languages.mu.RLock()
+	defer languages.mu.RUnlock()
+
+	return append([]LanguageInfo(nil), languages.list...)
+}
+
+// ParseLanguage normalizes codes like "ru-RU" or "UZ" and checks that the
+// language is registered.
+func ParseLanguage(code string) (Language, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+
+	lang := Language(code)
+	if !lang.IsSupported() {
+		return "", errors.New("unsupported language")
+	}
+
+	return lang, nil
+}
+
+func (l Language) Info() (LanguageInfo, bool) {
+	languages.mu.RLock()
+	defer languages.mu.RUnlock()
+
+	info, ok := languages.byCode[l]
+	return info, ok
+}
+
+func (l Language) IsSupported() bool {
+	_, ok := l.Info()
+	return ok
+}
+
+// SearchConfig returns the postgres text search configuration for the language.
+func (l Language) SearchConfig() string {
+	if info, ok := l.Info(); ok && info.SearchConfig != "" {
+		return info.SearchConfig
+	}
+	return "simple"
+}
+
+// Fallbacks returns the lookup order for translations: the language itself,
+// its fallback chain and finally DefaultLanguage.
+func (l Language) Fallbacks() []Language {
+	languages.mu.RLock()
+	defer languages.mu.RUnlock()
+
+	chain := make([]Language, 0, 3)
+	seen := make(map[Language]bool, 3)
+	for lang := l; lang != "" && !seen[lang]; {
+		chain = append(chain, lang)
+		seen[lang] = true
+		lang = languages.byCode[lang].Fallback
+	}
+
+	if !seen[DefaultLanguage] {
+		chain = append(chain, DefaultLanguage)
+	}
+
+	return chain
+}
+
+// Translations holds localized values keyed by language.
+type Translations map[Language]string
+
+// Get returns the value for lang following its fallback chain. As a last
+// resort any stored value is returned, so a name is never empty.
+func (t Translations) Get(lang Language) string {
+	for _, l := range lang.Fallbacks() {
+		if v := t[l]; v != "" {
+			return v
+		}
+	}
+
+	for _, info := range SupportedLanguages() {
+		if v := t[info.Code]; v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// Clone copies the translations, so the copy can be edited without
+// touching values shared with a cache.
+func (t Translations) Clone() Translations {
+	return maps.Clone(t)
+}
+
+// Repository
+type LanguageRepository interface {
+	FindAll(ctx context.Context) ([]*LanguageInfo, error)
+}
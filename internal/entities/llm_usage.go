@@ -0,0 +1,140 @@
+package entities
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan decides the LLM quotas of a user.
+type Plan string
+
+const (
+	PlanFree    Plan = "free"
+	PlanPremium Plan = "premium"
+)
+
+func (p Plan) String() string {
+	return string(p)
+}
+
+func Plans() []Plan {
+	return []Plan{PlanFree, PlanPremium}
+}
+
+// UsageOperation is the parse request the usage was spent on.
+type UsageOperation string
+
+const (
+	UsageText  UsageOperation = "text"
+	UsageVoice UsageOperation = "voice"
+	UsageImage UsageOperation = "image"
+)
+
+func (o UsageOperation) String() string {
+	return string(o)
+}
+
+// LLMUsage is what one request cost, every model call and repair included.
+type LLMUsage struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	Operation        UsageOperation
+	PromptTokens     int
+	CompletionTokens int
+	AudioSeconds     float64
+	OCRCalls         int
+	CreatedAt        time.Time
+}
+
+func NewLLMUsage(userID uuid.UUID, operation UsageOperation) *LLMUsage {
+	return &LLMUsage{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Operation: operation,
+		CreatedAt: time.Now(),
+	}
+}
+
+// IsEmpty reports whether nothing billable was spent, e.g. every answer
+// came from the cache.
+func (u *LLMUsage) IsEmpty() bool {
+	return u.PromptTokens == 0 && u.CompletionTokens == 0 && u.AudioSeconds == 0 && u.OCRCalls == 0
+}
+
+// UsageTotals sums usage over a period.
+type UsageTotals struct {
+	Requests         int
+	PromptTokens     int64
+	CompletionTokens int64
+	AudioSeconds     float64
+	OCRCalls         int64
+}
+
+func (t UsageTotals) Tokens() int64 {
+	return t.PromptTokens + t.CompletionTokens
+}
+
+// UsageLimits caps usage over a period, zero leaves a metric unlimited.
+type UsageLimits struct {
+	Tokens       int64
+	AudioSeconds int64
+	OCRCalls     int64
+}
+
+// Exceeded names the first metric the totals reached the limit of, empty
+// while all are below.
+func (l UsageLimits) Exceeded(totals UsageTotals) string {
+	switch {
+	case l.Tokens > 0 && totals.Tokens() >= l.Tokens:
+		return "tokens"
+	case l.AudioSeconds > 0 && totals.AudioSeconds >= float64(l.AudioSeconds):
+		return "audio_seconds"
+	case l.OCRCalls > 0 && totals.OCRCalls >= l.OCRCalls:
+		return "ocr_calls"
+	default:
+		return ""
+	}
+}
+
+// PlanQuota holds the limits of a plan. Days and months are the user's
+// local ones.
+type PlanQuota struct {
+	Daily   UsageLimits
+	Monthly UsageLimits
+}
+
+// UsageDay is the day quotas count t in, in the location of t.
+func UsageDay(t time.Time) (from, to time.Time) {
+	from = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return from, from.AddDate(0, 0, 1)
+}
+
+// UsageMonth is the month quotas count t in, in the location of t.
+func UsageMonth(t time.Time) (from, to time.Time) {
+	from = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return from, from.AddDate(0, 1, 0)
+}
+
+// UserUsageRow is one user's totals in the admin report.
+type UserUsageRow struct {
+	UserID uuid.UUID
+	Plan   Plan
+	UsageTotals
+}
+
+// Repository
+
+type LLMUsageRepository interface {
+	Save(ctx context.Context, usage *LLMUsage) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// LockUser serializes the quota checks of the user until the transaction
+	// carried by ctx ends.
+	LockUser(ctx context.Context, userID uuid.UUID) error
+	// GetTotals sums the user's usage recorded in [from, to).
+	GetTotals(ctx context.Context, userID uuid.UUID, from, to time.Time) (UsageTotals, error)
+	// GetReport sums usage recorded in [from, to) per user, the heaviest
+	// users first.
+	GetReport(ctx context.Context, from, to time.Time, limit int) ([]UserUsageRow, error)
+}
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	AlertUnusualAmount NotificationType = "unusual_amount"
+	AlertDuplicate     NotificationType = "duplicate_charge"
+	AlertNewMerchant   NotificationType = "new_merchant"
+	DigestWeekly       NotificationType = "weekly_digest"
+	DigestMonthly      NotificationType = "monthly_digest"
+)
+
+func (t NotificationType) String() string {
+	return string(t)
+}
+
+// NotificationTypes lists every notification a user can turn on or off.
+func NotificationTypes() []NotificationType {
+	return []NotificationType{AlertUnusualAmount, AlertDuplicate, AlertNewMerchant, DigestWeekly, DigestMonthly}
+}
+
+func ParseNotificationType(s string) (NotificationType, error) {
+	for _, t := range NotificationTypes() {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", errors.New("unknown notification type")
+}
+
+// NotificationSettings holds the user's choices. Types without an explicit
+// choice are enabled.
+type NotificationSettings struct {
+	UserID    uuid.UUID
+	Enabled   map[NotificationType]bool
+	UpdatedAt time.Time
+}
+
+func NewNotificationSettings(userID uuid.UUID) *NotificationSettings {
+	return &NotificationSettings{
+		UserID:  userID,
+		Enabled: make(map[NotificationType]bool),
+	}
+}
+
+func (s *NotificationSettings) IsEnabled(t NotificationType) bool {
+	enabled, ok := s.Enabled[t]
+	return !ok || enabled
+}
+
+func (s *NotificationSettings) Set(t NotificationType, enabled bool) {
+	s.Enabled[t] = enabled
+	s.UpdatedAt = time.Now()
+}
+
+// Repository
+type NotificationSettingsRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*NotificationSettings, error)
+	Save(ctx context.Context, settings *NotificationSettings) error
+}
@@ -0,0 +1,212 @@
+package entities
+
+import (
+	"time"
+)
+
+// NamedPeriod is a period clients can ask for by name instead of dates.
+type NamedPeriod string
+
+const (
+	PeriodToday      NamedPeriod = "today"
+	PeriodYesterday  NamedPeriod = "yesterday"
+	PeriodThisWeek   NamedPeriod = "this_week"
+	PeriodLastWeek   NamedPeriod = "last_week"
+	PeriodThisMonth  NamedPeriod = "this_month"
+	PeriodLastMonth  NamedPeriod = "last_month"
+	PeriodThisYear   NamedPeriod = "this_year"
+	PeriodLastYear   NamedPeriod = "last_year"
+	PeriodLast7Days  NamedPeriod = "last_7_days"
+	PeriodLast30Days NamedPeriod = "last_30_days"
+	PeriodLast90Days NamedPeriod = "last_90_days"
+)
+
+func (p NamedPeriod) String() string {
+	return string(p)
+}
+
+func NamedPeriods() []NamedPeriod {
+	return []NamedPeriod{
+		PeriodToday, PeriodYesterday,
+		PeriodThisWeek, PeriodLastWeek,
+		PeriodThisMonth, PeriodLastMonth,
+		PeriodThisYear, PeriodLastYear,
+		PeriodLast7Days, PeriodLast30Days, PeriodLast90Days,
+	}
+}
+
+// Period is the half-open range [From, To). A zero bound is open.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+func (p Period) Contains(t time.Time) bool {
+	return (p.From.IsZero() || !t.Before(p.From)) && (p.To.IsZero() || t.Before(p.To))
+}
+
+// PeriodError is a period the client got wrong, Field is the request field
+// to blame.
+type PeriodError struct {
+	Field   string
+	Message string
+}
+
+func (e *PeriodError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// InvalidField names the input at fault, it makes PeriodError an
+// inerr.FieldError.
+func (e *PeriodError) InvalidField() (string, string) {
+	return e.Field, e.Message
+}
+
+// PeriodResolver turns named periods and dates into ranges of whole days in
+// one time zone, the user's. Every stats, filter and reminder path resolves
+// its period through it so a day means the same everywhere.
+type PeriodResolver struct {
+	loc *time.Location
+	now time.Time
+}
+
+func NewPeriodResolver(loc *time.Location, now time.Time) *PeriodResolver {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &PeriodResolver{loc: loc, now: now.In(loc)}
+}
+
+// Periods resolves periods in the user's time zone as of now.
+func (u *User) Periods() *PeriodResolver {
+	return NewPeriodResolver(u.Location(), time.Now())
+}
+
+func (r *PeriodResolver) Location() *time.Location {
+	return r.loc
+}
+
+// Now is the current time in the resolver's location.
+func (r *PeriodResolver) Now() time.Time {
+	return r.now
+}
+
+// Day is the start of the local day t falls on.
+func (r *PeriodResolver) Day(t time.Time) time.Time {
+	t = t.In(r.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.loc)
+}
+
+// Today is the start of the current local day.
+func (r *PeriodResolver) Today() time.Time {
+	return r.Day(r.now)
+}
+
+// Tomorrow is the end of the current local day, the default upper bound.
+func (r *PeriodResolver) Tomorrow() time.Time {
+	return r.Today().AddDate(0, 0, 1)
+}
+
+// Hour is the current local hour.
+func (r *PeriodResolver) Hour() Period {
+	from := time.Date(r.now.Year(), r.now.Month(), r.now.Day(), r.now.Hour(), 0, 0, 0, r.loc)
+	return Period{From: from, To: from.Add(time.Hour)}
+}
+
+// LastDays is the n days up to and including today.
+func (r *PeriodResolver) LastDays(n int) Period {
+	to := r.Tomorrow()
+	return Period{From: to.AddDate(0, 0, -n), To: to}
+}
+
+// Named resolves a named period. Weeks start on Monday.
+func (r *PeriodResolver) Named(name NamedPeriod) (Period, error) {
+	today := r.Today()
+	switch name {
+	case PeriodToday:
+		return Period{From: today, To: today.AddDate(0, 0, 1)}, nil
+	case PeriodYesterday:
+		return Period{From: today.AddDate(0, 0, -1), To: today}, nil
+	case PeriodThisWeek, PeriodLastWeek:
+		from := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		if name == PeriodLastWeek {
+			from = from.AddDate(0, 0, -7)
+		}
+		return Period{From: from, To: from.AddDate(0, 0, 7)}, nil
+	case PeriodThisMonth, PeriodLastMonth:
+		from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, r.loc)
+		if name == PeriodLastMonth {
+			from = from.AddDate(0, -1, 0)
+		}
+		return Period{From: from, To: from.AddDate(0, 1, 0)}, nil
+	case PeriodThisYear, PeriodLastYear:
+		from := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, r.loc)
+		if name == PeriodLastYear {
+			from = from.AddDate(-1, 0, 0)
+		}
+		return Period{From: from, To: from.AddDate(1, 0, 0)}, nil
+	case PeriodLast7Days:
+		return r.LastDays(7), nil
+	case PeriodLast30Days:
+		return r.LastDays(30), nil
+	case PeriodLast90Days:
+		return r.LastDays(90), nil
+	}
+	return Period{}, &PeriodError{Field: "period", Message: "unknown period"}
+}
+
+// Month resolves a "YYYY-MM" month.
+func (r *PeriodResolver) Month(month string) (Period, error) {
+	from, err := time.ParseInLocation(ReportMonthLayout, month, r.loc)
+	if err != nil {
+		return Period{}, &PeriodError{Field: "month", Message: "month must be in YYYY-MM format"}
+	}
+	return Period{From: from, To: from.AddDate(0, 1, 0)}, nil
+}
+
+// Date parses a "YYYY-MM-DD" date as the start of that local day.
+func (r *PeriodResolver) Date(field, date string) (time.Time, error) {
+	t, err := time.ParseInLocation(time.DateOnly, date, r.loc)
+	if err != nil {
+		return time.Time{}, &PeriodError{Field: field, Message: "invalid date format"}
+	}
+	return t, nil
+}
+
+// Resolve reads a period as clients send it, either a name or inclusive
+// "YYYY-MM-DD" dates, not both. Without a name To defaults to the end of
+// today and From to defaultFrom(To), which may be nil to leave it open.
+func (r *PeriodResolver) Resolve(name, from, to string, defaultFrom func(to time.Time) time.Time) (Period, error) {
+	if name != "" {
+		if from != "" || to != "" {
+			return Period{}, &PeriodError{Field: "period", Message: "period cannot be combined with from and to"}
+		}
+		return r.Named(NamedPeriod(name))
+	}
+
+	period := Period{To: r.Tomorrow()}
+	if to != "" {
+		t, err := r.Date("to", to)
+		if err != nil {
+			return Period{}, err
+		}
+		period.To = t.AddDate(0, 0, 1)
+	}
+
+	switch {
+	case from != "":
+		t, err := r.Date("from", from)
+		if err != nil {
+			return Period{}, err
+		}
+		period.From = t
+	case defaultFrom != nil:
+		period.From = defaultFrom(period.To)
+	}
+
+	if !period.From.IsZero() && !period.From.Before(period.To) {
+		return Period{}, &PeriodError{Field: "from", Message: "from must be before to"}
+	}
+
+	return period, nil
+}
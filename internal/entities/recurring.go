@@ -0,0 +1,117 @@
+package entities
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecurringFlow is a deposit or withdrawal seen on the same day of month
+// across several months, like a salary or a rent payment.
+type RecurringFlow struct {
+	Type       TrnType
+	DayOfMonth int
+	Amount     int64
+	// Transactions are the occurrences the flow was found in.
+	Transactions []*Transaction
+}
+
+// DueOn reports whether the flow is expected on date. A flow on the 31st is
+// expected on the last day of shorter months.
+func (f RecurringFlow) DueOn(date time.Time) bool {
+	return date.Day() == clampDay(date, f.DayOfMonth)
+}
+
+// RecurringDetector finds deposits and withdrawals repeating on the same day
+// of month.
+type RecurringDetector struct {
+	// MinMonths is how many distinct months a day of month must be seen in.
+	MinMonths int
+	// MaxSpread bounds the ratio between the largest and the smallest
+	// amount of a flow, zero accepts any amounts.
+	MaxSpread float64
+}
+
+// Detect groups deposits and withdrawals by their day of month in loc and
+// keeps the groups seen in enough distinct months. It returns the flows,
+// ordered by day, and the transactions that are not part of any.
+func (d RecurringDetector) Detect(transactions []*Transaction, loc *time.Location) ([]RecurringFlow, []*Transaction) {
+	type key struct {
+		trnType TrnType
+		day     int
+	}
+
+	groups := make(map[key][]*Transaction)
+	for _, t := range transactions {
+		if t.Type != Deposit && t.Type != Withdrawal {
+			continue
+		}
+		k := key{trnType: t.Type, day: t.OccurredAt().In(loc).Day()}
+		groups[k] = append(groups[k], t)
+	}
+
+	var (
+		flows     []RecurringFlow
+		recurring = make(map[uuid.UUID]bool)
+	)
+	for k, group := range groups {
+		months := make(map[string]bool)
+		var total, minAmount, maxAmount int64
+		for i, t := range group {
+			amount := t.AmountMinor()
+			months[t.OccurredAt().In(loc).Format("2006-01")] = true
+			total += amount
+			if i == 0 || amount < minAmount {
+				minAmount = amount
+			}
+			if amount > maxAmount {
+				maxAmount = amount
+			}
+		}
+
+		if len(months) < d.MinMonths || minAmount <= 0 {
+			continue
+		}
+		if d.MaxSpread > 0 && float64(maxAmount)/float64(minAmount) > d.MaxSpread {
+			continue
+		}
+
+		// Several hits in the same month add up, e.g. two rent transfers.
+		flows = append(flows, RecurringFlow{
+			Type:         k.trnType,
+			DayOfMonth:   k.day,
+			Amount:       total / int64(len(months)),
+			Transactions: group,
+		})
+		for _, t := range group {
+			recurring[t.ID] = true
+		}
+	}
+
+	sort.Slice(flows, func(i, j int) bool {
+		if flows[i].DayOfMonth != flows[j].DayOfMonth {
+			return flows[i].DayOfMonth < flows[j].DayOfMonth
+		}
+		return flows[i].Type < flows[j].Type
+	})
+
+	var rest []*Transaction
+	for _, t := range transactions {
+		if !recurring[t.ID] {
+			rest = append(rest, t)
+		}
+	}
+
+	return flows, rest
+}
+
+// clampDay returns day, or the last day of date's month when the month is
+// shorter, so a salary on the 31st is expected on the 30th in April.
+func clampDay(date time.Time, day int) int {
+	last := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+	if day > last {
+		return last
+	}
+	return day
+}
@@ -0,0 +1,220 @@
+package entities
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ReportBudgetMonths is how many months before the report are averaged
+	// into the expected spending of every category.
+	ReportBudgetMonths = 3
+	// reportTopMerchants is how many merchants the report lists.
+	reportTopMerchants = 5
+)
+
+// ReportMonthLayout is how report months are written, e.g. "2025-01".
+const ReportMonthLayout = "2006-01"
+
+// MonthlyReportInput is everything a monthly report is built from.
+// Transactions are the completed ones performed since ReportBudgetMonths
+// before From up to To. NetAfter is what was performed from To on per
+// account, it walks the current account balances back to the end of the
+// month.
+type MonthlyReportInput struct {
+	From         time.Time
+	To           time.Time
+	Accounts     []*Account
+	Transactions []*Transaction
+	NetAfter     map[uuid.UUID]int64
+}
+
+type MonthlyReport struct {
+	From         time.Time
+	To           time.Time
+	Income       int64
+	Expense      int64
+	IncomeCount  int
+	ExpenseCount int
+	// Categories break the expenses down, largest first. Category is nil
+	// for uncategorized transactions.
+	Categories []ReportCategory
+	Merchants  []ReportMerchant
+	Accounts   []ReportAccount
+	Budget     []ReportBudget
+	// Daily holds the expenses of every day of the month.
+	Daily []int64
+}
+
+type ReportCategory struct {
+	Category *Category
+	Total    int64
+	Count    int
+	Share    float64
+}
+
+type ReportMerchant struct {
+	Name  string
+	Total int64
+	Count int
+}
+
+type ReportAccount struct {
+	AccountID uuid.UUID
+	Name      string
+	Start     int64
+	End       int64
+}
+
+// ReportBudget compares the month's spending in a category with its
+// average over the previous months. There are no user defined budgets, so
+// the habit is the budget.
+type ReportBudget struct {
+	Category *Category
+	Expected int64
+	Spent    int64
+}
+
+func (b ReportBudget) Over() bool {
+	return b.Spent > b.Expected
+}
+
+// IsEmpty reports a month without a single transaction.
+func (r *MonthlyReport) IsEmpty() bool {
+	return r.IncomeCount == 0 && r.ExpenseCount == 0
+}
+
+func (r *MonthlyReport) Net() int64 {
+	return r.Income - r.Expense
+}
+
+// BuildMonthlyReport aggregates the month [From, To). Calendar math uses
+// the location of From.
+func BuildMonthlyReport(input MonthlyReportInput) *MonthlyReport {
+	loc := input.From.Location()
+	historyFrom := input.From.AddDate(0, -ReportBudgetMonths, 0)
+
+	report := &MonthlyReport{
+		From:  input.From,
+		To:    input.To,
+		Daily: make([]int64, input.To.AddDate(0, 0, -1).Day()),
+	}
+
+	var (
+		categories    = make(map[int]*ReportCategory)
+		merchants     = make(map[string]*ReportMerchant)
+		history       = make(map[int]int64)
+		historyMonths = make(map[time.Month]bool)
+		during        = make(map[uuid.UUID]int64)
+		categoryByID  = make(map[int]*Category)
+	)
+
+	for _, t := range input.Transactions {
+		occurredAt := t.OccurredAt().In(loc)
+
+		categoryID := 0
+		if t.Category != nil {
+			categoryID = t.Category.ID.Int()
+			categoryByID[categoryID] = t.Category
+		}
+
+		switch {
+		case !occurredAt.Before(input.To):
+			continue
+		case occurredAt.Before(historyFrom):
+			continue
+		case occurredAt.Before(input.From):
+			if t.Type == Withdrawal {
+				history[categoryID] += t.AmountMinor()
+				historyMonths[occurredAt.Month()] = true
+			}
+			continue
+		}
+
+		during[t.AccountID] += signedAmount(t)
+
+		switch t.Type {
+		case Deposit:
+			report.Income += t.AmountMinor()
+			report.IncomeCount++
+		case Withdrawal:
+			report.Expense += t.AmountMinor()
+			report.ExpenseCount++
+			report.Daily[occurredAt.Day()-1] += t.AmountMinor()
+
+			category := categories[categoryID]
+			if category == nil {
+				category = &ReportCategory{Category: t.Category}
+				categories[categoryID] = category
+			}
+			category.Total += t.AmountMinor()
+			category.Count++
+
+			if key := normalizeMerchant(t.Merchant); key != "" {
+				merchant := merchants[key]
+				if merchant == nil {
+					merchant = &ReportMerchant{Name: t.Merchant}
+					merchants[key] = merchant
+				}
+				merchant.Total += t.AmountMinor()
+				merchant.Count++
+			}
+		}
+	}
+
+	for _, category := range categories {
+		if report.Expense > 0 {
+			category.Share = float64(category.Total) / float64(report.Expense)
+		}
+		report.Categories = append(report.Categories, *category)
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Total > report.Categories[j].Total
+	})
+
+	for _, merchant := range merchants {
+		report.Merchants = append(report.Merchants, *merchant)
+	}
+	sort.Slice(report.Merchants, func(i, j int) bool {
+		if report.Merchants[i].Total != report.Merchants[j].Total {
+			return report.Merchants[i].Total > report.Merchants[j].Total
+		}
+		return report.Merchants[i].Name < report.Merchants[j].Name
+	})
+	if len(report.Merchants) > reportTopMerchants {
+		report.Merchants = report.Merchants[:reportTopMerchants]
+	}
+
+	for _, account := range input.Accounts {
+		end := account.Balance - input.NetAfter[account.ID]
+		report.Accounts = append(report.Accounts, ReportAccount{
+			AccountID: account.ID,
+			Name:      account.Name,
+			Start:     end - during[account.ID],
+			End:       end,
+		})
+	}
+
+	// Months without any expense are left out of the average, so a new
+	// user is not told they overspent compared to months they did not
+	// track.
+	if months := int64(len(historyMonths)); months > 0 {
+		for categoryID, total := range history {
+			budget := ReportBudget{
+				Category: categoryByID[categoryID],
+				Expected: total / months,
+			}
+			if category, ok := categories[categoryID]; ok {
+				budget.Spent = category.Total
+			}
+			report.Budget = append(report.Budget, budget)
+		}
+		sort.Slice(report.Budget, func(i, j int) bool {
+			return report.Budget[i].Expected > report.Budget[j].Expected
+		})
+	}
+
+	return report
+}
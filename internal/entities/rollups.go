@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// The transactions repository keeps daily rollups of completed deposits and
+// withdrawals per account, category, subcategory and local day of the user.
+// Stats read them instead of scanning the transactions. They are maintained
+// in the same database transaction as every write, the repository below
+// only repairs them.
+
+// Repository
+type RollupRepository interface {
+	// Rebuild recomputes the user's rollups from their transactions, in the
+	// user's current time zone.
+	Rebuild(ctx context.Context, userID uuid.UUID) error
+	// Verify compares the user's rollups with an aggregation of their
+	// transactions and returns how many rollup rows disagree.
+	Verify(ctx context.Context, userID uuid.UUID) (int, error)
+}
@@ -8,6 +8,9 @@ const (
 	EN Language = "en"
 	RU Language = "ru"
 	UZ Language = "uz"
+	KK Language = "kk"
+	TG Language = "tg"
+	TR Language = "tr"
 )
 
 func (l Language) String() string {
@@ -38,6 +41,12 @@ func (c Currency) String() string {
 	return string(c)
 }
 
+// IsKnown reports whether the currency is one the app supports.
+func (c Currency) IsKnown() bool {
+	_, ok := currencyScale[c]
+	return ok
+}
+
 func (c Currency) Scale() int {
 	if s, ok := currencyScale[c]; ok {
 		return s
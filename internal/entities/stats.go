@@ -0,0 +1,175 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Granularity is the size of a time-series bucket. Values match the units
+// accepted by postgres date_trunc.
+type Granularity string
+
+const (
+	Day   Granularity = "day"
+	Week  Granularity = "week"
+	Month Granularity = "month"
+)
+
+func (g Granularity) String() string {
+	return string(g)
+}
+
+func ParseGranularity(s string) (Granularity, error) {
+	switch g := Granularity(s); g {
+	case Day, Week, Month:
+		return g, nil
+	default:
+		return "", errors.New("unsupported granularity")
+	}
+}
+
+// Truncate returns the start of the bucket t falls into, in t's location.
+// Weeks start on Monday, same as date_trunc.
+func (g Granularity) Truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch g {
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// Next returns the start of the bucket following start.
+func (g Granularity) Next(start time.Time) time.Time {
+	switch g {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Buckets lists bucket starts covering [from, to).
+func (g Granularity) Buckets(from, to time.Time) []time.Time {
+	var buckets []time.Time
+	for start := g.Truncate(from); start.Before(to); start = g.Next(start) {
+		buckets = append(buckets, start)
+	}
+	return buckets
+}
+
+// DefaultFrom is where a series ending at to starts when no start is given,
+// 30 days, 12 weeks or 12 months back.
+func (g Granularity) DefaultFrom(to time.Time) time.Time {
+	switch g {
+	case Week:
+		return g.Truncate(to.AddDate(0, 0, -7*12))
+	case Month:
+		return g.Truncate(to.AddDate(0, -11, -1))
+	default:
+		return to.AddDate(0, 0, -30)
+	}
+}
+
+// TimeseriesFilter selects the transactions aggregated into a time series.
+// Buckets are computed on the transaction's local time in Location.
+type TimeseriesFilter struct {
+	UserID      uuid.UUID
+	AccountID   *uuid.UUID
+	Granularity Granularity
+	Location    *time.Location
+	From        time.Time
+	To          time.Time
+	ByCategory  bool
+}
+
+// TimeseriesRow is one aggregated bucket. CategoryID is only set when the
+// series is split by category, 0 stands for uncategorized transactions.
+type TimeseriesRow struct {
+	Bucket     time.Time
+	CategoryID int
+	Income     int64
+	Expense    int64
+}
+
+// PreviousPeriod returns the period of the same length right before the
+// half-open [from, to) range. Ranges starting on the first of a month are
+// shifted by whole months, so March compares to February and March 1-19 to
+// February 1-19, whatever the month lengths are.
+func PreviousPeriod(from, to time.Time) (time.Time, time.Time) {
+	if from.Day() != 1 {
+		days := int(to.Sub(from).Hours()/24 + 0.5)
+		return from.AddDate(0, 0, -days), from
+	}
+
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() != 1 || to.Hour() != 0 || to.Minute() != 0 {
+		months++
+	}
+	if months < 1 {
+		months = 1
+	}
+
+	return addMonthsClamped(from, -months), addMonthsClamped(to, -months)
+}
+
+// addMonthsClamped moves t by n months keeping the day within the target
+// month, unlike time.AddDate which overflows March 31 into March 3.
+func addMonthsClamped(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// PeriodComparisonFilter selects the two periods compared by
+// GetCategoryTotalsComparison. Both ranges are half-open and bounded by
+// local midnights of the user, the rollups hold whole days.
+type PeriodComparisonFilter struct {
+	UserID       uuid.UUID
+	AccountID    *uuid.UUID
+	CurrentFrom  time.Time
+	CurrentTo    time.Time
+	PreviousFrom time.Time
+	PreviousTo   time.Time
+}
+
+// CategoryComparisonRow holds the totals of one category and type for both
+// periods. CategoryID 0 stands for uncategorized transactions.
+type CategoryComparisonRow struct {
+	CategoryID int
+	Type       TrnType
+	Current    int64
+	Previous   int64
+}
+
+// CategoryBreakdownFilter selects the transactions summed by
+// GetCategoryBreakdown. Nil bounds leave the range open, others are local
+// midnights of the user.
+type CategoryBreakdownFilter struct {
+	UserID    uuid.UUID
+	AccountID *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+}
+
+// CategoryBreakdownRow holds the total and count of one type, category and
+// subcategory. CategoryID and SubcategoryID are 0 when the column is NULL.
+type CategoryBreakdownRow struct {
+	Type          TrnType
+	CategoryID    int
+	SubcategoryID int
+	Total         int64
+	Count         int
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,10 +14,9 @@ type Subcategory struct {
 	CategoryID int
 	UserID     uuid.UUID
 	Position   int
-	NameEN     string
-	NameRU     string
-	NameUZ     string
+	Names      Translations
 	Emoji      string
+	Hidden     bool // per user, resolved from preferences like Position
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
@@ -42,24 +42,71 @@ func NewUserSubcategory(
 	return &Subcategory{
 		CategoryID: categoryID,
 		UserID:     userID,
-		NameEN:     name,
-		NameRU:     name,
-		NameUZ:     name,
+		Names:      Translations{DefaultLanguage: name},
 		Emoji:      emoji,
 	}, nil
 }
 
 func (s *Subcategory) GetName(lang Language) string {
-	switch lang {
-	case EN:
-		return s.NameEN
-	case RU:
-		return s.NameRU
-	case UZ:
-		return s.NameUZ
-	default:
-		return s.NameEN
+	return s.Names.Get(lang)
+}
+
+// IsSystem reports whether the subcategory is a seeded one shared by all users.
+func (s *Subcategory) IsSystem() bool {
+	return s.UserID == uuid.Nil
+}
+
+// IsVisibleTo reports whether the user can see the subcategory: either a system
+// one or one the user created.
+func (s *Subcategory) IsVisibleTo(userID uuid.UUID) bool {
+	return s.IsSystem() || s.UserID == userID
+}
+
+// Update renames the subcategory and changes its emoji. A non-empty name replaces
+// every translation, the same way a new user subcategory is created, while
+// localized names only replace the language they belong to. An empty emoji
+// keeps the current one.
+func (s *Subcategory) Update(name string, localized map[Language]string, emoji string) error {
+	if s.IsSystem() {
+		return errors.New("system subcategory cannot be modified")
+	}
+
+	if name = strings.TrimSpace(name); name != "" {
+		s.Names = Translations{DefaultLanguage: name}
+	}
+
+	if err := s.Localize(localized); err != nil {
+		return err
 	}
+
+	if emoji != "" {
+		s.Emoji = emoji
+	}
+
+	s.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// Localize replaces the names of the given languages, skipping empty ones.
+func (s *Subcategory) Localize(names map[Language]string) error {
+	for lang, value := range names {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !lang.IsSupported() {
+			return errors.New("unsupported language")
+		}
+
+		if s.Names == nil {
+			s.Names = make(Translations)
+		}
+		s.Names[lang] = value
+	}
+
+	return nil
 }
 
 // Repository
@@ -69,4 +116,7 @@ type SubcategoryRepository interface {
 	FindByID(ctx context.Context, id int) (*Subcategory, error)
 	FindByCategoryID(ctx context.Context, categoryID int, userID uuid.UUID) ([]*Subcategory, error)
 	Delete(ctx context.Context, userID uuid.UUID, id int) error
+	SetPositions(ctx context.Context, userID uuid.UUID, positions []CategoryPosition) error
+	SetHidden(ctx context.Context, userID uuid.UUID, id int, hidden bool) error
+	MoveToCategory(ctx context.Context, userID uuid.UUID, fromCategoryID, toCategoryID int) (int, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -49,9 +50,14 @@ type Transaction struct {
 	OriginalCurrencyCode Currency
 	FxRate               float64
 	RowText              string
-	PerformedAt          time.Time
-	RejectedAt           time.Time
-	CreatedAt            time.Time
+	Merchant             string
+	ImportSessionID      *uuid.UUID
+	// ExternalID is the bank's reference for imported transactions, unique
+	// per account.
+	ExternalID  string
+	PerformedAt time.Time
+	RejectedAt  time.Time
+	CreatedAt   time.Time
 }
 
 func NewTransaction(
@@ -170,6 +176,25 @@ func (t *Transaction) SetFxRate(rate float64) error {
 	return nil
 }
 
+func (t *Transaction) SetMerchant(merchant string) {
+	t.Merchant = strings.TrimSpace(merchant)
+}
+
+// Imported links the transaction to the import session it came from, so the
+// import can be rolled back.
+func (t *Transaction) Imported(sessionID uuid.UUID) {
+	t.ImportSessionID = &sessionID
+}
+
+// OccurredAt is when the money actually moved, falling back to when the
+// transaction was recorded.
+func (t *Transaction) OccurredAt() time.Time {
+	if t.PerformedAt.IsZero() {
+		return t.CreatedAt
+	}
+	return t.PerformedAt
+}
+
 func (t *Transaction) Performed(performedAt time.Time) {
 	t.Status = Completed
 	t.PerformedAt = performedAt
@@ -186,11 +211,13 @@ func (t *Transaction) Update(
 	originalCurrency *string,
 	fxRate *float64,
 	rowText string,
+	merchant string,
 	performedAt *time.Time,
 ) error {
 	t.Type = trnType
 	t.RowText = rowText
 	t.AccountID = accountID
+	t.SetMerchant(merchant)
 
 	err := t.Categorise(category, subcategory)
 	if err != nil {
@@ -230,17 +257,59 @@ func (t *Transaction) Update(
 	return nil
 }
 
+// TransactionSearchHit is a single full-text search match with its relevance
+// rank and highlighted fragments of the matched fields.
+type TransactionSearchHit struct {
+	Transaction       *Transaction
+	Rank              float64
+	NoteHighlight     string
+	MerchantHighlight string
+}
+
+// TransactionFilter narrows a listing of a user's transactions. Zero values
+// leave the matching dimension unfiltered.
+type TransactionFilter struct {
+	UserID    uuid.UUID
+	AccountID *uuid.UUID
+	Types     []TrnType
+	From      *time.Time
+	To        *time.Time
+	// ImportSessionID keeps only transactions created by that import.
+	ImportSessionID *uuid.UUID
+}
+
+// TransactionCursor points after the last transaction of a batch, ordered by
+// when it occurred and then by id.
+type TransactionCursor struct {
+	OccurredAt time.Time
+	ID         uuid.UUID
+}
+
 // Repository
 
 type TransactionRepository interface {
 	Save(ctx context.Context, transaction *Transaction) error
+	SaveBatch(ctx context.Context, transactions []*Transaction) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Transaction, error)
+	// GetByIDForUpdate locks the transaction until the transaction carried
+	// by ctx ends.
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*Transaction, error)
 	GetByUserID(ctx context.Context, limit, offset int, userID uuid.UUID, trnType []TrnType) ([]*Transaction, int, error)
 	GetByAccountID(ctx context.Context, accountID uuid.UUID) ([]*Transaction, error)
-	GetTotalByType(ctx context.Context, userID uuid.UUID, trnType TrnType, from, to *time.Time) (int64, error)
-	GetTotalByTypeAndAccount(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, trnType TrnType, from, to *time.Time) (int64, error)
-	GetTotalsByCategories(ctx context.Context, userID uuid.UUID, trnType TrnType, from, to *time.Time) (map[int]int64, []int, error)
-	GetTotalsByCategoriesAndAccount(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, trnType TrnType, from, to *time.Time) (map[int]int64, []int, error)
 	GetAllBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*Transaction, error)
+	GetPerformedBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*Transaction, error)
+	// GetNetByAccountSince sums completed deposits less withdrawals
+	// performed at or after from, per account.
+	GetNetByAccountSince(ctx context.Context, userID uuid.UUID, from time.Time) (map[uuid.UUID]int64, error)
+	GetBatch(ctx context.Context, filter TransactionFilter, after *TransactionCursor, limit int) ([]*Transaction, error)
+	Search(ctx context.Context, userID uuid.UUID, lang Language, text string, limit, offset int) ([]*TransactionSearchHit, int, error)
+	CountByCategory(ctx context.Context, userID uuid.UUID, categoryID int) (int, error)
+	ReassignCategory(ctx context.Context, userID uuid.UUID, fromCategoryID, toCategoryID int) (int, error)
+	ClearSubcategory(ctx context.Context, userID uuid.UUID, subcategoryID int) (int, error)
+	GetTimeseries(ctx context.Context, filter TimeseriesFilter) ([]*TimeseriesRow, error)
+	GetCategoryTotalsComparison(ctx context.Context, filter PeriodComparisonFilter) ([]*CategoryComparisonRow, error)
+	GetCategoryBreakdown(ctx context.Context, filter CategoryBreakdownFilter) ([]*CategoryBreakdownRow, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByImportSession(ctx context.Context, sessionID uuid.UUID) (int, error)
+	FindExternalIDs(ctx context.Context, accountID uuid.UUID, ids []string) (map[string]bool, error)
 }
@@ -17,6 +17,7 @@ type User struct {
 	LanguageCode Language
 	CurrencyCode Currency
 	Timezone     string
+	Plan         Plan
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
@@ -32,6 +33,7 @@ func NewUser(tgUserID int64, firstName, lastName, username string) (*User, error
 		FirstName: firstName,
 		LastName:  lastName,
 		Username:  username,
+		Plan:      PlanFree,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}, nil
@@ -52,6 +54,20 @@ func (u *User) UpdateTimezone(timezone string) {
 	u.UpdatedAt = time.Now()
 }
 
+// Location returns the user's time zone, UTC when it is unset or unknown.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
 // Refpository
 
 type UserRepository interface {
@@ -0,0 +1,32 @@
+package inerr
+
+import "time"
+
+// ErrQuotaExceeded is returned when the user spent their LLM quota of a
+// period, Metric names what ran out.
+type ErrQuotaExceeded struct {
+	Period  string
+	Metric  string
+	ResetAt time.Time
+}
+
+func (e ErrQuotaExceeded) Error() string {
+	return e.Period + " " + e.Metric + " quota exceeded"
+}
+
+func (e ErrQuotaExceeded) Is(target error) bool {
+	_, ok := target.(ErrQuotaExceeded)
+	if ok {
+		return true
+	}
+	_, ok = target.(*ErrQuotaExceeded)
+	return ok
+}
+
+func NewErrQuotaExceeded(period, metric string, resetAt time.Time) error {
+	return ErrQuotaExceeded{
+		Period:  period,
+		Metric:  metric,
+		ResetAt: resetAt,
+	}
+}
@@ -1,5 +1,7 @@
 package inerr
 
+import "errors"
+
 type ErrValidation struct {
 	Item string
 	Msg  string
@@ -24,3 +26,19 @@ func NewErrValidation(item, msg string) error {
 		Msg:  msg,
 	}
 }
+
+// FieldError is a domain error blaming one input field, see AsValidation.
+type FieldError interface {
+	error
+	InvalidField() (item, msg string)
+}
+
+// AsValidation reports a FieldError anywhere in err's chain as a validation
+// error of its field, other errors are returned as they are.
+func AsValidation(err error) error {
+	var ferr FieldError
+	if errors.As(err, &ferr) {
+		return NewErrValidation(ferr.InvalidField())
+	}
+	return err
+}
@@ -3,6 +3,8 @@ package dictionary
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
@@ -15,15 +17,13 @@ import (
 type Categories struct {
 	bun.BaseModel `bun:"table:categories,alias:c"`
 
-	ID        int        `bun:"id,pk,autoincrement"`
-	UserID    *string    `bun:"user_id"`
-	Position  int        `bun:"position"`
-	NameEN    string     `bun:"name_en"`
-	NameRU    string     `bun:"name_ru"`
-	NameUZ    string     `bun:"name_uz"`
-	Emoji     string     `bun:"emoji"`
-	CreatedAt time.Time  `bun:"created_at"`
-	UpdatedAt *time.Time `bun:"updated_at,nullzero"`
+	ID        int                   `bun:"id,pk,autoincrement"`
+	UserID    *string               `bun:"user_id"`
+	Position  int                   `bun:"position"`
+	Names     entities.Translations `bun:"-"`
+	Emoji     string                `bun:"emoji"`
+	CreatedAt time.Time             `bun:"created_at"`
+	UpdatedAt *time.Time            `bun:"updated_at,nullzero"`
 }
 
 func (c Categories) Key() int {
@@ -34,6 +34,18 @@ func (c Categories) Code() string {
 	return fmt.Sprintf("%d", c.ID)
 }
 
+// CategoryPreferences keeps per-user ordering and visibility, which is how users
+// arrange categories they don't own.
+type CategoryPreferences struct {
+	bun.BaseModel `bun:"table:category_preferences,alias:cp"`
+
+	UserID     string    `bun:"user_id,pk"`
+	CategoryID int       `bun:"category_id,pk"`
+	Position   *int      `bun:"position,nullzero"`
+	Hidden     bool      `bun:"hidden"`
+	UpdatedAt  time.Time `bun:"updated_at"`
+}
+
 type categoriesDict struct {
 	*postgres.BaseDictionary[int, *Categories]
 	db bun.IDB
@@ -41,8 +53,20 @@ type categoriesDict struct {
 
 func NewCategoriesDict(db bun.IDB) entities.CategoryRepository {
 	return &categoriesDict{
-		BaseDictionary: postgres.NewDictionary(db, postgres.WithOrderBy[int, *Categories]("position", "asc")),
-		db:             db,
+		BaseDictionary: postgres.NewDictionary(db,
+			postgres.WithOrderBy[int, *Categories]("position", "asc"),
+			postgres.WithOnLoad[int](func(items []*Categories) error {
+				names, err := loadTranslations(context.Background(), db, translationEntityCategory)
+				if err != nil {
+					return err
+				}
+				for _, item := range items {
+					item.Names = names[item.ID]
+				}
+				return nil
+			}),
+		),
+		db: db,
 	}
 }
 
@@ -51,23 +75,27 @@ func (d *categoriesDict) Save(ctx context.Context, category *entities.Category)
 	model := d.ToModel(category)
 
 	var id int
-	_, err := db.NewInsert().Model(model).
-		On("CONFLICT (id) DO UPDATE").
-		Set("user_id = excluded.user_id").
-		Set("position = excluded.position").
-		Set("name_en = excluded.name_en").
-		Set("name_ru = excluded.name_ru").
-		Set("name_uz = excluded.name_uz").
-		Set("emoji = excluded.emoji").
-		Set("updated_at = excluded.updated_at").
-		Returning("id").
-		Exec(ctx, &id)
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(model).
+			On("CONFLICT (id) DO UPDATE").
+			Set("user_id = excluded.user_id").
+			Set("position = excluded.position").
+			Set("emoji = excluded.emoji").
+			Set("updated_at = excluded.updated_at").
+			Returning("id").
+			Exec(ctx, &id)
+		if err != nil {
+			return postgres.Error(err, model)
+		}
+
+		return saveTranslations(ctx, tx, translationEntityCategory, id, category.Names)
+	})
 	if err != nil {
-		return postgres.Error(err, model)
+		return err
 	}
 
 	category.ID = entities.CategoryID(id)
-	d.BaseDictionary.Load(ctx)
+	postgres.AfterCommit(ctx, d.reload)
 
 	return nil
 }
@@ -86,6 +114,10 @@ func (d *categoriesDict) FindAll(ctx context.Context, userID uuid.UUID) ([]*enti
 		categories = append(categories, d.ToEntity(item))
 	}
 
+	if err := d.applyPreferences(ctx, userID, categories); err != nil {
+		return nil, err
+	}
+
 	return categories, nil
 }
 func (d *categoriesDict) FindByID(ctx context.Context, id int) (*entities.Category, error) {
@@ -100,19 +132,132 @@ func (d *categoriesDict) FindByID(ctx context.Context, id int) (*entities.Catego
 func (d *categoriesDict) Delete(ctx context.Context, userID uuid.UUID, id int) error {
 	db := postgres.FromContext(ctx, d.db)
 
-	_, err := db.NewDelete().Model(&Categories{}).
-		Where("user_id = ?", userID.String()).
-		Where("id = ?", id).
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewDelete().Model(&Categories{}).
+			Where("user_id = ?", userID.String()).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, Categories{})
+		}
+
+		// translations have no foreign key, so only clean them up when the
+		// row was actually ours to delete
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return nil
+		}
+
+		return deleteTranslations(ctx, tx, translationEntityCategory, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	postgres.AfterCommit(ctx, d.reload)
+
+	return nil
+}
+
+func (d *categoriesDict) SetPositions(ctx context.Context, userID uuid.UUID, positions []entities.CategoryPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	db := postgres.FromContext(ctx, d.db)
+	now := time.Now()
+
+	models := make([]*CategoryPreferences, 0, len(positions))
+	for _, p := range positions {
+		models = append(models, &CategoryPreferences{
+			UserID:     userID.String(),
+			CategoryID: p.ID,
+			Position:   pointer.Int(p.Position),
+			UpdatedAt:  now,
+		})
+	}
+
+	_, err := db.NewInsert().Model(&models).
+		On("CONFLICT (user_id, category_id) DO UPDATE").
+		Set("position = EXCLUDED.position").
+		Set("updated_at = EXCLUDED.updated_at").
 		Exec(ctx)
 	if err != nil {
-		return postgres.Error(err, Categories{})
+		return postgres.Error(err, CategoryPreferences{})
 	}
 
-	d.BaseDictionary.Load(ctx)
+	return nil
+}
+
+func (d *categoriesDict) SetHidden(ctx context.Context, userID uuid.UUID, id int, hidden bool) error {
+	db := postgres.FromContext(ctx, d.db)
+
+	model := &CategoryPreferences{
+		UserID:     userID.String(),
+		CategoryID: id,
+		Hidden:     hidden,
+		UpdatedAt:  time.Now(),
+	}
+
+	_, err := db.NewInsert().Model(model).
+		On("CONFLICT (user_id, category_id) DO UPDATE").
+		Set("hidden = EXCLUDED.hidden").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, model)
+	}
 
 	return nil
 }
 
+// applyPreferences overlays the user's ordering and visibility on top of the
+// cached dictionary items and re-sorts them by the resulting position.
+func (d *categoriesDict) applyPreferences(ctx context.Context, userID uuid.UUID, items []*entities.Category) error {
+	db := postgres.FromContext(ctx, d.db)
+
+	var prefs []*CategoryPreferences
+	err := db.NewSelect().Model(&prefs).
+		Where("user_id = ?", userID.String()).
+		Scan(ctx)
+	if err != nil {
+		return postgres.Error(err, CategoryPreferences{})
+	}
+
+	if len(prefs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*CategoryPreferences, len(prefs))
+	for _, pref := range prefs {
+		byID[pref.CategoryID] = pref
+	}
+
+	for _, item := range items {
+		pref, ok := byID[item.ID.Int()]
+		if !ok {
+			continue
+		}
+		if pref.Position != nil {
+			item.Position = *pref.Position
+		}
+		item.Hidden = pref.Hidden
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Position < items[j].Position
+	})
+
+	return nil
+}
+
+// reload refreshes the cached items once the change is visible to other
+// connections, see postgres.AfterCommit.
+func (d *categoriesDict) reload(ctx context.Context) {
+	if err := d.BaseDictionary.Load(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to reload dictionary", slog.String("error.message", err.Error()))
+	}
+}
+
 func (d *categoriesDict) ToEntity(c *Categories) *entities.Category {
 	var userID uuid.UUID
 	if c.UserID != nil {
@@ -123,9 +268,7 @@ func (d *categoriesDict) ToEntity(c *Categories) *entities.Category {
 		ID:        entities.CategoryID(c.ID),
 		UserID:    userID,
 		Position:  c.Position,
-		NameEN:    c.NameEN,
-		NameRU:    c.NameRU,
-		NameUZ:    c.NameUZ,
+		Names:     c.Names.Clone(),
 		Emoji:     c.Emoji,
 		CreatedAt: c.CreatedAt,
 		UpdatedAt: pointer.TimeValue(c.UpdatedAt),
@@ -137,9 +280,7 @@ func (d *categoriesDict) ToModel(c *entities.Category) *Categories {
 		ID:        c.ID.Int(),
 		UserID:    pointer.String(c.UserID.String()),
 		Position:  c.Position,
-		NameEN:    c.NameEN,
-		NameRU:    c.NameRU,
-		NameUZ:    c.NameUZ,
+		Names:     c.Names.Clone(),
 		Emoji:     c.Emoji,
 		CreatedAt: c.CreatedAt,
 		UpdatedAt: pointer.Time(c.UpdatedAt),
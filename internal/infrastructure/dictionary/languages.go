@@ -0,0 +1,66 @@
+package dictionary
+
+import (
+	"context"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/shogo82148/pointer"
+	"github.com/uptrace/bun"
+)
+
+type Languages struct {
+	bun.BaseModel `bun:"table:languages,alias:lg"`
+
+	Locale       string  `bun:"code,pk"`
+	Name         string  `bun:"name"`
+	Fallback     *string `bun:"fallback,nullzero"`
+	SearchConfig string  `bun:"search_config"`
+	Position     int     `bun:"position"`
+	Enabled      bool    `bun:"enabled"`
+}
+
+func (l Languages) Key() string {
+	return l.Locale
+}
+
+func (l Languages) Code() string {
+	return l.Locale
+}
+
+type languagesDict struct {
+	*postgres.BaseDictionary[string, *Languages]
+}
+
+func NewLanguagesDict(db bun.IDB) entities.LanguageRepository {
+	return &languagesDict{
+		BaseDictionary: postgres.NewDictionary(db, postgres.WithOrderBy[string, *Languages]("position", "asc")),
+	}
+}
+
+func (d *languagesDict) FindAll(ctx context.Context) ([]*entities.LanguageInfo, error) {
+	items, err := d.BaseDictionary.Values(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var languages []*entities.LanguageInfo
+	for _, item := range items {
+		if !item.Enabled {
+			continue
+		}
+		languages = append(languages, d.ToEntity(item))
+	}
+
+	return languages, nil
+}
+
+func (d *languagesDict) ToEntity(l *Languages) *entities.LanguageInfo {
+	return &entities.LanguageInfo{
+		Code:         entities.Language(l.Locale),
+		Name:         l.Name,
+		Fallback:     entities.Language(pointer.StringValue(l.Fallback)),
+		SearchConfig: l.SearchConfig,
+		Position:     l.Position,
+	}
+}
@@ -3,6 +3,8 @@ package dictionary
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
@@ -15,16 +17,14 @@ import (
 type Subcategories struct {
 	bun.BaseModel `bun:"table:subcategories,alias:sb"`
 
-	ID         int        `bun:"id,pk,autoincrement"`
-	CategoryID int        `bun:"category_id"`
-	UserID     *string    `bun:"user_id,nullzero"`
-	Position   int        `bun:"position"`
-	NameEN     string     `bun:"name_en"`
-	NameRU     string     `bun:"name_ru"`
-	NameUZ     string     `bun:"name_uz"`
-	Emoji      string     `bun:"emoji"`
-	CreatedAt  time.Time  `bun:"created_at"`
-	UpdatedAt  *time.Time `bun:"updated_at,nullzero"`
+	ID         int                   `bun:"id,pk,autoincrement"`
+	CategoryID int                   `bun:"category_id"`
+	UserID     *string               `bun:"user_id,nullzero"`
+	Position   int                   `bun:"position"`
+	Names      entities.Translations `bun:"-"`
+	Emoji      string                `bun:"emoji"`
+	CreatedAt  time.Time             `bun:"created_at"`
+	UpdatedAt  *time.Time            `bun:"updated_at,nullzero"`
 }
 
 func (s Subcategories) Key() int {
@@ -35,6 +35,18 @@ func (s Subcategories) Code() string {
 	return fmt.Sprintf("%d", s.ID)
 }
 
+// SubcategoryPreferences keeps per-user ordering and visibility, which is how users
+// arrange subcategories they don't own.
+type SubcategoryPreferences struct {
+	bun.BaseModel `bun:"table:subcategory_preferences,alias:sbp"`
+
+	UserID        string    `bun:"user_id,pk"`
+	SubcategoryID int       `bun:"subcategory_id,pk"`
+	Position      *int      `bun:"position,nullzero"`
+	Hidden        bool      `bun:"hidden"`
+	UpdatedAt     time.Time `bun:"updated_at"`
+}
+
 type subcategoriesDict struct {
 	*postgres.BaseDictionary[int, *Subcategories]
 	db bun.IDB
@@ -42,8 +54,20 @@ type subcategoriesDict struct {
 
 func NewSubcategoriesDict(db bun.IDB) entities.SubcategoryRepository {
 	return &subcategoriesDict{
-		BaseDictionary: postgres.NewDictionary(db, postgres.WithOrderBy[int, *Subcategories]("position", "asc")),
-		db:             db,
+		BaseDictionary: postgres.NewDictionary(db,
+			postgres.WithOrderBy[int, *Subcategories]("position", "asc"),
+			postgres.WithOnLoad[int](func(items []*Subcategories) error {
+				names, err := loadTranslations(context.Background(), db, translationEntitySubcategory)
+				if err != nil {
+					return err
+				}
+				for _, item := range items {
+					item.Names = names[item.ID]
+				}
+				return nil
+			}),
+		),
+		db: db,
 	}
 }
 
@@ -52,24 +76,28 @@ func (d *subcategoriesDict) Save(ctx context.Context, subcategory *entities.Subc
 	model := d.ToModel(subcategory)
 
 	var id int
-	_, err := db.NewInsert().Model(model).
-		On("CONFLICT (id) DO UPDATE").
-		Set("category_id = excluded.category_id").
-		Set("user_id = excluded.user_id").
-		Set("position = excluded.position").
-		Set("name_en = excluded.name_en").
-		Set("name_ru = excluded.name_ru").
-		Set("name_uz = excluded.name_uz").
-		Set("emoji = excluded.emoji").
-		Set("updated_at = excluded.updated_at").
-		Returning("id").
-		Exec(ctx, &id)
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(model).
+			On("CONFLICT (id) DO UPDATE").
+			Set("category_id = excluded.category_id").
+			Set("user_id = excluded.user_id").
+			Set("position = excluded.position").
+			Set("emoji = excluded.emoji").
+			Set("updated_at = excluded.updated_at").
+			Returning("id").
+			Exec(ctx, &id)
+		if err != nil {
+			return postgres.Error(err, model)
+		}
+
+		return saveTranslations(ctx, tx, translationEntitySubcategory, id, subcategory.Names)
+	})
 	if err != nil {
-		return postgres.Error(err, model)
+		return err
 	}
 
 	subcategory.ID = id
-	d.BaseDictionary.Load(ctx)
+	postgres.AfterCommit(ctx, d.reload)
 
 	return nil
 }
@@ -88,6 +116,10 @@ func (d *subcategoriesDict) FindAll(ctx context.Context, userID uuid.UUID) ([]*e
 		subcategories = append(subcategories, d.ToEntity(item))
 	}
 
+	if err := d.applyPreferences(ctx, userID, subcategories); err != nil {
+		return nil, err
+	}
+
 	return subcategories, nil
 }
 
@@ -123,19 +155,157 @@ func (d *subcategoriesDict) FindByCategoryID(ctx context.Context, categoryID int
 func (d *subcategoriesDict) Delete(ctx context.Context, userID uuid.UUID, id int) error {
 	db := postgres.FromContext(ctx, d.db)
 
-	_, err := db.NewDelete().Model(&Subcategories{}).
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewDelete().Model(&Subcategories{}).
+			Where("user_id = ?", userID.String()).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, Subcategories{})
+		}
+
+		// translations have no foreign key, so only clean them up when the
+		// row was actually ours to delete
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return nil
+		}
+
+		return deleteTranslations(ctx, tx, translationEntitySubcategory, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	postgres.AfterCommit(ctx, d.reload)
+
+	return nil
+}
+
+// MoveToCategory re-parents the user's own subcategories. System ones are
+// never moved since they are shared with other users.
+func (d *subcategoriesDict) MoveToCategory(ctx context.Context, userID uuid.UUID, fromCategoryID, toCategoryID int) (int, error) {
+	db := postgres.FromContext(ctx, d.db)
+
+	res, err := db.NewUpdate().Model((*Subcategories)(nil)).
+		Set("category_id = ?", toCategoryID).
+		Set("updated_at = ?", time.Now()).
 		Where("user_id = ?", userID.String()).
-		Where("id = ?", id).
+		Where("category_id = ?", fromCategoryID).
+		Exec(ctx)
+	if err != nil {
+		return 0, postgres.Error(err, Subcategories{})
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	postgres.AfterCommit(ctx, d.reload)
+
+	return int(affected), nil
+}
+
+func (d *subcategoriesDict) SetPositions(ctx context.Context, userID uuid.UUID, positions []entities.CategoryPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	db := postgres.FromContext(ctx, d.db)
+	now := time.Now()
+
+	models := make([]*SubcategoryPreferences, 0, len(positions))
+	for _, p := range positions {
+		models = append(models, &SubcategoryPreferences{
+			UserID:        userID.String(),
+			SubcategoryID: p.ID,
+			Position:      pointer.Int(p.Position),
+			UpdatedAt:     now,
+		})
+	}
+
+	_, err := db.NewInsert().Model(&models).
+		On("CONFLICT (user_id, subcategory_id) DO UPDATE").
+		Set("position = EXCLUDED.position").
+		Set("updated_at = EXCLUDED.updated_at").
 		Exec(ctx)
 	if err != nil {
-		return postgres.Error(err, Subcategories{})
+		return postgres.Error(err, SubcategoryPreferences{})
 	}
 
-	d.BaseDictionary.Load(ctx)
+	return nil
+}
+
+func (d *subcategoriesDict) SetHidden(ctx context.Context, userID uuid.UUID, id int, hidden bool) error {
+	db := postgres.FromContext(ctx, d.db)
+
+	model := &SubcategoryPreferences{
+		UserID:        userID.String(),
+		SubcategoryID: id,
+		Hidden:        hidden,
+		UpdatedAt:     time.Now(),
+	}
+
+	_, err := db.NewInsert().Model(model).
+		On("CONFLICT (user_id, subcategory_id) DO UPDATE").
+		Set("hidden = EXCLUDED.hidden").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, model)
+	}
 
 	return nil
 }
 
+// applyPreferences overlays the user's ordering and visibility on top of the
+// cached dictionary items and re-sorts them by the resulting position.
+func (d *subcategoriesDict) applyPreferences(ctx context.Context, userID uuid.UUID, items []*entities.Subcategory) error {
+	db := postgres.FromContext(ctx, d.db)
+
+	var prefs []*SubcategoryPreferences
+	err := db.NewSelect().Model(&prefs).
+		Where("user_id = ?", userID.String()).
+		Scan(ctx)
+	if err != nil {
+		return postgres.Error(err, SubcategoryPreferences{})
+	}
+
+	if len(prefs) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*SubcategoryPreferences, len(prefs))
+	for _, pref := range prefs {
+		byID[pref.SubcategoryID] = pref
+	}
+
+	for _, item := range items {
+		pref, ok := byID[item.ID]
+		if !ok {
+			continue
+		}
+		if pref.Position != nil {
+			item.Position = *pref.Position
+		}
+		item.Hidden = pref.Hidden
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Position < items[j].Position
+	})
+
+	return nil
+}
+
+// reload refreshes the cached items once the change is visible to other
+// connections, see postgres.AfterCommit.
+func (d *subcategoriesDict) reload(ctx context.Context) {
+	if err := d.BaseDictionary.Load(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to reload dictionary", slog.String("error.message", err.Error()))
+	}
+}
+
 func (d *subcategoriesDict) ToEntity(s *Subcategories) *entities.Subcategory {
 
 	var userID uuid.UUID
@@ -148,9 +318,7 @@ func (d *subcategoriesDict) ToEntity(s *Subcategories) *entities.Subcategory {
 		CategoryID: s.CategoryID,
 		UserID:     userID,
 		Position:   s.Position,
-		NameEN:     s.NameEN,
-		NameRU:     s.NameRU,
-		NameUZ:     s.NameUZ,
+		Names:      s.Names.Clone(),
 		Emoji:      s.Emoji,
 		CreatedAt:  s.CreatedAt,
 		UpdatedAt:  pointer.TimeValue(s.UpdatedAt),
@@ -163,9 +331,7 @@ func (d *subcategoriesDict) ToModel(s *entities.Subcategory) *Subcategories {
 		CategoryID: s.CategoryID,
 		UserID:     pointer.String(s.UserID.String()),
 		Position:   s.Position,
-		NameEN:     s.NameEN,
-		NameRU:     s.NameRU,
-		NameUZ:     s.NameUZ,
+		Names:      s.Names.Clone(),
 		Emoji:      s.Emoji,
 		CreatedAt:  s.CreatedAt,
 		UpdatedAt:  pointer.Time(s.UpdatedAt),
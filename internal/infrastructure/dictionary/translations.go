@@ -0,0 +1,90 @@
+package dictionary
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/uptrace/bun"
+)
+
+const (
+	translationEntityCategory    = "category"
+	translationEntitySubcategory = "subcategory"
+)
+
+type Translations struct {
+	bun.BaseModel `bun:"table:translations,alias:tr"`
+
+	Entity    string     `bun:"entity,pk"`
+	EntityID  int        `bun:"entity_id,pk"`
+	Locale    string     `bun:"locale,pk"`
+	Value     string     `bun:"value"`
+	CreatedAt time.Time  `bun:"created_at,nullzero,default:current_timestamp"`
+	UpdatedAt *time.Time `bun:"updated_at,nullzero"`
+}
+
+// loadTranslations returns every translation of the entity grouped by id. It
+// runs in the dictionaries' onLoad hook, so names are cached with the items.
+func loadTranslations(ctx context.Context, db bun.IDB, entity string) (map[int]entities.Translations, error) {
+	var rows []*Translations
+	err := db.NewSelect().Model(&rows).
+		Where("entity = ?", entity).
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, Translations{})
+	}
+
+	result := make(map[int]entities.Translations)
+	for _, row := range rows {
+		if result[row.EntityID] == nil {
+			result[row.EntityID] = make(entities.Translations)
+		}
+		result[row.EntityID][entities.Language(row.Locale)] = row.Value
+	}
+
+	return result, nil
+}
+
+// saveTranslations replaces the stored translations of a single entity.
+func saveTranslations(ctx context.Context, db bun.IDB, entity string, id int, names entities.Translations) error {
+	if err := deleteTranslations(ctx, db, entity, id); err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]*Translations, 0, len(names))
+	for locale, value := range names {
+		rows = append(rows, &Translations{
+			Entity:    entity,
+			EntityID:  id,
+			Locale:    locale.String(),
+			Value:     value,
+			UpdatedAt: &now,
+		})
+	}
+
+	_, err := db.NewInsert().Model(&rows).Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, Translations{})
+	}
+
+	return nil
+}
+
+func deleteTranslations(ctx context.Context, db bun.IDB, entity string, id int) error {
+	_, err := db.NewDelete().Model((*Translations)(nil)).
+		Where("entity = ?", entity).
+		Where("entity_id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, Translations{})
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package llm_chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Provider is one link of the chain, Name shows up in logs and traces.
+type Provider struct {
+	Name string
+	ports.LLMProvider
+}
+
+// chain tries its providers in order and returns the first answer. Each
+// attempt gets its own timeout, so a hanging provider leaves time for the
+// next one.
+type chain struct {
+	logger    *logger.Logger
+	timeout   time.Duration
+	providers []Provider
+}
+
+func New(logger *logger.Logger, timeout time.Duration, providers ...Provider) (ports.LLMProvider, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("no llm providers")
+	}
+
+	return &chain{
+		logger:    logger,
+		timeout:   timeout,
+		providers: providers,
+	}, nil
+}
+
+func (c *chain) ChatCompletion(ctx context.Context, model, system, message string) (string, ports.LLMUsage, error) {
+	return c.try(ctx, func(ctx context.Context, provider Provider) (string, ports.LLMUsage, error) {
+		return provider.ChatCompletion(ctx, model, system, message)
+	})
+}
+
+func (c *chain) AudioToText(ctx context.Context, model, filePath, language string) (string, ports.LLMUsage, error) {
+	return c.try(ctx, func(ctx context.Context, provider Provider) (string, ports.LLMUsage, error) {
+		return provider.AudioToText(ctx, model, filePath, language)
+	})
+}
+
+// try returns the usage summed over all attempts, a failed call may still
+// have been billed.
+func (c *chain) try(ctx context.Context, call func(ctx context.Context, provider Provider) (string, ports.LLMUsage, error)) (string, ports.LLMUsage, error) {
+	var (
+		errs  []error
+		total ports.LLMUsage
+	)
+	for i, provider := range c.providers {
+		// the caller gave up, the next provider would fail the same way
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		resp, usage, err := call(attemptCtx, provider)
+		cancel()
+		total.PromptTokens += usage.PromptTokens
+		total.CompletionTokens += usage.CompletionTokens
+		total.AudioSeconds += usage.AudioSeconds
+		if err == nil {
+			otlp.Annotate(ctx,
+				attribute.String("llm.provider", provider.Name),
+				attribute.Int("llm.fallbacks", i),
+			)
+			return resp, total, nil
+		}
+
+		c.logger.ErrorContext(ctx, "llm provider failed", err, "provider", provider.Name)
+		otlp.Event(ctx, "llm.fallback", attribute.String("llm.provider", provider.Name))
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name, err))
+	}
+
+	return "", total, errors.Join(errs...)
+}
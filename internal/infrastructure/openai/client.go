@@ -2,8 +2,10 @@ package openai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/config"
@@ -14,6 +16,10 @@ import (
 
 type apiClient struct {
 	client *openai.Client
+	// chatModel and audioModel replace the model asked by the caller when
+	// set, see NewCompatible.
+	chatModel  string
+	audioModel string
 }
 
 func New(cfg *config.Config) (ports.LLMProvider, error) {
@@ -29,7 +35,29 @@ func New(cfg *config.Config) (ports.LLMProvider, error) {
 	}, nil
 }
 
-func (c *apiClient) ChatCompletion(ctx context.Context, model string, system string, message string) (string, error) {
+// NewCompatible talks to any server implementing the OpenAI API at the
+// configured base URL, e.g. Ollama or vLLM running next to the app.
+func NewCompatible(cfg *config.Config) (ports.LLMProvider, error) {
+	if cfg.LLM.Compatible.BaseURL == "" {
+		return nil, errors.New("base url is not set")
+	}
+
+	config := openai.DefaultConfig(cfg.LLM.Compatible.APIKey)
+	config.BaseURL = strings.TrimRight(cfg.LLM.Compatible.BaseURL, "/")
+	config.HTTPClient = &http.Client{
+		Transport: otelhttp.NewTransport(utils.DefaultInsecureTransport()),
+	}
+
+	client := openai.NewClientWithConfig(config)
+
+	return &apiClient{
+		client:     client,
+		chatModel:  cfg.LLM.Compatible.Model,
+		audioModel: cfg.LLM.Compatible.TranscriptionModel,
+	}, nil
+}
+
+func (c *apiClient) ChatCompletion(ctx context.Context, model string, system string, message string) (string, ports.LLMUsage, error) {
 	chatCompletionMessages := []openai.ChatCompletionMessage{}
 
 	if system != "" {
@@ -44,6 +72,10 @@ func (c *apiClient) ChatCompletion(ctx context.Context, model string, system str
 		Content: message,
 	})
 
+	if c.chatModel != "" {
+		model = c.chatModel
+	}
+
 	req := openai.ChatCompletionRequest{
 		Model:    model,
 		Messages: chatCompletionMessages,
@@ -51,27 +83,45 @@ func (c *apiClient) ChatCompletion(ctx context.Context, model string, system str
 
 	completion, err := c.client.CreateChatCompletion(ctx, req)
 	if err != nil {
-		return "", err
+		return "", ports.LLMUsage{}, err
+	}
+
+	usage := ports.LLMUsage{
+		PromptTokens:     completion.Usage.PromptTokens,
+		CompletionTokens: completion.Usage.CompletionTokens,
+	}
+
+	if len(completion.Choices) == 0 {
+		return "", usage, errors.New("completion has no choices")
 	}
 
 	response := completion.Choices[0].Message.Content
 
-	return response, nil
+	return response, usage, nil
 }
 
-func (c *apiClient) AudioToText(ctx context.Context, filePath string, language string) (string, error) {
+func (c *apiClient) AudioToText(ctx context.Context, model string, filePath string, language string) (string, ports.LLMUsage, error) {
+	if c.audioModel != "" {
+		model = c.audioModel
+	}
+
 	req := openai.AudioRequest{
-		Model:    "gpt-4o-transcribe",
+		Model:    model,
 		Prompt:   fmt.Sprintf("The audio might be in %s language.", language),
 		FilePath: filePath,
 	}
 
 	transcript, err := c.client.CreateTranscription(ctx, req)
 	if err != nil {
-		return "", err
+		return "", ports.LLMUsage{}, err
+	}
+
+	// only whisper reports the duration, callers estimate it otherwise
+	usage := ports.LLMUsage{
+		AudioSeconds: transcript.Duration,
 	}
 
-	return transcript.Text, nil
+	return transcript.Text, usage, nil
 }
 
 func (c *apiClient) ImageToText(ctx context.Context, imageURL string) (string, error) {
@@ -0,0 +1,60 @@
+package parse_cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/redis"
+)
+
+type parseCache struct {
+	redis   *redis.RedisClient
+	ttl     time.Duration
+	lookups *otlp.CacheLookups
+}
+
+// New keeps parser results in redis for ttl.
+func New(redis *redis.RedisClient, ttl time.Duration) ports.ParseCache {
+	return &parseCache{
+		redis:   redis,
+		ttl:     ttl,
+		lookups: otlp.NewCacheLookups("parse_cache"),
+	}
+}
+
+func (c *parseCache) Get(ctx context.Context, key string, dest any) (bool, error) {
+	data, err := c.redis.GetBytes(ctx, entryKey(key))
+	if err != nil && !redis.IsNil(err) {
+		return false, err
+	}
+
+	if len(data) == 0 {
+		c.lookups.Record(ctx, false)
+		return false, nil
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		// a value written by an older layout, ask the model again
+		c.lookups.Record(ctx, false)
+		return false, nil
+	}
+
+	c.lookups.Record(ctx, true)
+	return true, nil
+}
+
+func (c *parseCache) Set(ctx context.Context, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.redis.SetBytes(ctx, entryKey(key), data, c.ttl)
+}
+
+func entryKey(key string) string {
+	return "parse:" + key
+}
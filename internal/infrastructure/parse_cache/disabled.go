@@ -0,0 +1,22 @@
+package parse_cache
+
+import (
+	"context"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+)
+
+type disabled struct{}
+
+// Disabled never finds anything, every parse asks the model.
+func Disabled() ports.ParseCache {
+	return disabled{}
+}
+
+func (disabled) Get(context.Context, string, any) (bool, error) {
+	return false, nil
+}
+
+func (disabled) Set(context.Context, string, any) error {
+	return nil
+}
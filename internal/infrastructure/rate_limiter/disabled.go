@@ -0,0 +1,18 @@
+package rate_limiter
+
+import (
+	"context"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+)
+
+type disabled struct{}
+
+// Disabled lets every request through.
+func Disabled() ports.RateLimiter {
+	return disabled{}
+}
+
+func (disabled) Allow(context.Context, string, ports.RateLimit) (ports.RateLimitResult, error) {
+	return ports.RateLimitResult{Allowed: true}, nil
+}
@@ -0,0 +1,77 @@
+package rate_limiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/redis"
+)
+
+// gcra is a token bucket kept as a single timestamp, the theoretical arrival
+// time (tat) of the next request. Each request pushes it one interval
+// further, a request is denied while that would put it more than a window
+// ahead of now. The clock of redis is used, so instances with skewed clocks
+// agree.
+//
+// KEYS[1] bucket, ARGV[1] interval in microseconds, ARGV[2] limit.
+// Returns {allowed, remaining, reset, retry after}, durations in
+// microseconds.
+var gcra = redis.NewScript(`
+-- TIME before a write needs effects replication, the default from redis 7
+redis.replicate_commands()
+
+local interval = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local window = interval * limit
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local next_tat = tat + interval
+local allow_at = next_tat - window
+if allow_at > now then
+	return {0, 0, tat - now, allow_at - now}
+end
+
+redis.call('SET', KEYS[1], next_tat, 'PX', math.ceil((next_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), next_tat - now, 0}
+`)
+
+type limiter struct {
+	redis *redis.RedisClient
+}
+
+func New(redis *redis.RedisClient) ports.RateLimiter {
+	return &limiter{
+		redis: redis,
+	}
+}
+
+func (l *limiter) Allow(ctx context.Context, key string, limit ports.RateLimit) (ports.RateLimitResult, error) {
+	if limit.Limit <= 0 || limit.Window <= 0 {
+		return ports.RateLimitResult{Allowed: true}, nil
+	}
+
+	interval := limit.Window.Microseconds() / int64(limit.Limit)
+	if interval < 1 {
+		interval = 1
+	}
+
+	values, err := l.redis.RunScript(ctx, gcra, []string{"ratelimit:" + key}, interval, limit.Limit)
+	if err != nil {
+		return ports.RateLimitResult{}, err
+	}
+
+	return ports.RateLimitResult{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Microsecond,
+		RetryAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
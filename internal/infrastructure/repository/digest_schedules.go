@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type DigestSchedules struct {
+	bun.BaseModel `bun:"table:digest_schedules,alias:ds"`
+
+	UserID    string    `bun:"user_id,type:uuid,pk"`
+	Weekday   int       `bun:"weekday"`
+	Hour      int       `bun:"hour"`
+	UpdatedAt time.Time `bun:"updated_at,nullzero"`
+}
+
+type digestSchedulesRepo struct {
+	db bun.IDB
+}
+
+func NewDigestSchedulesRepo(db bun.IDB) entities.DigestScheduleRepository {
+	return &digestSchedulesRepo{
+		db: db,
+	}
+}
+
+// FindByUserID never fails with not found, a user without a row gets the
+// default schedule.
+func (r *digestSchedulesRepo) FindByUserID(ctx context.Context, userID uuid.UUID) (*entities.DigestSchedule, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var models []DigestSchedules
+	err := db.NewSelect().Model(&models).
+		Where("user_id = ?", userID.String()).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, DigestSchedules{})
+	}
+
+	if len(models) == 0 {
+		return entities.NewDigestSchedule(userID), nil
+	}
+
+	return r.toEntity(&models[0]), nil
+}
+
+func (r *digestSchedulesRepo) FindAll(ctx context.Context) ([]*entities.DigestSchedule, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var models []DigestSchedules
+	err := db.NewSelect().Model(&models).Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, DigestSchedules{})
+	}
+
+	schedules := make([]*entities.DigestSchedule, 0, len(models))
+	for i := range models {
+		schedules = append(schedules, r.toEntity(&models[i]))
+	}
+
+	return schedules, nil
+}
+
+func (r *digestSchedulesRepo) Save(ctx context.Context, schedule *entities.DigestSchedule) error {
+	db := postgres.FromContext(ctx, r.db)
+
+	model := &DigestSchedules{
+		UserID:    schedule.UserID.String(),
+		Weekday:   int(schedule.Weekday),
+		Hour:      schedule.Hour,
+		UpdatedAt: schedule.UpdatedAt,
+	}
+
+	_, err := db.NewInsert().Model(model).
+		On("CONFLICT (user_id) DO UPDATE").
+		Set("weekday = EXCLUDED.weekday").
+		Set("hour = EXCLUDED.hour").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, DigestSchedules{})
+	}
+
+	return nil
+}
+
+func (r *digestSchedulesRepo) toEntity(model *DigestSchedules) *entities.DigestSchedule {
+	userID, _ := uuid.Parse(model.UserID)
+
+	return &entities.DigestSchedule{
+		UserID:    userID,
+		Weekday:   time.Weekday(model.Weekday),
+		Hour:      model.Hour,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
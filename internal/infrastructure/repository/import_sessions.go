@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/google/uuid"
+	"github.com/shogo82148/pointer"
+	"github.com/uptrace/bun"
+)
+
+type ImportSessions struct {
+	bun.BaseModel `bun:"table:import_sessions,alias:is"`
+
+	ID               string         `bun:"id,type:uuid,pk"`
+	UserID           string         `bun:"user_id,type:uuid"`
+	AccountID        string         `bun:"account_id,type:uuid"`
+	Source           string         `bun:"source"`
+	Filename         *string        `bun:"filename,nullzero"`
+	Status           string         `bun:"status"`
+	Encoding         *string        `bun:"encoding,nullzero"`
+	Delimiter        *string        `bun:"delimiter,nullzero"`
+	DateFormat       *string        `bun:"date_format,nullzero"`
+	DecimalSeparator *string        `bun:"decimal_separator,nullzero"`
+	Header           []string       `bun:"header,type:jsonb"`
+	Mapping          map[string]int `bun:"mapping,type:jsonb"`
+	RowCount         int            `bun:"row_count"`
+	ImportedCount    int            `bun:"imported_count"`
+	CreatedAt        time.Time      `bun:"created_at,default:current_timestamp"`
+	UpdatedAt        *time.Time     `bun:"updated_at,nullzero"`
+	CommittedAt      *time.Time     `bun:"committed_at,nullzero"`
+}
+
+type ImportRows struct {
+	bun.BaseModel `bun:"table:import_rows,alias:ir"`
+
+	SessionID string   `bun:"session_id,type:uuid,pk"`
+	Line      int      `bun:"line,pk"`
+	Data      []string `bun:"data,type:jsonb"`
+}
+
+// importRowsChunk keeps a single insert well under the bind parameter limit.
+const importRowsChunk = 1000
+
+type importSessionsRepo struct {
+	db bun.IDB
+}
+
+func NewImportSessionsRepo(db bun.IDB) entities.ImportSessionRepository {
+	return &importSessionsRepo{
+		db: db,
+	}
+}
+
+func (r *importSessionsRepo) Save(ctx context.Context, session *entities.ImportSession) error {
+	db := postgres.FromContext(ctx, r.db)
+	var model = r.ToModel(session)
+
+	_, err := db.NewInsert().Model(model).
+		On("CONFLICT (id) DO UPDATE").
+		Set("status = EXCLUDED.status").
+		Set("date_format = EXCLUDED.date_format").
+		Set("decimal_separator = EXCLUDED.decimal_separator").
+		Set("mapping = EXCLUDED.mapping").
+		Set("imported_count = EXCLUDED.imported_count").
+		Set("updated_at = EXCLUDED.updated_at").
+		Set("committed_at = EXCLUDED.committed_at").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, model)
+	}
+
+	return nil
+}
+
+func (r *importSessionsRepo) FindByID(ctx context.Context, id uuid.UUID) (*entities.ImportSession, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var model ImportSessions
+	err := db.NewSelect().Model(&model).
+		Where("id = ?", id.String()).
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, model)
+	}
+
+	return r.ToEntity(&model), nil
+}
+
+func (r *importSessionsRepo) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entities.ImportSession, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var model ImportSessions
+	err := db.NewSelect().Model(&model).
+		Where("id = ?", id.String()).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, model)
+	}
+
+	return r.ToEntity(&model), nil
+}
+
+func (r *importSessionsRepo) SaveRows(ctx context.Context, sessionID uuid.UUID, rows []entities.ImportRow) error {
+	db := postgres.FromContext(ctx, r.db)
+
+	for start := 0; start < len(rows); start += importRowsChunk {
+		chunk := rows[start:min(start+importRowsChunk, len(rows))]
+
+		models := make([]ImportRows, 0, len(chunk))
+		for _, row := range chunk {
+			models = append(models, ImportRows{
+				SessionID: sessionID.String(),
+				Line:      row.Line,
+				Data:      row.Values,
+			})
+		}
+
+		_, err := db.NewInsert().Model(&models).
+			On("CONFLICT (session_id, line) DO UPDATE").
+			Set("data = EXCLUDED.data").
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, ImportRows{})
+		}
+	}
+
+	return nil
+}
+
+func (r *importSessionsRepo) GetRows(ctx context.Context, sessionID uuid.UUID, limit, offset int) ([]entities.ImportRow, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var models []ImportRows
+	query := db.NewSelect().Model(&models).
+		Where("session_id = ?", sessionID.String()).
+		Order("line asc")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, ImportRows{})
+	}
+
+	rows := make([]entities.ImportRow, 0, len(models))
+	for _, model := range models {
+		rows = append(rows, entities.ImportRow{Line: model.Line, Values: model.Data})
+	}
+
+	return rows, nil
+}
+
+func (r *importSessionsRepo) ToModel(e *entities.ImportSession) *ImportSessions {
+	if e == nil {
+		return nil
+	}
+
+	mapping := make(map[string]int, len(e.Mapping))
+	for field, column := range e.Mapping {
+		mapping[field.String()] = column
+	}
+
+	return &ImportSessions{
+		ID:               e.ID.String(),
+		UserID:           e.UserID.String(),
+		AccountID:        e.AccountID.String(),
+		Source:           e.Source,
+		Filename:         pointer.StringOrNil(e.Filename),
+		Status:           e.Status.String(),
+		Encoding:         pointer.StringOrNil(e.Format.Encoding),
+		Delimiter:        pointer.StringOrNil(e.Format.Delimiter),
+		DateFormat:       pointer.StringOrNil(e.Format.DateFormat),
+		DecimalSeparator: pointer.StringOrNil(e.Format.DecimalSeparator),
+		Header:           e.Header,
+		Mapping:          mapping,
+		RowCount:         e.RowCount,
+		ImportedCount:    e.ImportedCount,
+		CreatedAt:        e.CreatedAt,
+		UpdatedAt:        pointer.TimeOrNil(e.UpdatedAt),
+		CommittedAt:      pointer.TimeOrNil(e.CommittedAt),
+	}
+}
+
+func (r *importSessionsRepo) ToEntity(m *ImportSessions) *entities.ImportSession {
+	if m == nil {
+		return nil
+	}
+
+	id, _ := uuid.Parse(m.ID)
+	userID, _ := uuid.Parse(m.UserID)
+	accountID, _ := uuid.Parse(m.AccountID)
+
+	mapping := make(entities.ImportMapping, len(m.Mapping))
+	for field, column := range m.Mapping {
+		mapping[entities.ImportField(field)] = column
+	}
+
+	return &entities.ImportSession{
+		ID:        id,
+		UserID:    userID,
+		AccountID: accountID,
+		Source:    m.Source,
+		Filename:  pointer.StringValue(m.Filename),
+		Status:    entities.ImportStatus(m.Status),
+		Format: entities.ImportFormat{
+			Encoding:         pointer.StringValue(m.Encoding),
+			Delimiter:        pointer.StringValue(m.Delimiter),
+			DateFormat:       pointer.StringValue(m.DateFormat),
+			DecimalSeparator: pointer.StringValue(m.DecimalSeparator),
+		},
+		Header:        m.Header,
+		Mapping:       mapping,
+		RowCount:      m.RowCount,
+		ImportedCount: m.ImportedCount,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     pointer.TimeValue(m.UpdatedAt),
+		CommittedAt:   pointer.TimeValue(m.CommittedAt),
+	}
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type LLMUsage struct {
+	bun.BaseModel `bun:"table:llm_usage,alias:lu"`
+
+	ID               string    `bun:"id,type:uuid,pk"`
+	UserID           string    `bun:"user_id,type:uuid"`
+	Operation        string    `bun:"operation"`
+	PromptTokens     int       `bun:"prompt_tokens"`
+	CompletionTokens int       `bun:"completion_tokens"`
+	AudioSeconds     float64   `bun:"audio_seconds"`
+	OCRCalls         int       `bun:"ocr_calls"`
+	CreatedAt        time.Time `bun:"created_at,default:current_timestamp"`
+}
+
+type usageTotals struct {
+	UserID           string  `bun:"user_id"`
+	Plan             string  `bun:"plan"`
+	Requests         int     `bun:"requests"`
+	PromptTokens     int64   `bun:"prompt_tokens"`
+	CompletionTokens int64   `bun:"completion_tokens"`
+	AudioSeconds     float64 `bun:"audio_seconds"`
+	OCRCalls         int64   `bun:"ocr_calls"`
+}
+
+type llmUsageRepo struct {
+	db bun.IDB
+}
+
+func NewLLMUsageRepo(db bun.IDB) entities.LLMUsageRepository {
+	return &llmUsageRepo{
+		db: db,
+	}
+}
+
+// Save upserts the usage, a reservation is saved again with what was spent.
+func (r *llmUsageRepo) Save(ctx context.Context, usage *entities.LLMUsage) error {
+	db := postgres.FromContext(ctx, r.db)
+	model := r.ToModel(usage)
+
+	_, err := db.NewInsert().Model(model).
+		On("CONFLICT (id) DO UPDATE").
+		Set("prompt_tokens = EXCLUDED.prompt_tokens").
+		Set("completion_tokens = EXCLUDED.completion_tokens").
+		Set("audio_seconds = EXCLUDED.audio_seconds").
+		Set("ocr_calls = EXCLUDED.ocr_calls").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, model)
+	}
+
+	return nil
+}
+
+func (r *llmUsageRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	db := postgres.FromContext(ctx, r.db)
+
+	_, err := db.NewDelete().
+		Model((*LLMUsage)(nil)).
+		Where("id = ?", id.String()).
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, LLMUsage{})
+	}
+
+	return nil
+}
+
+func (r *llmUsageRepo) LockUser(ctx context.Context, userID uuid.UUID) error {
+	db := postgres.FromContext(ctx, r.db)
+
+	_, err := db.NewSelect().
+		Model((*Users)(nil)).
+		Column("id").
+		Where("id = ?", userID.String()).
+		For("NO KEY UPDATE").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, Users{})
+	}
+
+	return nil
+}
+
+func (r *llmUsageRepo) GetTotals(ctx context.Context, userID uuid.UUID, from, to time.Time) (entities.UsageTotals, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var row usageTotals
+	err := db.NewSelect().Model((*LLMUsage)(nil)).
+		ColumnExpr("COUNT(*) AS requests").
+		ColumnExpr("COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens").
+		ColumnExpr("COALESCE(SUM(completion_tokens), 0) AS completion_tokens").
+		ColumnExpr("COALESCE(SUM(audio_seconds), 0) AS audio_seconds").
+		ColumnExpr("COALESCE(SUM(ocr_calls), 0) AS ocr_calls").
+		Where("user_id = ?", userID.String()).
+		Where("created_at >= ?", from).
+		Where("created_at < ?", to).
+		Scan(ctx, &row)
+	if err != nil {
+		return entities.UsageTotals{}, postgres.Error(err, LLMUsage{})
+	}
+
+	return row.toTotals(), nil
+}
+
+func (r *llmUsageRepo) GetReport(ctx context.Context, from, to time.Time, limit int) ([]entities.UserUsageRow, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var rows []usageTotals
+	err := db.NewSelect().Model((*LLMUsage)(nil)).
+		Join("JOIN users AS u ON u.id = lu.user_id").
+		ColumnExpr("lu.user_id").
+		ColumnExpr("u.plan").
+		ColumnExpr("COUNT(*) AS requests").
+		ColumnExpr("SUM(lu.prompt_tokens) AS prompt_tokens").
+		ColumnExpr("SUM(lu.completion_tokens) AS completion_tokens").
+		ColumnExpr("SUM(lu.audio_seconds) AS audio_seconds").
+		ColumnExpr("SUM(lu.ocr_calls) AS ocr_calls").
+		Where("lu.created_at >= ?", from).
+		Where("lu.created_at < ?", to).
+		GroupExpr("lu.user_id, u.plan").
+		OrderExpr("SUM(lu.prompt_tokens + lu.completion_tokens) DESC, lu.user_id").
+		Limit(limit).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, postgres.Error(err, LLMUsage{})
+	}
+
+	report := make([]entities.UserUsageRow, 0, len(rows))
+	for _, row := range rows {
+		userID, _ := uuid.Parse(row.UserID)
+		report = append(report, entities.UserUsageRow{
+			UserID:      userID,
+			Plan:        entities.Plan(row.Plan),
+			UsageTotals: row.toTotals(),
+		})
+	}
+
+	return report, nil
+}
+
+func (r usageTotals) toTotals() entities.UsageTotals {
+	return entities.UsageTotals{
+		Requests:         r.Requests,
+		PromptTokens:     r.PromptTokens,
+		CompletionTokens: r.CompletionTokens,
+		AudioSeconds:     r.AudioSeconds,
+		OCRCalls:         r.OCRCalls,
+	}
+}
+
+func (r *llmUsageRepo) ToModel(e *entities.LLMUsage) *LLMUsage {
+	if e == nil {
+		return nil
+	}
+
+	return &LLMUsage{
+		ID:               e.ID.String(),
+		UserID:           e.UserID.String(),
+		Operation:        e.Operation.String(),
+		PromptTokens:     e.PromptTokens,
+		CompletionTokens: e.CompletionTokens,
+		AudioSeconds:     e.AudioSeconds,
+		OCRCalls:         e.OCRCalls,
+		CreatedAt:        e.CreatedAt,
+	}
+}
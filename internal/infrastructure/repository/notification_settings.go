@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type NotificationSettings struct {
+	bun.BaseModel `bun:"table:notification_settings,alias:ns"`
+
+	UserID    string    `bun:"user_id,type:uuid,pk"`
+	Type      string    `bun:"type,pk"`
+	Enabled   bool      `bun:"enabled"`
+	UpdatedAt time.Time `bun:"updated_at,nullzero"`
+}
+
+type notificationSettingsRepo struct {
+	db bun.IDB
+}
+
+func NewNotificationSettingsRepo(db bun.IDB) entities.NotificationSettingsRepository {
+	return &notificationSettingsRepo{
+		db: db,
+	}
+}
+
+// FindByUserID never fails with not found, a user without rows gets the
+// defaults.
+func (r *notificationSettingsRepo) FindByUserID(ctx context.Context, userID uuid.UUID) (*entities.NotificationSettings, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var models []NotificationSettings
+	err := db.NewSelect().Model(&models).
+		Where("user_id = ?", userID.String()).
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, NotificationSettings{})
+	}
+
+	settings := entities.NewNotificationSettings(userID)
+	for _, model := range models {
+		settings.Enabled[entities.NotificationType(model.Type)] = model.Enabled
+		if model.UpdatedAt.After(settings.UpdatedAt) {
+			settings.UpdatedAt = model.UpdatedAt
+		}
+	}
+
+	return settings, nil
+}
+
+func (r *notificationSettingsRepo) Save(ctx context.Context, settings *entities.NotificationSettings) error {
+	if len(settings.Enabled) == 0 {
+		return nil
+	}
+
+	db := postgres.FromContext(ctx, r.db)
+
+	models := make([]NotificationSettings, 0, len(settings.Enabled))
+	for t, enabled := range settings.Enabled {
+		models = append(models, NotificationSettings{
+			UserID:    settings.UserID.String(),
+			Type:      t.String(),
+			Enabled:   enabled,
+			UpdatedAt: settings.UpdatedAt,
+		})
+	}
+
+	_, err := db.NewInsert().Model(&models).
+		On("CONFLICT (user_id, type) DO UPDATE").
+		Set("enabled = EXCLUDED.enabled").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, NotificationSettings{})
+	}
+
+	return nil
+}
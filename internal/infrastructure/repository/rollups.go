@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type TransactionRollups struct {
+	bun.BaseModel `bun:"table:transaction_rollups,alias:tr"`
+
+	UserID        string    `bun:"user_id,type:uuid,pk"`
+	AccountID     string    `bun:"account_id,type:uuid,pk"`
+	CategoryID    int       `bun:"category_id,pk"`
+	SubcategoryID int       `bun:"subcategory_id,pk"`
+	Type          string    `bun:"type,pk"`
+	Day           time.Time `bun:"day,type:date,pk"`
+	Total         int64     `bun:"total"`
+	Count         int       `bun:"count"`
+}
+
+type rollupsRepo struct {
+	db bun.IDB
+}
+
+func NewRollupsRepo(db bun.IDB) entities.RollupRepository {
+	return &rollupsRepo{
+		db: db,
+	}
+}
+
+func (r *rollupsRepo) Rebuild(ctx context.Context, userID uuid.UUID) error {
+	db := postgres.FromContext(ctx, r.db)
+
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		loc, err := rollupLocation(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*TransactionRollups)(nil)).
+			Where("user_id = ?", userID.String()).
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, TransactionRollups{})
+		}
+
+		return applyRollups(ctx, tx, userID, loc, 1, nil)
+	})
+}
+
+func (r *rollupsRepo) Verify(ctx context.Context, userID uuid.UUID) (int, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	loc, err := rollupLocation(ctx, db, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	stored := db.NewSelect().
+		Model((*TransactionRollups)(nil)).
+		Where("user_id = ?", userID.String())
+
+	var drift int
+	err = db.NewRaw(`
+		SELECT COUNT(*)
+		FROM (?) AS raw
+		FULL OUTER JOIN (?) AS stored USING (user_id, account_id, category_id, subcategory_id, type, day)
+		WHERE raw.total IS DISTINCT FROM stored.total OR raw.count IS DISTINCT FROM stored.count
+	`, rollupSource(db, userID, loc, 1), stored).Scan(ctx, &drift)
+	if err != nil {
+		return 0, postgres.Error(err, TransactionRollups{})
+	}
+
+	return drift, nil
+}
+
+// rollupLocation is the time zone the user's rollup days are counted in, the
+// same one their stats are asked in.
+func rollupLocation(ctx context.Context, db bun.IDB, userID uuid.UUID) (*time.Location, error) {
+	var timezone string
+	err := db.NewSelect().
+		Model((*Users)(nil)).
+		ColumnExpr("COALESCE(timezone, '')").
+		Where("id = ?", userID.String()).
+		Scan(ctx, &timezone)
+	if err != nil {
+		return nil, postgres.Error(err, Users{})
+	}
+
+	user := entities.User{Timezone: timezone}
+	return user.Location(), nil
+}
+
+// rollupSource aggregates the user's completed deposits and withdrawals into
+// rollup rows. A negative sign produces the rows that take them out again.
+func rollupSource(db bun.IDB, userID uuid.UUID, loc *time.Location, sign int) *bun.SelectQuery {
+	return db.NewSelect().
+		Model((*Transactions)(nil)).
+		Column("user_id", "account_id").
+		ColumnExpr("COALESCE(category_id, 0) AS category_id").
+		ColumnExpr("COALESCE(subcategory_id, 0) AS subcategory_id").
+		Column("type").
+		ColumnExpr("(coalesce(performed_at, created_at) AT TIME ZONE ?)::date AS day", loc.String()).
+		ColumnExpr("? * SUM(amount) AS total", sign).
+		ColumnExpr("? * COUNT(*) AS count", sign).
+		Where("user_id = ?", userID.String()).
+		Where("status = ?", entities.Completed.String()).
+		Where("type IN (?)", bun.In([]string{entities.Deposit.String(), entities.Withdrawal.String()})).
+		GroupExpr("user_id, account_id, COALESCE(category_id, 0), COALESCE(subcategory_id, 0), type, day")
+}
+
+// applyRollups adds the transactions selected by where to the user's rollups,
+// or subtracts them with a negative sign. Callers subtract the rows they are
+// about to change or delete and add them back once written, in the same
+// database transaction.
+func applyRollups(ctx context.Context, db bun.IDB, userID uuid.UUID, loc *time.Location, sign int, where func(q *bun.SelectQuery) *bun.SelectQuery) error {
+	source := rollupSource(db, userID, loc, sign)
+	if where != nil {
+		source = where(source)
+	}
+
+	_, err := db.NewRaw(`
+		INSERT INTO transaction_rollups (user_id, account_id, category_id, subcategory_id, type, day, total, count)
+		?
+		ON CONFLICT (user_id, day, account_id, category_id, subcategory_id, type) DO UPDATE
+		SET total = transaction_rollups.total + EXCLUDED.total,
+			count = transaction_rollups.count + EXCLUDED.count
+	`, source).Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, TransactionRollups{})
+	}
+
+	if sign < 0 {
+		_, err = db.NewDelete().
+			Model((*TransactionRollups)(nil)).
+			Where("user_id = ?", userID.String()).
+			Where("count <= 0").
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, TransactionRollups{})
+		}
+	}
+
+	return nil
+}
+
+// rollupDay is the rollup day a bound falls on. Stats bounds are local
+// midnights, so this is the day they start.
+func rollupDay(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(time.DateOnly)
+}
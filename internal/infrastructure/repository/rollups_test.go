@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/infrastructure/dictionary"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/uptrace/bun"
+)
+
+// testDB connects to the migrated database named by the DB_* variables and
+// skips the test when there is none.
+func testDB(t *testing.T) *bun.DB {
+	t.Helper()
+
+	host := os.Getenv("DB_HOST")
+	if host == "" {
+		t.Skip("DB_HOST is not set")
+	}
+
+	opts := []postgres.Options{postgres.WithHost(host)}
+	if port := os.Getenv("DB_PORT"); port != "" {
+		opts = append(opts, postgres.WithPort(port))
+	}
+	if name := os.Getenv("DB_DATABASE"); name != "" {
+		opts = append(opts, postgres.WithDB(name))
+	}
+	if user := os.Getenv("DB_USERNAME"); user != "" {
+		opts = append(opts, postgres.WithUser(user))
+	}
+	if password := os.Getenv("DB_PASSWORD"); password != "" {
+		opts = append(opts, postgres.WithPassword(password))
+	}
+
+	db, err := postgres.NewBunDB(opts...)
+	if err != nil {
+		t.Skipf("postgres is not available: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Skipf("postgres is not available: %v", err)
+	}
+
+	return db
+}
+
+func TestRollupsMatchRawAggregation(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	usersRepo := NewUsersRepo(db)
+	accountsRepo := NewAccountsRepo(db)
+	categoriesRepo := dictionary.NewCategoriesDict(db)
+	subcategoriesRepo := dictionary.NewSubcategoriesDict(db)
+	transactionsRepo := NewTransactionsRepo(db, categoriesRepo, subcategoriesRepo)
+	rollupsRepo := NewRollupsRepo(db)
+
+	user, err := entities.NewUser(time.Now().UnixNano(), "Rollup", "Test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Timezone = "Asia/Tashkent"
+	if err := usersRepo.Save(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := entities.NewAccount(user.ID, "Rollups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := accountsRepo.Save(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := entities.NewUserCategory(user.ID, "Source", "📦")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := categoriesRepo.Save(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := entities.NewUserCategory(user.ID, "Target", "🎯")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := categoriesRepo.Save(ctx, target); err != nil {
+		t.Fatal(err)
+	}
+
+	subcategory, err := entities.NewUserSubcategory(source.ID.Int(), user.ID, "Test", "🧪")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := subcategoriesRepo.Save(ctx, subcategory); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.NewDelete().Model((*Transactions)(nil)).Where("user_id = ?", user.ID.String()).Exec(ctx)
+		subcategoriesRepo.Delete(ctx, user.ID, subcategory.ID)
+		categoriesRepo.Delete(ctx, user.ID, source.ID.Int())
+		categoriesRepo.Delete(ctx, user.ID, target.ID.Int())
+		db.NewDelete().Model((*Users)(nil)).Where("id = ?", user.ID.String()).Exec(ctx)
+	})
+
+	verify := func(step string) {
+		t.Helper()
+
+		drift, err := rollupsRepo.Verify(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("%s: %v", step, err)
+		}
+		if drift != 0 {
+			t.Errorf("%s: %d rollup rows differ from the raw aggregation", step, drift)
+		}
+	}
+
+	newTransaction := func(trnType entities.TrnType, amount int64, performedAt time.Time, category *entities.Category, subcategory *entities.Subcategory) *entities.Transaction {
+		transaction, err := entities.NewTransaction(user.ID, account.ID, trnType, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		transaction.Status = entities.Completed
+		transaction.Amount = amount
+		transaction.CurrencyCode = entities.UZS
+		transaction.PerformedAt = performedAt
+		transaction.Category = category
+		transaction.Subcategory = subcategory
+		return transaction
+	}
+
+	// 20:30 UTC is already the next day in Tashkent
+	evening := time.Date(2025, 5, 10, 20, 30, 0, 0, time.UTC)
+
+	first := newTransaction(entities.Withdrawal, 10_000, evening, source, subcategory)
+	second := newTransaction(entities.Withdrawal, 25_000, evening.Add(2*time.Hour), source, subcategory)
+	third := newTransaction(entities.Withdrawal, 7_000, evening, target, nil)
+	salary := newTransaction(entities.Deposit, 500_000, evening.AddDate(0, 0, -5), nil, nil)
+
+	if err := transactionsRepo.Save(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := transactionsRepo.SaveBatch(ctx, []*entities.Transaction{second, third, salary}); err != nil {
+		t.Fatal(err)
+	}
+	verify("create")
+
+	first.Amount = 12_000
+	first.PerformedAt = evening.AddDate(0, 0, -1)
+	if err := transactionsRepo.Save(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	verify("update")
+
+	if err := transactionsRepo.Delete(ctx, salary.ID); err != nil {
+		t.Fatal(err)
+	}
+	verify("delete")
+
+	// a category merge moves the transactions and their subcategory over,
+	// second lands on a day the target already has rows for
+	reassigned, err := transactionsRepo.ReassignCategory(ctx, user.ID, source.ID.Int(), target.ID.Int())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reassigned != 2 {
+		t.Errorf("reassigned %d transactions, want 2", reassigned)
+	}
+	if _, err := subcategoriesRepo.MoveToCategory(ctx, user.ID, source.ID.Int(), target.ID.Int()); err != nil {
+		t.Fatal(err)
+	}
+	verify("reassign category")
+
+	cleared, err := transactionsRepo.ClearSubcategory(ctx, user.ID, subcategory.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleared != 2 {
+		t.Errorf("cleared %d transactions, want 2", cleared)
+	}
+	verify("clear subcategory")
+
+	if err := rollupsRepo.Rebuild(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	verify("rebuild")
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/google/uuid"
+	"github.com/shogo82148/pointer"
+	"github.com/uptrace/bun"
+)
+
+type TransactionDrafts struct {
+	bun.BaseModel `bun:"table:transaction_drafts,alias:td"`
+
+	ID                   string     `bun:"id,type:uuid,pk"`
+	UserID               string     `bun:"user_id,type:uuid"`
+	AccountID            *string    `bun:"account_id,type:uuid,nullzero"`
+	CategoryID           *int       `bun:"category_id,nullzero"`
+	SubcategoryID        *int       `bun:"subcategory_id,nullzero"`
+	Type                 string     `bun:"type"`
+	Status               string     `bun:"status"`
+	Source               string     `bun:"source"`
+	Amount               int64      `bun:"amount"`
+	CurrencyCode         string     `bun:"currency_code"`
+	OriginalAmount       *int64     `bun:"original_amount,nullzero"`
+	OriginalCurrencyCode *string    `bun:"original_currency_code,nullzero"`
+	FxRate               *float64   `bun:"fx_rate,nullzero"`
+	Note                 string     `bun:"note"`
+	Merchant             *string    `bun:"merchant,nullzero"`
+	Confidence           float64    `bun:"confidence"`
+	PerformedAt          *time.Time `bun:"performed_at,nullzero"`
+	TransactionID        *string    `bun:"transaction_id,type:uuid,nullzero"`
+	ExpiresAt            time.Time  `bun:"expires_at"`
+	CreatedAt            time.Time  `bun:"created_at,default:current_timestamp"`
+	UpdatedAt            *time.Time `bun:"updated_at,nullzero"`
+}
+
+type transactionDraftsRepo struct {
+	db bun.IDB
+}
+
+func NewTransactionDraftsRepo(db bun.IDB) entities.TransactionDraftRepository {
+	return &transactionDraftsRepo{
+		db: db,
+	}
+}
+
+func (r *transactionDraftsRepo) Save(ctx context.Context, draft *entities.TransactionDraft) error {
+	return r.SaveBatch(ctx, []*entities.TransactionDraft{draft})
+}
+
+func (r *transactionDraftsRepo) SaveBatch(ctx context.Context, drafts []*entities.TransactionDraft) error {
+	if len(drafts) == 0 {
+		return nil
+	}
+
+	db := postgres.FromContext(ctx, r.db)
+
+	models := make([]*TransactionDrafts, 0, len(drafts))
+	for _, draft := range drafts {
+		models = append(models, r.ToModel(draft))
+	}
+
+	_, err := db.NewInsert().Model(&models).
+		On("CONFLICT (id) DO UPDATE").
+		Set("account_id = EXCLUDED.account_id").
+		Set("category_id = EXCLUDED.category_id").
+		Set("subcategory_id = EXCLUDED.subcategory_id").
+		Set("type = EXCLUDED.type").
+		Set("status = EXCLUDED.status").
+		Set("amount = EXCLUDED.amount").
+		Set("currency_code = EXCLUDED.currency_code").
+		Set("original_amount = EXCLUDED.original_amount").
+		Set("original_currency_code = EXCLUDED.original_currency_code").
+		Set("fx_rate = EXCLUDED.fx_rate").
+		Set("note = EXCLUDED.note").
+		Set("merchant = EXCLUDED.merchant").
+		Set("performed_at = EXCLUDED.performed_at").
+		Set("transaction_id = EXCLUDED.transaction_id").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		return postgres.Error(err, TransactionDrafts{})
+	}
+
+	return nil
+}
+
+func (r *transactionDraftsRepo) FindByID(ctx context.Context, id uuid.UUID) (*entities.TransactionDraft, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var model TransactionDrafts
+	err := db.NewSelect().Model(&model).
+		Where("id = ?", id.String()).
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, model)
+	}
+
+	return r.ToEntity(&model), nil
+}
+
+func (r *transactionDraftsRepo) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*entities.TransactionDraft, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var model TransactionDrafts
+	err := db.NewSelect().Model(&model).
+		Where("id = ?", id.String()).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, model)
+	}
+
+	return r.ToEntity(&model), nil
+}
+
+func (r *transactionDraftsRepo) GetPending(ctx context.Context, userID uuid.UUID, now time.Time) ([]*entities.TransactionDraft, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var models []*TransactionDrafts
+	err := db.NewSelect().Model(&models).
+		Where("user_id = ?", userID.String()).
+		Where("status = ?", entities.DraftPending.String()).
+		Where("expires_at > ?", now).
+		Order("created_at asc", "id asc").
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, TransactionDrafts{})
+	}
+
+	drafts := make([]*entities.TransactionDraft, 0, len(models))
+	for _, model := range models {
+		drafts = append(drafts, r.ToEntity(model))
+	}
+
+	return drafts, nil
+}
+
+func (r *transactionDraftsRepo) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	res, err := db.NewDelete().Model((*TransactionDrafts)(nil)).
+		Where("expires_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, postgres.Error(err, TransactionDrafts{})
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
+
+func (r *transactionDraftsRepo) ToModel(e *entities.TransactionDraft) *TransactionDrafts {
+	if e == nil {
+		return nil
+	}
+
+	model := &TransactionDrafts{
+		ID:            e.ID.String(),
+		UserID:        e.UserID.String(),
+		CategoryID:    e.CategoryID,
+		SubcategoryID: e.SubcategoryID,
+		Type:          e.Type.String(),
+		Status:        e.Status.String(),
+		Source:        e.Source.String(),
+		Amount:        e.Amount,
+		CurrencyCode:  e.CurrencyCode.String(),
+		Note:          e.Note,
+		Merchant:      pointer.StringOrNil(e.Merchant),
+		Confidence:    e.Confidence,
+		PerformedAt:   pointer.TimeOrNil(e.PerformedAt),
+		ExpiresAt:     e.ExpiresAt,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     pointer.TimeOrNil(e.UpdatedAt),
+	}
+
+	if e.AccountID != nil {
+		model.AccountID = pointer.String(e.AccountID.String())
+	}
+
+	if e.OriginalAmount > 0 {
+		model.OriginalAmount = pointer.Int64(e.OriginalAmount)
+		model.OriginalCurrencyCode = pointer.String(e.OriginalCurrencyCode.String())
+		model.FxRate = pointer.Float64OrNil(e.FxRate)
+	}
+
+	if e.TransactionID != nil {
+		model.TransactionID = pointer.String(e.TransactionID.String())
+	}
+
+	return model
+}
+
+func (r *transactionDraftsRepo) ToEntity(m *TransactionDrafts) *entities.TransactionDraft {
+	if m == nil {
+		return nil
+	}
+
+	id, _ := uuid.Parse(m.ID)
+	userID, _ := uuid.Parse(m.UserID)
+
+	draft := &entities.TransactionDraft{
+		ID:                   id,
+		UserID:               userID,
+		CategoryID:           m.CategoryID,
+		SubcategoryID:        m.SubcategoryID,
+		Type:                 entities.TrnType(m.Type),
+		Status:               entities.DraftStatus(m.Status),
+		Source:               entities.DraftSource(m.Source),
+		Amount:               m.Amount,
+		CurrencyCode:         entities.Currency(m.CurrencyCode),
+		OriginalAmount:       pointer.Int64Value(m.OriginalAmount),
+		OriginalCurrencyCode: entities.Currency(pointer.StringValue(m.OriginalCurrencyCode)),
+		FxRate:               pointer.Float64Value(m.FxRate),
+		Note:                 m.Note,
+		Merchant:             pointer.StringValue(m.Merchant),
+		Confidence:           m.Confidence,
+		PerformedAt:          pointer.TimeValue(m.PerformedAt),
+		ExpiresAt:            m.ExpiresAt,
+		CreatedAt:            m.CreatedAt,
+		UpdatedAt:            pointer.TimeValue(m.UpdatedAt),
+	}
+
+	if m.AccountID != nil {
+		if accountID, err := uuid.Parse(*m.AccountID); err == nil {
+			draft.AccountID = &accountID
+		}
+	}
+
+	if m.TransactionID != nil {
+		if transactionID, err := uuid.Parse(*m.TransactionID); err == nil {
+			draft.TransactionID = &transactionID
+		}
+	}
+
+	return draft
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
@@ -27,6 +28,9 @@ type Transactions struct {
 	OriginalCurrencyCode *string    `bun:"original_currency_code,nullzero"`
 	FxRate               *float64   `bun:"fx_rate,nullzero"`
 	RowText              string     `bun:"row_text"`
+	Merchant             *string    `bun:"merchant,nullzero"`
+	ImportSessionID      *string    `bun:"import_session_id,type:uuid,nullzero"`
+	ExternalID           *string    `bun:"external_id,nullzero"`
 	PerformedAt          *time.Time `bun:"performed_at,nullzero"`
 	RejectedAt           *time.Time `bun:"rejected_at,nullzero"`
 	CreatedAt            time.Time  `bun:"created_at,default:current_timestamp"`
@@ -46,46 +50,219 @@ func NewTransactionsRepo(db bun.IDB, categoriesRepo entities.CategoryRepository,
 	}
 }
 
+// Save upserts the transaction and moves its contribution in the daily
+// rollups from the previous version to the new one.
 func (r *transactionsRepo) Save(ctx context.Context, transaction *entities.Transaction) error {
 	db := postgres.FromContext(ctx, r.db)
 	var model = r.ToModel(transaction)
 
-	_, err := db.NewInsert().Model(model).
-		On("CONFLICT (id) DO UPDATE").
-		Set("user_id = EXCLUDED.user_id").
-		Set("account_id = EXCLUDED.account_id").
-		Set("category_id = EXCLUDED.category_id").
-		Set("subcategory_id = EXCLUDED.subcategory_id").
-		Set("type = EXCLUDED.type").
-		Set("status = EXCLUDED.status").
-		Set("amount = EXCLUDED.amount").
-		Set("currency_code = EXCLUDED.currency_code").
-		Set("original_amount = EXCLUDED.original_amount").
-		Set("original_currency_code = EXCLUDED.original_currency_code").
-		Set("fx_rate = EXCLUDED.fx_rate").
-		Set("row_text = EXCLUDED.row_text").
-		Set("performed_at = EXCLUDED.performed_at").
-		Set("rejected_at = EXCLUDED.rejected_at").
-		Exec(ctx)
-	if err != nil {
-		return postgres.Error(err, model)
+	byID := func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("id = ?", model.ID)
 	}
 
-	return err
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		loc, err := rollupLocation(ctx, tx, transaction.UserID)
+		if err != nil {
+			return err
+		}
+
+		err = applyRollups(ctx, tx, transaction.UserID, loc, -1, byID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewInsert().Model(model).
+			On("CONFLICT (id) DO UPDATE").
+			Set("user_id = EXCLUDED.user_id").
+			Set("account_id = EXCLUDED.account_id").
+			Set("category_id = EXCLUDED.category_id").
+			Set("subcategory_id = EXCLUDED.subcategory_id").
+			Set("type = EXCLUDED.type").
+			Set("status = EXCLUDED.status").
+			Set("amount = EXCLUDED.amount").
+			Set("currency_code = EXCLUDED.currency_code").
+			Set("original_amount = EXCLUDED.original_amount").
+			Set("original_currency_code = EXCLUDED.original_currency_code").
+			Set("fx_rate = EXCLUDED.fx_rate").
+			Set("row_text = EXCLUDED.row_text").
+			Set("merchant = EXCLUDED.merchant").
+			Set("import_session_id = EXCLUDED.import_session_id").
+			Set("external_id = EXCLUDED.external_id").
+			Set("performed_at = EXCLUDED.performed_at").
+			Set("rejected_at = EXCLUDED.rejected_at").
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, model)
+		}
+
+		return applyRollups(ctx, tx, transaction.UserID, loc, 1, byID)
+	})
+}
+
+// SaveBatch inserts new transactions with a single statement and adds them
+// to the daily rollups.
+func (r *transactionsRepo) SaveBatch(ctx context.Context, transactions []*entities.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	db := postgres.FromContext(ctx, r.db)
+
+	models := make([]*Transactions, 0, len(transactions))
+	idsByUser := make(map[uuid.UUID][]string)
+	for _, transaction := range transactions {
+		models = append(models, r.ToModel(transaction))
+		idsByUser[transaction.UserID] = append(idsByUser[transaction.UserID], transaction.ID.String())
+	}
+
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(&models).Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+
+		for userID, ids := range idsByUser {
+			loc, err := rollupLocation(ctx, tx, userID)
+			if err != nil {
+				return err
+			}
+
+			err = applyRollups(ctx, tx, userID, loc, 1, func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("id IN (?)", bun.In(ids))
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *transactionsRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	db := postgres.FromContext(ctx, r.db)
 
-	_, err := db.NewDelete().
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var userIDs []string
+		err := tx.NewSelect().
+			Model((*Transactions)(nil)).
+			Column("user_id").
+			Where("id = ?", id).
+			Scan(ctx, &userIDs)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+
+		// gone already, e.g. merged or deleted concurrently, its balance
+		// was reverted by whoever removed it
+		if len(userIDs) == 0 {
+			return postgres.Error(sql.ErrNoRows, Transactions{})
+		}
+
+		for _, userID := range userIDs {
+			userID, _ := uuid.Parse(userID)
+
+			loc, err := rollupLocation(ctx, tx, userID)
+			if err != nil {
+				return err
+			}
+
+			err = applyRollups(ctx, tx, userID, loc, -1, func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("id = ?", id)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.NewDelete().
+			Model((*Transactions)(nil)).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+
+		return nil
+	})
+}
+
+func (r *transactionsRepo) DeleteByImportSession(ctx context.Context, sessionID uuid.UUID) (int, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var affected int64
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var userIDs []string
+		err := tx.NewSelect().
+			Model((*Transactions)(nil)).
+			Distinct().
+			Column("user_id").
+			Where("import_session_id = ?", sessionID.String()).
+			Scan(ctx, &userIDs)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+
+		for _, userID := range userIDs {
+			userID, _ := uuid.Parse(userID)
+
+			loc, err := rollupLocation(ctx, tx, userID)
+			if err != nil {
+				return err
+			}
+
+			err = applyRollups(ctx, tx, userID, loc, -1, func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("import_session_id = ?", sessionID.String())
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		res, err := tx.NewDelete().
+			Model((*Transactions)(nil)).
+			Where("import_session_id = ?", sessionID.String()).
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
+
+// FindExternalIDs reports which of the bank references are already on the
+// account.
+func (r *transactionsRepo) FindExternalIDs(ctx context.Context, accountID uuid.UUID, ids []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	db := postgres.FromContext(ctx, r.db)
+
+	var existing []string
+	err := db.NewSelect().
 		Model((*Transactions)(nil)).
-		Where("id = ?", id).
-		Exec(ctx)
+		Column("external_id").
+		Where("account_id = ?", accountID.String()).
+		Where("external_id IN (?)", bun.In(ids)).
+		Scan(ctx, &existing)
 	if err != nil {
-		return postgres.Error(err, Transactions{})
+		return nil, postgres.Error(err, Transactions{})
 	}
 
-	return nil
+	for _, id := range existing {
+		found[id] = true
+	}
+
+	return found, nil
 }
 
 func (r *transactionsRepo) GetByID(ctx context.Context, transactionID uuid.UUID) (*entities.Transaction, error) {
@@ -102,6 +279,21 @@ func (r *transactionsRepo) GetByID(ctx context.Context, transactionID uuid.UUID)
 	return r.ToEntity(ctx, &model), nil
 }
 
+func (r *transactionsRepo) GetByIDForUpdate(ctx context.Context, transactionID uuid.UUID) (*entities.Transaction, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var model Transactions
+	err := db.NewSelect().Model(&model).
+		Where("id = ?", transactionID.String()).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, model)
+	}
+
+	return r.ToEntity(ctx, &model), nil
+}
+
 func (r *transactionsRepo) GetByUserID(ctx context.Context, limit, offset int, userID uuid.UUID, trnType []entities.TrnType) ([]*entities.Transaction, int, error) {
 	db := postgres.FromContext(ctx, r.db)
 
@@ -158,162 +350,509 @@ func (r *transactionsRepo) GetByAccountID(ctx context.Context, accountID uuid.UU
 	return transactions, nil
 }
 
-func (r *transactionsRepo) GetTotalByType(ctx context.Context, userID uuid.UUID, trnType entities.TrnType, from, to *time.Time) (int64, error) {
+func (r *transactionsRepo) GetAllBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.Transaction, error) {
 	db := postgres.FromContext(ctx, r.db)
 
-	var total int64
-	query := db.NewSelect().
-		Model((*Transactions)(nil)).
-		ColumnExpr("COALESCE(SUM(amount), 0)").
+	var models []Transactions
+	err := db.NewSelect().Model(&models).
 		Where("user_id = ?", userID.String()).
-		Where("type = ?", trnType.String())
-
-	if from != nil {
-		query = query.Where("created_at >= ?", from)
+		Where("created_at >= ?", from).
+		Where("created_at < ?", to).
+		Where("status = ?", entities.Completed.String()).
+		Scan(ctx)
+	if err != nil {
+		return nil, postgres.Error(err, models)
 	}
-	if to != nil {
-		query = query.Where("created_at < ?", to)
+
+	var transactions []*entities.Transaction
+	for _, model := range models {
+		transactions = append(transactions, r.ToEntity(ctx, &model))
 	}
 
-	err := query.Scan(ctx, &total)
+	return transactions, nil
+}
+
+// GetPerformedBetween returns completed transactions by when they were
+// performed rather than recorded, so future-dated ones can be found.
+func (r *transactionsRepo) GetPerformedBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.Transaction, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var models []Transactions
+	err := db.NewSelect().Model(&models).
+		Where("user_id = ?", userID.String()).
+		Where("coalesce(performed_at, created_at) >= ?", from).
+		Where("coalesce(performed_at, created_at) < ?", to).
+		Where("status = ?", entities.Completed.String()).
+		Scan(ctx)
 	if err != nil {
-		return 0, postgres.Error(err, Transactions{})
+		return nil, postgres.Error(err, models)
+	}
+
+	var transactions []*entities.Transaction
+	for _, model := range models {
+		transactions = append(transactions, r.ToEntity(ctx, &model))
 	}
 
-	return total, nil
+	return transactions, nil
 }
 
-func (r *transactionsRepo) GetTotalsByCategories(ctx context.Context, userID uuid.UUID, trnType entities.TrnType, from, to *time.Time) (map[int]int64, []int, error) {
+// GetNetByAccountSince sums the user's completed deposits less withdrawals
+// performed at or after from, per account.
+func (r *transactionsRepo) GetNetByAccountSince(ctx context.Context, userID uuid.UUID, from time.Time) (map[uuid.UUID]int64, error) {
 	db := postgres.FromContext(ctx, r.db)
 
-	var results []struct {
-		CategoryID int   `bun:"category_id"`
-		Total      int64 `bun:"total"`
+	var rows []struct {
+		AccountID string `bun:"account_id"`
+		Net       int64  `bun:"net"`
 	}
-
-	query := db.NewSelect().
+	err := db.NewSelect().
 		Model((*Transactions)(nil)).
-		Column("category_id").
-		ColumnExpr("SUM(amount) as total").
+		Column("account_id").
+		ColumnExpr("SUM(CASE type WHEN ? THEN amount WHEN ? THEN -amount ELSE 0 END) AS net",
+			entities.Deposit.String(), entities.Withdrawal.String()).
 		Where("user_id = ?", userID.String()).
-		Where("type = ?", trnType.String()).
-		Group("category_id").
-		Order("total desc")
+		Where("coalesce(performed_at, created_at) >= ?", from).
+		Where("status = ?", entities.Completed.String()).
+		Group("account_id").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, postgres.Error(err, Transactions{})
+	}
 
-	if from != nil {
-		query = query.Where("created_at >= ?", from)
+	net := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		accountID, _ := uuid.Parse(row.AccountID)
+		net[accountID] = row.Net
 	}
-	if to != nil {
-		query = query.Where("created_at < ?", to)
+
+	return net, nil
+}
+
+// GetBatch pages through transactions with a keyset cursor, which stays
+// cheap however deep an export goes.
+func (r *transactionsRepo) GetBatch(ctx context.Context, filter entities.TransactionFilter, after *entities.TransactionCursor, limit int) ([]*entities.Transaction, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var models []Transactions
+	query := db.NewSelect().Model(&models).
+		Where("user_id = ?", filter.UserID.String()).
+		OrderExpr("coalesce(performed_at, created_at) ASC, id ASC").
+		Limit(limit)
+
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", filter.AccountID.String())
+	}
+	if len(filter.Types) > 0 {
+		types := make([]string, 0, len(filter.Types))
+		for _, t := range filter.Types {
+			types = append(types, t.String())
+		}
+		query = query.Where("type IN (?)", bun.In(types))
+	}
+	if filter.From != nil {
+		query = query.Where("coalesce(performed_at, created_at) >= ?", filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("coalesce(performed_at, created_at) < ?", filter.To)
+	}
+	if filter.ImportSessionID != nil {
+		query = query.Where("import_session_id = ?", filter.ImportSessionID.String())
+	}
+	if after != nil {
+		query = query.Where("(coalesce(performed_at, created_at), id) > (?, ?)", after.OccurredAt, after.ID.String())
 	}
 
-	err := query.Scan(ctx, &results)
+	err := query.Scan(ctx)
 	if err != nil {
-		return nil, nil, postgres.Error(err, Transactions{})
+		return nil, postgres.Error(err, models)
 	}
 
-	totals := make(map[int]int64)
-	categories := make([]int, 0, len(results))
-	for _, result := range results {
-		categories = append(categories, result.CategoryID)
-		totals[result.CategoryID] = result.Total
+	transactions := make([]*entities.Transaction, 0, len(models))
+	for _, model := range models {
+		transactions = append(transactions, r.ToEntity(ctx, &model))
 	}
 
-	return totals, categories, nil
+	return transactions, nil
+}
+
+type transactionSearchRow struct {
+	Transactions
+	Rank              float64 `bun:"rank"`
+	NoteHighlight     string  `bun:"note_highlight"`
+	MerchantHighlight string  `bun:"merchant_highlight"`
+	Total             int     `bun:"total"`
 }
 
-func (r *transactionsRepo) GetTotalByTypeAndAccount(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, trnType entities.TrnType, from, to *time.Time) (int64, error) {
+// Search matches the note and merchant with russian, english and simple text
+// search configurations plus trigram similarity (Uzbek Latin has no stemmer),
+// and also matches transactions whose category or subcategory name matches
+// the query in any language, each translation with its own configuration.
+func (r *transactionsRepo) Search(ctx context.Context, userID uuid.UUID, lang entities.Language, text string, limit, offset int) ([]*entities.TransactionSearchHit, int, error) {
 	db := postgres.FromContext(ctx, r.db)
 
-	var total int64
-	query := db.NewSelect().
-		Model((*Transactions)(nil)).
-		ColumnExpr("COALESCE(SUM(amount), 0)").
-		Where("user_id = ?", userID.String()).
-		Where("type = ?", trnType.String())
+	config := lang.SearchConfig()
+
+	var rows []transactionSearchRow
+	err := db.NewRaw(`
+		WITH q AS (
+			SELECT
+				websearch_to_tsquery('russian', ?0) AS ru,
+				websearch_to_tsquery('english', ?0) AS en,
+				websearch_to_tsquery('simple', ?0) AS sm,
+				websearch_to_tsquery(?2::regconfig, ?0) AS hl,
+				lower(?0) AS raw
+		),
+		matched_names AS (
+			SELECT tr.entity, tr.entity_id
+			FROM translations tr
+			JOIN languages lg ON lg.code = tr.locale, q
+			WHERE to_tsvector(lg.search_config::regconfig, tr.value) @@ websearch_to_tsquery(lg.search_config::regconfig, ?0)
+			OR to_tsvector('simple', tr.value) @@ q.sm
+			OR q.raw <% lower(tr.value)
+		),
+		matched_categories AS (
+			SELECT c.id
+			FROM categories c
+			JOIN matched_names mn ON mn.entity = 'category' AND mn.entity_id = c.id
+			WHERE c.user_id IS NULL OR c.user_id = ?1
+		),
+		matched_subcategories AS (
+			SELECT sb.id
+			FROM subcategories sb
+			JOIN matched_names mn ON mn.entity = 'subcategory' AND mn.entity_id = sb.id
+			WHERE sb.user_id IS NULL OR sb.user_id = ?1
+		)
+		SELECT
+			t.*,
+			ts_rank(to_tsvector('russian', coalesce(t.row_text, '') || ' ' || coalesce(t.merchant, '')), q.ru)
+				+ ts_rank(to_tsvector('english', coalesce(t.row_text, '') || ' ' || coalesce(t.merchant, '')), q.en)
+				+ ts_rank(to_tsvector('simple', coalesce(t.row_text, '') || ' ' || coalesce(t.merchant, '')), q.sm)
+				+ 0.5 * greatest(
+					word_similarity(q.raw, lower(coalesce(t.row_text, ''))),
+					word_similarity(q.raw, lower(coalesce(t.merchant, '')))
+				)
+				+ CASE
+					WHEN t.subcategory_id IN (SELECT id FROM matched_subcategories) THEN 0.3
+					WHEN t.category_id IN (SELECT id FROM matched_categories) THEN 0.2
+					ELSE 0
+				END AS rank,
+			ts_headline(?2::regconfig, coalesce(t.row_text, ''), q.hl, 'StartSel=<b>, StopSel=</b>, MaxWords=20, MinWords=5') AS note_highlight,
+			ts_headline(?2::regconfig, coalesce(t.merchant, ''), q.hl, 'StartSel=<b>, StopSel=</b>, HighlightAll=true') AS merchant_highlight,
+			count(*) OVER () AS total
+		FROM transactions t, q
+		WHERE t.user_id = ?1
+		AND (
+			to_tsvector('russian', coalesce(t.row_text, '') || ' ' || coalesce(t.merchant, '')) @@ q.ru
+			OR to_tsvector('english', coalesce(t.row_text, '') || ' ' || coalesce(t.merchant, '')) @@ q.en
+			OR to_tsvector('simple', coalesce(t.row_text, '') || ' ' || coalesce(t.merchant, '')) @@ q.sm
+			-- the trigram indexes' expressions, a NULL never matches anyway
+			OR q.raw <% lower(t.row_text)
+			OR q.raw <% lower(t.merchant)
+			OR t.category_id IN (SELECT id FROM matched_categories)
+			OR t.subcategory_id IN (SELECT id FROM matched_subcategories)
+		)
+		ORDER BY rank DESC, t.performed_at DESC NULLS LAST
+		LIMIT ?3 OFFSET ?4
+	`, text, userID.String(), config, limit, offset).Scan(ctx, &rows)
+	if err != nil {
+		return nil, 0, postgres.Error(err, Transactions{})
+	}
 
-	if accountID != nil {
-		query = query.Where("account_id = ?", accountID.String())
+	var total int
+	hits := make([]*entities.TransactionSearchHit, 0, len(rows))
+	for _, row := range rows {
+		total = row.Total
+		hits = append(hits, &entities.TransactionSearchHit{
+			Transaction:       r.ToEntity(ctx, &row.Transactions),
+			Rank:              row.Rank,
+			NoteHighlight:     row.NoteHighlight,
+			MerchantHighlight: row.MerchantHighlight,
+		})
 	}
 
-	if from != nil {
-		query = query.Where("created_at >= ?", from)
+	return hits, total, nil
+}
+
+func (r *transactionsRepo) CountByCategory(ctx context.Context, userID uuid.UUID, categoryID int) (int, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	count, err := db.NewSelect().
+		Model((*Transactions)(nil)).
+		Where("user_id = ?", userID.String()).
+		Where("category_id = ?", categoryID).
+		Count(ctx)
+	if err != nil {
+		return 0, postgres.Error(err, Transactions{})
 	}
-	if to != nil {
-		query = query.Where("created_at < ?", to)
+
+	return count, nil
+}
+
+func (r *transactionsRepo) ReassignCategory(ctx context.Context, userID uuid.UUID, fromCategoryID, toCategoryID int) (int, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var affected int64
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		loc, err := rollupLocation(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		// Both categories leave the rollups and the merged one comes back.
+		err = applyRollups(ctx, tx, userID, loc, -1, func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("category_id IN (?)", bun.In([]int{fromCategoryID, toCategoryID}))
+		})
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.NewUpdate().
+			Model((*Transactions)(nil)).
+			Set("category_id = ?", toCategoryID).
+			Where("user_id = ?", userID.String()).
+			Where("category_id = ?", fromCategoryID).
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+
+		affected, err = res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		return applyRollups(ctx, tx, userID, loc, 1, func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("category_id = ?", toCategoryID)
+		})
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	err := query.Scan(ctx, &total)
+	return int(affected), nil
+}
+
+// ClearSubcategory takes the subcategory off the user's transactions, ahead
+// of deleting it. The foreign key would do it too, but behind the rollups'
+// back.
+func (r *transactionsRepo) ClearSubcategory(ctx context.Context, userID uuid.UUID, subcategoryID int) (int, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	var affected int64
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		loc, err := rollupLocation(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		byIDs := func(ids []string) func(q *bun.SelectQuery) *bun.SelectQuery {
+			return func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("id IN (?)", bun.In(ids))
+			}
+		}
+
+		var ids []string
+		err = tx.NewSelect().
+			Model((*Transactions)(nil)).
+			Column("id").
+			Where("user_id = ?", userID.String()).
+			Where("subcategory_id = ?", subcategoryID).
+			Scan(ctx, &ids)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		err = applyRollups(ctx, tx, userID, loc, -1, byIDs(ids))
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.NewUpdate().
+			Model((*Transactions)(nil)).
+			Set("subcategory_id = NULL").
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return postgres.Error(err, Transactions{})
+		}
+
+		affected, err = res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		return applyRollups(ctx, tx, userID, loc, 1, byIDs(ids))
+	})
 	if err != nil {
-		return 0, postgres.Error(err, Transactions{})
+		return 0, err
 	}
 
-	return total, nil
+	return int(affected), nil
 }
 
-func (r *transactionsRepo) GetTotalsByCategoriesAndAccount(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, trnType entities.TrnType, from, to *time.Time) (map[int]int64, []int, error) {
+// GetTimeseries sums completed income and expense per bucket from the daily
+// rollups. Their days are local to the user, so a transaction made at 01:00
+// in Tashkent lands on that day and not the previous one in UTC.
+func (r *transactionsRepo) GetTimeseries(ctx context.Context, filter entities.TimeseriesFilter) ([]*entities.TimeseriesRow, error) {
 	db := postgres.FromContext(ctx, r.db)
 
-	var results []struct {
-		CategoryID int   `bun:"category_id"`
-		Total      int64 `bun:"total"`
+	loc := filter.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var rows []struct {
+		Bucket     time.Time `bun:"bucket"`
+		CategoryID int       `bun:"category_id"`
+		Income     int64     `bun:"income"`
+		Expense    int64     `bun:"expense"`
 	}
 
 	query := db.NewSelect().
-		Model((*Transactions)(nil)).
-		Column("category_id").
-		ColumnExpr("SUM(amount) as total").
-		Where("user_id = ?", userID.String()).
-		Where("type = ?", trnType.String()).
-		Group("category_id").
-		Order("total desc")
+		Model((*TransactionRollups)(nil)).
+		ColumnExpr("date_trunc(?, day::timestamp) AS bucket", filter.Granularity.String()).
+		ColumnExpr("COALESCE(SUM(total) FILTER (WHERE type = ?), 0) AS income", entities.Deposit.String()).
+		ColumnExpr("COALESCE(SUM(total) FILTER (WHERE type = ?), 0) AS expense", entities.Withdrawal.String()).
+		Where("user_id = ?", filter.UserID.String()).
+		Where("day >= ?", rollupDay(filter.From, loc)).
+		Where("day < ?", rollupDay(filter.To, loc)).
+		GroupExpr("bucket").
+		OrderExpr("bucket ASC")
+
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", filter.AccountID.String())
+	}
 
-	if accountID != nil {
-		query = query.Where("account_id = ?", accountID.String())
+	if filter.ByCategory {
+		query = query.
+			Column("category_id").
+			GroupExpr("category_id")
+	}
+
+	err := query.Scan(ctx, &rows)
+	if err != nil {
+		return nil, postgres.Error(err, TransactionRollups{})
 	}
 
-	if from != nil {
-		query = query.Where("created_at >= ?", from)
+	result := make([]*entities.TimeseriesRow, 0, len(rows))
+	for _, row := range rows {
+		// date_trunc returns a timestamp without time zone, re-attach the location
+		b := row.Bucket
+		result = append(result, &entities.TimeseriesRow{
+			Bucket:     time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, loc),
+			CategoryID: row.CategoryID,
+			Income:     row.Income,
+			Expense:    row.Expense,
+		})
 	}
-	if to != nil {
-		query = query.Where("created_at < ?", to)
+
+	return result, nil
+}
+
+// GetCategoryTotalsComparison sums both periods per category and type in a
+// single pass over the daily rollups.
+func (r *transactionsRepo) GetCategoryTotalsComparison(ctx context.Context, filter entities.PeriodComparisonFilter) ([]*entities.CategoryComparisonRow, error) {
+	db := postgres.FromContext(ctx, r.db)
+
+	loc := filter.CurrentFrom.Location()
+	currentFrom, currentTo := rollupDay(filter.CurrentFrom, loc), rollupDay(filter.CurrentTo, loc)
+	previousFrom, previousTo := rollupDay(filter.PreviousFrom, loc), rollupDay(filter.PreviousTo, loc)
+
+	var rows []struct {
+		CategoryID int    `bun:"category_id"`
+		Type       string `bun:"type"`
+		Current    int64  `bun:"current"`
+		Previous   int64  `bun:"previous"`
 	}
 
-	err := query.Scan(ctx, &results)
+	query := db.NewSelect().
+		Model((*TransactionRollups)(nil)).
+		Column("category_id", "type").
+		ColumnExpr("COALESCE(SUM(total) FILTER (WHERE day >= ? AND day < ?), 0) AS current", currentFrom, currentTo).
+		ColumnExpr("COALESCE(SUM(total) FILTER (WHERE day >= ? AND day < ?), 0) AS previous", previousFrom, previousTo).
+		Where("user_id = ?", filter.UserID.String()).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				WhereOr("day >= ? AND day < ?", currentFrom, currentTo).
+				WhereOr("day >= ? AND day < ?", previousFrom, previousTo)
+		}).
+		GroupExpr("category_id, type")
+
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", filter.AccountID.String())
+	}
+
+	err := query.Scan(ctx, &rows)
 	if err != nil {
-		return nil, nil, postgres.Error(err, Transactions{})
+		return nil, postgres.Error(err, TransactionRollups{})
 	}
 
-	totals := make(map[int]int64)
-	categories := make([]int, 0, len(results))
-	for _, result := range results {
-		categories = append(categories, result.CategoryID)
-		totals[result.CategoryID] = result.Total
+	result := make([]*entities.CategoryComparisonRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, &entities.CategoryComparisonRow{
+			CategoryID: row.CategoryID,
+			Type:       entities.TrnType(row.Type),
+			Current:    row.Current,
+			Previous:   row.Previous,
+		})
 	}
 
-	return totals, categories, nil
+	return result, nil
 }
 
-func (r *transactionsRepo) GetAllBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.Transaction, error) {
+// GetCategoryBreakdown sums completed deposits and withdrawals per category
+// and subcategory from the daily rollups, keeping uncategorized rows under
+// category 0.
+func (r *transactionsRepo) GetCategoryBreakdown(ctx context.Context, filter entities.CategoryBreakdownFilter) ([]*entities.CategoryBreakdownRow, error) {
 	db := postgres.FromContext(ctx, r.db)
 
-	var models []Transactions
-	err := db.NewSelect().Model(&models).
-		Where("user_id = ?", userID.String()).
-		Where("created_at >= ?", from).
-		Where("created_at < ?", to).
-		Where("status = ?", entities.Completed.String()).
-		Scan(ctx)
+	var rows []struct {
+		Type          string `bun:"type"`
+		CategoryID    int    `bun:"category_id"`
+		SubcategoryID int    `bun:"subcategory_id"`
+		Total         int64  `bun:"total"`
+		Count         int    `bun:"count"`
+	}
+
+	query := db.NewSelect().
+		Model((*TransactionRollups)(nil)).
+		Column("type", "category_id", "subcategory_id").
+		ColumnExpr("SUM(total) AS total").
+		ColumnExpr("SUM(count) AS count").
+		Where("user_id = ?", filter.UserID.String()).
+		GroupExpr("type, category_id, subcategory_id").
+		OrderExpr("total DESC")
+
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", filter.AccountID.String())
+	}
+	if filter.From != nil {
+		query = query.Where("day >= ?", rollupDay(*filter.From, filter.From.Location()))
+	}
+	if filter.To != nil {
+		query = query.Where("day < ?", rollupDay(*filter.To, filter.To.Location()))
+	}
+
+	err := query.Scan(ctx, &rows)
 	if err != nil {
-		return nil, postgres.Error(err, models)
+		return nil, postgres.Error(err, TransactionRollups{})
 	}
 
-	var transactions []*entities.Transaction
-	for _, model := range models {
-		transactions = append(transactions, r.ToEntity(ctx, &model))
+	result := make([]*entities.CategoryBreakdownRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, &entities.CategoryBreakdownRow{
+			Type:          entities.TrnType(row.Type),
+			CategoryID:    row.CategoryID,
+			SubcategoryID: row.SubcategoryID,
+			Total:         row.Total,
+			Count:         row.Count,
+		})
 	}
 
-	return transactions, nil
+	return result, nil
 }
 
 func (r *transactionsRepo) ToModel(e *entities.Transaction) *Transactions {
@@ -333,6 +872,8 @@ func (r *transactionsRepo) ToModel(e *entities.Transaction) *Transactions {
 		OriginalCurrencyCode: pointer.StringOrNil(e.OriginalCurrencyCode.String()),
 		FxRate:               pointer.Float64OrNil(e.FxRate),
 		RowText:              e.RowText,
+		Merchant:             pointer.StringOrNil(e.Merchant),
+		ExternalID:           pointer.StringOrNil(e.ExternalID),
 		PerformedAt:          pointer.TimeOrNil(e.PerformedAt),
 		RejectedAt:           pointer.TimeOrNil(e.RejectedAt),
 		CreatedAt:            e.CreatedAt,
@@ -346,6 +887,10 @@ func (r *transactionsRepo) ToModel(e *entities.Transaction) *Transactions {
 		transactions.SubcategoryID = pointer.Int(e.Subcategory.ID)
 	}
 
+	if e.ImportSessionID != nil {
+		transactions.ImportSessionID = pointer.String(e.ImportSessionID.String())
+	}
+
 	return transactions
 }
 
@@ -370,11 +915,19 @@ func (r *transactionsRepo) ToEntity(ctx context.Context, m *Transactions) *entit
 		OriginalCurrencyCode: entities.Currency(pointer.StringValue(m.OriginalCurrencyCode)),
 		FxRate:               pointer.Float64Value(m.FxRate),
 		RowText:              m.RowText,
+		Merchant:             pointer.StringValue(m.Merchant),
+		ExternalID:           pointer.StringValue(m.ExternalID),
 		PerformedAt:          pointer.TimeValue(m.PerformedAt),
 		RejectedAt:           pointer.TimeValue(m.RejectedAt),
 		CreatedAt:            m.CreatedAt,
 	}
 
+	if m.ImportSessionID != nil {
+		if sessionID, err := uuid.Parse(*m.ImportSessionID); err == nil {
+			e.ImportSessionID = &sessionID
+		}
+	}
+
 	if m.CategoryID != nil {
 		category, err := r.categoriesRepo.FindByID(ctx, *m.CategoryID)
 		if err == nil && category != nil {
@@ -22,6 +22,7 @@ type Users struct {
 	LanguageCode string     `bun:"language_code,nullzero"`
 	CurrencyCode string     `bun:"currency_code,nullzero"`
 	Timezone     string     `bun:"timezone,nullzero"`
+	Plan         string     `bun:"plan,nullzero"`
 	CreatedAt    time.Time  `bun:"created_at,default:current_timestamp"`
 	UpdatedAt    *time.Time `bun:"updated_at,nullzero"`
 }
@@ -49,6 +50,7 @@ func (r *usersRepo) Save(ctx context.Context, user *entities.User) error {
 		Set("language_code = EXCLUDED.language_code").
 		Set("currency_code = EXCLUDED.currency_code").
 		Set("timezone = EXCLUDED.timezone").
+		Set("plan = EXCLUDED.plan").
 		Set("updated_at = EXCLUDED.updated_at").
 		Exec(ctx)
 	if err != nil {
@@ -118,6 +120,7 @@ func (r *usersRepo) ToModel(e *entities.User) *Users {
 		LanguageCode: e.LanguageCode.String(),
 		CurrencyCode: e.CurrencyCode.String(),
 		Timezone:     e.Timezone,
+		Plan:         e.Plan.String(),
 		CreatedAt:    e.CreatedAt,
 		UpdatedAt:    pointer.TimeOrNil(e.UpdatedAt),
 	}
@@ -141,6 +144,7 @@ func (r *usersRepo) ToEntity(m *Users) *entities.User {
 		LanguageCode: entities.Language(m.LanguageCode),
 		CurrencyCode: entities.Currency(m.CurrencyCode),
 		Timezone:     m.Timezone,
+		Plan:         entities.Plan(m.Plan),
 		CreatedAt:    m.CreatedAt,
 		UpdatedAt:    pointer.TimeValue(m.UpdatedAt),
 	}
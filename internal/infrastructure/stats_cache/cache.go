@@ -0,0 +1,85 @@
+package stats_cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/redis"
+	"github.com/google/uuid"
+)
+
+type statsCache struct {
+	redis   *redis.RedisClient
+	ttl     time.Duration
+	lookups *otlp.CacheLookups
+}
+
+// New keeps stats in redis for ttl. The generation counters never expire,
+// a counter starting over could make entries of a past generation reachable
+// again.
+func New(redis *redis.RedisClient, ttl time.Duration) ports.StatsCache {
+	return &statsCache{
+		redis:   redis,
+		ttl:     ttl,
+		lookups: otlp.NewCacheLookups("stats_cache"),
+	}
+}
+
+func (c *statsCache) Generation(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return c.redis.GetInt64(ctx, generationKey(userID))
+}
+
+func (c *statsCache) Get(ctx context.Context, userID uuid.UUID, generation int64, key string, dest any) (bool, error) {
+	data, err := c.redis.GetBytes(ctx, entryKey(userID, generation, key))
+	if err != nil && !redis.IsNil(err) {
+		return false, err
+	}
+
+	if len(data) == 0 {
+		c.lookups.Record(ctx, false)
+		return false, nil
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		// a value written by an older layout, recompute it
+		c.lookups.Record(ctx, false)
+		return false, nil
+	}
+
+	c.lookups.Record(ctx, true)
+	return true, nil
+}
+
+func (c *statsCache) Set(ctx context.Context, userID uuid.UUID, generation int64, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.redis.SetBytes(ctx, entryKey(userID, generation, key), data, c.ttl)
+}
+
+func (c *statsCache) Invalidate(ctx context.Context, userID uuid.UUID) {
+	postgres.AfterCommit(ctx, func(ctx context.Context) {
+		if _, err := c.redis.Incr(ctx, generationKey(userID)); err != nil {
+			slog.WarnContext(ctx, "failed to invalidate stats cache",
+				slog.String("user_id", userID.String()),
+				slog.String("error.message", err.Error()),
+			)
+		}
+	})
+}
+
+func generationKey(userID uuid.UUID) string {
+	return "stats:gen:" + userID.String()
+}
+
+func entryKey(userID uuid.UUID, generation int64, key string) string {
+	return fmt.Sprintf("stats:%s:%d:%s", userID, generation, key)
+}
@@ -0,0 +1,219 @@
+package stats_cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/redis"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// testRedis connects to the redis named by REDIS_HOST and REDIS_PORT, a local
+// one by default, and skips the test when it is not reachable.
+func testRedis(t *testing.T) *redis.RedisClient {
+	t.Helper()
+
+	host, port := os.Getenv("REDIS_HOST"), os.Getenv("REDIS_PORT")
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "6379"
+	}
+
+	client, err := redis.New(
+		redis.WithAddress(net.JoinHostPort(host, port)),
+		redis.WithPassword(os.Getenv("REDIS_PASSWORD")),
+		redis.WithDialTimeout(time.Second),
+		redis.WithKeyPrefix("test:"+uuid.NewString()+":"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	if _, err := client.Exists(context.Background(), "ping"); err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+
+	return client
+}
+
+// testDB connects to the database named by the DB_* variables and skips the
+// test when there is none. Only its transactions are used.
+func testDB(t *testing.T) *bun.DB {
+	t.Helper()
+
+	host := os.Getenv("DB_HOST")
+	if host == "" {
+		t.Skip("DB_HOST is not set")
+	}
+
+	opts := []postgres.Options{postgres.WithHost(host)}
+	if port := os.Getenv("DB_PORT"); port != "" {
+		opts = append(opts, postgres.WithPort(port))
+	}
+	if name := os.Getenv("DB_DATABASE"); name != "" {
+		opts = append(opts, postgres.WithDB(name))
+	}
+	if user := os.Getenv("DB_USERNAME"); user != "" {
+		opts = append(opts, postgres.WithUser(user))
+	}
+	if password := os.Getenv("DB_PASSWORD"); password != "" {
+		opts = append(opts, postgres.WithPassword(password))
+	}
+
+	db, err := postgres.NewBunDB(opts...)
+	if err != nil {
+		t.Skipf("postgres is not available: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Skipf("postgres is not available: %v", err)
+	}
+
+	return db
+}
+
+type testStats struct {
+	Income  int64 `json:"income"`
+	Expense int64 `json:"expense"`
+}
+
+func TestInvalidateBumpsGeneration(t *testing.T) {
+	client := testRedis(t)
+	cache := New(client, time.Minute)
+	ctx := context.Background()
+	userID := uuid.New()
+	t.Cleanup(func() { client.Delete(ctx, generationKey(userID)) })
+
+	generation, err := cache.Generation(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := testStats{Income: 100, Expense: 40}
+	if err := cache.Set(ctx, userID, generation, "summary", want); err != nil {
+		t.Fatal(err)
+	}
+
+	var got testStats
+	found, err := cache.Get(ctx, userID, generation, "summary", &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || got != want {
+		t.Fatalf("Get = %+v, %v; want %+v, true", got, found, want)
+	}
+
+	cache.Invalidate(ctx, userID)
+
+	next, err := cache.Generation(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != generation+1 {
+		t.Fatalf("generation = %d after invalidation, want %d", next, generation+1)
+	}
+
+	found, err = cache.Get(ctx, userID, next, "summary", &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found {
+		t.Error("entry of the previous generation is still reachable")
+	}
+
+	// the generation of another user is left alone
+	other, err := cache.Generation(ctx, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other != 0 {
+		t.Errorf("generation of another user = %d, want 0", other)
+	}
+}
+
+// TestInvalidateWaitsForCommit bumps the generation only once the database
+// transaction it was called in commits, readers must not cache stats of
+// writes that are not visible yet, nor lose entries to a rollback.
+func TestInvalidateWaitsForCommit(t *testing.T) {
+	client := testRedis(t)
+	txManager := postgres.NewTxManager(testDB(t))
+	cache := New(client, time.Minute)
+	ctx := context.Background()
+	userID := uuid.New()
+	t.Cleanup(func() { client.Delete(ctx, generationKey(userID)) })
+
+	generation := func() int64 {
+		t.Helper()
+		value, err := cache.Generation(ctx, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	err := txManager.WithTx(ctx, func(ctx context.Context) error {
+		cache.Invalidate(ctx, userID)
+		if got := generation(); got != 0 {
+			t.Errorf("generation = %d before the commit, want 0", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := generation(); got != 1 {
+		t.Errorf("generation = %d after the commit, want 1", got)
+	}
+
+	errRollback := errors.New("rollback")
+	err = txManager.WithTx(ctx, func(ctx context.Context) error {
+		cache.Invalidate(ctx, userID)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx = %v, want the rollback error", err)
+	}
+	if got := generation(); got != 1 {
+		t.Errorf("generation = %d after the rollback, want 1", got)
+	}
+}
+
+func TestEntriesExpire(t *testing.T) {
+	client := testRedis(t)
+	cache := New(client, time.Second)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	if err := cache.Set(ctx, userID, 0, "summary", testStats{Income: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got testStats
+	found, err := cache.Get(ctx, userID, 0, "summary", &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("entry is missing right after Set")
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+
+	found, err = cache.Get(ctx, userID, 0, "summary", &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found {
+		t.Error("entry is still there after its ttl")
+	}
+}
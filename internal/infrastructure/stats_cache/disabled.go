@@ -0,0 +1,29 @@
+package stats_cache
+
+import (
+	"context"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/google/uuid"
+)
+
+type disabled struct{}
+
+// Disabled never finds anything, stats are computed on every request.
+func Disabled() ports.StatsCache {
+	return disabled{}
+}
+
+func (disabled) Generation(context.Context, uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (disabled) Get(context.Context, uuid.UUID, int64, string, any) (bool, error) {
+	return false, nil
+}
+
+func (disabled) Set(context.Context, uuid.UUID, int64, string, any) error {
+	return nil
+}
+
+func (disabled) Invalidate(context.Context, uuid.UUID) {}
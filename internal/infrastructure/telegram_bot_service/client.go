@@ -1,8 +1,10 @@
 package telegram_bot_service
 
 import (
+	"bytes"
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/AsaHero/e-wallet/internal/inerr"
 	"github.com/AsaHero/e-wallet/internal/usecase/ports"
@@ -69,3 +71,34 @@ func (c *apiClient) SendMessage(ctx context.Context, req *ports.SendMessageReque
 
 	return nil
 }
+
+// SendDocument uploads a file to the chat as multipart form data.
+func (c *apiClient) SendDocument(ctx context.Context, req *ports.SendDocumentRequest) error {
+	var response Response
+	httpResponse, err := c.httpClient.R().
+		SetContext(ctx).
+		SetMultipartFormData(map[string]string{
+			"userId":    strconv.FormatInt(req.UserID, 10),
+			"caption":   req.Caption,
+			"parseMode": req.ParseMode,
+		}).
+		SetFileReader("document", req.Filename, bytes.NewReader(req.Data)).
+		SetResult(&response).
+		Post("/api/send-document")
+
+	if err != nil {
+		return err
+	}
+
+	if !httpResponse.IsSuccess() || !response.Success {
+		return inerr.NewErrHttp(
+			httpResponse.StatusCode(),
+			httpResponse.Request.Method,
+			httpResponse.Request.URL,
+			response.Error,
+			httpResponse.Bytes(),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+)
+
+const AnomalyDetectTaskName string = "anomaly:detect"
+
+type AnomalyDetectPayload struct {
+	UserID        string `json:"user_id"`
+	TransactionID string `json:"transaction_id"`
+}
+
+func NewAnomalyDetectTask(userID string, transactionID string) (*asynq.Task, error) {
+	payload := AnomalyDetectPayload{
+		UserID:        userID,
+		TransactionID: transactionID,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(AnomalyDetectTaskName, data, asynq.Queue("medium")), nil
+}
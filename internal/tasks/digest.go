@@ -0,0 +1,48 @@
+package tasks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const DigestScheduleTaskName string = "digest:schedule"
+const DigestSendTaskName string = "digest:send"
+
+// DigestScheduleCron fires every hour, digests go out at the hour each user
+// picked in their own time zone.
+const DigestScheduleCron = "0 * * * *"
+
+func NewDigestScheduleTask() *asynq.Task {
+	return asynq.NewTask(DigestScheduleTaskName, nil, asynq.Queue("low"), asynq.Unique(30*time.Minute))
+}
+
+type DigestSendPayload struct {
+	UserID string `json:"user_id"`
+	Period string `json:"period"`
+	// Date is the local day the digest is for, "YYYY-MM-DD". The period
+	// ends at its start.
+	Date string `json:"date"`
+}
+
+// NewDigestSendTask is identified by user, period and day and kept after it
+// is done, so a digest is sent once however often the schedule fires.
+func NewDigestSendTask(userID string, period string, date string) (*asynq.Task, error) {
+	payload := DigestSendPayload{
+		UserID: userID,
+		Period: period,
+		Date:   date,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(DigestSendTaskName, data,
+		asynq.Queue("low"),
+		asynq.TaskID(DigestSendTaskName+":"+userID+":"+period+":"+date),
+		asynq.Retention(48*time.Hour),
+	), nil
+}
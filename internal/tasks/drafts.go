@@ -0,0 +1,16 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const DraftsCleanupTaskName string = "drafts:cleanup"
+
+// DraftsCleanupCron fires every hour, drafts live for a day.
+const DraftsCleanupCron = "15 * * * *"
+
+func NewDraftsCleanupTask() *asynq.Task {
+	return asynq.NewTask(DraftsCleanupTaskName, nil, asynq.Queue("low"), asynq.Unique(time.Hour))
+}
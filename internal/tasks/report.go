@@ -0,0 +1,45 @@
+package tasks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const MonthlyReportScheduleTaskName string = "report:monthly:schedule"
+const MonthlyReportSendTaskName string = "report:monthly:send"
+
+// MonthlyReportScheduleCron fires at midnight UTC on the 1st, when the new
+// month has started or is about to start in every time zone.
+const MonthlyReportScheduleCron = "0 0 1 * *"
+
+func NewMonthlyReportScheduleTask() *asynq.Task {
+	return asynq.NewTask(MonthlyReportScheduleTaskName, nil, asynq.Queue("low"), asynq.Unique(time.Hour))
+}
+
+type MonthlyReportSendPayload struct {
+	UserID string `json:"user_id"`
+	Month  string `json:"month"`
+}
+
+// NewMonthlyReportSendTask is identified by user and month and kept after
+// it is done, so a schedule fired twice does not send the report twice.
+func NewMonthlyReportSendTask(userID string, month string, processAt time.Time) (*asynq.Task, error) {
+	payload := MonthlyReportSendPayload{
+		UserID: userID,
+		Month:  month,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(MonthlyReportSendTaskName, data,
+		asynq.Queue("low"),
+		asynq.TaskID(MonthlyReportSendTaskName+":"+userID+":"+month),
+		asynq.Retention(7*24*time.Hour),
+		asynq.ProcessAt(processAt),
+	), nil
+}
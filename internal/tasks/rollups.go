@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const RollupsVerifyTaskName string = "rollups:verify"
+const RollupsRebuildTaskName string = "rollups:rebuild"
+
+// RollupsVerifyCron fires every night, when the stats are least used.
+const RollupsVerifyCron = "30 2 * * *"
+
+func NewRollupsVerifyTask() *asynq.Task {
+	return asynq.NewTask(RollupsVerifyTaskName, nil, asynq.Queue("low"), asynq.Unique(time.Hour))
+}
+
+type RollupsRebuildPayload struct {
+	UserID string `json:"user_id"`
+}
+
+// NewRollupsRebuildTask is unique per user until it is processed, requests
+// for a rebuild that is still pending are folded into it.
+func NewRollupsRebuildTask(userID string) (*asynq.Task, error) {
+	payload := RollupsRebuildPayload{
+		UserID: userID,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(RollupsRebuildTaskName, data, asynq.Queue("medium"), asynq.Unique(time.Hour)), nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
@@ -20,6 +21,7 @@ type CreateAccountUsecase struct {
 	accountsRepo     entities.AccountRepository
 	transactionsRepo entities.TransactionRepository
 	categoryRepo     entities.CategoryRepository
+	statsCache       ports.StatsCache
 }
 
 func NewCreateAccountUsecase(
@@ -29,6 +31,7 @@ func NewCreateAccountUsecase(
 	accountsRepo entities.AccountRepository,
 	transactionsRepo entities.TransactionRepository,
 	categoryRepo entities.CategoryRepository,
+	statsCache ports.StatsCache,
 ) *CreateAccountUsecase {
 	return &CreateAccountUsecase{
 		contextTimeout:   timeout,
@@ -37,6 +40,7 @@ func NewCreateAccountUsecase(
 		transactionsRepo: transactionsRepo,
 		categoryRepo:     categoryRepo,
 		logger:           logger,
+		statsCache:       statsCache,
 	}
 }
 
@@ -125,5 +129,7 @@ func (u *CreateAccountUsecase) CreateAccount(ctx context.Context, cmd *CreateAcc
 		}
 	}
 
+	u.statsCache.Invalidate(ctx, user.ID)
+
 	return account, nil
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
@@ -18,6 +19,7 @@ type DeleteAccountUsecase struct {
 	logger         *logger.Logger
 	usersRepo      entities.UserRepository
 	accountsRepo   entities.AccountRepository
+	statsCache     ports.StatsCache
 }
 
 func NewDeleteAccountUsecase(
@@ -25,12 +27,14 @@ func NewDeleteAccountUsecase(
 	logger *logger.Logger,
 	usersRepo entities.UserRepository,
 	accountsRepo entities.AccountRepository,
+	statsCache ports.StatsCache,
 ) *DeleteAccountUsecase {
 	return &DeleteAccountUsecase{
 		contextTimeout: timeout,
 		usersRepo:      usersRepo,
 		accountsRepo:   accountsRepo,
 		logger:         logger,
+		statsCache:     statsCache,
 	}
 }
 
@@ -85,5 +89,7 @@ func (u *DeleteAccountUsecase) DeleteAccount(ctx context.Context, cmd *DeleteAcc
 		return err
 	}
 
+	u.statsCache.Invalidate(ctx, account.UserID)
+
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ type UpdateAccountUsecase struct {
 	usersRepo             entities.UserRepository
 	accountsRepo          entities.AccountRepository
 	accountsDomainService *entities.AccountsService
+	statsCache            ports.StatsCache
 }
 
 func NewUpdateAccountUsecase(
@@ -27,6 +29,7 @@ func NewUpdateAccountUsecase(
 	usersRepo entities.UserRepository,
 	accountsRepo entities.AccountRepository,
 	accountsDomainService *entities.AccountsService,
+	statsCache ports.StatsCache,
 ) *UpdateAccountUsecase {
 	return &UpdateAccountUsecase{
 		contextTimeout:        timeout,
@@ -34,6 +37,7 @@ func NewUpdateAccountUsecase(
 		accountsRepo:          accountsRepo,
 		accountsDomainService: accountsDomainService,
 		logger:                logger,
+		statsCache:            statsCache,
 	}
 }
 
@@ -106,5 +110,7 @@ func (u *UpdateAccountUsecase) UpdateAccount(ctx context.Context, cmd *UpdateAcc
 		return nil, err
 	}
 
+	u.statsCache.Invalidate(ctx, account.UserID)
+
 	return account, nil
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/usecase/accounts/command"
 	"github.com/AsaHero/e-wallet/internal/usecase/accounts/query"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 
 	"github.com/AsaHero/e-wallet/pkg/logger"
 )
@@ -18,6 +19,7 @@ type Commands struct {
 
 type Query struct {
 	*query.GetAccountsByUserIDUsecase
+	*query.GetCashFlowForecastUsecase
 }
 
 type Module struct {
@@ -33,15 +35,17 @@ func NewModule(
 	accountsDomainService *entities.AccountsService,
 	trnasctionsRepo entities.TransactionRepository,
 	categoriesRepo entities.CategoryRepository,
+	statsCache ports.StatsCache,
 ) *Module {
 	m := &Module{
 		Command: Commands{
-			CreateAccountUsecase: command.NewCreateAccountUsecase(timeout, logger, usersRepo, accountsRepo, trnasctionsRepo, categoriesRepo),
-			UpdateAccountUsecase: command.NewUpdateAccountUsecase(timeout, logger, usersRepo, accountsRepo, accountsDomainService),
-			DeleteAccountUsecase: command.NewDeleteAccountUsecase(timeout, logger, usersRepo, accountsRepo),
+			CreateAccountUsecase: command.NewCreateAccountUsecase(timeout, logger, usersRepo, accountsRepo, trnasctionsRepo, categoriesRepo, statsCache),
+			UpdateAccountUsecase: command.NewUpdateAccountUsecase(timeout, logger, usersRepo, accountsRepo, accountsDomainService, statsCache),
+			DeleteAccountUsecase: command.NewDeleteAccountUsecase(timeout, logger, usersRepo, accountsRepo, statsCache),
 		},
 		Query: Query{
 			GetAccountsByUserIDUsecase: query.NewGetAccountsByUserIDUsecase(timeout, logger, accountsRepo),
+			GetCashFlowForecastUsecase: query.NewGetCashFlowForecastUsecase(timeout, logger, usersRepo, accountsRepo, trnasctionsRepo),
 		},
 	}
 
@@ -0,0 +1,226 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultForecastDays = 30
+	maxForecastDays     = 90
+	// forecastHistoryDays is the window recurring patterns and weekday
+	// averages are mined from, long enough to see a salary three times.
+	forecastHistoryDays = 120
+)
+
+type GetCashFlowForecastUsecase struct {
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	usersRepo        entities.UserRepository
+	accountsRepo     entities.AccountRepository
+	transactionsRepo entities.TransactionRepository
+}
+
+func NewGetCashFlowForecastUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+) *GetCashFlowForecastUsecase {
+	return &GetCashFlowForecastUsecase{
+		contextTimeout:   timeout,
+		logger:           logger,
+		usersRepo:        usersRepo,
+		accountsRepo:     accountsRepo,
+		transactionsRepo: transactionsRepo,
+	}
+}
+
+type GetCashFlowForecastQuery struct {
+	UserID    string
+	AccountID string
+	Days      int
+}
+
+type CashFlowForecastView struct {
+	Timezone string                `json:"timezone"`
+	Currency string                `json:"currency"`
+	From     string                `json:"from"`
+	To       string                `json:"to"`
+	Accounts []AccountForecastView `json:"accounts"`
+}
+
+type AccountForecastView struct {
+	AccountID      string              `json:"account_id"`
+	AccountName    string              `json:"account_name"`
+	StartBalance   float64             `json:"start_balance"`
+	MinBalance     float64             `json:"min_balance"`
+	FirstBelowZero *string             `json:"first_below_zero"`
+	Recurring      []RecurringFlowView `json:"recurring"`
+	Days           []ForecastDayView   `json:"days"`
+}
+
+type RecurringFlowView struct {
+	Type       string  `json:"type"`
+	DayOfMonth int     `json:"day_of_month"`
+	Amount     float64 `json:"amount"`
+}
+
+type ForecastDayView struct {
+	Date      string  `json:"date"`
+	Income    float64 `json:"income"`
+	Expense   float64 `json:"expense"`
+	Balance   float64 `json:"balance"`
+	BelowZero bool    `json:"below_zero"`
+}
+
+func (u *GetCashFlowForecastUsecase) GetCashFlowForecast(ctx context.Context, query *GetCashFlowForecastQuery) (_ *CashFlowForecastView, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("accounts"), "GetCashFlowForecast",
+		attribute.String("user_id", query.UserID),
+		attribute.String("account_id", query.AccountID),
+		attribute.Int("days", query.Days),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		accountID *uuid.UUID
+		days      int
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(query.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		if query.AccountID != "" {
+			accountID, err := uuid.Parse(query.AccountID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to parse account id", err)
+				return nil, inerr.NewErrValidation("account_id", "invalid uuid type")
+			}
+			input.accountID = &accountID
+		}
+
+		input.days = defaultForecastDays
+		if query.Days != 0 {
+			if query.Days < 1 || query.Days > maxForecastDays {
+				return nil, inerr.NewErrValidation("days", "days must be between 1 and 90")
+			}
+			input.days = query.Days
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	accounts, err := u.accountsRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get accounts", err)
+		return nil, err
+	}
+
+	if input.accountID != nil {
+		var selected []*entities.Account
+		for _, account := range accounts {
+			if account.ID == *input.accountID {
+				selected = append(selected, account)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, inerr.NewErrNotFound("account")
+		}
+		accounts = selected
+	}
+
+	periods := user.Periods()
+	loc := periods.Location()
+	start := periods.Tomorrow()
+
+	history, err := u.transactionsRepo.GetPerformedBetween(ctx, user.ID, start.AddDate(0, 0, -forecastHistoryDays), start)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get transactions history", err)
+		return nil, err
+	}
+
+	// Everything dated in the future is already in the balance, so the model
+	// needs all of it, not only the part inside the horizon.
+	scheduled, err := u.transactionsRepo.GetPerformedBetween(ctx, user.ID, start, start.AddDate(100, 0, 0))
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get scheduled transactions", err)
+		return nil, err
+	}
+
+	forecasts := entities.ForecastCashFlow(entities.ForecastInput{
+		Accounts:    accounts,
+		History:     history,
+		Scheduled:   scheduled,
+		Start:       start,
+		Days:        input.days,
+		HistoryDays: forecastHistoryDays,
+	})
+
+	scale := user.CurrencyCode.Scale()
+	view := &CashFlowForecastView{
+		Timezone: loc.String(),
+		Currency: user.CurrencyCode.String(),
+		From:     start.Format(time.DateOnly),
+		To:       start.AddDate(0, 0, input.days-1).Format(time.DateOnly),
+		Accounts: make([]AccountForecastView, 0, len(forecasts)),
+	}
+
+	for i, forecast := range forecasts {
+		item := AccountForecastView{
+			AccountID:    forecast.AccountID.String(),
+			AccountName:  accounts[i].Name,
+			StartBalance: entities.MajorFromMinor(forecast.StartBalance, scale),
+			MinBalance:   entities.MajorFromMinor(forecast.MinBalance, scale),
+			Recurring:    make([]RecurringFlowView, 0, len(forecast.Recurring)),
+			Days:         make([]ForecastDayView, 0, len(forecast.Days)),
+		}
+
+		if forecast.FirstBelowZero != nil {
+			date := forecast.FirstBelowZero.Format(time.DateOnly)
+			item.FirstBelowZero = &date
+		}
+
+		for _, flow := range forecast.Recurring {
+			item.Recurring = append(item.Recurring, RecurringFlowView{
+				Type:       flow.Type.String(),
+				DayOfMonth: flow.DayOfMonth,
+				Amount:     entities.MajorFromMinor(flow.Amount, scale),
+			})
+		}
+
+		for _, day := range forecast.Days {
+			item.Days = append(item.Days, ForecastDayView{
+				Date:      day.Date.Format(time.DateOnly),
+				Income:    entities.MajorFromMinor(day.Income, scale),
+				Expense:   entities.MajorFromMinor(day.Expense, scale),
+				Balance:   entities.MajorFromMinor(day.Balance, scale),
+				BelowZero: day.BelowZero,
+			})
+		}
+
+		view.Accounts = append(view.Accounts, item)
+	}
+
+	return view, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
 	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
@@ -30,9 +31,10 @@ func NewCreateCategoryUsecase(timeout time.Duration, logger *logger.Logger, user
 }
 
 type CreateCategoryCommand struct {
-	UserID string `json:"user_id"`
-	Name   string `json:"name"`
-	Emoji  string `json:"emoji"`
+	UserID string            `json:"user_id"`
+	Name   string            `json:"name"`
+	Names  map[string]string `json:"names"`
+	Emoji  string            `json:"emoji"`
 }
 
 func (c *CreateCategoryUsecase) CreateCategory(ctx context.Context, cmd *CreateCategoryCommand) (_ *models.Category, err error) {
@@ -60,6 +62,12 @@ func (c *CreateCategoryUsecase) CreateCategory(ctx context.Context, cmd *CreateC
 		return nil, err
 	}
 
+	err = category.Localize(toLocalized(cmd.Names))
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to localize category", err)
+		return nil, inerr.NewErrValidation("names", err.Error())
+	}
+
 	err = c.categoryRepo.Save(ctx, category)
 	if err != nil {
 		c.logger.ErrorContext(ctx, "failed to save category", err)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
 	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
@@ -30,10 +31,11 @@ func NewCreateSubcategoryUsecase(timeout time.Duration, logger *logger.Logger, u
 }
 
 type CreateSubcategoryCommand struct {
-	UserID     string `json:"user_id"`
-	CategoryID int    `json:"category_id"`
-	Name       string `json:"name"`
-	Emoji      string `json:"emoji"`
+	UserID     string            `json:"user_id"`
+	CategoryID int               `json:"category_id"`
+	Name       string            `json:"name"`
+	Names      map[string]string `json:"names"`
+	Emoji      string            `json:"emoji"`
 }
 
 func (c *CreateSubcategoryUsecase) CreateSubcategory(ctx context.Context, cmd *CreateSubcategoryCommand) (_ *models.Subcategory, err error) {
@@ -61,6 +63,12 @@ func (c *CreateSubcategoryUsecase) CreateSubcategory(ctx context.Context, cmd *C
 		return nil, err
 	}
 
+	err = subcategory.Localize(toLocalized(cmd.Names))
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to localize subcategory", err)
+		return nil, inerr.NewErrValidation("names", err.Error())
+	}
+
 	err = c.subcategoryRepo.Save(ctx, subcategory)
 	if err != nil {
 		c.logger.ErrorContext(ctx, "failed to save category", err)
@@ -4,6 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
@@ -16,21 +19,33 @@ type DeleteCategoryUsecase struct {
 	contextTimeout time.Duration
 	logger         *logger.Logger
 	usersRepo      entities.UserRepository
-	categoryRepo   entities.CategoryRepository
+	merger         *categoryMerger
 }
 
-func NewDeleteCategoryUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, categoryRepo entities.CategoryRepository) *DeleteCategoryUsecase {
+func NewDeleteCategoryUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	categoryRepo entities.CategoryRepository,
+	subcategoryRepo entities.SubcategoryRepository,
+	transactionsRepo entities.TransactionRepository,
+	statsCache ports.StatsCache,
+) *DeleteCategoryUsecase {
 	return &DeleteCategoryUsecase{
 		contextTimeout: timeout,
 		logger:         logger,
-		categoryRepo:   categoryRepo,
 		usersRepo:      usersRepo,
+		merger:         newCategoryMerger(txManager, categoryRepo, subcategoryRepo, transactionsRepo, statsCache),
 	}
 }
 
 type DeleteCategoryCommand struct {
 	UserID     string `json:"user_id"`
 	CategoryID int    `json:"category_id"`
+	// TargetCategoryID receives the transactions and subcategories of the
+	// deleted category. Defaults to the "Other" category.
+	TargetCategoryID *int `json:"target_category_id"`
 }
 
 func (c *DeleteCategoryUsecase) DeleteCategory(ctx context.Context, cmd *DeleteCategoryCommand) (err error) {
@@ -43,7 +58,7 @@ func (c *DeleteCategoryUsecase) DeleteCategory(ctx context.Context, cmd *DeleteC
 	userID, err := uuid.Parse(cmd.UserID)
 	if err != nil {
 		c.logger.ErrorContext(ctx, "failed to parse user id", err)
-		return err
+		return inerr.NewErrValidation("user_id", "invalid user id")
 	}
 
 	_, err = c.usersRepo.FindByID(ctx, userID)
@@ -52,7 +67,17 @@ func (c *DeleteCategoryUsecase) DeleteCategory(ctx context.Context, cmd *DeleteC
 		return err
 	}
 
-	err = c.categoryRepo.Delete(ctx, userID, cmd.CategoryID)
+	targetID := entities.OtherCategory.Int()
+	if cmd.TargetCategoryID != nil {
+		targetID = *cmd.TargetCategoryID
+	}
+
+	source, target, err := c.merger.resolve(ctx, userID, cmd.CategoryID, targetID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.merger.merge(ctx, userID, source, target)
 	if err != nil {
 		c.logger.ErrorContext(ctx, "failed to delete category", err)
 		return err
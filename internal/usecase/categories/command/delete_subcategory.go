@@ -4,27 +4,36 @@ import (
 	"context"
 	"time"
 
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
 )
 
 type DeleteSubcategoryUsecase struct {
-	contextTimeout  time.Duration
-	logger          *logger.Logger
-	usersRepo       entities.UserRepository
-	subcategoryRepo entities.SubcategoryRepository
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	txManager        postgres.TxManager
+	usersRepo        entities.UserRepository
+	subcategoryRepo  entities.SubcategoryRepository
+	transactionsRepo entities.TransactionRepository
+	statsCache       ports.StatsCache
 }
 
-func NewDeleteSubcategoryUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, subcategoryRepo entities.SubcategoryRepository) *DeleteSubcategoryUsecase {
+func NewDeleteSubcategoryUsecase(timeout time.Duration, logger *logger.Logger, txManager postgres.TxManager, usersRepo entities.UserRepository, subcategoryRepo entities.SubcategoryRepository, transactionsRepo entities.TransactionRepository, statsCache ports.StatsCache) *DeleteSubcategoryUsecase {
 	return &DeleteSubcategoryUsecase{
-		contextTimeout:  timeout,
-		logger:          logger,
-		subcategoryRepo: subcategoryRepo,
-		usersRepo:       usersRepo,
+		contextTimeout:   timeout,
+		logger:           logger,
+		txManager:        txManager,
+		subcategoryRepo:  subcategoryRepo,
+		transactionsRepo: transactionsRepo,
+		usersRepo:        usersRepo,
+		statsCache:       statsCache,
 	}
 }
 
@@ -52,9 +61,39 @@ func (c *DeleteSubcategoryUsecase) DeleteSubcategory(ctx context.Context, cmd *D
 		return err
 	}
 
-	err = c.subcategoryRepo.Delete(ctx, userID, cmd.SubcategoryID)
+	subcategory, err := c.subcategoryRepo.FindByID(ctx, cmd.SubcategoryID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find subcategory", err)
+		return err
+	}
+
+	if !subcategory.IsVisibleTo(userID) {
+		return inerr.NewErrNotFound("subcategory")
+	}
+
+	if subcategory.IsSystem() {
+		return inerr.ErrorPermissionDenied
+	}
+
+	err = c.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// cleared first so the rollups follow the transactions
+		_, err := c.transactionsRepo.ClearSubcategory(ctx, userID, cmd.SubcategoryID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to clear subcategory from transactions", err)
+			return err
+		}
+
+		err = c.subcategoryRepo.Delete(ctx, userID, cmd.SubcategoryID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to delete subcategory", err)
+			return err
+		}
+
+		c.statsCache.Invalidate(ctx, userID)
+
+		return nil
+	})
 	if err != nil {
-		c.logger.ErrorContext(ctx, "failed to delete subcategory", err)
 		return err
 	}
 
@@ -0,0 +1,182 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type MergeCategoriesUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usersRepo      entities.UserRepository
+	merger         *categoryMerger
+}
+
+func NewMergeCategoriesUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	categoryRepo entities.CategoryRepository,
+	subcategoryRepo entities.SubcategoryRepository,
+	transactionsRepo entities.TransactionRepository,
+	statsCache ports.StatsCache,
+) *MergeCategoriesUsecase {
+	return &MergeCategoriesUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usersRepo:      usersRepo,
+		merger:         newCategoryMerger(txManager, categoryRepo, subcategoryRepo, transactionsRepo, statsCache),
+	}
+}
+
+type MergeCategoriesCommand struct {
+	UserID           string `json:"user_id"`
+	SourceCategoryID int    `json:"source_category_id"`
+	TargetCategoryID int    `json:"target_category_id"`
+}
+
+type MergeCategoriesResult struct {
+	SourceCategoryID   int `json:"source_category_id"`
+	TargetCategoryID   int `json:"target_category_id"`
+	TransactionsMoved  int `json:"transactions_moved"`
+	SubcategoriesMoved int `json:"subcategories_moved"`
+}
+
+func (c *MergeCategoriesUsecase) MergeCategories(ctx context.Context, cmd *MergeCategoriesCommand) (_ *MergeCategoriesResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "MergeCategories",
+		attribute.Int("source_category_id", cmd.SourceCategoryID),
+		attribute.Int("target_category_id", cmd.TargetCategoryID),
+	)
+	defer func() { end(err) }()
+
+	userID, err := uuid.Parse(cmd.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to parse user id", err)
+		return nil, inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	_, err = c.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find user", err)
+		return nil, err
+	}
+
+	source, target, err := c.merger.resolve(ctx, userID, cmd.SourceCategoryID, cmd.TargetCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.merger.merge(ctx, userID, source, target)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to merge categories", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// categoryMerger moves everything attached to a user category into another
+// one and removes it, so transaction history keeps its categorization.
+type categoryMerger struct {
+	txManager        postgres.TxManager
+	categoryRepo     entities.CategoryRepository
+	subcategoryRepo  entities.SubcategoryRepository
+	transactionsRepo entities.TransactionRepository
+	statsCache       ports.StatsCache
+}
+
+func newCategoryMerger(
+	txManager postgres.TxManager,
+	categoryRepo entities.CategoryRepository,
+	subcategoryRepo entities.SubcategoryRepository,
+	transactionsRepo entities.TransactionRepository,
+	statsCache ports.StatsCache,
+) *categoryMerger {
+	return &categoryMerger{
+		txManager:        txManager,
+		categoryRepo:     categoryRepo,
+		subcategoryRepo:  subcategoryRepo,
+		transactionsRepo: transactionsRepo,
+		statsCache:       statsCache,
+	}
+}
+
+// resolve loads both sides of a merge. Only the user's own categories can be
+// merged away, the target can be any category the user sees.
+func (m *categoryMerger) resolve(ctx context.Context, userID uuid.UUID, sourceID, targetID int) (*entities.Category, *entities.Category, error) {
+	if sourceID == targetID {
+		return nil, nil, inerr.NewErrValidation("target_category_id", "target must differ from source")
+	}
+
+	source, err := m.categoryRepo.FindByID(ctx, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !source.IsVisibleTo(userID) {
+		return nil, nil, inerr.NewErrNotFound("category")
+	}
+
+	if source.IsSystem() {
+		return nil, nil, inerr.ErrorPermissionDenied
+	}
+
+	target, err := m.categoryRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !target.IsVisibleTo(userID) {
+		return nil, nil, inerr.NewErrNotFound("target category")
+	}
+
+	return source, target, nil
+}
+
+func (m *categoryMerger) merge(ctx context.Context, userID uuid.UUID, source, target *entities.Category) (*MergeCategoriesResult, error) {
+	result := &MergeCategoriesResult{
+		SourceCategoryID: source.ID.Int(),
+		TargetCategoryID: target.ID.Int(),
+	}
+
+	err := m.txManager.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		result.TransactionsMoved, err = m.transactionsRepo.ReassignCategory(ctx, userID, source.ID.Int(), target.ID.Int())
+		if err != nil {
+			return err
+		}
+
+		result.SubcategoriesMoved, err = m.subcategoryRepo.MoveToCategory(ctx, userID, source.ID.Int(), target.ID.Int())
+		if err != nil {
+			return err
+		}
+
+		if err := m.categoryRepo.Delete(ctx, userID, source.ID.Int()); err != nil {
+			return err
+		}
+
+		m.statsCache.Invalidate(ctx, userID)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
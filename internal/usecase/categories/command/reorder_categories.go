@@ -0,0 +1,115 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type ReorderCategoriesUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usersRepo      entities.UserRepository
+	categoryRepo   entities.CategoryRepository
+}
+
+func NewReorderCategoriesUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, categoryRepo entities.CategoryRepository) *ReorderCategoriesUsecase {
+	return &ReorderCategoriesUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usersRepo:      usersRepo,
+		categoryRepo:   categoryRepo,
+	}
+}
+
+type ReorderItem struct {
+	ID       int `json:"id"`
+	Position int `json:"position"`
+}
+
+type ReorderCategoriesCommand struct {
+	UserID string        `json:"user_id"`
+	Items  []ReorderItem `json:"items"`
+}
+
+func (c *ReorderCategoriesUsecase) ReorderCategories(ctx context.Context, cmd *ReorderCategoriesCommand) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "ReorderCategories",
+		attribute.Int("items", len(cmd.Items)),
+	)
+	defer func() { end(err) }()
+
+	userID, err := uuid.Parse(cmd.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to parse user id", err)
+		return inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	_, err = c.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find user", err)
+		return err
+	}
+
+	categories, err := c.categoryRepo.FindAll(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to get categories", err)
+		return err
+	}
+
+	known := make(map[int]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID.Int()] = true
+	}
+
+	positions, err := toPositions(cmd.Items, known)
+	if err != nil {
+		return err
+	}
+
+	err = c.categoryRepo.SetPositions(ctx, userID, positions)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to set category positions", err)
+		return err
+	}
+
+	return nil
+}
+
+// toPositions validates a reorder request against the ids the user can see.
+func toPositions(items []ReorderItem, known map[int]bool) ([]entities.CategoryPosition, error) {
+	if len(items) == 0 {
+		return nil, inerr.NewErrValidation("items", "items are empty")
+	}
+
+	seen := make(map[int]bool, len(items))
+	positions := make([]entities.CategoryPosition, 0, len(items))
+	for _, item := range items {
+		if !known[item.ID] {
+			return nil, inerr.NewErrValidation("items", "unknown id in items")
+		}
+		if seen[item.ID] {
+			return nil, inerr.NewErrValidation("items", "duplicate id in items")
+		}
+		if item.Position < 0 {
+			return nil, inerr.NewErrValidation("items", "position must not be negative")
+		}
+		seen[item.ID] = true
+
+		positions = append(positions, entities.CategoryPosition{
+			ID:       item.ID,
+			Position: item.Position,
+		})
+	}
+
+	return positions, nil
+}
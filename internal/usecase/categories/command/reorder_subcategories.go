@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type ReorderSubcategoriesUsecase struct {
+	contextTimeout  time.Duration
+	logger          *logger.Logger
+	usersRepo       entities.UserRepository
+	subcategoryRepo entities.SubcategoryRepository
+}
+
+func NewReorderSubcategoriesUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, subcategoryRepo entities.SubcategoryRepository) *ReorderSubcategoriesUsecase {
+	return &ReorderSubcategoriesUsecase{
+		contextTimeout:  timeout,
+		logger:          logger,
+		usersRepo:       usersRepo,
+		subcategoryRepo: subcategoryRepo,
+	}
+}
+
+type ReorderSubcategoriesCommand struct {
+	UserID string        `json:"user_id"`
+	Items  []ReorderItem `json:"items"`
+}
+
+func (c *ReorderSubcategoriesUsecase) ReorderSubcategories(ctx context.Context, cmd *ReorderSubcategoriesCommand) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "ReorderSubcategories",
+		attribute.Int("items", len(cmd.Items)),
+	)
+	defer func() { end(err) }()
+
+	userID, err := uuid.Parse(cmd.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to parse user id", err)
+		return inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	_, err = c.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find user", err)
+		return err
+	}
+
+	subcategories, err := c.subcategoryRepo.FindAll(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to get subcategories", err)
+		return err
+	}
+
+	known := make(map[int]bool, len(subcategories))
+	for _, subcategory := range subcategories {
+		known[subcategory.ID] = true
+	}
+
+	positions, err := toPositions(cmd.Items, known)
+	if err != nil {
+		return err
+	}
+
+	err = c.subcategoryRepo.SetPositions(ctx, userID, positions)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to set subcategory positions", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type SetCategoryHiddenUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usersRepo      entities.UserRepository
+	categoryRepo   entities.CategoryRepository
+}
+
+func NewSetCategoryHiddenUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, categoryRepo entities.CategoryRepository) *SetCategoryHiddenUsecase {
+	return &SetCategoryHiddenUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usersRepo:      usersRepo,
+		categoryRepo:   categoryRepo,
+	}
+}
+
+type SetCategoryHiddenCommand struct {
+	UserID     string `json:"user_id"`
+	CategoryID int    `json:"category_id"`
+	Hidden     bool   `json:"hidden"`
+}
+
+func (c *SetCategoryHiddenUsecase) SetCategoryHidden(ctx context.Context, cmd *SetCategoryHiddenCommand) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "SetCategoryHidden",
+		attribute.Int("category_id", cmd.CategoryID),
+		attribute.Bool("hidden", cmd.Hidden),
+	)
+	defer func() { end(err) }()
+
+	userID, err := uuid.Parse(cmd.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to parse user id", err)
+		return inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	_, err = c.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find user", err)
+		return err
+	}
+
+	category, err := c.categoryRepo.FindByID(ctx, cmd.CategoryID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find category", err)
+		return err
+	}
+
+	if !category.IsVisibleTo(userID) {
+		return inerr.NewErrNotFound("category")
+	}
+
+	// "Other" is the classifier's fallback, it has to stay available.
+	if cmd.Hidden && category.ID == entities.OtherCategory {
+		return inerr.NewErrValidation("category_id", "fallback category cannot be hidden")
+	}
+
+	err = c.categoryRepo.SetHidden(ctx, userID, category.ID.Int(), cmd.Hidden)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to set category visibility", err)
+		return err
+	}
+
+	return nil
+}
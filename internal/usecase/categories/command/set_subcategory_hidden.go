@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type SetSubcategoryHiddenUsecase struct {
+	contextTimeout  time.Duration
+	logger          *logger.Logger
+	usersRepo       entities.UserRepository
+	subcategoryRepo entities.SubcategoryRepository
+}
+
+func NewSetSubcategoryHiddenUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, subcategoryRepo entities.SubcategoryRepository) *SetSubcategoryHiddenUsecase {
+	return &SetSubcategoryHiddenUsecase{
+		contextTimeout:  timeout,
+		logger:          logger,
+		usersRepo:       usersRepo,
+		subcategoryRepo: subcategoryRepo,
+	}
+}
+
+type SetSubcategoryHiddenCommand struct {
+	UserID        string `json:"user_id"`
+	SubcategoryID int    `json:"subcategory_id"`
+	Hidden        bool   `json:"hidden"`
+}
+
+func (c *SetSubcategoryHiddenUsecase) SetSubcategoryHidden(ctx context.Context, cmd *SetSubcategoryHiddenCommand) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "SetSubcategoryHidden",
+		attribute.Int("subcategory_id", cmd.SubcategoryID),
+		attribute.Bool("hidden", cmd.Hidden),
+	)
+	defer func() { end(err) }()
+
+	userID, err := uuid.Parse(cmd.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to parse user id", err)
+		return inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	_, err = c.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find user", err)
+		return err
+	}
+
+	subcategory, err := c.subcategoryRepo.FindByID(ctx, cmd.SubcategoryID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find subcategory", err)
+		return err
+	}
+
+	if !subcategory.IsVisibleTo(userID) {
+		return inerr.NewErrNotFound("subcategory")
+	}
+
+	err = c.subcategoryRepo.SetHidden(ctx, userID, subcategory.ID, cmd.Hidden)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to set subcategory visibility", err)
+		return err
+	}
+
+	return nil
+}
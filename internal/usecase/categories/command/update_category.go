@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"github.com/shogo82148/pointer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type UpdateCategoryUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usersRepo      entities.UserRepository
+	categoryRepo   entities.CategoryRepository
+	statsCache     ports.StatsCache
+}
+
+func NewUpdateCategoryUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, categoryRepo entities.CategoryRepository, statsCache ports.StatsCache) *UpdateCategoryUsecase {
+	return &UpdateCategoryUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usersRepo:      usersRepo,
+		categoryRepo:   categoryRepo,
+		statsCache:     statsCache,
+	}
+}
+
+type UpdateCategoryCommand struct {
+	UserID     string            `json:"user_id"`
+	CategoryID int               `json:"category_id"`
+	Name       string            `json:"name"`
+	Names      map[string]string `json:"names"`
+	Emoji      string            `json:"emoji"`
+}
+
+func (c *UpdateCategoryUsecase) UpdateCategory(ctx context.Context, cmd *UpdateCategoryCommand) (_ *models.Category, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "UpdateCategory",
+		attribute.Int("category_id", cmd.CategoryID),
+	)
+	defer func() { end(err) }()
+
+	userID, err := uuid.Parse(cmd.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to parse user id", err)
+		return nil, inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	user, err := c.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find user", err)
+		return nil, err
+	}
+
+	category, err := c.categoryRepo.FindByID(ctx, cmd.CategoryID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find category", err)
+		return nil, err
+	}
+
+	if !category.IsVisibleTo(userID) {
+		return nil, inerr.NewErrNotFound("category")
+	}
+
+	if category.IsSystem() {
+		return nil, inerr.ErrorPermissionDenied
+	}
+
+	err = category.Update(cmd.Name, toLocalized(cmd.Names), cmd.Emoji)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to update category", err)
+		return nil, inerr.NewErrValidation("category", err.Error())
+	}
+
+	err = c.categoryRepo.Save(ctx, category)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to save category", err)
+		return nil, err
+	}
+
+	c.statsCache.Invalidate(ctx, user.ID)
+
+	return &models.Category{
+		ID:        category.ID.Int(),
+		UserID:    pointer.StringOrNil(user.ID.String()),
+		Name:      category.GetName(user.LanguageCode),
+		Emoji:     category.Emoji,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}, nil
+}
+
+func toLocalized(names map[string]string) map[entities.Language]string {
+	localized := make(map[entities.Language]string, len(names))
+	for code, name := range names {
+		localized[entities.Language(code)] = name
+	}
+	return localized
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/delivery/api/models"
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"github.com/shogo82148/pointer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type UpdateSubcategoryUsecase struct {
+	contextTimeout  time.Duration
+	logger          *logger.Logger
+	usersRepo       entities.UserRepository
+	subcategoryRepo entities.SubcategoryRepository
+	statsCache      ports.StatsCache
+}
+
+func NewUpdateSubcategoryUsecase(timeout time.Duration, logger *logger.Logger, usersRepo entities.UserRepository, subcategoryRepo entities.SubcategoryRepository, statsCache ports.StatsCache) *UpdateSubcategoryUsecase {
+	return &UpdateSubcategoryUsecase{
+		contextTimeout:  timeout,
+		logger:          logger,
+		usersRepo:       usersRepo,
+		subcategoryRepo: subcategoryRepo,
+		statsCache:      statsCache,
+	}
+}
+
+type UpdateSubcategoryCommand struct {
+	UserID        string            `json:"user_id"`
+	SubcategoryID int               `json:"subcategory_id"`
+	Name          string            `json:"name"`
+	Names         map[string]string `json:"names"`
+	Emoji         string            `json:"emoji"`
+}
+
+func (c *UpdateSubcategoryUsecase) UpdateSubcategory(ctx context.Context, cmd *UpdateSubcategoryCommand) (_ *models.Subcategory, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "UpdateSubcategory",
+		attribute.Int("subcategory_id", cmd.SubcategoryID),
+	)
+	defer func() { end(err) }()
+
+	userID, err := uuid.Parse(cmd.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to parse user id", err)
+		return nil, inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	user, err := c.usersRepo.FindByID(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find user", err)
+		return nil, err
+	}
+
+	subcategory, err := c.subcategoryRepo.FindByID(ctx, cmd.SubcategoryID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find subcategory", err)
+		return nil, err
+	}
+
+	if !subcategory.IsVisibleTo(userID) {
+		return nil, inerr.NewErrNotFound("subcategory")
+	}
+
+	if subcategory.IsSystem() {
+		return nil, inerr.ErrorPermissionDenied
+	}
+
+	err = subcategory.Update(cmd.Name, toLocalized(cmd.Names), cmd.Emoji)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to update subcategory", err)
+		return nil, inerr.NewErrValidation("subcategory", err.Error())
+	}
+
+	err = c.subcategoryRepo.Save(ctx, subcategory)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to save subcategory", err)
+		return nil, err
+	}
+
+	c.statsCache.Invalidate(ctx, user.ID)
+
+	return &models.Subcategory{
+		ID:         subcategory.ID,
+		CategoryID: subcategory.CategoryID,
+		UserID:     pointer.StringOrNil(user.ID.String()),
+		Name:       subcategory.GetName(user.LanguageCode),
+		Emoji:      subcategory.Emoji,
+		CreatedAt:  subcategory.CreatedAt,
+		UpdatedAt:  subcategory.UpdatedAt,
+	}, nil
+}
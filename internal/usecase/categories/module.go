@@ -6,6 +6,8 @@ import (
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/usecase/categories/command"
 	"github.com/AsaHero/e-wallet/internal/usecase/categories/query"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 )
 
@@ -14,27 +16,52 @@ type Command struct {
 	*command.CreateSubcategoryUsecase
 	*command.DeleteCategoryUsecase
 	*command.DeleteSubcategoryUsecase
+	*command.UpdateCategoryUsecase
+	*command.UpdateSubcategoryUsecase
+	*command.ReorderCategoriesUsecase
+	*command.ReorderSubcategoriesUsecase
+	*command.SetCategoryHiddenUsecase
+	*command.SetSubcategoryHiddenUsecase
+	*command.MergeCategoriesUsecase
 }
 type Query struct {
 	*query.GetAllCategoriesUsecase
 	*query.GetAllSubcategoriesUsecase
+	*query.GetCategoryMergePreviewUsecase
 }
 type Module struct {
 	Command Command
 	Query   Query
 }
 
-func NewModule(timeout time.Duration, logger *logger.Logger, categoriesRepo entities.CategoryRepository, subcategoriesRepo entities.SubcategoryRepository, usersRepo entities.UserRepository) *Module {
+func NewModule(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	usersRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+	statsCache ports.StatsCache,
+) *Module {
 	m := &Module{
 		Command: Command{
-			CreateCategoryUsecase:    command.NewCreateCategoryUsecase(timeout, logger, usersRepo, categoriesRepo),
-			CreateSubcategoryUsecase: command.NewCreateSubcategoryUsecase(timeout, logger, usersRepo, subcategoriesRepo),
-			DeleteCategoryUsecase:    command.NewDeleteCategoryUsecase(timeout, logger, usersRepo, categoriesRepo),
-			DeleteSubcategoryUsecase: command.NewDeleteSubcategoryUsecase(timeout, logger, usersRepo, subcategoriesRepo),
+			CreateCategoryUsecase:       command.NewCreateCategoryUsecase(timeout, logger, usersRepo, categoriesRepo),
+			CreateSubcategoryUsecase:    command.NewCreateSubcategoryUsecase(timeout, logger, usersRepo, subcategoriesRepo),
+			DeleteCategoryUsecase:       command.NewDeleteCategoryUsecase(timeout, logger, txManager, usersRepo, categoriesRepo, subcategoriesRepo, transactionsRepo, statsCache),
+			DeleteSubcategoryUsecase:    command.NewDeleteSubcategoryUsecase(timeout, logger, txManager, usersRepo, subcategoriesRepo, transactionsRepo, statsCache),
+			UpdateCategoryUsecase:       command.NewUpdateCategoryUsecase(timeout, logger, usersRepo, categoriesRepo, statsCache),
+			UpdateSubcategoryUsecase:    command.NewUpdateSubcategoryUsecase(timeout, logger, usersRepo, subcategoriesRepo, statsCache),
+			ReorderCategoriesUsecase:    command.NewReorderCategoriesUsecase(timeout, logger, usersRepo, categoriesRepo),
+			ReorderSubcategoriesUsecase: command.NewReorderSubcategoriesUsecase(timeout, logger, usersRepo, subcategoriesRepo),
+			SetCategoryHiddenUsecase:    command.NewSetCategoryHiddenUsecase(timeout, logger, usersRepo, categoriesRepo),
+			SetSubcategoryHiddenUsecase: command.NewSetSubcategoryHiddenUsecase(timeout, logger, usersRepo, subcategoriesRepo),
+			MergeCategoriesUsecase:      command.NewMergeCategoriesUsecase(timeout, logger, txManager, usersRepo, categoriesRepo, subcategoriesRepo, transactionsRepo, statsCache),
 		},
 		Query: Query{
-			GetAllCategoriesUsecase:    query.NewGetAllCategoriesUsecase(timeout, logger, usersRepo, categoriesRepo),
-			GetAllSubcategoriesUsecase: query.NewGetAllSubcategoriesUsecase(timeout, logger, usersRepo, subcategoriesRepo),
+			GetAllCategoriesUsecase:        query.NewGetAllCategoriesUsecase(timeout, logger, usersRepo, categoriesRepo),
+			GetAllSubcategoriesUsecase:     query.NewGetAllSubcategoriesUsecase(timeout, logger, usersRepo, subcategoriesRepo),
+			GetCategoryMergePreviewUsecase: query.NewGetCategoryMergePreviewUsecase(timeout, logger, usersRepo, categoriesRepo, subcategoriesRepo, transactionsRepo),
 		},
 	}
 
@@ -40,6 +40,7 @@ type Category struct {
 	Position int    `json:"position"`
 	Name     string `json:"name"`
 	Emoji    string `json:"emoji"`
+	Hidden   bool   `json:"hidden"`
 }
 
 func (u *GetAllCategoriesUsecase) GetAllCategories(ctx context.Context, userID string) (_ []Category, err error) {
@@ -73,6 +74,7 @@ func (u *GetAllCategoriesUsecase) GetAllCategories(ctx context.Context, userID s
 			Position: category.Position,
 			Name:     category.GetName(user.LanguageCode),
 			Emoji:    category.Emoji,
+			Hidden:   category.Hidden,
 		})
 	}
 
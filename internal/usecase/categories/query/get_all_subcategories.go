@@ -38,6 +38,7 @@ type Subcategory struct {
 	Position   int    `json:"position"`
 	Name       string `json:"name"`
 	Emoji      string `json:"emoji"`
+	Hidden     bool   `json:"hidden"`
 }
 
 func (u *GetAllSubcategoriesUsecase) GetAllSubcategories(ctx context.Context, userID string) (_ []Subcategory, err error) {
@@ -70,6 +71,7 @@ func (u *GetAllSubcategoriesUsecase) GetAllSubcategories(ctx context.Context, us
 			Position:   subcategory.Position,
 			Name:       subcategory.GetName(user.LanguageCode),
 			Emoji:      subcategory.Emoji,
+			Hidden:     subcategory.Hidden,
 		})
 	}
 
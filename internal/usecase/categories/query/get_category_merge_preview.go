@@ -0,0 +1,128 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type GetCategoryMergePreviewUsecase struct {
+	contextTimeout    time.Duration
+	logger            *logger.Logger
+	usersRepo         entities.UserRepository
+	categoriesRepo    entities.CategoryRepository
+	subcategoriesRepo entities.SubcategoryRepository
+	transactionsRepo  entities.TransactionRepository
+}
+
+func NewGetCategoryMergePreviewUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	transactionsRepo entities.TransactionRepository,
+) *GetCategoryMergePreviewUsecase {
+	return &GetCategoryMergePreviewUsecase{
+		contextTimeout:    timeout,
+		logger:            logger,
+		usersRepo:         usersRepo,
+		categoriesRepo:    categoriesRepo,
+		subcategoriesRepo: subcategoriesRepo,
+		transactionsRepo:  transactionsRepo,
+	}
+}
+
+// CategoryMergePreview tells how much would be moved by merging or deleting
+// the source category, without changing anything.
+type CategoryMergePreview struct {
+	SourceCategoryID int    `json:"source_category_id"`
+	SourceName       string `json:"source_name"`
+	TargetCategoryID int    `json:"target_category_id"`
+	TargetName       string `json:"target_name"`
+	Transactions     int    `json:"transactions"`
+	Subcategories    int    `json:"subcategories"`
+}
+
+func (u *GetCategoryMergePreviewUsecase) GetCategoryMergePreview(ctx context.Context, userID string, sourceID, targetID int) (_ *CategoryMergePreview, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("categories"), "GetCategoryMergePreview",
+		attribute.Int("source_category_id", sourceID),
+		attribute.Int("target_category_id", targetID),
+	)
+	defer func() { end(err) }()
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, inerr.NewErrValidation("user_id", "invalid user id")
+	}
+
+	if sourceID == targetID {
+		return nil, inerr.NewErrValidation("target_category_id", "target must differ from source")
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, uid)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	source, err := u.categoriesRepo.FindByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !source.IsVisibleTo(uid) {
+		return nil, inerr.NewErrNotFound("category")
+	}
+
+	if source.IsSystem() {
+		return nil, inerr.ErrorPermissionDenied
+	}
+
+	target, err := u.categoriesRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !target.IsVisibleTo(uid) {
+		return nil, inerr.NewErrNotFound("target category")
+	}
+
+	transactions, err := u.transactionsRepo.CountByCategory(ctx, uid, sourceID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to count transactions", err)
+		return nil, err
+	}
+
+	subcategories, err := u.subcategoriesRepo.FindByCategoryID(ctx, sourceID, uid)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get subcategories", err)
+		return nil, err
+	}
+
+	var ownSubcategories int
+	for _, subcategory := range subcategories {
+		if !subcategory.IsSystem() {
+			ownSubcategories++
+		}
+	}
+
+	return &CategoryMergePreview{
+		SourceCategoryID: source.ID.Int(),
+		SourceName:       source.GetName(user.LanguageCode),
+		TargetCategoryID: target.ID.Int(),
+		TargetName:       target.GetName(user.LanguageCode),
+		Transactions:     transactions,
+		Subcategories:    ownSubcategories,
+	}, nil
+}
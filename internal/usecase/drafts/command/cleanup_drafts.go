@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type CleanupDraftsUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	draftsRepo     entities.TransactionDraftRepository
+}
+
+func NewCleanupDraftsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	draftsRepo entities.TransactionDraftRepository,
+) *CleanupDraftsUsecase {
+	return &CleanupDraftsUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		draftsRepo:     draftsRepo,
+	}
+}
+
+// CleanupDrafts deletes drafts past their expiry. Confirmed ones go too, the
+// transaction they created is what is kept.
+func (u *CleanupDraftsUsecase) CleanupDrafts(ctx context.Context) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("drafts"), "CleanupDrafts")
+	defer func() { end(err) }()
+
+	deleted, err := u.draftsRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to delete expired drafts", err)
+		return err
+	}
+
+	otlp.Annotate(ctx, attribute.Int("drafts.deleted", deleted))
+
+	return nil
+}
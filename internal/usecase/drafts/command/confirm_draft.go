@@ -0,0 +1,198 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	transactions "github.com/AsaHero/e-wallet/internal/usecase/transactions/command"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type ConfirmDraftUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	txManager      postgres.TxManager
+	usersRepo      entities.UserRepository
+	accountsRepo   entities.AccountRepository
+	draftsRepo     entities.TransactionDraftRepository
+	creator        *transactions.TransactionCreator
+}
+
+func NewConfirmDraftUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	draftsRepo entities.TransactionDraftRepository,
+	creator *transactions.TransactionCreator,
+) *ConfirmDraftUsecase {
+	return &ConfirmDraftUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		txManager:      txManager,
+		usersRepo:      usersRepo,
+		accountsRepo:   accountsRepo,
+		draftsRepo:     draftsRepo,
+		creator:        creator,
+	}
+}
+
+// ConfirmDraft turns the draft into a real transaction and applies it to the
+// balance. Drafts without an account go to the user's default one.
+func (u *ConfirmDraftUsecase) ConfirmDraft(ctx context.Context, userID, draftID string) (_ *transactions.CreateTransactionResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("drafts"), "ConfirmDraft",
+		attribute.String("user_id", userID),
+		attribute.String("draft_id", draftID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID  uuid.UUID
+		draftID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.draftID, err = uuid.Parse(draftID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse draft id", err)
+			return nil, inerr.NewErrValidation("id", "invalid uuid type")
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	var transaction *entities.Transaction
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		draft, err := u.draftsRepo.FindByIDForUpdate(ctx, input.draftID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get draft", err)
+			return err
+		}
+
+		if draft.UserID != user.ID {
+			return inerr.ErrorPermissionDenied
+		}
+
+		if err := draft.Open(time.Now()); err != nil {
+			return inerr.NewErrConflict("draft")
+		}
+
+		if draft.Amount <= 0 {
+			return inerr.NewErrValidation("amount", "must be greater than 0")
+		}
+
+		accountID, err := u.accountID(ctx, user, draft)
+		if err != nil {
+			return err
+		}
+
+		transaction, err = entities.NewTransaction(user.ID, accountID, draft.Type, draft.Note)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to create transaction", err)
+			return err
+		}
+
+		// checked again, the category or subcategory may have been deleted
+		// since the draft was written
+		err = u.creator.Categorise(ctx, transaction, draft.CategoryID, draft.SubcategoryID)
+		if err != nil {
+			return err
+		}
+
+		transaction.SetMerchant(draft.Merchant)
+
+		err = transaction.SetAmountMinor(draft.Amount, draft.CurrencyCode)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to set amount minor", err)
+			return err
+		}
+
+		if draft.OriginalAmount > 0 {
+			err = transaction.SetOriginalAmountMinor(draft.OriginalAmount, draft.OriginalCurrencyCode)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to set original amount minor", err)
+				return err
+			}
+
+			err = transaction.SetFxRate(draft.FxRate)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to set fx rate", err)
+				return err
+			}
+		}
+
+		if !draft.PerformedAt.IsZero() {
+			transaction.Performed(draft.PerformedAt)
+		} else {
+			transaction.Performed(time.Now())
+		}
+
+		err = u.creator.Save(ctx, transaction)
+		if err != nil {
+			return err
+		}
+
+		if err := draft.Confirm(transaction.ID); err != nil {
+			return inerr.NewErrConflict("draft")
+		}
+
+		err = u.draftsRepo.Save(ctx, draft)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to save draft", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transactions.CreateTransactionResult{
+		Transaction: transaction,
+		Duplicates:  u.creator.Created(ctx, transaction),
+	}, nil
+}
+
+// accountID is the account the draft was parsed with, or the user's default
+// one when the message did not name any.
+func (u *ConfirmDraftUsecase) accountID(ctx context.Context, user *entities.User, draft *entities.TransactionDraft) (uuid.UUID, error) {
+	if draft.AccountID != nil {
+		return *draft.AccountID, nil
+	}
+
+	accounts, err := u.accountsRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get accounts", err)
+		return uuid.Nil, err
+	}
+
+	for _, account := range accounts {
+		if account.IsDefault {
+			return account.ID, nil
+		}
+	}
+
+	return uuid.Nil, inerr.NewErrValidation("account_id", "draft has no account and there is no default one")
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type RejectDraftUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	txManager      postgres.TxManager
+	draftsRepo     entities.TransactionDraftRepository
+}
+
+func NewRejectDraftUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	draftsRepo entities.TransactionDraftRepository,
+) *RejectDraftUsecase {
+	return &RejectDraftUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		txManager:      txManager,
+		draftsRepo:     draftsRepo,
+	}
+}
+
+// RejectDraft closes the draft without creating a transaction.
+func (u *RejectDraftUsecase) RejectDraft(ctx context.Context, userID, draftID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("drafts"), "RejectDraft",
+		attribute.String("user_id", userID),
+		attribute.String("draft_id", draftID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID  uuid.UUID
+		draftID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.draftID, err = uuid.Parse(draftID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse draft id", err)
+			return inerr.NewErrValidation("id", "invalid uuid type")
+		}
+	}
+
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		draft, err := u.draftsRepo.FindByIDForUpdate(ctx, input.draftID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get draft", err)
+			return err
+		}
+
+		if draft.UserID != input.userID {
+			return inerr.ErrorPermissionDenied
+		}
+
+		if err := draft.Reject(); err != nil {
+			return inerr.NewErrConflict("draft")
+		}
+
+		if err := u.draftsRepo.Save(ctx, draft); err != nil {
+			u.logger.ErrorContext(ctx, "failed to save draft", err)
+			return err
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,240 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type UpdateDraftUsecase struct {
+	contextTimeout    time.Duration
+	logger            *logger.Logger
+	txManager         postgres.TxManager
+	usersRepo         entities.UserRepository
+	accountsRepo      entities.AccountRepository
+	categoriesRepo    entities.CategoryRepository
+	subcategoriesRepo entities.SubcategoryRepository
+	draftsRepo        entities.TransactionDraftRepository
+}
+
+func NewUpdateDraftUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	draftsRepo entities.TransactionDraftRepository,
+) *UpdateDraftUsecase {
+	return &UpdateDraftUsecase{
+		contextTimeout:    timeout,
+		logger:            logger,
+		txManager:         txManager,
+		usersRepo:         usersRepo,
+		accountsRepo:      accountsRepo,
+		categoriesRepo:    categoriesRepo,
+		subcategoriesRepo: subcategoriesRepo,
+		draftsRepo:        draftsRepo,
+	}
+}
+
+// UpdateDraftCommand only changes the fields that are set. Amount is in the
+// user's currency and replaces any converted one.
+type UpdateDraftCommand struct {
+	UserID        string
+	DraftID       string
+	AccountID     *string
+	CategoryID    *int
+	SubcategoryID *int
+	Type          *string
+	Amount        *float64
+	Note          *string
+	Merchant      *string
+	PerformedAt   *time.Time
+}
+
+func (u *UpdateDraftUsecase) UpdateDraft(ctx context.Context, cmd *UpdateDraftCommand) (_ *entities.TransactionDraft, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("drafts"), "UpdateDraft",
+		attribute.String("user_id", cmd.UserID),
+		attribute.String("draft_id", cmd.DraftID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID      uuid.UUID
+		draftID     uuid.UUID
+		accountID   *uuid.UUID
+		category    *entities.Category
+		subcategory *entities.Subcategory
+		trnType     *entities.TrnType
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.draftID, err = uuid.Parse(cmd.DraftID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse draft id", err)
+			return nil, inerr.NewErrValidation("id", "invalid uuid type")
+		}
+
+		if cmd.AccountID != nil {
+			accountID, err := uuid.Parse(*cmd.AccountID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to parse account id", err)
+				return nil, inerr.NewErrValidation("account_id", "invalid uuid type")
+			}
+
+			account, err := u.accountsRepo.GetByID(ctx, accountID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to get account", err)
+				return nil, err
+			}
+
+			if account.UserID != input.userID {
+				return nil, inerr.NewErrValidation("account_id", "account not found")
+			}
+
+			input.accountID = &account.ID
+		}
+
+		if cmd.CategoryID != nil {
+			category, err := u.categoriesRepo.FindByID(ctx, *cmd.CategoryID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to get category", err)
+				return nil, err
+			}
+
+			if !category.IsVisibleTo(input.userID) {
+				return nil, inerr.NewErrValidation("category_id", "category not found")
+			}
+
+			input.category = category
+		}
+
+		if cmd.SubcategoryID != nil {
+			subcategory, err := u.subcategoriesRepo.FindByID(ctx, *cmd.SubcategoryID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to get subcategory", err)
+				return nil, err
+			}
+
+			if !subcategory.IsVisibleTo(input.userID) {
+				return nil, inerr.NewErrValidation("subcategory_id", "subcategory not found")
+			}
+
+			input.subcategory = subcategory
+		}
+
+		if cmd.Type != nil {
+			trnType := entities.TrnType(*cmd.Type)
+			if trnType != entities.Deposit && trnType != entities.Withdrawal {
+				return nil, inerr.NewErrValidation("type", "must be deposit or withdrawal")
+			}
+
+			input.trnType = &trnType
+		}
+
+		if cmd.Amount != nil && *cmd.Amount <= 0 {
+			return nil, inerr.NewErrValidation("amount", "must be greater than 0")
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	var draft *entities.TransactionDraft
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		draft, err = u.draftsRepo.FindByIDForUpdate(ctx, input.draftID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get draft", err)
+			return err
+		}
+
+		if draft.UserID != input.userID {
+			return inerr.ErrorPermissionDenied
+		}
+
+		if err := draft.Open(time.Now()); err != nil {
+			return inerr.NewErrConflict("draft")
+		}
+
+		if input.accountID != nil {
+			draft.AccountID = input.accountID
+		}
+
+		if input.category != nil {
+			categoryID := input.category.ID.Int()
+			// a subcategory of the previous category no longer fits
+			if draft.CategoryID == nil || *draft.CategoryID != categoryID {
+				draft.SubcategoryID = nil
+			}
+			draft.CategoryID = &categoryID
+		}
+
+		if input.subcategory != nil {
+			if draft.CategoryID == nil || *draft.CategoryID != input.subcategory.CategoryID {
+				return inerr.NewErrValidation("subcategory_id", "subcategory does not belong to the category")
+			}
+			draft.SubcategoryID = &input.subcategory.ID
+		}
+
+		if input.trnType != nil {
+			if err := draft.SetType(*input.trnType); err != nil {
+				return inerr.NewErrValidation("type", err.Error())
+			}
+		}
+
+		if cmd.Amount != nil {
+			if err := draft.SetAmountMajor(*cmd.Amount, user.CurrencyCode); err != nil {
+				return inerr.NewErrValidation("amount", err.Error())
+			}
+			draft.ClearOriginalAmount()
+		}
+
+		if cmd.Note != nil {
+			draft.Note = *cmd.Note
+		}
+
+		if cmd.Merchant != nil {
+			draft.SetMerchant(*cmd.Merchant)
+		}
+
+		if cmd.PerformedAt != nil {
+			draft.PerformedAt = *cmd.PerformedAt
+		}
+
+		draft.Touch()
+
+		if err := u.draftsRepo.Save(ctx, draft); err != nil {
+			u.logger.ErrorContext(ctx, "failed to save draft", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
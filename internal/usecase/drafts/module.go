@@ -0,0 +1,64 @@
+package drafts
+
+import (
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/drafts/command"
+	"github.com/AsaHero/e-wallet/internal/usecase/drafts/query"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	transactions "github.com/AsaHero/e-wallet/internal/usecase/transactions/command"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/hibiken/asynq"
+)
+
+type Commands struct {
+	*command.UpdateDraftUsecase
+	*command.ConfirmDraftUsecase
+	*command.RejectDraftUsecase
+	*command.CleanupDraftsUsecase
+}
+
+type Query struct {
+	*query.GetDraftsUsecase
+}
+
+type Module struct {
+	Command Commands
+	Query   Query
+}
+
+func NewModule(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	draftsRepo entities.TransactionDraftRepository,
+	taskQueue *asynq.Client,
+	statsCache ports.StatsCache,
+) *Module {
+	return &Module{
+		Command: Commands{
+			UpdateDraftUsecase: command.NewUpdateDraftUsecase(timeout, logger, txManager, usersRepo, accountsRepo, categoriesRepo, subcategoriesRepo, draftsRepo),
+			ConfirmDraftUsecase: command.NewConfirmDraftUsecase(
+				timeout,
+				logger,
+				txManager,
+				usersRepo,
+				accountsRepo,
+				draftsRepo,
+				transactions.NewTransactionCreator(logger, accountsRepo, transactionsRepo, categoriesRepo, subcategoriesRepo, taskQueue, statsCache),
+			),
+			RejectDraftUsecase:   command.NewRejectDraftUsecase(timeout, logger, txManager, draftsRepo),
+			CleanupDraftsUsecase: command.NewCleanupDraftsUsecase(timeout, logger, draftsRepo),
+		},
+		Query: Query{
+			GetDraftsUsecase: query.NewGetDraftsUsecase(timeout, logger, draftsRepo),
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type GetDraftsUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	draftsRepo     entities.TransactionDraftRepository
+}
+
+func NewGetDraftsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	draftsRepo entities.TransactionDraftRepository,
+) *GetDraftsUsecase {
+	return &GetDraftsUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		draftsRepo:     draftsRepo,
+	}
+}
+
+// GetDrafts lists the drafts the user can still confirm, oldest first.
+func (u *GetDraftsUsecase) GetDrafts(ctx context.Context, userID string) (_ []*entities.TransactionDraft, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("drafts"), "GetDrafts",
+		attribute.String("user_id", userID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+	}
+
+	drafts, err := u.draftsRepo.GetPending(ctx, input.userID, time.Now())
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get drafts", err)
+		return nil, err
+	}
+
+	return drafts, nil
+}
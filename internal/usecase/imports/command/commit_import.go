@@ -0,0 +1,422 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// importBatchSize is how many transactions go into one insert.
+	importBatchSize = 500
+	// categorizerHistoryMonths is how far back merchants are looked up to
+	// reuse the user's own categories.
+	categorizerHistoryMonths = 6
+)
+
+type CommitImportUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	txManager          postgres.TxManager
+	usersRepo          entities.UserRepository
+	accountsRepo       entities.AccountRepository
+	transactionsRepo   entities.TransactionRepository
+	categoriesRepo     entities.CategoryRepository
+	subcategoriesRepo  entities.SubcategoryRepository
+	importSessionsRepo entities.ImportSessionRepository
+	fxRatesProvider    ports.FXRatesProvider
+	statsCache         ports.StatsCache
+}
+
+func NewCommitImportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	importSessionsRepo entities.ImportSessionRepository,
+	fxRatesProvider ports.FXRatesProvider,
+	statsCache ports.StatsCache,
+) *CommitImportUsecase {
+	return &CommitImportUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		txManager:          txManager,
+		usersRepo:          usersRepo,
+		accountsRepo:       accountsRepo,
+		transactionsRepo:   transactionsRepo,
+		categoriesRepo:     categoriesRepo,
+		subcategoriesRepo:  subcategoriesRepo,
+		importSessionsRepo: importSessionsRepo,
+		fxRatesProvider:    fxRatesProvider,
+		statsCache:         statsCache,
+	}
+}
+
+type CommitImportCommand struct {
+	UserID    string
+	SessionID string
+}
+
+// CommitImportResult lists the rows that could not be imported next to the
+// committed session, and the imported rows that look like transactions the
+// user already recorded by hand.
+type CommitImportResult struct {
+	Session    *entities.ImportSession
+	Skipped    []entities.ImportRecord
+	Duplicates []ImportDuplicate
+}
+
+type ImportDuplicate struct {
+	Line        int
+	Transaction *entities.Transaction
+	DuplicateOf *entities.Transaction
+	Score       float64
+}
+
+// CommitImport creates a transaction for every valid row and moves the
+// account balance once. Everything happens in one database transaction, a
+// failed commit leaves the session as it was so it can be retried.
+func (u *CommitImportUsecase) CommitImport(ctx context.Context, cmd *CommitImportCommand) (_ *CommitImportResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("imports"), "CommitImport",
+		attribute.String("user_id", cmd.UserID),
+		attribute.String("session_id", cmd.SessionID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		sessionID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.sessionID, err = uuid.Parse(cmd.SessionID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse session id", err)
+			return nil, inerr.NewErrValidation("id", "invalid uuid type")
+		}
+	}
+
+	session, err := u.importSessionsRepo.FindByID(ctx, input.sessionID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get import session", err)
+		return nil, err
+	}
+
+	if session.UserID != input.userID {
+		return nil, inerr.ErrorPermissionDenied
+	}
+
+	switch session.CanCommit() {
+	case nil:
+	case entities.ErrImportCommitted:
+		return nil, inerr.NewErrConflict("import session")
+	default:
+		return nil, inerr.NewErrValidation("mapping", "map the columns before committing")
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	rows, err := u.importSessionsRepo.GetRows(ctx, session.ID, 0, 0)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get import rows", err)
+		return nil, err
+	}
+
+	categorizer, err := u.newCategorizer(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[entities.Currency]float64)
+	result := &CommitImportResult{Session: session}
+
+	var (
+		transactions = make([]*entities.Transaction, 0, len(rows))
+		records      = make([]entities.ImportRecord, 0, len(rows))
+		externalIDs  = make(map[string]bool)
+	)
+	for _, row := range rows {
+		record := session.ParseRow(row, user.Location())
+
+		if record.ExternalID != "" {
+			if externalIDs[record.ExternalID] {
+				record.Error = "duplicate of an earlier row"
+				result.Skipped = append(result.Skipped, record)
+				continue
+			}
+			externalIDs[record.ExternalID] = true
+		}
+
+		rate := 1.0
+		if record.Error == "" && record.Currency != "" && record.Currency != user.CurrencyCode {
+			rate, err = u.rate(ctx, rates, record.Currency, user.CurrencyCode)
+			if err != nil {
+				record.Error = "unknown currency " + record.Currency.String()
+			}
+		}
+
+		transaction, err := record.Transaction(session, user.CurrencyCode, rate)
+		if err != nil {
+			record.Error = err.Error()
+			result.Skipped = append(result.Skipped, record)
+			continue
+		}
+
+		if err := transaction.Categorise(categorizer.Categorize(record.Merchant, record.Description)); err != nil {
+			u.logger.ErrorContext(ctx, "failed to categorise transaction", err)
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+		records = append(records, record)
+	}
+
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// The checks above ran without a lock, a concurrent commit could
+		// have passed them too. The session is locked before the account,
+		// the order rollbacks use as well.
+		locked, err := u.importSessionsRepo.FindByIDForUpdate(ctx, session.ID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to lock import session", err)
+			return err
+		}
+
+		if err := locked.CanCommit(); err != nil || !locked.UpdatedAt.Equal(session.UpdatedAt) {
+			// committed meanwhile, or remapped after its rows were read
+			return inerr.NewErrConflict("import session")
+		}
+
+		account, err := u.accountsRepo.GetByIDForUpdate(ctx, session.AccountID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get account", err)
+			return err
+		}
+
+		// Checked under the account lock so overlapping statements imported
+		// at the same time cannot both add an entry.
+		transactions, records, err = u.skipImported(ctx, account.ID, transactions, records, result)
+		if err != nil {
+			return err
+		}
+
+		for start := 0; start < len(transactions); start += importBatchSize {
+			batch := transactions[start:min(start+importBatchSize, len(transactions))]
+
+			err = u.transactionsRepo.SaveBatch(ctx, batch)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to save transactions", err)
+				return err
+			}
+
+			for _, transaction := range batch {
+				if err := account.ApplyTransaction(transaction); err != nil {
+					u.logger.ErrorContext(ctx, "failed to apply transaction", err)
+					return err
+				}
+			}
+		}
+
+		err = u.accountsRepo.Save(ctx, account)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to save account", err)
+			return err
+		}
+
+		session.Committed(len(transactions))
+
+		err = u.importSessionsRepo.Save(ctx, session)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to save import session", err)
+			return err
+		}
+
+		u.statsCache.Invalidate(ctx, session.UserID)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Duplicates = u.findDuplicates(ctx, session, transactions, records)
+
+	otlp.Event(ctx, "import_committed",
+		attribute.Int("imported", len(transactions)),
+		attribute.Int("skipped", len(result.Skipped)),
+		attribute.Int("possible_duplicates", len(result.Duplicates)),
+	)
+
+	return result, nil
+}
+
+// skipImported drops transactions whose bank reference is already on the
+// account and reports them as skipped. The records are kept in step.
+func (u *CommitImportUsecase) skipImported(
+	ctx context.Context,
+	accountID uuid.UUID,
+	transactions []*entities.Transaction,
+	records []entities.ImportRecord,
+	result *CommitImportResult,
+) ([]*entities.Transaction, []entities.ImportRecord, error) {
+	existing := make(map[string]bool)
+	for start := 0; start < len(transactions); start += importBatchSize {
+		var ids []string
+		for _, transaction := range transactions[start:min(start+importBatchSize, len(transactions))] {
+			if transaction.ExternalID != "" {
+				ids = append(ids, transaction.ExternalID)
+			}
+		}
+
+		found, err := u.transactionsRepo.FindExternalIDs(ctx, accountID, ids)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to find imported transactions", err)
+			return nil, nil, err
+		}
+		for id := range found {
+			existing[id] = true
+		}
+	}
+
+	if len(existing) == 0 {
+		return transactions, records, nil
+	}
+
+	fresh := make([]*entities.Transaction, 0, len(transactions)-len(existing))
+	freshRecords := make([]entities.ImportRecord, 0, len(transactions)-len(existing))
+	for i, transaction := range transactions {
+		if transaction.ExternalID != "" && existing[transaction.ExternalID] {
+			record := records[i]
+			record.Error = "already imported"
+			result.Skipped = append(result.Skipped, record)
+			continue
+		}
+		fresh = append(fresh, transaction)
+		freshRecords = append(freshRecords, records[i])
+	}
+
+	return fresh, freshRecords, nil
+}
+
+// findDuplicates flags imported transactions that match one recorded
+// before the import. Best effort, the import is already committed.
+func (u *CommitImportUsecase) findDuplicates(
+	ctx context.Context,
+	session *entities.ImportSession,
+	transactions []*entities.Transaction,
+	records []entities.ImportRecord,
+) []ImportDuplicate {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	from, to := transactions[0].OccurredAt(), transactions[0].OccurredAt()
+	for _, transaction := range transactions[1:] {
+		if transaction.OccurredAt().Before(from) {
+			from = transaction.OccurredAt()
+		}
+		if transaction.OccurredAt().After(to) {
+			to = transaction.OccurredAt()
+		}
+	}
+
+	history, err := u.transactionsRepo.GetPerformedBetween(ctx, session.UserID,
+		from.Add(-entities.DuplicateMaxGap),
+		to.Add(entities.DuplicateMaxGap+time.Second),
+	)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to find duplicate transactions", err)
+		return nil
+	}
+
+	candidates := make([]*entities.Transaction, 0, len(history))
+	for _, transaction := range history {
+		if transaction.ImportSessionID == nil || *transaction.ImportSessionID != session.ID {
+			candidates = append(candidates, transaction)
+		}
+	}
+
+	var duplicates []ImportDuplicate
+	for i, transaction := range transactions {
+		if matches := entities.FindDuplicates(transaction, candidates); len(matches) > 0 {
+			duplicates = append(duplicates, ImportDuplicate{
+				Line:        records[i].Line,
+				Transaction: transaction,
+				DuplicateOf: matches[0].Transaction,
+				Score:       matches[0].Score,
+			})
+		}
+	}
+
+	return duplicates
+}
+
+func (u *CommitImportUsecase) newCategorizer(ctx context.Context, userID uuid.UUID) (*entities.Categorizer, error) {
+	now := time.Now()
+	history, err := u.transactionsRepo.GetPerformedBetween(ctx, userID, now.AddDate(0, -categorizerHistoryMonths, 0), now)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get transactions history", err)
+		return nil, err
+	}
+
+	categories, err := u.categoriesRepo.FindAll(ctx, userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get categories", err)
+		return nil, err
+	}
+
+	subcategories, err := u.subcategoriesRepo.FindAll(ctx, userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get subcategories", err)
+		return nil, err
+	}
+
+	return entities.NewCategorizer(history, categories, subcategories), nil
+}
+
+// rate looks every currency up once per import, a failed lookup is
+// remembered as a zero rate.
+func (u *CommitImportUsecase) rate(ctx context.Context, rates map[entities.Currency]float64, from, to entities.Currency) (float64, error) {
+	if rate, ok := rates[from]; ok {
+		if rate == 0 {
+			return 0, errors.New("no fx rate for " + from.String())
+		}
+		return rate, nil
+	}
+
+	rate, err := u.fxRatesProvider.GetRate(ctx, from.String(), to.String())
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get fx rate", err)
+		rates[from] = 0
+		return 0, err
+	}
+
+	rates[from] = rate
+	return rate, nil
+}
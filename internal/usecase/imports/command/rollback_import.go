@@ -0,0 +1,169 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type RollbackImportUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	txManager          postgres.TxManager
+	accountsRepo       entities.AccountRepository
+	transactionsRepo   entities.TransactionRepository
+	importSessionsRepo entities.ImportSessionRepository
+	statsCache         ports.StatsCache
+}
+
+func NewRollbackImportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	importSessionsRepo entities.ImportSessionRepository,
+	statsCache ports.StatsCache,
+) *RollbackImportUsecase {
+	return &RollbackImportUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		txManager:          txManager,
+		accountsRepo:       accountsRepo,
+		transactionsRepo:   transactionsRepo,
+		importSessionsRepo: importSessionsRepo,
+		statsCache:         statsCache,
+	}
+}
+
+type RollbackImportCommand struct {
+	UserID    string
+	SessionID string
+}
+
+// RollbackImport deletes the transactions the import created and reverts
+// them on the balances. Transactions moved to another account since are
+// reverted there, deleted ones were already reverted.
+func (u *RollbackImportUsecase) RollbackImport(ctx context.Context, cmd *RollbackImportCommand) (_ *entities.ImportSession, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("imports"), "RollbackImport",
+		attribute.String("user_id", cmd.UserID),
+		attribute.String("session_id", cmd.SessionID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		sessionID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.sessionID, err = uuid.Parse(cmd.SessionID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse session id", err)
+			return nil, inerr.NewErrValidation("id", "invalid uuid type")
+		}
+	}
+
+	var session *entities.ImportSession
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Locked before any account, so a concurrent rollback waits here
+		// and then finds the session rolled back.
+		var err error
+		session, err = u.importSessionsRepo.FindByIDForUpdate(ctx, input.sessionID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get import session", err)
+			return err
+		}
+
+		if session.UserID != input.userID {
+			return inerr.ErrorPermissionDenied
+		}
+
+		if err := session.RollBack(); err != nil {
+			return inerr.NewErrConflict("import session")
+		}
+
+		accounts := make(map[uuid.UUID]*entities.Account)
+		filter := entities.TransactionFilter{
+			UserID:          session.UserID,
+			ImportSessionID: &session.ID,
+		}
+
+		var cursor *entities.TransactionCursor
+		for {
+			batch, err := u.transactionsRepo.GetBatch(ctx, filter, cursor, importBatchSize)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to get imported transactions", err)
+				return err
+			}
+
+			for _, transaction := range batch {
+				account, ok := accounts[transaction.AccountID]
+				if !ok {
+					account, err = u.accountsRepo.GetByIDForUpdate(ctx, transaction.AccountID)
+					if err != nil {
+						u.logger.ErrorContext(ctx, "failed to get account", err)
+						return err
+					}
+					accounts[account.ID] = account
+				}
+
+				if err := account.RevertTransaction(transaction); err != nil {
+					u.logger.ErrorContext(ctx, "failed to revert transaction", err)
+					return err
+				}
+			}
+
+			if len(batch) < importBatchSize {
+				break
+			}
+
+			last := batch[len(batch)-1]
+			cursor = &entities.TransactionCursor{OccurredAt: last.OccurredAt(), ID: last.ID}
+		}
+
+		for _, account := range accounts {
+			if err := u.accountsRepo.Save(ctx, account); err != nil {
+				u.logger.ErrorContext(ctx, "failed to save account", err)
+				return err
+			}
+		}
+
+		if _, err := u.transactionsRepo.DeleteByImportSession(ctx, session.ID); err != nil {
+			u.logger.ErrorContext(ctx, "failed to delete imported transactions", err)
+			return err
+		}
+
+		if err := u.importSessionsRepo.Save(ctx, session); err != nil {
+			u.logger.ErrorContext(ctx, "failed to save import session", err)
+			return err
+		}
+
+		u.statsCache.Invalidate(ctx, session.UserID)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
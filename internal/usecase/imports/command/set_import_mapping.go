@@ -0,0 +1,123 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type SetImportMappingUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	txManager          postgres.TxManager
+	importSessionsRepo entities.ImportSessionRepository
+}
+
+func NewSetImportMappingUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	importSessionsRepo entities.ImportSessionRepository,
+) *SetImportMappingUsecase {
+	return &SetImportMappingUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		txManager:          txManager,
+		importSessionsRepo: importSessionsRepo,
+	}
+}
+
+// SetImportMappingCommand maps field names to zero-based columns. Empty
+// DateFormat and DecimalSeparator keep the detected ones.
+type SetImportMappingCommand struct {
+	UserID           string
+	SessionID        string
+	Mapping          map[string]int
+	DateFormat       string
+	DecimalSeparator string
+}
+
+func (u *SetImportMappingUsecase) SetImportMapping(ctx context.Context, cmd *SetImportMappingCommand) (_ *entities.ImportSession, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("imports"), "SetImportMapping",
+		attribute.String("user_id", cmd.UserID),
+		attribute.String("session_id", cmd.SessionID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		sessionID uuid.UUID
+		mapping   entities.ImportMapping
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.sessionID, err = uuid.Parse(cmd.SessionID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse session id", err)
+			return nil, inerr.NewErrValidation("id", "invalid uuid type")
+		}
+
+		input.mapping = make(entities.ImportMapping, len(cmd.Mapping))
+		for key, column := range cmd.Mapping {
+			field, err := entities.ParseImportField(key)
+			if err != nil {
+				return nil, inerr.NewErrValidation("mapping", "unknown field "+key)
+			}
+			input.mapping[field] = column
+		}
+	}
+
+	// Locked so a commit running meanwhile is not overwritten with the
+	// session as it was before.
+	var session *entities.ImportSession
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		session, err = u.importSessionsRepo.FindByIDForUpdate(ctx, input.sessionID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get import session", err)
+			return err
+		}
+
+		if session.UserID != input.userID {
+			return inerr.ErrorPermissionDenied
+		}
+
+		if session.Status == entities.ImportCommitted {
+			return inerr.NewErrConflict("import session")
+		}
+
+		if err := session.SetMapping(input.mapping, cmd.DateFormat, cmd.DecimalSeparator); err != nil {
+			return inerr.NewErrValidation("mapping", err.Error())
+		}
+
+		err = u.importSessionsRepo.Save(ctx, session)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to save import session", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
@@ -0,0 +1,242 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/csvsniff"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/statement"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MaxImportRows caps a single statement, a year of daily card spending is
+// well below it.
+const MaxImportRows = 20000
+
+type UploadImportUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	txManager          postgres.TxManager
+	usersRepo          entities.UserRepository
+	accountsRepo       entities.AccountRepository
+	importSessionsRepo entities.ImportSessionRepository
+}
+
+func NewUploadImportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	importSessionsRepo entities.ImportSessionRepository,
+) *UploadImportUsecase {
+	return &UploadImportUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		txManager:          txManager,
+		usersRepo:          usersRepo,
+		accountsRepo:       accountsRepo,
+		importSessionsRepo: importSessionsRepo,
+	}
+}
+
+type UploadImportCommand struct {
+	UserID    string
+	AccountID string
+	Filename  string
+	Data      []byte
+}
+
+// UploadImport parses the file and stores it as a session with the detected
+// formats and a suggested mapping. OFX, QIF and CAMT.053 statements are
+// turned into rows of a fixed layout that needs no mapping. Nothing is
+// imported yet.
+func (u *UploadImportUsecase) UploadImport(ctx context.Context, cmd *UploadImportCommand) (_ *entities.ImportSession, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("imports"), "UploadImport",
+		attribute.String("user_id", cmd.UserID),
+		attribute.String("account_id", cmd.AccountID),
+		attribute.Int("size", len(cmd.Data)),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		accountID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.accountID, err = uuid.Parse(cmd.AccountID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse account id", err)
+			return nil, inerr.NewErrValidation("account_id", "invalid uuid type")
+		}
+	}
+
+	account, err := u.accountsRepo.GetByID(ctx, input.accountID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get account", err)
+		return nil, err
+	}
+
+	if account.UserID != input.userID {
+		return nil, inerr.ErrorPermissionDenied
+	}
+
+	text, encoding, err := csvsniff.Decode(cmd.Data)
+	if err != nil {
+		return nil, inerr.NewErrValidation("file", "file encoding is not supported")
+	}
+
+	var (
+		source   = entities.ImportSourceCSV
+		format   entities.ImportFormat
+		analysis *entities.ImportAnalysis
+	)
+
+	switch kind := statement.Detect(cmd.Filename, []byte(text)); kind {
+	case statement.CSV:
+		sniffed, err := csvsniff.Sniff(cmd.Data)
+		if err != nil {
+			if errors.Is(err, csvsniff.ErrEmpty) {
+				return nil, inerr.NewErrValidation("file", "file is empty")
+			}
+			return nil, inerr.NewErrValidation("file", "file is not a valid csv: "+err.Error())
+		}
+
+		analysis = entities.AnalyzeImport(sniffed.Records)
+		format = entities.ImportFormat{
+			Encoding:         sniffed.Encoding,
+			Delimiter:        string(sniffed.Delimiter),
+			DateFormat:       analysis.DateFormat,
+			DecimalSeparator: analysis.DecimalSeparator,
+		}
+	default:
+		entries, err := statement.Parse(kind, text)
+		if err != nil {
+			if errors.Is(err, statement.ErrNoEntries) {
+				return nil, inerr.NewErrValidation("file", "file has no rows")
+			}
+			return nil, inerr.NewErrValidation("file", err.Error())
+		}
+
+		user, err := u.usersRepo.FindByID(ctx, input.userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get user", err)
+			return nil, err
+		}
+
+		source = string(kind)
+		analysis = statementAnalysis(entries, user.Location())
+		format = entities.ImportFormat{
+			Encoding:         encoding,
+			DateFormat:       analysis.DateFormat,
+			DecimalSeparator: analysis.DecimalSeparator,
+		}
+	}
+
+	if len(analysis.Rows) == 0 {
+		return nil, inerr.NewErrValidation("file", "file has no rows")
+	}
+	if len(analysis.Rows) > MaxImportRows {
+		return nil, inerr.NewErrValidation("file", "file has too many rows")
+	}
+
+	session, err := entities.NewImportSession(
+		input.userID,
+		account.ID,
+		source,
+		cmd.Filename,
+		format,
+		analysis.Header,
+		analysis.Mapping,
+		len(analysis.Rows),
+	)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to create import session", err)
+		return nil, inerr.NewErrValidation("file", err.Error())
+	}
+
+	rows := make([]entities.ImportRow, 0, len(analysis.Rows))
+	for i, values := range analysis.Rows {
+		rows = append(rows, entities.ImportRow{Line: i + 1, Values: values})
+	}
+
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.importSessionsRepo.Save(ctx, session); err != nil {
+			u.logger.ErrorContext(ctx, "failed to save import session", err)
+			return err
+		}
+
+		if err := u.importSessionsRepo.SaveRows(ctx, session.ID, rows); err != nil {
+			u.logger.ErrorContext(ctx, "failed to save import rows", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// statementAnalysis lays statement entries out as rows the CSV pipeline
+// reads, so mapping, preview and commit work the same for every format.
+// Times are written in the user's zone, dates without a time stay as they
+// are.
+func statementAnalysis(entries []statement.Entry, loc *time.Location) *entities.ImportAnalysis {
+	const layout = "2006-01-02 15:04:05"
+
+	header := []string{"date", "amount", "currency", "merchant", "description", "external_id"}
+
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		postedAt := entry.PostedAt.In(loc)
+		if entry.DateOnly {
+			postedAt = entry.PostedAt.UTC()
+		}
+
+		rows = append(rows, []string{
+			postedAt.Format(layout),
+			strconv.FormatFloat(entry.Amount, 'f', -1, 64),
+			entry.Currency,
+			entry.Payee,
+			entry.Memo,
+			entry.ID,
+		})
+	}
+
+	return &entities.ImportAnalysis{
+		Header:           header,
+		Rows:             rows,
+		DateFormat:       "YYYY-MM-DD HH:mm:ss",
+		DecimalSeparator: ".",
+		Mapping: entities.ImportMapping{
+			entities.ImportDate:        0,
+			entities.ImportAmount:      1,
+			entities.ImportCurrency:    2,
+			entities.ImportMerchant:    3,
+			entities.ImportDescription: 4,
+			entities.ImportExternalID:  5,
+		},
+	}
+}
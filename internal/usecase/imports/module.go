@@ -0,0 +1,70 @@
+package imports
+
+import (
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/imports/command"
+	"github.com/AsaHero/e-wallet/internal/usecase/imports/query"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+)
+
+type Commands struct {
+	*command.UploadImportUsecase
+	*command.SetImportMappingUsecase
+	*command.CommitImportUsecase
+	*command.RollbackImportUsecase
+}
+
+type Query struct {
+	*query.GetImportUsecase
+	*query.PreviewImportUsecase
+}
+
+type Module struct {
+	Command Commands
+	Query   Query
+}
+
+func NewModule(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	importSessionsRepo entities.ImportSessionRepository,
+	fxRatesProvider ports.FXRatesProvider,
+	statsCache ports.StatsCache,
+) *Module {
+	m := &Module{
+		Command: Commands{
+			UploadImportUsecase:     command.NewUploadImportUsecase(timeout, logger, txManager, usersRepo, accountsRepo, importSessionsRepo),
+			SetImportMappingUsecase: command.NewSetImportMappingUsecase(timeout, logger, txManager, importSessionsRepo),
+			CommitImportUsecase: command.NewCommitImportUsecase(
+				timeout,
+				logger,
+				txManager,
+				usersRepo,
+				accountsRepo,
+				transactionsRepo,
+				categoriesRepo,
+				subcategoriesRepo,
+				importSessionsRepo,
+				fxRatesProvider,
+				statsCache,
+			),
+			RollbackImportUsecase: command.NewRollbackImportUsecase(timeout, logger, txManager, accountsRepo, transactionsRepo, importSessionsRepo, statsCache),
+		},
+		Query: Query{
+			GetImportUsecase:     query.NewGetImportUsecase(timeout, logger, importSessionsRepo),
+			PreviewImportUsecase: query.NewPreviewImportUsecase(timeout, logger, usersRepo, transactionsRepo, categoriesRepo, subcategoriesRepo, importSessionsRepo),
+		},
+	}
+
+	return m
+}
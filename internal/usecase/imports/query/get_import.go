@@ -0,0 +1,74 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type GetImportUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	importSessionsRepo entities.ImportSessionRepository
+}
+
+func NewGetImportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	importSessionsRepo entities.ImportSessionRepository,
+) *GetImportUsecase {
+	return &GetImportUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		importSessionsRepo: importSessionsRepo,
+	}
+}
+
+func (u *GetImportUsecase) GetImport(ctx context.Context, userID, sessionID string) (_ *entities.ImportSession, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("imports"), "GetImport",
+		attribute.String("user_id", userID),
+		attribute.String("session_id", sessionID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		sessionID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.sessionID, err = uuid.Parse(sessionID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse session id", err)
+			return nil, inerr.NewErrValidation("id", "invalid uuid type")
+		}
+	}
+
+	session, err := u.importSessionsRepo.FindByID(ctx, input.sessionID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get import session", err)
+		return nil, err
+	}
+
+	if session.UserID != input.userID {
+		return nil, inerr.ErrorPermissionDenied
+	}
+
+	return session, nil
+}
@@ -0,0 +1,267 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultPreviewLimit = 50
+	maxPreviewLimit     = 200
+	// categorizerHistoryMonths matches the commit, so the preview shows the
+	// categories the rows will get.
+	categorizerHistoryMonths = 6
+)
+
+type PreviewImportUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	usersRepo          entities.UserRepository
+	transactionsRepo   entities.TransactionRepository
+	categoriesRepo     entities.CategoryRepository
+	subcategoriesRepo  entities.SubcategoryRepository
+	importSessionsRepo entities.ImportSessionRepository
+}
+
+func NewPreviewImportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	importSessionsRepo entities.ImportSessionRepository,
+) *PreviewImportUsecase {
+	return &PreviewImportUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		usersRepo:          usersRepo,
+		transactionsRepo:   transactionsRepo,
+		categoriesRepo:     categoriesRepo,
+		subcategoriesRepo:  subcategoriesRepo,
+		importSessionsRepo: importSessionsRepo,
+	}
+}
+
+type PreviewImportQuery struct {
+	UserID    string
+	SessionID string
+	Limit     int
+	Offset    int
+}
+
+type ImportPreviewView struct {
+	SessionID string             `json:"session_id"`
+	Status    string             `json:"status"`
+	Currency  string             `json:"currency"`
+	Total     int                `json:"total"`
+	Rows      []ImportPreviewRow `json:"rows"`
+}
+
+// ImportPreviewRow shows a raw row next to the transaction it would become.
+// Amounts are in the row currency, conversion happens on commit.
+type ImportPreviewRow struct {
+	Line            int        `json:"line"`
+	Values          []string   `json:"values"`
+	Type            string     `json:"type,omitempty"`
+	Amount          float64    `json:"amount,omitempty"`
+	Currency        string     `json:"currency,omitempty"`
+	PerformedAt     *time.Time `json:"performed_at,omitempty"`
+	Merchant        string     `json:"merchant,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	CategoryID      *int       `json:"category_id,omitempty"`
+	CategoryName    string     `json:"category_name,omitempty"`
+	CategoryEmoji   string     `json:"category_emoji,omitempty"`
+	SubcategoryID   *int       `json:"subcategory_id,omitempty"`
+	SubcategoryName string     `json:"subcategory_name,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+func (u *PreviewImportUsecase) PreviewImport(ctx context.Context, query *PreviewImportQuery) (_ *ImportPreviewView, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("imports"), "PreviewImport",
+		attribute.String("user_id", query.UserID),
+		attribute.String("session_id", query.SessionID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		sessionID uuid.UUID
+		limit     int
+		offset    int
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(query.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.sessionID, err = uuid.Parse(query.SessionID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse session id", err)
+			return nil, inerr.NewErrValidation("id", "invalid uuid type")
+		}
+
+		input.limit = query.Limit
+		if input.limit <= 0 {
+			input.limit = defaultPreviewLimit
+		}
+		if input.limit > maxPreviewLimit {
+			return nil, inerr.NewErrValidation("limit", "limit must be at most 200")
+		}
+
+		input.offset = query.Offset
+		if input.offset < 0 {
+			return nil, inerr.NewErrValidation("offset", "offset must not be negative")
+		}
+	}
+
+	session, err := u.importSessionsRepo.FindByID(ctx, input.sessionID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get import session", err)
+		return nil, err
+	}
+
+	if session.UserID != input.userID {
+		return nil, inerr.ErrorPermissionDenied
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	rows, err := u.importSessionsRepo.GetRows(ctx, session.ID, input.limit, input.offset)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get import rows", err)
+		return nil, err
+	}
+
+	categorizer, err := u.newCategorizer(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &ImportPreviewView{
+		SessionID: session.ID.String(),
+		Status:    session.Status.String(),
+		Currency:  user.CurrencyCode.String(),
+		Total:     session.RowCount,
+		Rows:      make([]ImportPreviewRow, 0, len(rows)),
+	}
+
+	records := make([]entities.ImportRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, session.ParseRow(row, user.Location()))
+	}
+
+	// Once committed the session's own transactions carry the references.
+	if session.Status != entities.ImportCommitted {
+		if err := u.markImported(ctx, session.AccountID, records); err != nil {
+			return nil, err
+		}
+	}
+
+	lang := user.LanguageCode
+	for i, row := range rows {
+		record := records[i]
+
+		previewRow := ImportPreviewRow{
+			Line:        record.Line,
+			Values:      row.Values,
+			Merchant:    record.Merchant,
+			Description: record.Description,
+			Error:       record.Error,
+		}
+
+		if record.Error == "" {
+			previewRow.Type = record.Type.String()
+			previewRow.Amount = record.Amount
+			previewRow.Currency = user.CurrencyCode.String()
+			if record.Currency != "" {
+				previewRow.Currency = record.Currency.String()
+			}
+			performedAt := record.PerformedAt
+			previewRow.PerformedAt = &performedAt
+
+			category, subcategory := categorizer.Categorize(record.Merchant, record.Description)
+			if category != nil {
+				id := category.ID.Int()
+				previewRow.CategoryID = &id
+				previewRow.CategoryName = category.GetName(lang)
+				previewRow.CategoryEmoji = category.Emoji
+			}
+			if subcategory != nil {
+				id := subcategory.ID
+				previewRow.SubcategoryID = &id
+				previewRow.SubcategoryName = subcategory.GetName(lang)
+			}
+		}
+
+		view.Rows = append(view.Rows, previewRow)
+	}
+
+	return view, nil
+}
+
+// markImported sets an error on records whose bank reference is already on
+// the account, commit skips them.
+func (u *PreviewImportUsecase) markImported(ctx context.Context, accountID uuid.UUID, records []entities.ImportRecord) error {
+	var ids []string
+	for _, record := range records {
+		if record.Error == "" && record.ExternalID != "" {
+			ids = append(ids, record.ExternalID)
+		}
+	}
+
+	existing, err := u.transactionsRepo.FindExternalIDs(ctx, accountID, ids)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to find imported transactions", err)
+		return err
+	}
+
+	for i := range records {
+		if records[i].Error == "" && existing[records[i].ExternalID] {
+			records[i].Error = "already imported"
+		}
+	}
+
+	return nil
+}
+
+func (u *PreviewImportUsecase) newCategorizer(ctx context.Context, userID uuid.UUID) (*entities.Categorizer, error) {
+	now := time.Now()
+	history, err := u.transactionsRepo.GetPerformedBetween(ctx, userID, now.AddDate(0, -categorizerHistoryMonths, 0), now)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get transactions history", err)
+		return nil, err
+	}
+
+	categories, err := u.categoriesRepo.FindAll(ctx, userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get categories", err)
+		return nil, err
+	}
+
+	subcategories, err := u.subcategoriesRepo.FindAll(ctx, userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get subcategories", err)
+		return nil, err
+	}
+
+	return entities.NewCategorizer(history, categories, subcategories), nil
+}
@@ -0,0 +1,219 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// anomalyHistoryMonths is how far back comparable charges are looked up.
+const anomalyHistoryMonths = 6
+
+type anomalyText struct {
+	title         string
+	unusualAmount string
+	duplicate     string
+	newMerchant   string
+	expense       string
+	footer        string
+}
+
+// anomalyTexts are picked following the user's language fallback chain.
+var anomalyTexts = map[entities.Language]anomalyText{
+	entities.EN: {
+		title:         "⚠️ <b>Unusual transaction</b>",
+		unusualAmount: "<b>%s</b>: %s is %.1f× what you usually spend here (about %s).",
+		duplicate:     "<b>%s</b>: %s looks like a repeat of the same charge at %s.",
+		newMerchant:   "<b>%s</b>: %s is your first purchase here and it is %.1f× your typical expense.",
+		expense:       "Expense",
+		footer:        "You can turn these alerts off in the settings.",
+	},
+	entities.RU: {
+		title:         "⚠️ <b>Необычная операция</b>",
+		unusualAmount: "<b>%s</b>: %s — это в %.1f раза больше, чем вы обычно тратите здесь (около %s).",
+		duplicate:     "<b>%s</b>: %s похоже на повтор такого же платежа в %s.",
+		newMerchant:   "<b>%s</b>: %s — первая покупка здесь, и она в %.1f раза больше обычной траты.",
+		expense:       "Расход",
+		footer:        "Эти уведомления можно отключить в настройках.",
+	},
+	entities.UZ: {
+		title:         "⚠️ <b>G'ayrioddiy tranzaksiya</b>",
+		unusualAmount: "<b>%s</b>: %s odatdagidan %.1f barobar ko'p (odatda taxminan %s).",
+		duplicate:     "<b>%s</b>: %s soat %s dagi xuddi shunday to'lovning takroriga o'xshaydi.",
+		newMerchant:   "<b>%s</b>: %s bu yerdagi birinchi xarid va odatdagi xarajatdan %.1f barobar ko'p.",
+		expense:       "Xarajat",
+		footer:        "Bu bildirishnomalarni sozlamalarda o'chirish mumkin.",
+	},
+}
+
+type anomalyDetectUsecase struct {
+	contextTimeout           time.Duration
+	logger                   *logger.Logger
+	userRepo                 entities.UserRepository
+	transactionsRepo         entities.TransactionRepository
+	notificationSettingsRepo entities.NotificationSettingsRepository
+	telegramBotService       ports.TelegramBotService
+}
+
+func NewAnomalyDetectUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	userRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+	notificationSettingsRepo entities.NotificationSettingsRepository,
+	telegramBotService ports.TelegramBotService,
+) *anomalyDetectUsecase {
+	return &anomalyDetectUsecase{
+		contextTimeout:           timeout,
+		logger:                   logger,
+		userRepo:                 userRepo,
+		transactionsRepo:         transactionsRepo,
+		notificationSettingsRepo: notificationSettingsRepo,
+		telegramBotService:       telegramBotService,
+	}
+}
+
+func (r *anomalyDetectUsecase) AnomalyDetect(ctx context.Context, userID string, transactionID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("notifications"), "AnomalyDetect",
+		attribute.String("user_id", userID),
+		attribute.String("transaction_id", transactionID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID        uuid.UUID
+		transactionID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			return inerr.NewErrValidation("user_id", err.Error())
+		}
+
+		input.transactionID, err = uuid.Parse(transactionID)
+		if err != nil {
+			return inerr.NewErrValidation("transaction_id", err.Error())
+		}
+	}
+
+	settings, err := r.notificationSettingsRepo.FindByUserID(ctx, input.userID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	enabled := false
+	for _, t := range []entities.NotificationType{entities.AlertUnusualAmount, entities.AlertDuplicate, entities.AlertNewMerchant} {
+		enabled = enabled || settings.IsEnabled(t)
+	}
+	if !enabled {
+		otlp.Event(ctx, "anomaly_skipped", attribute.String("reason", "alerts_disabled"))
+		return nil
+	}
+
+	transaction, err := r.transactionsRepo.GetByID(ctx, input.transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if transaction.UserID != input.userID {
+		return inerr.ErrorPermissionDenied
+	}
+
+	occurredAt := transaction.OccurredAt()
+	history, err := r.transactionsRepo.GetPerformedBetween(ctx, input.userID,
+		occurredAt.AddDate(0, -anomalyHistoryMonths, 0),
+		occurredAt.Add(entities.DuplicateWindow),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions history: %w", err)
+	}
+
+	var anomalies []entities.Anomaly
+	for _, anomaly := range entities.DetectAnomalies(transaction, history) {
+		if settings.IsEnabled(anomaly.Type) {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	user, err := r.userRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := r.telegramBotService.SendMessage(ctx, &ports.SendMessageRequest{
+		UserID:    user.TGUserID,
+		Text:      r.constructAnomalyText(user, transaction, anomalies),
+		ParseMode: "HTML",
+	}); err != nil {
+		return err
+	}
+
+	for _, anomaly := range anomalies {
+		otlp.Event(ctx, "anomaly_sent", attribute.String("type", anomaly.Type.String()))
+	}
+
+	return nil
+}
+
+func (r *anomalyDetectUsecase) constructAnomalyText(user *entities.User, transaction *entities.Transaction, anomalies []entities.Anomaly) string {
+	texts := anomalyTexts[entities.EN]
+	for _, lang := range user.LanguageCode.Fallbacks() {
+		if t, ok := anomalyTexts[lang]; ok {
+			texts = t
+			break
+		}
+	}
+
+	label := transaction.Merchant
+	if label == "" && transaction.Category != nil {
+		label = transaction.Category.GetName(user.LanguageCode)
+	}
+	if label == "" {
+		label = texts.expense
+	}
+	label = html.EscapeString(label)
+
+	amount := formatAmount(transaction.AmountMinor(), transaction.CurrencyCode)
+
+	lines := []string{texts.title, ""}
+	for _, anomaly := range anomalies {
+		switch anomaly.Type {
+		case entities.AlertUnusualAmount:
+			lines = append(lines, fmt.Sprintf(texts.unusualAmount, label, amount, anomaly.Ratio,
+				formatAmount(anomaly.Baseline, transaction.CurrencyCode)))
+		case entities.AlertDuplicate:
+			lines = append(lines, fmt.Sprintf(texts.duplicate, label, amount,
+				anomaly.Duplicate.OccurredAt().In(user.Location()).Format("15:04")))
+		case entities.AlertNewMerchant:
+			lines = append(lines, fmt.Sprintf(texts.newMerchant, label, amount, anomaly.Ratio))
+		}
+	}
+	lines = append(lines, "", "<i>"+texts.footer+"</i>")
+
+	return strings.Join(lines, "\n")
+}
+
+func formatAmount(minor int64, currency entities.Currency) string {
+	scale := currency.Scale()
+	return strconv.FormatFloat(entities.MajorFromMinor(minor, scale), 'f', scale, 64) + " " + currency.String()
+}
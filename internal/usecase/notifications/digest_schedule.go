@@ -0,0 +1,104 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type digestScheduleUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	userRepo           entities.UserRepository
+	digestScheduleRepo entities.DigestScheduleRepository
+	taskQueue          *asynq.Client
+}
+
+func NewDigestScheduleUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	userRepo entities.UserRepository,
+	digestScheduleRepo entities.DigestScheduleRepository,
+	taskQueue *asynq.Client,
+) *digestScheduleUsecase {
+	return &digestScheduleUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		userRepo:           userRepo,
+		digestScheduleRepo: digestScheduleRepo,
+		taskQueue:          taskQueue,
+	}
+}
+
+// DigestSchedule runs every hour and queues the digests of the users for
+// whom it is now their chosen hour, in their own time zone. Whether a digest
+// is turned on is checked when it is sent.
+func (r *digestScheduleUsecase) DigestSchedule(ctx context.Context) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("notifications"), "DigestSchedule")
+	defer func() { end(err) }()
+
+	users, err := r.userRepo.FindAll(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get users", err)
+		return err
+	}
+
+	saved, err := r.digestScheduleRepo.FindAll(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get digest schedules", err)
+		return err
+	}
+
+	schedules := make(map[uuid.UUID]*entities.DigestSchedule, len(saved))
+	for _, schedule := range saved {
+		schedules[schedule.UserID] = schedule
+	}
+
+	now := time.Now()
+	var scheduled, duplicates int
+	for _, user := range users {
+		schedule, ok := schedules[user.ID]
+		if !ok {
+			schedule = entities.NewDigestSchedule(user.ID)
+		}
+
+		local := entities.NewPeriodResolver(user.Location(), now).Now()
+		for _, period := range schedule.Due(local) {
+			task, err := tasks.NewDigestSendTask(user.ID.String(), period.String(), local.Format(time.DateOnly))
+			if err != nil {
+				r.logger.ErrorContext(ctx, "failed to create task", err)
+				return err
+			}
+
+			if _, err := r.taskQueue.EnqueueContext(ctx, task); err != nil {
+				if errors.Is(err, asynq.ErrTaskIDConflict) {
+					duplicates++
+					continue
+				}
+				r.logger.ErrorContext(ctx, "failed to enqueue task", err)
+				return err
+			}
+			scheduled++
+		}
+	}
+
+	otlp.Annotate(ctx,
+		attribute.Int("total_users", len(users)),
+		attribute.Int("total_tasks_created", scheduled),
+		attribute.Int("total_duplicates", duplicates),
+	)
+
+	return nil
+}
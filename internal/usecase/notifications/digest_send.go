@@ -0,0 +1,269 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// digestTemplates are rendered with a digestView whose strings are already
+// HTML escaped, and picked following the user's language fallback chain.
+var digestTemplates = map[entities.Language]*template.Template{
+	entities.EN: template.Must(template.New("en").Parse(
+		`🗓 <b>{{if .Weekly}}Your week{{else}}Your month{{end}} in review</b>
+<i>{{.From}} – {{.To}}</i>
+
+You spent <b>{{.Spent}}</b>{{if .Change}}, {{.Change}} vs last {{if .Weekly}}week{{else}}month{{end}}{{end}}.
+{{- if .Income}}
+Income: <b>{{.Income}}</b>{{end}}
+Transactions: {{.Count}}
+{{- if .Categories}}
+
+<b>Top categories</b>{{range .Categories}}
+{{.Rank}}. {{.Name}} — {{.Amount}}{{end}}{{end}}
+{{- with .Biggest}}
+
+Biggest purchase: <b>{{.Label}}</b>, {{.Amount}} on {{.Date}}{{end}}`)),
+	entities.RU: template.Must(template.New("ru").Parse(
+		`🗓 <b>{{if .Weekly}}Итоги недели{{else}}Итоги месяца{{end}}</b>
+<i>{{.From}} – {{.To}}</i>
+
+Вы потратили <b>{{.Spent}}</b>{{if .Change}}, {{.Change}} к {{if .Weekly}}прошлой неделе{{else}}прошлому месяцу{{end}}{{end}}.
+{{- if .Income}}
+Доходы: <b>{{.Income}}</b>{{end}}
+Операций: {{.Count}}
+{{- if .Categories}}
+
+<b>Основные категории</b>{{range .Categories}}
+{{.Rank}}. {{.Name}} — {{.Amount}}{{end}}{{end}}
+{{- with .Biggest}}
+
+Самая крупная покупка: <b>{{.Label}}</b>, {{.Amount}}, {{.Date}}{{end}}`)),
+	entities.UZ: template.Must(template.New("uz").Parse(
+		`🗓 <b>{{if .Weekly}}Hafta{{else}}Oy{{end}} yakunlari</b>
+<i>{{.From}} – {{.To}}</i>
+
+Siz <b>{{.Spent}}</b> sarfladingiz{{if .Change}}, o'tgan {{if .Weekly}}haftaga{{else}}oyga{{end}} nisbatan {{.Change}}{{end}}.
+{{- if .Income}}
+Daromad: <b>{{.Income}}</b>{{end}}
+Tranzaksiyalar: {{.Count}}
+{{- if .Categories}}
+
+<b>Asosiy kategoriyalar</b>{{range .Categories}}
+{{.Rank}}. {{.Name}} — {{.Amount}}{{end}}{{end}}
+{{- with .Biggest}}
+
+Eng katta xarid: <b>{{.Label}}</b>, {{.Amount}}, {{.Date}}{{end}}`)),
+}
+
+var digestUncategorized = map[entities.Language]string{
+	entities.EN: "Uncategorized",
+	entities.RU: "Без категории",
+	entities.UZ: "Kategoriyasiz",
+}
+
+type digestView struct {
+	Weekly     bool
+	From       string
+	To         string
+	Spent      string
+	Change     string
+	Income     string
+	Count      int
+	Categories []digestCategoryView
+	Biggest    *digestPurchaseView
+}
+
+type digestCategoryView struct {
+	Rank   int
+	Name   string
+	Amount string
+}
+
+type digestPurchaseView struct {
+	Label  string
+	Amount string
+	Date   string
+}
+
+type digestSendUsecase struct {
+	contextTimeout           time.Duration
+	logger                   *logger.Logger
+	userRepo                 entities.UserRepository
+	transactionsRepo         entities.TransactionRepository
+	notificationSettingsRepo entities.NotificationSettingsRepository
+	telegramBotService       ports.TelegramBotService
+}
+
+func NewDigestSendUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	userRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+	notificationSettingsRepo entities.NotificationSettingsRepository,
+	telegramBotService ports.TelegramBotService,
+) *digestSendUsecase {
+	return &digestSendUsecase{
+		contextTimeout:           timeout,
+		logger:                   logger,
+		userRepo:                 userRepo,
+		transactionsRepo:         transactionsRepo,
+		notificationSettingsRepo: notificationSettingsRepo,
+		telegramBotService:       telegramBotService,
+	}
+}
+
+// DigestSend sends the digest of the period that ends on the local day
+// date. Nothing is sent for a period without transactions.
+func (r *digestSendUsecase) DigestSend(ctx context.Context, userID string, period string, date string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("notifications"), "DigestSend",
+		attribute.String("user_id", userID),
+		attribute.String("period", period),
+		attribute.String("date", date),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+		period entities.DigestPeriod
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			return inerr.NewErrValidation("user_id", err.Error())
+		}
+
+		input.period, err = entities.ParseDigestPeriod(period)
+		if err != nil {
+			return inerr.NewErrValidation("period", err.Error())
+		}
+	}
+
+	settings, err := r.notificationSettingsRepo.FindByUserID(ctx, input.userID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	if !settings.IsEnabled(input.period.NotificationType()) {
+		otlp.Event(ctx, "digest_skipped", attribute.String("reason", "digest_disabled"))
+		return nil
+	}
+
+	user, err := r.userRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	day, err := user.Periods().Date("date", date)
+	if err != nil {
+		return inerr.NewErrValidation("date", "date must be in YYYY-MM-DD format")
+	}
+
+	from, previousFrom, to := input.period.Bounds(day)
+	transactions, err := r.transactionsRepo.GetPerformedBetween(ctx, input.userID, previousFrom, to)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	digest := entities.BuildDigest(input.period, previousFrom, from, to, transactions)
+	if digest.Count == 0 {
+		otlp.Event(ctx, "digest_skipped", attribute.String("reason", "no_transactions"))
+		return nil
+	}
+
+	text, err := r.constructDigestText(user, digest)
+	if err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	if err := r.telegramBotService.SendMessage(ctx, &ports.SendMessageRequest{
+		UserID:    user.TGUserID,
+		Text:      text,
+		ParseMode: "HTML",
+	}); err != nil {
+		return err
+	}
+
+	otlp.Event(ctx, "digest_sent", attribute.Int("transactions", digest.Count))
+
+	return nil
+}
+
+func (r *digestSendUsecase) constructDigestText(user *entities.User, digest *entities.Digest) (string, error) {
+	tmpl := digestTemplates[entities.EN]
+	uncategorized := digestUncategorized[entities.EN]
+	for _, lang := range user.LanguageCode.Fallbacks() {
+		if t, ok := digestTemplates[lang]; ok {
+			tmpl, uncategorized = t, digestUncategorized[lang]
+			break
+		}
+	}
+
+	loc := user.Location()
+	currency := user.CurrencyCode
+
+	view := digestView{
+		Weekly: digest.Period == entities.DigestPeriodWeekly,
+		From:   digest.From.In(loc).Format("02.01.2006"),
+		To:     digest.To.In(loc).AddDate(0, 0, -1).Format("02.01.2006"),
+		Spent:  formatAmount(digest.Expense, currency),
+		Count:  digest.Count,
+	}
+
+	if change, ok := digest.Change(); ok {
+		view.Change = fmt.Sprintf("%+.0f%%", change*100)
+	}
+	if digest.Income > 0 {
+		view.Income = formatAmount(digest.Income, currency)
+	}
+
+	for i, category := range digest.TopCategories {
+		name := uncategorized
+		if category.Category != nil {
+			name = strings.TrimSpace(category.Category.Emoji + " " + category.Category.GetName(user.LanguageCode))
+		}
+		view.Categories = append(view.Categories, digestCategoryView{
+			Rank:   i + 1,
+			Name:   html.EscapeString(name),
+			Amount: formatAmount(category.Total, currency),
+		})
+	}
+
+	if t := digest.BiggestPurchase; t != nil {
+		label := t.Merchant
+		if label == "" && t.Category != nil {
+			label = t.Category.GetName(user.LanguageCode)
+		}
+		if label == "" {
+			label = uncategorized
+		}
+		view.Biggest = &digestPurchaseView{
+			Label:  html.EscapeString(label),
+			Amount: formatAmount(t.AmountMinor(), t.CurrencyCode),
+			Date:   t.OccurredAt().In(loc).Format("02.01"),
+		}
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, view); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
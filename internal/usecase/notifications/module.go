@@ -12,17 +12,25 @@ import (
 type Module struct {
 	*recordReminderCalculateUsecase
 	*recordReminderSendUsecase
+	*anomalyDetectUsecase
+	*digestScheduleUsecase
+	*digestSendUsecase
 }
 
 func NewModule(
 	logger *logger.Logger,
 	transactionRepo entities.TransactionRepository,
 	userRepo entities.UserRepository,
+	notificationSettingsRepo entities.NotificationSettingsRepository,
+	digestScheduleRepo entities.DigestScheduleRepository,
 	taskQueue *asynq.Client,
 	telegramBotService ports.TelegramBotService,
 ) *Module {
 	return &Module{
 		recordReminderCalculateUsecase: NewRecordReminderCalculateUsecase(5*time.Minute, logger, transactionRepo, userRepo, taskQueue),
 		recordReminderSendUsecase:      NewRecordReminderSendUsecase(30*time.Second, logger, userRepo, transactionRepo, telegramBotService),
+		anomalyDetectUsecase:           NewAnomalyDetectUsecase(30*time.Second, logger, userRepo, transactionRepo, notificationSettingsRepo, telegramBotService),
+		digestScheduleUsecase:          NewDigestScheduleUsecase(5*time.Minute, logger, userRepo, digestScheduleRepo, taskQueue),
+		digestSendUsecase:              NewDigestSendUsecase(30*time.Second, logger, userRepo, transactionRepo, notificationSettingsRepo, telegramBotService),
 	}
 }
@@ -19,6 +19,12 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// incomeRecurring spots paydays, two months are all the history has room
+// for and salaries vary too much to bound their spread.
+var incomeRecurring = entities.RecurringDetector{
+	MinMonths: 2,
+}
+
 type recordReminderCalculateUsecase struct {
 	contextTimeout  time.Duration
 	logger          *logger.Logger
@@ -68,27 +74,20 @@ func (r *recordReminderCalculateUsecase) RecordReminderCalculate(ctx context.Con
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	loc := time.UTC
-	if user.Timezone != "" {
-		if l, err := time.LoadLocation(user.Timezone); err == nil {
-			loc = l
-		}
-	}
-
-	now := time.Now().In(loc)
-	from := now.Add(-60 * 24 * time.Hour)
-	transactions, err := r.transactionRepo.GetAllBetween(ctx, input.userID, from.UTC(), time.Now().UTC())
+	periods := user.Periods()
+	history := periods.LastDays(60)
+	transactions, err := r.transactionRepo.GetAllBetween(ctx, input.userID, history.From.UTC(), periods.Now().UTC())
 	if err != nil {
 		return err
 	}
 
 	// 1. Calculate Expenses Reminders
-	if err := r.processExpenseReminders(ctx, transactions, user, now, loc); err != nil {
+	if err := r.processExpenseReminders(ctx, transactions, user, periods); err != nil {
 		r.logger.ErrorContext(ctx, "failed to process expense reminders", err)
 	}
 
 	// 2. Calculate Income Reminders
-	if err := r.processIncomeReminders(ctx, transactions, user, now, loc); err != nil {
+	if err := r.processIncomeReminders(ctx, transactions, user, periods); err != nil {
 		r.logger.ErrorContext(ctx, "failed to process income reminders", err)
 	}
 
@@ -99,58 +98,58 @@ func (r *recordReminderCalculateUsecase) processExpenseReminders(
 	ctx context.Context,
 	allTransactions []*entities.Transaction,
 	user *entities.User,
-	now time.Time,
-	loc *time.Location,
+	periods *entities.PeriodResolver,
 ) error {
 	// Filter: Last 14 days, Withdrawal, Weekday matches Now
-	cutoff := now.Add(-14 * 24 * time.Hour)
+	now, loc := periods.Now(), periods.Location()
+	recent := periods.LastDays(14)
 	var filtered []*entities.Transaction
 
 	for _, t := range allTransactions {
 		tTime := t.CreatedAt.In(loc)
 		if t.Type == entities.Withdrawal &&
-			tTime.After(cutoff) &&
+			recent.Contains(tTime) &&
 			tTime.Weekday() == now.Weekday() {
 			filtered = append(filtered, t)
 		}
 	}
 
-	return r.scheduleReminders(ctx, filtered, user, now, loc, false)
+	return r.scheduleReminders(ctx, filtered, user, periods, false)
 }
 
 func (r *recordReminderCalculateUsecase) processIncomeReminders(
 	ctx context.Context,
 	allTransactions []*entities.Transaction,
 	user *entities.User,
-	now time.Time,
-	loc *time.Location,
+	periods *entities.PeriodResolver,
 ) error {
-	// Filter: Last 60 days (already fetched), Deposit, Day matches Now
-	// Note: We use the already fetched range which is 60 days.
+	// Deposits recurring on today's day of month over the last 60 days
+	// (already fetched), the same detector the cash-flow forecast uses.
+	now, loc := periods.Now(), periods.Location()
+
+	flows, _ := incomeRecurring.Detect(allTransactions, loc)
 
 	var filtered []*entities.Transaction
-	for _, t := range allTransactions {
-		tTime := t.CreatedAt.In(loc)
-		if t.Type == entities.Deposit &&
-			tTime.Day() == now.Day() {
-			filtered = append(filtered, t)
+	for _, flow := range flows {
+		if flow.Type == entities.Deposit && flow.DueOn(now) {
+			filtered = append(filtered, flow.Transactions...)
 		}
 	}
 
-	return r.scheduleReminders(ctx, filtered, user, now, loc, true)
+	return r.scheduleReminders(ctx, filtered, user, periods, true)
 }
 
 func (r *recordReminderCalculateUsecase) scheduleReminders(
 	ctx context.Context,
 	transactions []*entities.Transaction,
 	user *entities.User,
-	now time.Time,
-	loc *time.Location,
+	periods *entities.PeriodResolver,
 	isIncome bool,
 ) error {
 	if len(transactions) == 0 {
 		return nil
 	}
+	now, loc := periods.Now(), periods.Location()
 
 	// Calculate peak 2-hour windows
 	// Map window start hour (0, 2, ..., 22) -> count
@@ -59,10 +59,15 @@ func (r *recordReminderSendUsecase) RecordReminderSend(ctx context.Context, user
 		}
 	}
 
-	startOfHour := time.Now().Truncate(time.Hour)
-	endOfHour := startOfHour.Add(time.Hour)
+	user, err := r.userRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		return err
+	}
+
+	// The local hour, zones like +05:30 are off the UTC hour grid.
+	hour := user.Periods().Hour()
 
-	transactions, err := r.transactionsRepo.GetAllBetween(ctx, input.userID, startOfHour, endOfHour)
+	transactions, err := r.transactionsRepo.GetAllBetween(ctx, input.userID, hour.From, hour.To)
 	if err != nil {
 		return err
 	}
@@ -72,11 +77,6 @@ func (r *recordReminderSendUsecase) RecordReminderSend(ctx context.Context, user
 		return nil
 	}
 
-	user, err := r.userRepo.FindByID(ctx, input.userID)
-	if err != nil {
-		return err
-	}
-
 	if err := r.telegramBotService.SendMessage(ctx, &ports.SendMessageRequest{
 		UserID:    user.TGUserID,
 		Text:      text,
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+)
+
+// detailsCacheKey identifies a details answer: the text, everything of the
+// user the prompt mentions, the prompt version and the model.
+func detailsCacheKey(model string, user *entities.User, accounts []*entities.Account, text string) string {
+	parts := []string{user.CurrencyCode.String(), user.LanguageCode.String(), user.Timezone}
+	for _, account := range accounts {
+		parts = append(parts, account.ID.String()+"="+account.Name)
+	}
+	// the user context comes first, the account order does not matter
+	sort.Strings(parts[3:])
+
+	return fmt.Sprintf("details:%s:%s:%s:%s", PromptVersion, model, hashOf(parts...), hashOf(normalizeText(text)))
+}
+
+// classifyCacheKey identifies a classification answer: the transactions,
+// the categories offered, the prompt version and the model. Users seeing the
+// same categories share entries.
+func classifyCacheKey(model string, catalog []CategoryInfo, items []ClassificationItem) string {
+	var categories []string
+	for _, category := range catalog {
+		categories = append(categories, fmt.Sprintf("%d=%s", category.ID, category.Name))
+		for _, sub := range category.Subcategories {
+			categories = append(categories, fmt.Sprintf("%d.%d=%s", category.ID, sub.ID, sub.Name))
+		}
+	}
+	sort.Strings(categories)
+
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, normalizeText(item.Text), normalizeText(item.Note), normalizeText(item.Merchant))
+	}
+
+	return fmt.Sprintf("classify:%s:%s:%s:%s", PromptVersion, model, hashOf(categories...), hashOf(texts...))
+}
+
+// normalizeText folds case and whitespace, "Taxi  20000" and "taxi 20000"
+// get the same answer.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func hashOf(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// detailsCacheable leaves out answers below minConfidence and those with a
+// date, which may be relative to when the text was sent.
+func detailsCacheable(result TransactionDetailsListResult, minConfidence float64) bool {
+	if len(result.Transactions) == 0 {
+		return false
+	}
+	for _, trn := range result.Transactions {
+		if trn.Confidence < minConfidence || trn.PerformedAt != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// classifyCacheable requires every transaction to be classified with at
+// least minConfidence.
+func classifyCacheable(categories []CategoryClassificationResult, minConfidence float64) bool {
+	for _, category := range categories {
+		if category.Confidence < minConfidence {
+			return false
+		}
+	}
+	return true
+}
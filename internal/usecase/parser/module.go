@@ -5,6 +5,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 )
 
@@ -18,21 +19,37 @@ type Module struct {
 	Command Command
 }
 
+// Models is the model asked for each task, a provider may map it to one it
+// serves.
+type Models struct {
+	Classification string
+	Details        string
+	OCRCleanup     string
+	Transcription  string
+}
+
 func NewModule(
 	logger *logger.Logger,
+	txManager postgres.TxManager,
 	llmClient ports.LLMProvider,
+	models Models,
 	ocrProvider ports.OCRProvider,
 	usersRepo entities.UserRepository,
 	accountsRepo entities.AccountRepository,
 	categoriesRepo entities.CategoryRepository,
 	subcategoriesRepo entities.SubcategoryRepository,
 	fxRatesProvider ports.FXRatesProvider,
+	draftsRepo entities.TransactionDraftRepository,
+	parseCache ports.ParseCache,
+	cacheMinConfidence float64,
+	usageRepo entities.LLMUsageRepository,
+	quotas map[entities.Plan]entities.PlanQuota,
 ) *Module {
 	return &Module{
 		Command: Command{
-			parseTextUsecase:  NewParseTextUsecase(2*time.Minute, logger, llmClient, usersRepo, accountsRepo, categoriesRepo, subcategoriesRepo, fxRatesProvider),
-			parseAudioUsecase: NewParseAudioUsecase(2*time.Minute, logger, llmClient, usersRepo, accountsRepo, categoriesRepo, subcategoriesRepo, fxRatesProvider),
-			parseImageUsecase: NewParseImageUsecase(2*time.Minute, logger, llmClient, ocrProvider, usersRepo, accountsRepo, categoriesRepo, subcategoriesRepo, fxRatesProvider),
+			parseTextUsecase:  NewParseTextUsecase(2*time.Minute, logger, txManager, llmClient, models, usersRepo, accountsRepo, categoriesRepo, subcategoriesRepo, fxRatesProvider, draftsRepo, parseCache, cacheMinConfidence, usageRepo, quotas),
+			parseAudioUsecase: NewParseAudioUsecase(2*time.Minute, logger, txManager, llmClient, models, usersRepo, accountsRepo, categoriesRepo, subcategoriesRepo, fxRatesProvider, draftsRepo, parseCache, cacheMinConfidence, usageRepo, quotas),
+			parseImageUsecase: NewParseImageUsecase(2*time.Minute, logger, txManager, llmClient, models, ocrProvider, usersRepo, accountsRepo, categoriesRepo, subcategoriesRepo, fxRatesProvider, draftsRepo, parseCache, cacheMinConfidence, usageRepo, quotas),
 		},
 	}
 }
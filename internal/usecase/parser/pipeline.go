@@ -0,0 +1,439 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"github.com/shogo82148/pointer"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ParseView lists the transactions found in a message, a voice note or a
+// receipt. Text is what they were read from, the transcription or the
+// cleaned up receipt for audio and images.
+type ParseView struct {
+	Text         string                 `json:"text"`
+	Transactions []TransactionCandidate `json:"transactions"`
+}
+
+// TransactionCandidate is one transaction as the client would send it to
+// POST /transactions. Amount is in the user's currency, OriginalAmount keeps
+// what was said when it was another one.
+type TransactionCandidate struct {
+	AccountID        *string    `json:"account_id,omitempty"`
+	Type             string     `json:"type"`
+	Amount           float64    `json:"amount"`
+	Currency         string     `json:"currency,omitempty"`
+	OriginalAmount   *float64   `json:"original_amount,omitempty"`
+	OriginalCurrency *string    `json:"original_currency,omitempty"`
+	FxRate           *float64   `json:"fx_rate,omitempty"`
+	CategoryID       *int       `json:"category_id,omitempty"`
+	SubcategoryID    *int       `json:"subcategory_id,omitempty"`
+	Note             string     `json:"note,omitempty"`
+	Merchant         string     `json:"merchant,omitempty"`
+	PerformedAt      *time.Time `json:"performed_at,omitempty"`
+	Confidence       float64    `json:"confidence"`
+	// DraftID and ExpiresAt are set when the candidate was saved as a draft
+	// to confirm, edit or reject later.
+	DraftID   *string    `json:"draft_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type TransactionDetailsResult struct {
+	Text        string     `json:"text"`
+	Type        string     `json:"type"`
+	Amount      float64    `json:"amount"`
+	Currency    string     `json:"currency,omitempty"`
+	AccountID   *string    `json:"account_id,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	Merchant    *string    `json:"merchant,omitempty"`
+	PerformedAt *time.Time `json:"performed_at,omitempty"`
+	Confidence  float64    `json:"confidence"`
+}
+
+type TransactionDetailsListResult struct {
+	Transactions []TransactionDetailsResult `json:"transactions"`
+}
+
+// CategoryClassificationResult is the category of the transaction at Index
+// in the list sent to the classifier.
+type CategoryClassificationResult struct {
+	Index         int     `json:"index"`
+	CategoryID    *int    `json:"category_id"`
+	SubcategoryID *int    `json:"subcategory_id"`
+	Confidence    float64 `json:"confidence"`
+}
+
+type CategoryClassificationListResult struct {
+	Items []CategoryClassificationResult `json:"items"`
+}
+
+// pipeline turns text into candidate transactions, the part every parse
+// usecase shares once it has the text. Details are extracted first, as a
+// list, then all of them are classified in one call.
+type pipeline struct {
+	logger            *logger.Logger
+	llmClient         ports.LLMProvider
+	models            Models
+	accountsRepo      entities.AccountRepository
+	categoriesRepo    entities.CategoryRepository
+	subcategoriesRepo entities.SubcategoryRepository
+	fxRatesProvider   ports.FXRatesProvider
+	draftsRepo        entities.TransactionDraftRepository
+	parseCache        ports.ParseCache
+	// cacheMinConfidence is the lowest confidence of an answer kept in
+	// parseCache.
+	cacheMinConfidence float64
+}
+
+func newPipeline(
+	logger *logger.Logger,
+	llmClient ports.LLMProvider,
+	models Models,
+	accountsRepo entities.AccountRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	fxRatesProvider ports.FXRatesProvider,
+	draftsRepo entities.TransactionDraftRepository,
+	parseCache ports.ParseCache,
+	cacheMinConfidence float64,
+) *pipeline {
+	return &pipeline{
+		logger:             logger,
+		llmClient:          llmClient,
+		models:             models,
+		accountsRepo:       accountsRepo,
+		categoriesRepo:     categoriesRepo,
+		subcategoriesRepo:  subcategoriesRepo,
+		fxRatesProvider:    fxRatesProvider,
+		draftsRepo:         draftsRepo,
+		parseCache:         parseCache,
+		cacheMinConfidence: cacheMinConfidence,
+	}
+}
+
+// run parses text into candidates. With saveDrafts each of them is also kept
+// as a pending draft of the given source.
+func (p *pipeline) run(ctx context.Context, user *entities.User, text string, source entities.DraftSource, saveDrafts bool) (*ParseView, error) {
+	var catalog []CategoryInfo
+	var details TransactionDetailsListResult
+	var wg sync.WaitGroup
+	var errChan = make(chan error, 2)
+
+	// 1. Categories the classifier may pick from
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var err error
+		catalog, err = p.catalog(ctx, user)
+		if err != nil {
+			errChan <- err
+		}
+	}()
+
+	// 2. Details Extraction
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var err error
+		details, err = p.details(ctx, user, text)
+		if err != nil {
+			errChan <- err
+		}
+	}()
+
+	wg.Wait()
+	if len(errChan) > 0 {
+		return nil, <-errChan
+	}
+
+	otlp.Annotate(ctx, attribute.Int("parser.transactions", len(details.Transactions)))
+
+	result := &ParseView{
+		Text:         text,
+		Transactions: []TransactionCandidate{},
+	}
+	if len(details.Transactions) == 0 {
+		return result, nil
+	}
+
+	// 3. Category Classification
+	categories, err := p.classify(ctx, catalog, details.Transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge results, converting each currency once
+	rates := make(map[string]float64)
+	for i, detailsResult := range details.Transactions {
+		categoryResult := categories[i]
+		candidate := TransactionCandidate{
+			Type:          detailsResult.Type,
+			AccountID:     detailsResult.AccountID,
+			Note:          detailsResult.Note,
+			Merchant:      pointer.StringValue(detailsResult.Merchant),
+			PerformedAt:   detailsResult.PerformedAt,
+			CategoryID:    categoryResult.CategoryID,
+			SubcategoryID: categoryResult.SubcategoryID,
+			Confidence:    (detailsResult.Confidence + categoryResult.Confidence) / 2,
+			Amount:        detailsResult.Amount,
+			Currency:      user.CurrencyCode.String(),
+		}
+
+		if detailsResult.Currency != "" && detailsResult.Currency != user.CurrencyCode.String() {
+			fxRate, ok := rates[detailsResult.Currency]
+			if !ok {
+				fxRate, err = p.fxRatesProvider.GetRate(ctx, detailsResult.Currency, user.CurrencyCode.String())
+				if err != nil {
+					p.logger.ErrorContext(ctx, "failed to get fx rate", err)
+					return nil, err
+				}
+				rates[detailsResult.Currency] = fxRate
+			}
+
+			candidate.Amount = detailsResult.Amount * fxRate
+			candidate.OriginalAmount = pointer.Float64(detailsResult.Amount)
+			candidate.OriginalCurrency = pointer.String(detailsResult.Currency)
+			candidate.FxRate = pointer.Float64(fxRate)
+		}
+
+		result.Transactions = append(result.Transactions, candidate)
+	}
+
+	if saveDrafts {
+		if err := p.saveDrafts(ctx, user, source, result.Transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// saveDrafts stores the candidates as pending drafts and sets their ids in
+// place.
+func (p *pipeline) saveDrafts(ctx context.Context, user *entities.User, source entities.DraftSource, candidates []TransactionCandidate) error {
+	drafts := make([]*entities.TransactionDraft, 0, len(candidates))
+	for _, candidate := range candidates {
+		draft, err := entities.NewTransactionDraft(user.ID, source, entities.TrnType(candidate.Type))
+		if err != nil {
+			// the model can return a type we don't know, keep it editable
+			draft, err = entities.NewTransactionDraft(user.ID, source, entities.Withdrawal)
+			if err != nil {
+				return err
+			}
+		}
+
+		// an amount the model could not read is left at zero for the user to
+		// fill in, confirming refuses it until then
+		draft.CurrencyCode = user.CurrencyCode
+		if candidate.Amount > 0 {
+			if err := draft.SetAmountMajor(candidate.Amount, user.CurrencyCode); err != nil {
+				p.logger.ErrorContext(ctx, "failed to set draft amount", err)
+				return inerr.NewErrValidation("amount", err.Error())
+			}
+			if candidate.OriginalAmount != nil && candidate.OriginalCurrency != nil && candidate.FxRate != nil {
+				if err := draft.SetOriginalAmountMajor(*candidate.OriginalAmount, entities.Currency(*candidate.OriginalCurrency), *candidate.FxRate); err != nil {
+					p.logger.ErrorContext(ctx, "failed to set draft original amount", err)
+					return inerr.NewErrValidation("original_amount", err.Error())
+				}
+			}
+		}
+
+		if candidate.AccountID != nil {
+			if accountID, err := uuid.Parse(*candidate.AccountID); err == nil {
+				draft.AccountID = &accountID
+			}
+		}
+
+		draft.CategoryID = candidate.CategoryID
+		draft.SubcategoryID = candidate.SubcategoryID
+		draft.Note = candidate.Note
+		draft.SetMerchant(candidate.Merchant)
+		draft.Confidence = candidate.Confidence
+		if candidate.PerformedAt != nil {
+			draft.PerformedAt = *candidate.PerformedAt
+		}
+
+		drafts = append(drafts, draft)
+	}
+
+	if err := p.draftsRepo.SaveBatch(ctx, drafts); err != nil {
+		p.logger.ErrorContext(ctx, "failed to save drafts", err)
+		return err
+	}
+
+	for i, draft := range drafts {
+		candidates[i].DraftID = pointer.String(draft.ID.String())
+		candidates[i].ExpiresAt = pointer.Time(draft.ExpiresAt)
+	}
+
+	otlp.Annotate(ctx, attribute.Int("parser.drafts", len(drafts)))
+
+	return nil
+}
+
+// catalog lists the categories visible to the user with their
+// subcategories. Hidden ones are left out so the model can't pick them.
+func (p *pipeline) catalog(ctx context.Context, user *entities.User) ([]CategoryInfo, error) {
+	categories, err := p.categoriesRepo.FindAll(ctx, user.ID)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to get categories", err)
+		return nil, err
+	}
+
+	subcategories, err := p.subcategoriesRepo.FindAll(ctx, user.ID)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to get subcategories", err)
+		return nil, err
+	}
+
+	var catInfos []CategoryInfo
+	for _, cat := range categories {
+		if cat.Hidden {
+			continue
+		}
+		info := CategoryInfo{
+			ID:   cat.ID.Int(),
+			Name: cat.GetName(entities.EN),
+		}
+		for _, sub := range subcategories {
+			if sub.CategoryID == cat.ID.Int() && !sub.Hidden {
+				info.Subcategories = append(info.Subcategories, SubcategoryInfo{
+					ID:   sub.ID,
+					Name: sub.GetName(entities.EN),
+				})
+			}
+		}
+		catInfos = append(catInfos, info)
+	}
+
+	return catInfos, nil
+}
+
+func (p *pipeline) details(ctx context.Context, user *entities.User, text string) (TransactionDetailsListResult, error) {
+	var result TransactionDetailsListResult
+
+	accounts, err := p.accountsRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to get accounts", err)
+		return result, err
+	}
+
+	userPayment := UserPayment{
+		Language:    user.LanguageCode.String(),
+		Currency:    user.CurrencyCode.String(),
+		Timezone:    user.Timezone,
+		PaymentText: text,
+	}
+
+	accountIDs := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		userPayment.Accounts = append(userPayment.Accounts, UserPaymentAccount{
+			ID:   account.ID.String(),
+			Name: account.Name,
+		})
+		accountIDs[account.ID.String()] = true
+	}
+
+	cacheKey := detailsCacheKey(p.models.Details, user, accounts, text)
+	if ok, err := p.parseCache.Get(ctx, cacheKey, &result); err != nil {
+		p.logger.ErrorContext(ctx, "failed to get cached details", err)
+	} else if ok {
+		return result, nil
+	}
+
+	prompt := NewTransactionDetailsPrompt(userPayment)
+	problems, err := p.complete(ctx, p.models.Details, TransactionDetailsSystemMessage, prompt, &result, func() []string {
+		return checkDetails(&result, accountIDs)
+	})
+	if errors.Is(err, errUnparsable) {
+		// nothing usable came back, report no transactions rather than fail
+		p.logger.ErrorContext(ctx, "failed to parse details", err, "problems", problems)
+		return TransactionDetailsListResult{}, nil
+	}
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to get details", err)
+		return result, err
+	}
+
+	if len(problems) > 0 {
+		p.logger.WarnContext(ctx, "details are still invalid after repairs", "problems", problems)
+		sanitizeDetails(&result, accountIDs)
+		return result, nil
+	}
+
+	if detailsCacheable(result, p.cacheMinConfidence) {
+		if err := p.parseCache.Set(ctx, cacheKey, result); err != nil {
+			p.logger.ErrorContext(ctx, "failed to cache details", err)
+		}
+	}
+
+	return result, nil
+}
+
+// classify returns the category of every transaction, in their order.
+// Transactions the model skipped are left uncategorized.
+func (p *pipeline) classify(ctx context.Context, catalog []CategoryInfo, transactions []TransactionDetailsResult) ([]CategoryClassificationResult, error) {
+	items := make([]ClassificationItem, 0, len(transactions))
+	for i, transaction := range transactions {
+		items = append(items, ClassificationItem{
+			Index:    i,
+			Text:     transaction.Text,
+			Note:     transaction.Note,
+			Merchant: pointer.StringValue(transaction.Merchant),
+		})
+	}
+
+	categories := make([]CategoryClassificationResult, len(transactions))
+	cacheKey := classifyCacheKey(p.models.Classification, catalog, items)
+	if ok, err := p.parseCache.Get(ctx, cacheKey, &categories); err != nil {
+		p.logger.ErrorContext(ctx, "failed to get cached categories", err)
+	} else if ok && len(categories) == len(transactions) {
+		return categories, nil
+	}
+	categories = make([]CategoryClassificationResult, len(transactions))
+
+	var result CategoryClassificationListResult
+	prompt := NewCategoryClassificationPrompt(catalog, items)
+	problems, err := p.complete(ctx, p.models.Classification, CategoryClassificationSystemMessage, prompt, &result, func() []string {
+		return checkClassification(&result, catalog, len(transactions))
+	})
+	if errors.Is(err, errUnparsable) {
+		// the transactions are still worth returning, uncategorized
+		p.logger.ErrorContext(ctx, "failed to parse categories", err, "problems", problems)
+		return categories, nil
+	}
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to get categories", err)
+		return nil, err
+	}
+
+	for _, item := range result.Items {
+		if item.Index < 0 || item.Index >= len(categories) {
+			continue
+		}
+		categories[item.Index] = item
+	}
+
+	if len(problems) > 0 {
+		p.logger.WarnContext(ctx, "categories are still invalid after repairs", "problems", problems)
+		sanitizeClassification(categories, catalog)
+		return categories, nil
+	}
+
+	if classifyCacheable(categories, p.cacheMinConfidence) {
+		if err := p.parseCache.Set(ctx, cacheKey, categories); err != nil {
+			p.logger.ErrorContext(ctx, "failed to cache categories", err)
+		}
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+)
+
+// fakeLLM answers chat completions from a script, one answer per call, and
+// keeps the messages it was sent.
+type fakeLLM struct {
+	answers  []string
+	err      error
+	messages []string
+}
+
+func (f *fakeLLM) ChatCompletion(ctx context.Context, model, system, message string) (string, ports.LLMUsage, error) {
+	f.messages = append(f.messages, message)
+	if f.err != nil {
+		return "", ports.LLMUsage{}, f.err
+	}
+	if len(f.messages) > len(f.answers) {
+		return "", ports.LLMUsage{}, errors.New("no answer left in the script")
+	}
+	return f.answers[len(f.messages)-1], ports.LLMUsage{PromptTokens: 100, CompletionTokens: 10}, nil
+}
+
+func (f *fakeLLM) AudioToText(ctx context.Context, model, filePath, language string) (string, ports.LLMUsage, error) {
+	return "", ports.LLMUsage{}, errors.New("not scripted")
+}
+
+const (
+	validAnswer   = "```json\n{\"transactions\":[{\"text\":\"taxi 25k\",\"type\":\"withdrawal\",\"amount\":25000,\"confidence\":0.9}]}\n```"
+	invalidAnswer = `{"transactions":[{"text":"taxi 25k","type":"transfer","amount":25000,"confidence":0.9}]}`
+	garbageAnswer = `I could not find any transaction in this message.`
+)
+
+func completeDetails(t *testing.T, llm *fakeLLM) (TransactionDetailsListResult, []string, error) {
+	t.Helper()
+
+	p := &pipeline{llmClient: llm}
+	ctx, m := withMeter(context.Background())
+
+	var result TransactionDetailsListResult
+	problems, err := p.complete(ctx, "model", "system", "PROMPT", &result, func() []string {
+		return checkDetails(&result, nil)
+	})
+
+	if want := 100 * len(llm.messages); err == nil && m.usage.PromptTokens != want {
+		t.Errorf("metered %d prompt tokens, want %d", m.usage.PromptTokens, want)
+	}
+
+	return result, problems, err
+}
+
+func TestCompleteRepairsWithinLimit(t *testing.T) {
+	for repairs := 0; repairs <= maxRepairs; repairs++ {
+		llm := &fakeLLM{}
+		for i := 0; i < repairs; i++ {
+			// alternate between a wrong value and an answer that is no JSON
+			if i%2 == 0 {
+				llm.answers = append(llm.answers, invalidAnswer)
+			} else {
+				llm.answers = append(llm.answers, garbageAnswer)
+			}
+		}
+		llm.answers = append(llm.answers, validAnswer)
+
+		result, problems, err := completeDetails(t, llm)
+		if err != nil || len(problems) != 0 {
+			t.Fatalf("%d repairs: complete = %q, %v; want no problems", repairs, problems, err)
+		}
+		if len(llm.messages) != repairs+1 {
+			t.Errorf("%d repairs: model was asked %d times", repairs, len(llm.messages))
+		}
+		if len(result.Transactions) != 1 || result.Transactions[0].Type != "withdrawal" {
+			t.Errorf("%d repairs: result = %+v", repairs, result)
+		}
+
+		// every repair quotes the original prompt, the rejected answer and
+		// why it was rejected
+		for i, message := range llm.messages[1:] {
+			if !strings.HasPrefix(message, "PROMPT") || !strings.Contains(message, llm.answers[i]) {
+				t.Errorf("%d repairs: repair %d does not quote the prompt and the answer:\n%s", repairs, i+1, message)
+			}
+		}
+		if repairs > 0 && !strings.Contains(llm.messages[1], `transactions[0].type must be "deposit" or "withdrawal", got "transfer"`) {
+			t.Errorf("%d repairs: first repair does not list the problem:\n%s", repairs, llm.messages[1])
+		}
+		if repairs > 1 && !strings.Contains(llm.messages[2], "the answer is not valid JSON of the requested format") {
+			t.Errorf("%d repairs: second repair does not list the problem:\n%s", repairs, llm.messages[2])
+		}
+	}
+}
+
+func TestCompleteGivesUpOnUnparsable(t *testing.T) {
+	llm := &fakeLLM{}
+	for i := 0; i <= maxRepairs+1; i++ {
+		llm.answers = append(llm.answers, garbageAnswer)
+	}
+
+	_, problems, err := completeDetails(t, llm)
+	if !errors.Is(err, errUnparsable) {
+		t.Fatalf("complete = %v, want errUnparsable", err)
+	}
+	if len(llm.messages) != maxRepairs+1 {
+		t.Errorf("model was asked %d times, want %d", len(llm.messages), maxRepairs+1)
+	}
+	if len(problems) != 1 {
+		t.Errorf("problems = %q, want the decoding error", problems)
+	}
+}
+
+func TestCompleteReturnsProblemsLeft(t *testing.T) {
+	llm := &fakeLLM{}
+	// the last answer decodes, the earlier garbage must not decide the outcome
+	for i := 0; i < maxRepairs; i++ {
+		llm.answers = append(llm.answers, garbageAnswer)
+	}
+	llm.answers = append(llm.answers, invalidAnswer, validAnswer)
+
+	result, problems, err := completeDetails(t, llm)
+	if err != nil {
+		t.Fatalf("complete = %v, want the problems for the caller to sanitize", err)
+	}
+	if len(llm.messages) != maxRepairs+1 {
+		t.Errorf("model was asked %d times, want %d", len(llm.messages), maxRepairs+1)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "type") {
+		t.Errorf("problems = %q, want the invalid type", problems)
+	}
+	if len(result.Transactions) != 1 || result.Transactions[0].Type != "transfer" {
+		t.Errorf("result = %+v, want the last answer", result)
+	}
+}
+
+func TestCompleteFailsOnProviderError(t *testing.T) {
+	providerErr := errors.New("rate limited")
+	llm := &fakeLLM{err: providerErr}
+
+	_, _, err := completeDetails(t, llm)
+	if !errors.Is(err, providerErr) {
+		t.Fatalf("complete = %v, want the provider error", err)
+	}
+	if len(llm.messages) != 1 {
+		t.Errorf("model was asked %d times, want 1", len(llm.messages))
+	}
+}
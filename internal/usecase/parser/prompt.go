@@ -5,6 +5,10 @@ import (
 	"time"
 )
 
+// PromptVersion is part of the parse cache keys, bump it whenever a prompt
+// changes what the model answers.
+const PromptVersion = "3"
+
 type CategoryInfo struct {
 	ID            int
 	Name          string
@@ -33,15 +37,15 @@ const CategoryClassificationSystemMessage = `
 You are a deterministic financial transaction category classifier used in a personal finance app.
 
 TASK
-Given a short transaction text (possibly noisy, multilingual, slang), choose the single best category_id and (optionally) subcategory_id.
+Given a numbered list of transactions taken from one message (possibly noisy, multilingual, slang), choose the single best category_id and (optionally) subcategory_id for EACH of them.
 
 INPUT 
 1) AVAILABLE CATEGORIES & SUBCATEGORIES:
 - Category ID <number>: <string>
   - Subcategory ID <number>: <string>
 
-2) INPUT TEXT:
-<TEXT>
+2) TRANSACTIONS:
+- Index <number>: <text> (note: <string>, merchant: <string>)
 
 
 OUTPUT (STRICT)
@@ -49,21 +53,37 @@ Return ONLY valid JSON. No markdown. No extra keys. No explanations.
 
 JSON SCHEMA
 {
-	"category_id": number,
-	"subcategory_id": number|null,
-	"confidence": number
+	"items": [
+		{
+			"index": number,
+			"category_id": number,
+			"subcategory_id": number|null,
+			"confidence": number
+		}
+	]
 }
 
 FIELD RULES
-1) category_id:
+1) items:
+	- exactly one item per input transaction, with its index.
+2) category_id:
 	- must be one of the provided categories.
-2) subcategory_id:
+3) subcategory_id:
 	- must be one of the provided subcategories of the chosen category, otherwise null.
-3) confidence:
+4) confidence:
 	- must be a floating point number between 0 and 1.
 `
 
-func NewCategoryClassificationPrompt(categories []CategoryInfo, text string, language string) string {
+// ClassificationItem is one transaction sent to the classifier, Text is the
+// part of the message it was read from.
+type ClassificationItem struct {
+	Index    int
+	Text     string
+	Note     string
+	Merchant string
+}
+
+func NewCategoryClassificationPrompt(categories []CategoryInfo, items []ClassificationItem) string {
 	cats := ""
 	for _, c := range categories {
 		cats += fmt.Sprintf("- Category ID %d: %s\n", c.ID, c.Name)
@@ -72,36 +92,57 @@ func NewCategoryClassificationPrompt(categories []CategoryInfo, text string, lan
 		}
 	}
 
+	transactions := ""
+	for _, item := range items {
+		transactions += fmt.Sprintf("- Index %d: %s (note: %s, merchant: %s)\n", item.Index, item.Text, item.Note, item.Merchant)
+	}
+
 	return fmt.Sprintf(`
 AVAILABLE CATEGORIES & SUBCATEGORIES:
 %s
 
-INPUT TEXT:
+TRANSACTIONS:
 %s
-`, cats, text)
+`, cats, transactions)
 }
 
 const TransactionDetailsSystemMessage = `
 You are a deterministic financial transaction parsing engine.
 
 TASK
-Extract exactly ONE transaction from the given text using the provided user context.
+Extract EVERY transaction from the given text using the provided user context.
+One message may mention several payments, e.g. "coffee 25k, taxi 30k and lunch 60k" is three transactions.
 
 OUTPUT (STRICT)
 Return ONLY valid JSON. No markdown. No commentary. No extra keys.
 
 JSON SCHEMA (must match exactly; include all keys)
 {
-  "type": "deposit"|"withdrawal",
-  "amount": number,
-  "currency": string,
-  "account_id": string|null,
-  "performed_at": string|null,
-  "note": string,
-  "confidence": number
+  "transactions": [
+    {
+      "text": string,
+      "type": "deposit"|"withdrawal",
+      "amount": number,
+      "currency": string,
+      "account_id": string|null,
+      "performed_at": string|null,
+      "note": string,
+      "merchant": string|null,
+      "confidence": number
+    }
+  ]
 }
 
+SPLITTING RULES
+- One entry per separate money movement, in the order they appear in the text.
+- A receipt is ONE transaction for its total. NEVER make one entry per receipt line item.
+- Context said once applies to every transaction it clearly covers ("from Main Card: coffee 25k, taxi 30k" -> both use Main Card).
+- If the text contains no transaction, return "transactions": [].
+
 FIELD RULES
+0) text:
+- The exact part of the input text this transaction was read from.
+
 1) type:
 - "deposit" for income/received/salary/refund/incoming
 - "withdrawal" for paid/bought/spent/fee/tax/subscription/outgoing
@@ -109,8 +150,9 @@ FIELD RULES
 
 2) amount:
 - Positive float number.
-- Extract the primary amount from the text.
-- Support shorthand: "k"=thousand, "m"=million if present in input language patterns.
+- Extract the primary amount of this transaction.
+- Support shorthand: "k"=thousand, "m"=million, "тыс"=thousand if present in input language patterns.
+- A bare number next to amounts in thousands is in thousands too ("taxi 30k and lunch 60" -> 60000).
 - Ignore currency symbols/words in amount extraction.
 
 3) currency:
@@ -133,8 +175,13 @@ FIELD RULES
 - Short, meaningful purpose/merchant.
 - If receipt-like summary: include merchant + 2–4 key items if clearly present.
 
-7) confidence:
-- Reflect overall clarity (type + amount + currency + time + account).
+7) merchant:
+- Store, company or service name the money was paid to / received from, as written in the input.
+- Set ONLY if a merchant is explicitly present (receipt header, shop/service name).
+- Otherwise -> null. NEVER invent a merchant from the category.
+
+8) confidence:
+- Reflect the clarity of this transaction (type + amount + currency + time + account).
 - Lower confidence if any major field is inferred/ambiguous.
 
 EXAMPLES (STYLE, STRUCTURE & BEHAVIOR REFERENCE ONLY)
@@ -153,63 +200,102 @@ TRANSACTION TEXT:
 Assosy kartadan 755k бензин
 
 OUTPUT:
-{ 
-  "type":"withdrawal",
-  "amount":755000, 
-  "currency":"UZS", 
-  "account_id":"15372648-53b3-4415-897e-fb0998798807",
-  "note":"Benzin",
-  "confidence":0.93,
-  "performed_at":null
+{
+  "transactions":[
+    {
+      "text":"Assosy kartadan 755k бензин",
+      "type":"withdrawal",
+      "amount":755000,
+      "currency":"UZS",
+      "account_id":"15372648-53b3-4415-897e-fb0998798807",
+      "note":"Benzin",
+      "merchant":null,
+      "confidence":0.93,
+      "performed_at":null
+    }
+  ]
 }
 
 
-Example 2 — Currency explicitly different from user currency
+Example 2 — Several transactions in one message
 
 USER CONTEXT:
-- Language: EN
+- Language: RU
 - Currency: UZS
 - Accounts:
   - e215c04d-36d7-481d-9783-2d023eb9f52f → Main Card
 - Current datetime: 2025-12-10T10:00:00Z
 
 TRANSACTION TEXT:
-Paid hosting 50.67 USD
+кофе 25 тыс, такси 30 тыс и обед 60
 
 OUTPUT:
 {
-  "type":"withdrawal",
-  "amount":50.67,
-  "currency":"USD",
-  "account_id":null,
-  "note":"Hosting payment",
-  "confidence":0.92,
-  "performed_at":null
+  "transactions":[
+    {
+      "text":"кофе 25 тыс",
+      "type":"withdrawal",
+      "amount":25000,
+      "currency":"UZS",
+      "account_id":null,
+      "note":"Кофе",
+      "merchant":null,
+      "confidence":0.92,
+      "performed_at":null
+    },
+    {
+      "text":"такси 30 тыс",
+      "type":"withdrawal",
+      "amount":30000,
+      "currency":"UZS",
+      "account_id":null,
+      "note":"Такси",
+      "merchant":null,
+      "confidence":0.92,
+      "performed_at":null
+    },
+    {
+      "text":"обед 60",
+      "type":"withdrawal",
+      "amount":60000,
+      "currency":"UZS",
+      "account_id":null,
+      "note":"Обед",
+      "merchant":null,
+      "confidence":0.8,
+      "performed_at":null
+    }
+  ]
 }
 
 
-Example 3 — No account, no currency override
+Example 3 — Currency explicitly different from user currency
 
 USER CONTEXT:
-- Language: UZ
-- Job title: Truck Driver
+- Language: EN
 - Currency: UZS
 - Accounts:
-  - 15372648-53b3-4415-897e-fb0998798807 → Assosy
-- Current datetime: 2025-12-14T19:26:00Z
+  - e215c04d-36d7-481d-9783-2d023eb9f52f → Main Card
+- Current datetime: 2025-12-10T10:00:00Z
 
 TRANSACTION TEXT:
-755k сум бензин
+Paid hosting 50.67 USD
 
 OUTPUT:
 {
-  "type":"withdrawal",
-  "amount":755000,
-  "currency":"UZS",
-  "account_id":null,
-  "note":"Benzin",
-  "confidence":0.90,
-  "performed_at":null
+  "transactions":[
+    {
+      "text":"Paid hosting 50.67 USD",
+      "type":"withdrawal",
+      "amount":50.67,
+      "currency":"USD",
+      "account_id":null,
+      "note":"Hosting payment",
+      "merchant":null,
+      "confidence":0.92,
+      "performed_at":null
+    }
+  ]
 }
 
 
@@ -217,7 +303,6 @@ Example 4 — Receipt with store name, no account mentioned
 
 USER CONTEXT:
 - Language: RU
-- Job title: Office Worker
 - Currency: UZS
 - Accounts:
   - e215c04d-36d7-481d-9783-2d023eb9f52f → Main Card
@@ -231,13 +316,19 @@ TOTAL 18000.60
 
 OUTPUT:
 {
-  "type":"withdrawal",
-  "amount":18000,
-  "currency":"UZS",
-  "account_id":null,
-  "note":"Magnum: milk, bread",
-  "confidence":0.94,
-  "performed_at":null
+  "transactions":[
+    {
+      "text":"MAGNUM TOTAL 18000.60",
+      "type":"withdrawal",
+      "amount":18000,
+      "currency":"UZS",
+      "account_id":null,
+      "note":"Magnum: milk, bread",
+      "merchant":"Magnum",
+      "confidence":0.94,
+      "performed_at":null
+    }
+  ]
 }
 
 `
@@ -281,3 +372,22 @@ Now clean and rewrite this receipt:
 %s
 `, ocr_text)
 }
+
+// NewRepairPrompt re-asks with the previous answer and what was wrong with
+// it, the original request is repeated since every call is stateless.
+func NewRepairPrompt(prompt string, response string, problems []string) string {
+	issues := ""
+	for _, problem := range problems {
+		issues += fmt.Sprintf("- %s\n", problem)
+	}
+
+	return fmt.Sprintf(`%s
+
+YOUR PREVIOUS ANSWER:
+%s
+
+IT WAS REJECTED BECAUSE:
+%s
+Fix these problems and answer again with the corrected JSON only, in the same format. Use only the IDs listed above, or null when none fits.
+`, prompt, response, issues)
+}
@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// meter adds up what one parse request spends. It travels in the context so
+// every model call down the pipeline is counted, repairs included.
+type meter struct {
+	mu    sync.Mutex
+	usage ports.LLMUsage
+	ocr   int
+}
+
+type meterKey struct{}
+
+func withMeter(ctx context.Context) (context.Context, *meter) {
+	m := &meter{}
+	return context.WithValue(ctx, meterKey{}, m), m
+}
+
+// addUsage counts a model call on the request's meter, if it has one.
+func addUsage(ctx context.Context, usage ports.LLMUsage) {
+	m, ok := ctx.Value(meterKey{}).(*meter)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.usage.PromptTokens += usage.PromptTokens
+	m.usage.CompletionTokens += usage.CompletionTokens
+	m.usage.AudioSeconds += usage.AudioSeconds
+}
+
+// addOCR counts an OCR call on the request's meter, if it has one.
+func addOCR(ctx context.Context) {
+	m, ok := ctx.Value(meterKey{}).(*meter)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ocr++
+}
+
+// usageEstimates is what a request of each operation is reserved for up
+// front, a little above a typical one. Receipts may take a second OCR pass.
+var usageEstimates = map[entities.UsageOperation]entities.LLMUsage{
+	entities.UsageText:  {PromptTokens: 2000, CompletionTokens: 500},
+	entities.UsageVoice: {PromptTokens: 2000, CompletionTokens: 500, AudioSeconds: 30},
+	entities.UsageImage: {PromptTokens: 3000, CompletionTokens: 800, OCRCalls: 1},
+}
+
+// metering reserves an estimate of a request against the quota of the
+// user's plan before it runs and settles it with what was spent after.
+// Reservations of concurrent requests count against each other, so only a
+// request spending above its estimate can overshoot, by the difference.
+type metering struct {
+	logger    *logger.Logger
+	txManager postgres.TxManager
+	usageRepo entities.LLMUsageRepository
+	quotas    map[entities.Plan]entities.PlanQuota
+}
+
+func newMetering(logger *logger.Logger, txManager postgres.TxManager, usageRepo entities.LLMUsageRepository, quotas map[entities.Plan]entities.PlanQuota) *metering {
+	return &metering{
+		logger:    logger,
+		txManager: txManager,
+		usageRepo: usageRepo,
+		quotas:    quotas,
+	}
+}
+
+// reserve fails with inerr.ErrQuotaExceeded once the user spent the daily
+// or the monthly quota of their plan, and records the estimate of the
+// request otherwise. Users are locked meanwhile so concurrent requests see
+// each other's reservations. A plan with no quota is unlimited and reserves
+// nothing.
+func (m *metering) reserve(ctx context.Context, user *entities.User, operation entities.UsageOperation) (*entities.LLMUsage, error) {
+	usage := entities.NewLLMUsage(user.ID, operation)
+
+	quota, ok := m.quotas[user.Plan]
+	if !ok {
+		return usage, nil
+	}
+
+	now := time.Now().In(user.Location())
+	dayFrom, dayTo := entities.UsageDay(now)
+	monthFrom, monthTo := entities.UsageMonth(now)
+
+	periods := []struct {
+		name   string
+		limits entities.UsageLimits
+		from   time.Time
+		to     time.Time
+	}{
+		{name: "daily", limits: quota.Daily, from: dayFrom, to: dayTo},
+		{name: "monthly", limits: quota.Monthly, from: monthFrom, to: monthTo},
+	}
+
+	estimate := usageEstimates[operation]
+
+	err := m.txManager.WithTx(ctx, func(ctx context.Context) error {
+		err := m.usageRepo.LockUser(ctx, user.ID)
+		if err != nil {
+			m.logger.ErrorContext(ctx, "failed to lock user usage", err)
+			return err
+		}
+
+		for _, period := range periods {
+			if period.limits == (entities.UsageLimits{}) {
+				continue
+			}
+
+			totals, err := m.usageRepo.GetTotals(ctx, user.ID, period.from, period.to)
+			if err != nil {
+				m.logger.ErrorContext(ctx, "failed to get usage totals", err, "period", period.name)
+				return err
+			}
+
+			if metric := period.limits.Exceeded(totals); metric != "" {
+				otlp.Event(ctx, "parser.quota_exceeded",
+					attribute.String("quota.period", period.name),
+					attribute.String("quota.metric", metric),
+				)
+				return inerr.NewErrQuotaExceeded(period.name, metric, period.to)
+			}
+		}
+
+		usage.PromptTokens = estimate.PromptTokens
+		usage.CompletionTokens = estimate.CompletionTokens
+		usage.AudioSeconds = estimate.AudioSeconds
+		usage.OCRCalls = estimate.OCRCalls
+
+		err = m.usageRepo.Save(ctx, usage)
+		if err != nil {
+			m.logger.ErrorContext(ctx, "failed to reserve llm usage", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// record settles the reservation with what the request spent, failed
+// requests included since their model calls are billed all the same. A
+// request that spent nothing, e.g. answered from the cache, gives its
+// reservation back. It runs after the request, so it gets a context of its
+// own.
+func (m *metering) record(ctx context.Context, usage *entities.LLMUsage, spent *meter) {
+	reserved := !usage.IsEmpty()
+
+	spent.mu.Lock()
+	usage.PromptTokens = spent.usage.PromptTokens
+	usage.CompletionTokens = spent.usage.CompletionTokens
+	usage.AudioSeconds = spent.usage.AudioSeconds
+	usage.OCRCalls = spent.ocr
+	spent.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	var err error
+	switch {
+	case !usage.IsEmpty():
+		err = m.usageRepo.Save(ctx, usage)
+	case reserved:
+		err = m.usageRepo.Delete(ctx, usage.ID)
+	}
+	if err != nil {
+		m.logger.ErrorContext(ctx, "failed to record llm usage", err, "user_id", usage.UserID.String())
+	}
+}
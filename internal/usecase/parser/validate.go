@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxRepairs bounds how many times an invalid answer is sent back to the
+// model with its problems before falling back to safe defaults.
+const maxRepairs = 2
+
+// errUnparsable means no answer matched the expected JSON shape, there is
+// nothing to fall back on.
+var errUnparsable = errors.New("llm response is not valid json")
+
+// complete asks the model and decodes the answer into dest. Answers that are
+// not the expected JSON or that check finds problems in are re-asked, up to
+// maxRepairs times. The problems left in the last answer are returned for the
+// caller to sanitize.
+func (p *pipeline) complete(ctx context.Context, model, system, prompt string, dest any, check func() []string) ([]string, error) {
+	var problems []string
+	var decoded bool
+
+	message := prompt
+	for attempt := 0; attempt <= maxRepairs; attempt++ {
+		if attempt > 0 {
+			otlp.Event(ctx, "parser.repair", attribute.Int("parser.attempt", attempt))
+		}
+
+		resp, usage, err := p.llmClient.ChatCompletion(ctx, model, system, message)
+		addUsage(ctx, usage)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = utils.CleanMarkdownJSON(resp)
+		if err := decodeStrict(resp, dest); err != nil {
+			decoded = false
+			problems = []string{fmt.Sprintf("the answer is not valid JSON of the requested format: %s", err)}
+		} else {
+			decoded = true
+			problems = check()
+		}
+
+		if len(problems) == 0 {
+			otlp.Annotate(ctx, attribute.Int("parser.repairs", attempt))
+			return nil, nil
+		}
+
+		message = NewRepairPrompt(prompt, resp, problems)
+	}
+
+	otlp.Annotate(ctx, attribute.Int("parser.repairs", maxRepairs), attribute.Int("parser.problems", len(problems)))
+
+	if !decoded {
+		return problems, errUnparsable
+	}
+
+	return problems, nil
+}
+
+// decodeStrict is the schema check of an answer: one JSON object with no
+// fields beyond the ones requested, each of the expected type.
+func decodeStrict(data string, dest any) error {
+	// a previous attempt must not leak into this one
+	reflect.ValueOf(dest).Elem().SetZero()
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		return err
+	}
+
+	if decoder.More() {
+		return errors.New("unexpected data after the JSON object")
+	}
+
+	return nil
+}
+
+// checkDetails lists what is wrong with the extracted transactions, checked
+// against the user's accounts.
+func checkDetails(result *TransactionDetailsListResult, accounts map[string]bool) []string {
+	var problems []string
+	for i, trn := range result.Transactions {
+		if trn.Type != entities.Deposit.String() && trn.Type != entities.Withdrawal.String() {
+			problems = append(problems, fmt.Sprintf("transactions[%d].type must be \"deposit\" or \"withdrawal\", got %q", i, trn.Type))
+		}
+		if trn.Amount <= 0 {
+			problems = append(problems, fmt.Sprintf("transactions[%d].amount must be a positive number", i))
+		}
+		if trn.Currency != "" && !entities.Currency(trn.Currency).IsKnown() {
+			problems = append(problems, fmt.Sprintf("transactions[%d].currency %q is not a supported currency", i, trn.Currency))
+		}
+		if trn.AccountID != nil && !accounts[*trn.AccountID] {
+			problems = append(problems, fmt.Sprintf("transactions[%d].account_id %q is not one of the user's accounts", i, *trn.AccountID))
+		}
+		if trn.Confidence < 0 || trn.Confidence > 1 {
+			problems = append(problems, fmt.Sprintf("transactions[%d].confidence must be between 0 and 1", i))
+		}
+	}
+
+	return problems
+}
+
+// sanitizeDetails falls back to safe defaults for what is still invalid
+// after the repairs. Anything guessed drops the confidence to 0 so clients
+// ask the user to review it.
+func sanitizeDetails(result *TransactionDetailsListResult, accounts map[string]bool) {
+	for i := range result.Transactions {
+		trn := &result.Transactions[i]
+		if trn.Type != entities.Deposit.String() && trn.Type != entities.Withdrawal.String() {
+			trn.Type = entities.Withdrawal.String()
+			trn.Confidence = 0
+		}
+		if trn.Amount < 0 {
+			trn.Amount = 0
+		}
+		if trn.Amount == 0 {
+			trn.Confidence = 0
+		}
+		if trn.Currency != "" && !entities.Currency(trn.Currency).IsKnown() {
+			trn.Currency = ""
+			trn.Confidence = 0
+		}
+		if trn.AccountID != nil && !accounts[*trn.AccountID] {
+			trn.AccountID = nil
+		}
+		trn.Confidence = clampConfidence(trn.Confidence)
+	}
+}
+
+// checkClassification lists what is wrong with the categories picked for
+// count transactions. Only categories offered in the catalog are valid, they
+// are the ones visible to the user.
+func checkClassification(result *CategoryClassificationListResult, catalog []CategoryInfo, count int) []string {
+	var problems []string
+	seen := make(map[int]bool, len(result.Items))
+	for _, item := range result.Items {
+		if item.Index < 0 || item.Index >= count {
+			problems = append(problems, fmt.Sprintf("index %d is not one of the listed transactions", item.Index))
+			continue
+		}
+		if seen[item.Index] {
+			problems = append(problems, fmt.Sprintf("index %d is classified more than once", item.Index))
+		}
+		seen[item.Index] = true
+
+		problems = append(problems, checkCategory(item, catalog)...)
+
+		if item.Confidence < 0 || item.Confidence > 1 {
+			problems = append(problems, fmt.Sprintf("items[index=%d].confidence must be between 0 and 1", item.Index))
+		}
+	}
+
+	return problems
+}
+
+func checkCategory(item CategoryClassificationResult, catalog []CategoryInfo) []string {
+	if item.CategoryID == nil {
+		if item.SubcategoryID != nil {
+			return []string{fmt.Sprintf("items[index=%d] has a subcategory_id without a category_id", item.Index)}
+		}
+		return nil
+	}
+
+	category, ok := findCategory(catalog, *item.CategoryID)
+	if !ok {
+		return []string{fmt.Sprintf("items[index=%d].category_id %d is not one of the available categories", item.Index, *item.CategoryID)}
+	}
+
+	if item.SubcategoryID != nil && !hasSubcategory(category, *item.SubcategoryID) {
+		return []string{fmt.Sprintf("items[index=%d].subcategory_id %d does not belong to category %d", item.Index, *item.SubcategoryID, category.ID)}
+	}
+
+	return nil
+}
+
+// sanitizeClassification drops categories that are still invalid after the
+// repairs, the transaction is left uncategorized instead.
+func sanitizeClassification(categories []CategoryClassificationResult, catalog []CategoryInfo) {
+	for i := range categories {
+		item := &categories[i]
+		if item.CategoryID != nil {
+			category, ok := findCategory(catalog, *item.CategoryID)
+			if !ok {
+				item.CategoryID = nil
+				item.SubcategoryID = nil
+				item.Confidence = 0
+			} else if item.SubcategoryID != nil && !hasSubcategory(category, *item.SubcategoryID) {
+				item.SubcategoryID = nil
+			}
+		} else {
+			item.SubcategoryID = nil
+		}
+		item.Confidence = clampConfidence(item.Confidence)
+	}
+}
+
+func findCategory(catalog []CategoryInfo, id int) (CategoryInfo, bool) {
+	for _, category := range catalog {
+		if category.ID == id {
+			return category, true
+		}
+	}
+	return CategoryInfo{}, false
+}
+
+func hasSubcategory(category CategoryInfo, id int) bool {
+	for _, sub := range category.Subcategories {
+		if sub.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func clampConfidence(confidence float64) float64 {
+	if confidence < 0 {
+		return 0
+	}
+	if confidence > 1 {
+		return 1
+	}
+	return confidence
+}
@@ -0,0 +1,287 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shogo82148/pointer"
+)
+
+func TestDecodeStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			data: `{"transactions":[{"text":"taxi","type":"withdrawal","amount":25000,"confidence":0.9}]}`,
+		},
+		{
+			name:    "unknown field",
+			data:    `{"transactions":[{"type":"withdrawal","amount":1,"confidence":1,"category":"food"}]}`,
+			wantErr: `unknown field "category"`,
+		},
+		{
+			name:    "wrong type",
+			data:    `{"transactions":[{"type":"withdrawal","amount":"25 000","confidence":1}]}`,
+			wantErr: "cannot unmarshal string",
+		},
+		{
+			name:    "trailing garbage",
+			data:    `{"transactions":[]} and that's all`,
+			wantErr: "unexpected data after the JSON object",
+		},
+		{
+			name:    "second object",
+			data:    `{"transactions":[]}{"transactions":[]}`,
+			wantErr: "unexpected data after the JSON object",
+		},
+		{
+			name:    "not json",
+			data:    `Sure! Here are the transactions.`,
+			wantErr: "invalid character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result TransactionDetailsListResult
+			err := decodeStrict(tt.data, &result)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("decodeStrict = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("decodeStrict = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeStrictResetsDest(t *testing.T) {
+	result := TransactionDetailsListResult{
+		Transactions: []TransactionDetailsResult{{Type: "withdrawal", Amount: 10}},
+	}
+	if err := decodeStrict(`{}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Transactions != nil {
+		t.Errorf("transactions of the previous answer survived: %+v", result.Transactions)
+	}
+}
+
+func TestCheckDetails(t *testing.T) {
+	const accountID = "5f0b2a54-8f0e-4b7f-9a43-1f3c2f6f0a11"
+	accounts := map[string]bool{accountID: true}
+
+	valid := TransactionDetailsResult{Type: "withdrawal", Amount: 25000, Currency: "UZS", Confidence: 0.8}
+
+	tests := []struct {
+		name   string
+		modify func(trn *TransactionDetailsResult)
+		want   []string
+	}{
+		{name: "valid", modify: func(trn *TransactionDetailsResult) {}},
+		{name: "no currency or account", modify: func(trn *TransactionDetailsResult) {
+			trn.Currency = ""
+			trn.AccountID = nil
+		}},
+		{name: "known account", modify: func(trn *TransactionDetailsResult) {
+			trn.AccountID = pointer.String(accountID)
+		}},
+		{name: "unknown type", modify: func(trn *TransactionDetailsResult) {
+			trn.Type = "transfer"
+		}, want: []string{`transactions[0].type must be "deposit" or "withdrawal", got "transfer"`}},
+		{name: "zero amount", modify: func(trn *TransactionDetailsResult) {
+			trn.Amount = 0
+		}, want: []string{"transactions[0].amount must be a positive number"}},
+		{name: "negative amount", modify: func(trn *TransactionDetailsResult) {
+			trn.Amount = -5
+		}, want: []string{"transactions[0].amount must be a positive number"}},
+		{name: "unknown currency", modify: func(trn *TransactionDetailsResult) {
+			trn.Currency = "сум"
+		}, want: []string{`transactions[0].currency "сум" is not a supported currency`}},
+		{name: "lowercase currency", modify: func(trn *TransactionDetailsResult) {
+			trn.Currency = "usd"
+		}, want: []string{`transactions[0].currency "usd" is not a supported currency`}},
+		{name: "foreign account", modify: func(trn *TransactionDetailsResult) {
+			trn.AccountID = pointer.String("acc-1")
+		}, want: []string{`transactions[0].account_id "acc-1" is not one of the user's accounts`}},
+		{name: "confidence above one", modify: func(trn *TransactionDetailsResult) {
+			trn.Confidence = 1.5
+		}, want: []string{"transactions[0].confidence must be between 0 and 1"}},
+		{name: "everything wrong", modify: func(trn *TransactionDetailsResult) {
+			trn.Type = ""
+			trn.Amount = 0
+			trn.Confidence = -1
+		}, want: []string{
+			`transactions[0].type must be "deposit" or "withdrawal", got ""`,
+			"transactions[0].amount must be a positive number",
+			"transactions[0].confidence must be between 0 and 1",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trn := valid
+			tt.modify(&trn)
+
+			got := checkDetails(&TransactionDetailsListResult{Transactions: []TransactionDetailsResult{trn}}, accounts)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("checkDetails =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSanitizeDetails(t *testing.T) {
+	accounts := map[string]bool{"acc-1": true}
+	result := TransactionDetailsListResult{Transactions: []TransactionDetailsResult{
+		{Type: "transfer", Amount: 100, Confidence: 0.9},
+		{Type: "deposit", Amount: -100, Confidence: 0.9},
+		{Type: "withdrawal", Amount: 100, Currency: "XYZ", Confidence: 0.9},
+		{Type: "withdrawal", Amount: 100, AccountID: pointer.String("acc-2"), Confidence: 1.4},
+		{Type: "deposit", Amount: 100, AccountID: pointer.String("acc-1"), Currency: "USD", Confidence: 0.7},
+	}}
+
+	sanitizeDetails(&result, accounts)
+
+	want := []TransactionDetailsResult{
+		{Type: "withdrawal", Amount: 100, Confidence: 0},
+		{Type: "deposit", Amount: 0, Confidence: 0},
+		{Type: "withdrawal", Amount: 100, Currency: "", Confidence: 0},
+		{Type: "withdrawal", Amount: 100, AccountID: nil, Confidence: 1},
+		{Type: "deposit", Amount: 100, AccountID: pointer.String("acc-1"), Currency: "USD", Confidence: 0.7},
+	}
+	for i, got := range result.Transactions {
+		w := want[i]
+		if got.Type != w.Type || got.Amount != w.Amount || got.Currency != w.Currency || got.Confidence != w.Confidence ||
+			pointer.StringValue(got.AccountID) != pointer.StringValue(w.AccountID) {
+			t.Errorf("transactions[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if problems := checkDetails(&result, accounts); len(problems) != 1 || !strings.Contains(problems[0], "amount") {
+		t.Errorf("problems left after sanitizing = %q, want only the unread amount", problems)
+	}
+}
+
+// testCatalog offers groceries with one subcategory and transport with none.
+var testCatalog = []CategoryInfo{
+	{ID: 1, Name: "Groceries", Subcategories: []SubcategoryInfo{{ID: 10, Name: "Supermarket"}}},
+	{ID: 2, Name: "Transport"},
+}
+
+func TestCheckClassification(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []CategoryClassificationResult
+		count int
+		want  []string
+	}{
+		{
+			name: "valid",
+			items: []CategoryClassificationResult{
+				{Index: 0, CategoryID: pointer.Int(1), SubcategoryID: pointer.Int(10), Confidence: 0.9},
+				{Index: 1, CategoryID: pointer.Int(2), Confidence: 0.5},
+				{Index: 2},
+			},
+			count: 3,
+		},
+		{
+			name:  "index out of range",
+			items: []CategoryClassificationResult{{Index: 2}, {Index: -1}},
+			count: 2,
+			want: []string{
+				"index 2 is not one of the listed transactions",
+				"index -1 is not one of the listed transactions",
+			},
+		},
+		{
+			name:  "index twice",
+			items: []CategoryClassificationResult{{Index: 0}, {Index: 0}},
+			count: 1,
+			want:  []string{"index 0 is classified more than once"},
+		},
+		{
+			name:  "unknown category",
+			items: []CategoryClassificationResult{{Index: 0, CategoryID: pointer.Int(7)}},
+			count: 1,
+			want:  []string{"items[index=0].category_id 7 is not one of the available categories"},
+		},
+		{
+			name:  "subcategory of another category",
+			items: []CategoryClassificationResult{{Index: 0, CategoryID: pointer.Int(2), SubcategoryID: pointer.Int(10)}},
+			count: 1,
+			want:  []string{"items[index=0].subcategory_id 10 does not belong to category 2"},
+		},
+		{
+			name:  "unknown subcategory",
+			items: []CategoryClassificationResult{{Index: 0, CategoryID: pointer.Int(1), SubcategoryID: pointer.Int(11)}},
+			count: 1,
+			want:  []string{"items[index=0].subcategory_id 11 does not belong to category 1"},
+		},
+		{
+			name:  "subcategory without category",
+			items: []CategoryClassificationResult{{Index: 0, SubcategoryID: pointer.Int(10)}},
+			count: 1,
+			want:  []string{"items[index=0] has a subcategory_id without a category_id"},
+		},
+		{
+			name:  "confidence out of range",
+			items: []CategoryClassificationResult{{Index: 0, CategoryID: pointer.Int(1), Confidence: -0.1}},
+			count: 1,
+			want:  []string{"items[index=0].confidence must be between 0 and 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkClassification(&CategoryClassificationListResult{Items: tt.items}, testCatalog, tt.count)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("checkClassification =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSanitizeClassification(t *testing.T) {
+	categories := []CategoryClassificationResult{
+		{Index: 0, CategoryID: pointer.Int(7), SubcategoryID: pointer.Int(10), Confidence: 0.9},
+		{Index: 1, CategoryID: pointer.Int(2), SubcategoryID: pointer.Int(10), Confidence: 0.8},
+		{Index: 2, SubcategoryID: pointer.Int(10), Confidence: 0.4},
+		{Index: 3, CategoryID: pointer.Int(1), SubcategoryID: pointer.Int(10), Confidence: 3},
+	}
+
+	sanitizeClassification(categories, testCatalog)
+
+	want := []struct {
+		category, subcategory *int
+		confidence            float64
+	}{
+		{nil, nil, 0},
+		{pointer.Int(2), nil, 0.8},
+		{nil, nil, 0.4},
+		{pointer.Int(1), pointer.Int(10), 1},
+	}
+	for i, got := range categories {
+		w := want[i]
+		if !sameID(got.CategoryID, w.category) || !sameID(got.SubcategoryID, w.subcategory) || got.Confidence != w.confidence {
+			t.Errorf("categories[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if problems := checkClassification(&CategoryClassificationListResult{Items: categories}, testCatalog, len(categories)); len(problems) != 0 {
+		t.Errorf("problems left after sanitizing = %q", problems)
+	}
+}
+
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,12 @@
+package ports
+
+import "context"
+
+// ParseCache keeps LLM results of the parser for inputs seen before. Keys
+// carry everything the result depends on, entries are never invalidated and
+// only expire.
+type ParseCache interface {
+	// Get reports whether the entry was found and decoded into dest.
+	Get(ctx context.Context, key string, dest any) (bool, error)
+	Set(ctx context.Context, key string, value any) error
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StatsCache keeps computed stats per user. Entries are stored under the
+// user's generation, read it before computing and store the result under
+// the same one, so a write that lands meanwhile makes the entry unreachable.
+type StatsCache interface {
+	Generation(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Get reports whether the entry was found and decoded into dest.
+	Get(ctx context.Context, userID uuid.UUID, generation int64, key string, dest any) (bool, error)
+	Set(ctx context.Context, userID uuid.UUID, generation int64, key string, value any) error
+	// Invalidate bumps the user's generation once the transaction carried
+	// by ctx commits, right away outside of one. Failures are logged, the
+	// entries still expire on their own.
+	Invalidate(ctx context.Context, userID uuid.UUID)
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit allows Limit requests per Window, in bursts of up to Limit.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// Reset is how long until the full limit is available again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, zero when
+	// this one was.
+	RetryAfter time.Duration
+}
+
+// RateLimiter counts requests per key, shared by every instance of the app.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
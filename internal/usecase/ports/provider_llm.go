@@ -2,7 +2,17 @@ package ports
 
 import "context"
 
+// LLMProvider runs chat completions and transcriptions. The model is picked
+// by the caller for each task, providers may map it to one they serve.
 type LLMProvider interface {
-	ChatCompletion(ctx context.Context, model, system, message string) (string, error)
-	AudioToText(ctx context.Context, filePath string, language string) (string, error)
+	ChatCompletion(ctx context.Context, model, system, message string) (string, LLMUsage, error)
+	AudioToText(ctx context.Context, model, filePath, language string) (string, LLMUsage, error)
+}
+
+// LLMUsage is what a single call consumed, as reported by the provider.
+// Fields the provider does not report stay zero.
+type LLMUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	AudioSeconds     float64
 }
@@ -4,6 +4,7 @@ import "context"
 
 type TelegramBotService interface {
 	SendMessage(ctx context.Context, req *SendMessageRequest) error
+	SendDocument(ctx context.Context, req *SendDocumentRequest) error
 }
 
 type SendMessageRequest struct {
@@ -11,3 +12,11 @@ type SendMessageRequest struct {
 	Text      string
 	ParseMode string
 }
+
+type SendDocumentRequest struct {
+	UserID    int64
+	Filename  string
+	Data      []byte
+	Caption   string
+	ParseMode string
+}
@@ -0,0 +1,89 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/pdf"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type getMonthlyReportUsecase struct {
+	reportBuilder
+	contextTimeout time.Duration
+	usersRepo      entities.UserRepository
+}
+
+func NewGetMonthlyReportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	font *pdf.Font,
+) *getMonthlyReportUsecase {
+	return &getMonthlyReportUsecase{
+		reportBuilder: reportBuilder{
+			logger:           logger,
+			accountsRepo:     accountsRepo,
+			transactionsRepo: transactionsRepo,
+			font:             font,
+		},
+		contextTimeout: timeout,
+		usersRepo:      usersRepo,
+	}
+}
+
+// GetMonthlyReport renders the PDF report of a "YYYY-MM" month in the
+// user's time zone, the previous month when month is empty.
+func (u *getMonthlyReportUsecase) GetMonthlyReport(ctx context.Context, userID string, month string) (_ *MonthlyReportFile, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("reports"), "GetMonthlyReport",
+		attribute.String("user_id", userID),
+		attribute.String("month", month),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	periods := user.Periods()
+
+	var period entities.Period
+	if month == "" {
+		period, err = periods.Named(entities.PeriodLastMonth)
+	} else {
+		period, err = periods.Month(month)
+	}
+	if err != nil {
+		return nil, inerr.AsValidation(err)
+	}
+	if period.From.After(periods.Now()) {
+		return nil, inerr.NewErrValidation("month", "month is in the future")
+	}
+
+	return u.build(ctx, user, period.From, period.To)
+}
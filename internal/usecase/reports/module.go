@@ -0,0 +1,36 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/pdf"
+	"github.com/hibiken/asynq"
+)
+
+type Module struct {
+	*getMonthlyReportUsecase
+	*sendMonthlyReportUsecase
+	*scheduleMonthlyReportsUsecase
+}
+
+// NewModule takes the font the reports are written in. Without one they
+// fall back to Helvetica, which cannot show Cyrillic.
+func NewModule(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	taskQueue *asynq.Client,
+	telegramBotService ports.TelegramBotService,
+	font *pdf.Font,
+) *Module {
+	return &Module{
+		getMonthlyReportUsecase:       NewGetMonthlyReportUsecase(timeout, logger, usersRepo, accountsRepo, transactionsRepo, font),
+		sendMonthlyReportUsecase:      NewSendMonthlyReportUsecase(time.Minute, logger, usersRepo, accountsRepo, transactionsRepo, telegramBotService, font),
+		scheduleMonthlyReportsUsecase: NewScheduleMonthlyReportsUsecase(5*time.Minute, logger, usersRepo, taskQueue),
+	}
+}
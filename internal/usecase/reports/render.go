@@ -0,0 +1,365 @@
+package reports
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/pdf"
+)
+
+const (
+	pageMargin = 40.0
+	pageBottom = pdf.PageHeight - pageMargin
+	rowHeight  = 18.0
+	// maxCategoryRows keeps the category table on one page, the rest is
+	// summed up as other.
+	maxCategoryRows = 12
+)
+
+var (
+	accentColor = pdf.Color{R: 37, G: 99, B: 235}
+	mutedColor  = pdf.Color{R: 107, G: 114, B: 128}
+	stripeColor = pdf.Color{R: 243, G: 244, B: 246}
+	redColor    = pdf.Color{R: 220, G: 38, B: 38}
+	greenColor  = pdf.Color{R: 22, G: 163, B: 74}
+)
+
+type column struct {
+	title string
+	width float64
+	right bool
+}
+
+type cell struct {
+	text  string
+	color *pdf.Color
+}
+
+// renderer lays the report out top to bottom, starting a new page when a
+// block does not fit.
+type renderer struct {
+	doc      *pdf.Document
+	y        float64
+	texts    reportText
+	lang     entities.Language
+	currency entities.Currency
+}
+
+func renderMonthlyReport(font *pdf.Font, user *entities.User, report *entities.MonthlyReport) ([]byte, error) {
+	r := &renderer{
+		doc:      pdf.New(font),
+		texts:    textsFor(user.LanguageCode),
+		lang:     user.LanguageCode,
+		currency: user.CurrencyCode,
+	}
+
+	month := r.monthName(report)
+	r.doc.SetTitle(r.texts.title + " — " + month)
+	r.doc.AddPage()
+	r.y = pageMargin
+
+	r.header(month)
+	r.summary(report)
+
+	if report.IsEmpty() {
+		r.doc.SetFillColor(mutedColor)
+		r.doc.Text(pageMargin, r.y+20, 11, r.texts.noTransactions)
+		r.y += 40
+	} else {
+		r.chart(report.Daily)
+		r.categories(report)
+		r.merchants(report)
+	}
+	r.accounts(report)
+	r.budget(report)
+
+	return r.doc.Bytes()
+}
+
+func (r *renderer) monthName(report *entities.MonthlyReport) string {
+	return r.texts.months[report.From.Month()-1] + " " + strconv.Itoa(report.From.Year())
+}
+
+func (r *renderer) header(month string) {
+	r.doc.SetFillColor(pdf.Black)
+	r.doc.Text(pageMargin, r.y+20, 20, r.texts.title)
+	r.doc.SetFillColor(mutedColor)
+	r.doc.Text(pageMargin, r.y+40, 12, month)
+
+	r.doc.SetStrokeColor(accentColor)
+	r.doc.Line(pageMargin, r.y+52, pdf.PageWidth-pageMargin, r.y+52, 1.5)
+	r.y += 72
+}
+
+func (r *renderer) summary(report *entities.MonthlyReport) {
+	width := (pdf.PageWidth - 2*pageMargin) / 3
+
+	netColor := greenColor
+	if report.Net() < 0 {
+		netColor = redColor
+	}
+
+	items := []struct {
+		label string
+		value string
+		color pdf.Color
+	}{
+		{r.texts.income, formatMoney(report.Income, r.currency), greenColor},
+		{r.texts.expense, formatMoney(report.Expense, r.currency), redColor},
+		{r.texts.net, formatMoney(report.Net(), r.currency), netColor},
+	}
+
+	for i, item := range items {
+		x := pageMargin + float64(i)*width
+		r.doc.SetFillColor(stripeColor)
+		r.doc.Rect(x, r.y, width-8, 48)
+		r.doc.SetFillColor(mutedColor)
+		r.doc.Text(x+10, r.y+18, 9, item.label)
+		r.doc.SetFillColor(item.color)
+		r.doc.Text(x+10, r.y+38, 13, r.doc.Truncate(item.value, 13, width-28))
+	}
+	r.y += 68
+}
+
+// chart draws the daily expenses as bars, labelling every fifth day.
+func (r *renderer) chart(daily []int64) {
+	const height = 110.0
+
+	r.section(r.texts.dailySpending, height+30)
+
+	var peak int64
+	for _, v := range daily {
+		peak = max(peak, v)
+	}
+
+	width := pdf.PageWidth - 2*pageMargin
+	slot := width / float64(len(daily))
+	base := r.y + height
+
+	r.doc.SetFillColor(mutedColor)
+	r.doc.Text(pageMargin, r.y+8, 8, formatMoney(peak, r.currency))
+
+	r.doc.SetFillColor(accentColor)
+	for i, v := range daily {
+		if v == 0 || peak == 0 {
+			continue
+		}
+		h := float64(v) / float64(peak) * (height - 16)
+		r.doc.Rect(pageMargin+float64(i)*slot+slot*0.15, base-h, slot*0.7, h)
+	}
+
+	r.doc.SetStrokeColor(mutedColor)
+	r.doc.Line(pageMargin, base, pdf.PageWidth-pageMargin, base, 0.5)
+
+	r.doc.SetFillColor(mutedColor)
+	for i := range daily {
+		if day := i + 1; day == 1 || day%5 == 0 {
+			label := strconv.Itoa(day)
+			center := pageMargin + float64(i)*slot + slot/2
+			r.doc.Text(center-r.doc.TextWidth(label, 8)/2, base+12, 8, label)
+		}
+	}
+	r.y = base + 30
+}
+
+func (r *renderer) categories(report *entities.MonthlyReport) {
+	if len(report.Categories) == 0 {
+		return
+	}
+
+	rows := make([][]cell, 0, min(len(report.Categories), maxCategoryRows))
+	for i, category := range report.Categories {
+		if i == maxCategoryRows-1 && len(report.Categories) > maxCategoryRows {
+			var other entities.ReportCategory
+			for _, rest := range report.Categories[i:] {
+				other.Total += rest.Total
+				other.Count += rest.Count
+				other.Share += rest.Share
+			}
+			rows = append(rows, r.categoryRow(r.texts.other, other))
+			break
+		}
+		rows = append(rows, r.categoryRow(r.categoryName(category.Category), category))
+	}
+
+	r.table(r.texts.categories, []column{
+		{title: r.texts.category, width: 235},
+		{title: r.texts.count, width: 80, right: true},
+		{title: r.texts.amount, width: 130, right: true},
+		{title: r.texts.share, width: 70, right: true},
+	}, rows)
+}
+
+func (r *renderer) categoryRow(name string, category entities.ReportCategory) []cell {
+	return []cell{
+		{text: name},
+		{text: strconv.Itoa(category.Count)},
+		{text: formatMoney(category.Total, r.currency)},
+		{text: fmt.Sprintf("%.1f%%", category.Share*100)},
+	}
+}
+
+func (r *renderer) merchants(report *entities.MonthlyReport) {
+	if len(report.Merchants) == 0 {
+		return
+	}
+
+	rows := make([][]cell, 0, len(report.Merchants))
+	for _, merchant := range report.Merchants {
+		rows = append(rows, []cell{
+			{text: merchant.Name},
+			{text: strconv.Itoa(merchant.Count)},
+			{text: formatMoney(merchant.Total, r.currency)},
+		})
+	}
+
+	r.table(r.texts.merchants, []column{
+		{title: r.texts.merchant, width: 305},
+		{title: r.texts.count, width: 80, right: true},
+		{title: r.texts.amount, width: 130, right: true},
+	}, rows)
+}
+
+func (r *renderer) accounts(report *entities.MonthlyReport) {
+	if len(report.Accounts) == 0 {
+		return
+	}
+
+	rows := make([][]cell, 0, len(report.Accounts))
+	for _, account := range report.Accounts {
+		change := account.End - account.Start
+		rows = append(rows, []cell{
+			{text: account.Name},
+			{text: formatMoney(account.Start, r.currency)},
+			{text: formatMoney(account.End, r.currency)},
+			{text: formatMoney(change, r.currency), color: r.signColor(change)},
+		})
+	}
+
+	r.table(r.texts.accounts, []column{
+		{title: r.texts.account, width: 145},
+		{title: r.texts.start, width: 125, right: true},
+		{title: r.texts.end, width: 125, right: true},
+		{title: r.texts.change, width: 120, right: true},
+	}, rows)
+}
+
+func (r *renderer) budget(report *entities.MonthlyReport) {
+	if len(report.Budget) == 0 {
+		return
+	}
+
+	rows := make([][]cell, 0, len(report.Budget))
+	for _, budget := range report.Budget {
+		difference := budget.Expected - budget.Spent
+		rows = append(rows, []cell{
+			{text: r.categoryName(budget.Category)},
+			{text: formatMoney(budget.Expected, r.currency)},
+			{text: formatMoney(budget.Spent, r.currency)},
+			{text: formatMoney(difference, r.currency), color: r.signColor(difference)},
+		})
+	}
+
+	r.table(r.texts.budget, []column{
+		{title: r.texts.category, width: 145},
+		{title: r.texts.average, width: 125, right: true},
+		{title: r.texts.spent, width: 125, right: true},
+		{title: r.texts.difference, width: 120, right: true},
+	}, rows)
+
+	r.doc.SetFillColor(mutedColor)
+	r.doc.Text(pageMargin, r.y-6, 8, fmt.Sprintf(r.texts.budgetNote, entities.ReportBudgetMonths))
+	r.y += 8
+}
+
+func (r *renderer) categoryName(category *entities.Category) string {
+	if category == nil {
+		return r.texts.uncategorized
+	}
+
+	// Emoji are left out, the embedded font has no glyphs for them.
+	return category.GetName(r.lang)
+}
+
+func (r *renderer) signColor(v int64) *pdf.Color {
+	switch {
+	case v < 0:
+		return &redColor
+	case v > 0:
+		return &greenColor
+	}
+	return nil
+}
+
+// section starts a titled block, on a new page when the title and the
+// first height points of content would not fit.
+func (r *renderer) section(title string, height float64) {
+	if r.y+24+height > pageBottom {
+		r.doc.AddPage()
+		r.y = pageMargin
+	}
+
+	r.doc.SetFillColor(pdf.Black)
+	r.doc.Text(pageMargin, r.y+14, 13, title)
+	r.y += 24
+}
+
+// table draws a header row and striped rows, repeating the header when the
+// rows continue on the next page.
+func (r *renderer) table(title string, columns []column, rows [][]cell) {
+	r.section(title, rowHeight*2)
+	r.tableHeader(columns)
+
+	for i, row := range rows {
+		if r.y+rowHeight > pageBottom {
+			r.doc.AddPage()
+			r.y = pageMargin
+			r.tableHeader(columns)
+		}
+
+		if i%2 == 1 {
+			r.doc.SetFillColor(stripeColor)
+			r.doc.Rect(pageMargin, r.y, pdf.PageWidth-2*pageMargin, rowHeight)
+		}
+
+		x := pageMargin
+		for j, column := range columns {
+			color := pdf.Black
+			if row[j].color != nil {
+				color = *row[j].color
+			}
+			r.doc.SetFillColor(color)
+			r.cellText(x, column, row[j].text, 9)
+			x += column.width
+		}
+		r.y += rowHeight
+	}
+
+	r.y += 16
+}
+
+func (r *renderer) tableHeader(columns []column) {
+	r.doc.SetFillColor(mutedColor)
+	x := pageMargin
+	for _, column := range columns {
+		r.cellText(x, column, column.title, 8)
+		x += column.width
+	}
+	r.y += rowHeight
+
+	r.doc.SetStrokeColor(mutedColor)
+	r.doc.Line(pageMargin, r.y-4, pdf.PageWidth-pageMargin, r.y-4, 0.5)
+}
+
+func (r *renderer) cellText(x float64, column column, text string, size float64) {
+	const padding = 6.0
+
+	text = r.doc.Truncate(text, size, column.width-2*padding)
+	baseline := r.y + rowHeight - 6
+	if column.right {
+		r.doc.TextRight(x+column.width-padding, baseline, size, text)
+		return
+	}
+	r.doc.Text(x+padding, baseline, size, text)
+}
@@ -0,0 +1,67 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/pdf"
+)
+
+// reportBuilder loads and renders a monthly report, shared by the API and
+// the monthly delivery.
+type reportBuilder struct {
+	logger           *logger.Logger
+	accountsRepo     entities.AccountRepository
+	transactionsRepo entities.TransactionRepository
+	font             *pdf.Font
+}
+
+type MonthlyReportFile struct {
+	Filename string
+	Data     []byte
+	Report   *entities.MonthlyReport
+}
+
+func (b *reportBuilder) build(ctx context.Context, user *entities.User, from, to time.Time) (*MonthlyReportFile, error) {
+	accounts, err := b.accountsRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		b.logger.ErrorContext(ctx, "failed to get accounts", err)
+		return nil, err
+	}
+
+	transactions, err := b.transactionsRepo.GetPerformedBetween(ctx, user.ID, from.AddDate(0, -entities.ReportBudgetMonths, 0), to)
+	if err != nil {
+		b.logger.ErrorContext(ctx, "failed to get transactions", err)
+		return nil, err
+	}
+
+	// only the sums of what came after are needed, they walk the current
+	// balances back to the end of the month
+	netAfter, err := b.transactionsRepo.GetNetByAccountSince(ctx, user.ID, to)
+	if err != nil {
+		b.logger.ErrorContext(ctx, "failed to get transactions after the report", err)
+		return nil, err
+	}
+
+	report := entities.BuildMonthlyReport(entities.MonthlyReportInput{
+		From:         from,
+		To:           to,
+		Accounts:     accounts,
+		Transactions: transactions,
+		NetAfter:     netAfter,
+	})
+
+	data, err := renderMonthlyReport(b.font, user, report)
+	if err != nil {
+		b.logger.ErrorContext(ctx, "failed to render report", err)
+		return nil, err
+	}
+
+	return &MonthlyReportFile{
+		Filename: "report-" + from.Format(entities.ReportMonthLayout) + ".pdf",
+		Data:     data,
+		Report:   report,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// reportDeliveryHour is the local hour on the 1st reports are sent at.
+const reportDeliveryHour = 9
+
+type scheduleMonthlyReportsUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usersRepo      entities.UserRepository
+	taskQueue      *asynq.Client
+}
+
+func NewScheduleMonthlyReportsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	taskQueue *asynq.Client,
+) *scheduleMonthlyReportsUsecase {
+	return &scheduleMonthlyReportsUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usersRepo:      usersRepo,
+		taskQueue:      taskQueue,
+	}
+}
+
+// ScheduleMonthlyReports queues a report for every user, to be sent on the
+// morning of the 1st in their time zone. Users for whom the month has not
+// ended yet get the month that is ending.
+func (u *scheduleMonthlyReportsUsecase) ScheduleMonthlyReports(ctx context.Context) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("reports"), "ScheduleMonthlyReports")
+	defer func() { end(err) }()
+
+	users, err := u.usersRepo.FindAll(ctx)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get users", err)
+		return err
+	}
+
+	now := time.Now()
+	var scheduled, duplicates int
+	for _, user := range users {
+		periods := entities.NewPeriodResolver(user.Location(), now)
+
+		// Delivered once the month is over, on the 1st it is the last one.
+		month, _ := periods.Named(entities.PeriodThisMonth)
+		if periods.Now().Day() == 1 {
+			month, _ = periods.Named(entities.PeriodLastMonth)
+		}
+
+		task, err := tasks.NewMonthlyReportSendTask(user.ID.String(), month.From.Format(entities.ReportMonthLayout), month.To.Add(reportDeliveryHour*time.Hour))
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to create task", err)
+			return err
+		}
+
+		if _, err := u.taskQueue.EnqueueContext(ctx, task); err != nil {
+			if errors.Is(err, asynq.ErrTaskIDConflict) {
+				duplicates++
+				continue
+			}
+			u.logger.ErrorContext(ctx, "failed to enqueue task", err)
+			return err
+		}
+		scheduled++
+	}
+
+	otlp.Annotate(ctx,
+		attribute.Int("total_users", len(users)),
+		attribute.Int("total_tasks_created", scheduled),
+		attribute.Int("total_duplicates", duplicates),
+	)
+
+	return nil
+}
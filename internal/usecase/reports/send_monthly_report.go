@@ -0,0 +1,110 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/pdf"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type sendMonthlyReportUsecase struct {
+	reportBuilder
+	contextTimeout     time.Duration
+	usersRepo          entities.UserRepository
+	telegramBotService ports.TelegramBotService
+}
+
+func NewSendMonthlyReportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	telegramBotService ports.TelegramBotService,
+	font *pdf.Font,
+) *sendMonthlyReportUsecase {
+	return &sendMonthlyReportUsecase{
+		reportBuilder: reportBuilder{
+			logger:           logger,
+			accountsRepo:     accountsRepo,
+			transactionsRepo: transactionsRepo,
+			font:             font,
+		},
+		contextTimeout:     timeout,
+		usersRepo:          usersRepo,
+		telegramBotService: telegramBotService,
+	}
+}
+
+// SendMonthlyReport delivers the report of a "YYYY-MM" month through the
+// bot. Months without transactions are not sent.
+func (u *sendMonthlyReportUsecase) SendMonthlyReport(ctx context.Context, userID string, month string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("reports"), "SendMonthlyReport",
+		attribute.String("user_id", userID),
+		attribute.String("month", month),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			return inerr.NewErrValidation("user_id", err.Error())
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return err
+	}
+
+	period, err := user.Periods().Month(month)
+	if err != nil {
+		return inerr.AsValidation(err)
+	}
+	from := period.From
+
+	file, err := u.build(ctx, user, period.From, period.To)
+	if err != nil {
+		return err
+	}
+
+	if file.Report.IsEmpty() {
+		otlp.Event(ctx, "report_skipped", attribute.String("reason", "no_transactions"))
+		return nil
+	}
+
+	texts := textsFor(user.LanguageCode)
+	caption := fmt.Sprintf(texts.caption, texts.months[from.Month()-1]+" "+from.Format("2006"))
+
+	if err := u.telegramBotService.SendDocument(ctx, &ports.SendDocumentRequest{
+		UserID:    user.TGUserID,
+		Filename:  file.Filename,
+		Data:      file.Data,
+		Caption:   caption,
+		ParseMode: "HTML",
+	}); err != nil {
+		u.logger.ErrorContext(ctx, "failed to send report", err)
+		return err
+	}
+
+	otlp.Event(ctx, "report_sent", attribute.Int("size", len(file.Data)))
+
+	return nil
+}
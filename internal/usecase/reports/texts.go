@@ -0,0 +1,165 @@
+package reports
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+)
+
+type reportText struct {
+	title          string
+	months         [12]string
+	income         string
+	expense        string
+	net            string
+	dailySpending  string
+	categories     string
+	category       string
+	count          string
+	amount         string
+	share          string
+	merchants      string
+	merchant       string
+	accounts       string
+	account        string
+	start          string
+	end            string
+	change         string
+	budget         string
+	budgetNote     string
+	average        string
+	spent          string
+	difference     string
+	uncategorized  string
+	other          string
+	noTransactions string
+	caption        string
+}
+
+// reportTexts are picked following the user's language fallback chain.
+var reportTexts = map[entities.Language]reportText{
+	entities.EN: {
+		title:          "Monthly report",
+		months:         [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		income:         "Income",
+		expense:        "Expenses",
+		net:            "Net",
+		dailySpending:  "Daily spending",
+		categories:     "Expenses by category",
+		category:       "Category",
+		count:          "Transactions",
+		amount:         "Amount",
+		share:          "Share",
+		merchants:      "Top merchants",
+		merchant:       "Merchant",
+		accounts:       "Account balances",
+		account:        "Account",
+		start:          "Start of month",
+		end:            "End of month",
+		change:         "Change",
+		budget:         "Budget",
+		budgetNote:     "Compared with your average monthly spending over the previous %d months.",
+		average:        "Average",
+		spent:          "This month",
+		difference:     "Difference",
+		uncategorized:  "Uncategorized",
+		other:          "Other",
+		noTransactions: "No transactions this month.",
+		caption:        "📊 <b>Your report for %s</b>",
+	},
+	entities.RU: {
+		title:          "Отчёт за месяц",
+		months:         [12]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь", "Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"},
+		income:         "Доходы",
+		expense:        "Расходы",
+		net:            "Итог",
+		dailySpending:  "Расходы по дням",
+		categories:     "Расходы по категориям",
+		category:       "Категория",
+		count:          "Операций",
+		amount:         "Сумма",
+		share:          "Доля",
+		merchants:      "Крупнейшие получатели",
+		merchant:       "Получатель",
+		accounts:       "Балансы счетов",
+		account:        "Счёт",
+		start:          "Начало месяца",
+		end:            "Конец месяца",
+		change:         "Изменение",
+		budget:         "Бюджет",
+		budgetNote:     "В сравнении со средними расходами за предыдущие %d мес.",
+		average:        "В среднем",
+		spent:          "В этом месяце",
+		difference:     "Разница",
+		uncategorized:  "Без категории",
+		other:          "Другое",
+		noTransactions: "В этом месяце операций не было.",
+		caption:        "📊 <b>Ваш отчёт за %s</b>",
+	},
+	entities.UZ: {
+		title:          "Oylik hisobot",
+		months:         [12]string{"Yanvar", "Fevral", "Mart", "Aprel", "May", "Iyun", "Iyul", "Avgust", "Sentabr", "Oktabr", "Noyabr", "Dekabr"},
+		income:         "Daromad",
+		expense:        "Xarajatlar",
+		net:            "Natija",
+		dailySpending:  "Kunlik xarajatlar",
+		categories:     "Kategoriyalar bo'yicha xarajatlar",
+		category:       "Kategoriya",
+		count:          "Tranzaksiyalar",
+		amount:         "Summa",
+		share:          "Ulush",
+		merchants:      "Eng ko'p to'lovlar",
+		merchant:       "Qabul qiluvchi",
+		accounts:       "Hisob qoldiqlari",
+		account:        "Hisob",
+		start:          "Oy boshida",
+		end:            "Oy oxirida",
+		change:         "O'zgarish",
+		budget:         "Byudjet",
+		budgetNote:     "Oldingi %d oydagi o'rtacha oylik xarajatlar bilan taqqoslanadi.",
+		average:        "O'rtacha",
+		spent:          "Shu oy",
+		difference:     "Farq",
+		uncategorized:  "Kategoriyasiz",
+		other:          "Boshqa",
+		noTransactions: "Bu oyda tranzaksiyalar bo'lmagan.",
+		caption:        "📊 <b>%s uchun hisobotingiz</b>",
+	},
+}
+
+func textsFor(lang entities.Language) reportText {
+	for _, l := range lang.Fallbacks() {
+		if t, ok := reportTexts[l]; ok {
+			return t
+		}
+	}
+	return reportTexts[entities.EN]
+}
+
+// formatMoney writes minor units with grouped thousands, e.g.
+// "1 234 567.50 UZS".
+func formatMoney(minor int64, currency entities.Currency) string {
+	scale := currency.Scale()
+	value := strconv.FormatFloat(entities.MajorFromMinor(minor, scale), 'f', scale, 64)
+
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteByte('.')
+		b.WriteString(fraction)
+	}
+
+	return sign + b.String() + " " + currency.String()
+}
@@ -0,0 +1,28 @@
+package rollups
+
+import (
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/hibiken/asynq"
+)
+
+type Module struct {
+	*rebuildRollupsUsecase
+	*verifyRollupsUsecase
+}
+
+func NewModule(
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	rollupsRepo entities.RollupRepository,
+	taskQueue *asynq.Client,
+	statsCache ports.StatsCache,
+) *Module {
+	return &Module{
+		rebuildRollupsUsecase: NewRebuildRollupsUsecase(5*time.Minute, logger, rollupsRepo, statsCache),
+		verifyRollupsUsecase:  NewVerifyRollupsUsecase(time.Hour, logger, usersRepo, rollupsRepo, taskQueue),
+	}
+}
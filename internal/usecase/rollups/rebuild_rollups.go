@@ -0,0 +1,69 @@
+package rollups
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type rebuildRollupsUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	rollupsRepo    entities.RollupRepository
+	statsCache     ports.StatsCache
+}
+
+func NewRebuildRollupsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	rollupsRepo entities.RollupRepository,
+	statsCache ports.StatsCache,
+) *rebuildRollupsUsecase {
+	return &rebuildRollupsUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		rollupsRepo:    rollupsRepo,
+		statsCache:     statsCache,
+	}
+}
+
+// RebuildRollups recomputes the user's daily rollups from their
+// transactions, after their time zone changed or the rollups drifted.
+func (u *rebuildRollupsUsecase) RebuildRollups(ctx context.Context, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("rollups"), "RebuildRollups",
+		attribute.String("user_id", userID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			return inerr.NewErrValidation("user_id", err.Error())
+		}
+	}
+
+	err = u.rollupsRepo.Rebuild(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to rebuild rollups", err)
+		return err
+	}
+
+	u.statsCache.Invalidate(ctx, input.userID)
+
+	return nil
+}
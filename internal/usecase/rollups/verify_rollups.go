@@ -0,0 +1,91 @@
+package rollups
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type verifyRollupsUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usersRepo      entities.UserRepository
+	rollupsRepo    entities.RollupRepository
+	taskQueue      *asynq.Client
+}
+
+func NewVerifyRollupsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	rollupsRepo entities.RollupRepository,
+	taskQueue *asynq.Client,
+) *verifyRollupsUsecase {
+	return &verifyRollupsUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usersRepo:      usersRepo,
+		rollupsRepo:    rollupsRepo,
+		taskQueue:      taskQueue,
+	}
+}
+
+// VerifyRollups checks every user's daily rollups against an aggregation of
+// their transactions and queues a rebuild for those that disagree. Drift
+// means a write path bypassed the repository, so it is logged loudly.
+func (u *verifyRollupsUsecase) VerifyRollups(ctx context.Context) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("rollups"), "VerifyRollups")
+	defer func() { end(err) }()
+
+	users, err := u.usersRepo.FindAll(ctx)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get users", err)
+		return err
+	}
+
+	var drifted, rows int
+	for _, user := range users {
+		drift, err := u.rollupsRepo.Verify(ctx, user.ID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to verify rollups", err)
+			return err
+		}
+		if drift == 0 {
+			continue
+		}
+
+		u.logger.WarnContext(ctx, "rollups drifted from transactions", "user_id", user.ID.String(), "rows", drift)
+		drifted++
+		rows += drift
+
+		task, err := tasks.NewRollupsRebuildTask(user.ID.String())
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to create task", err)
+			return err
+		}
+
+		if _, err := u.taskQueue.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+			u.logger.ErrorContext(ctx, "failed to enqueue task", err)
+			return err
+		}
+	}
+
+	otlp.Annotate(ctx,
+		attribute.Int("total_users", len(users)),
+		attribute.Int("total_drifted_users", drifted),
+		attribute.Int("total_drifted_rows", rows),
+	)
+
+	return nil
+}
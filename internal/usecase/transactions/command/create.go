@@ -0,0 +1,160 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/hibiken/asynq"
+)
+
+// TransactionCreator is the one path new transactions take, whether entered
+// by hand or confirmed from a draft. Save runs inside the caller's database
+// transaction so it can carry writes of its own, Created once it commits.
+type TransactionCreator struct {
+	logger            *logger.Logger
+	accountsRepo      entities.AccountRepository
+	transactionsRepo  entities.TransactionRepository
+	categoriesRepo    entities.CategoryRepository
+	subcategoriesRepo entities.SubcategoryRepository
+	taskQueue         *asynq.Client
+	statsCache        ports.StatsCache
+}
+
+func NewTransactionCreator(
+	logger *logger.Logger,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	taskQueue *asynq.Client,
+	statsCache ports.StatsCache,
+) *TransactionCreator {
+	return &TransactionCreator{
+		logger:            logger,
+		accountsRepo:      accountsRepo,
+		transactionsRepo:  transactionsRepo,
+		categoriesRepo:    categoriesRepo,
+		subcategoriesRepo: subcategoriesRepo,
+		taskQueue:         taskQueue,
+		statsCache:        statsCache,
+	}
+}
+
+// Categorise puts the transaction in the category and subcategory, both
+// optional. They must be visible to the transaction's user.
+func (c *TransactionCreator) Categorise(ctx context.Context, transaction *entities.Transaction, categoryID, subcategoryID *int) error {
+	var category *entities.Category
+	if categoryID != nil {
+		var err error
+		category, err = c.categoriesRepo.FindByID(ctx, *categoryID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to get category", err)
+			return err
+		}
+
+		if !category.IsVisibleTo(transaction.UserID) {
+			return inerr.NewErrValidation("category_id", "category not found")
+		}
+	}
+
+	var subcategory *entities.Subcategory
+	if subcategoryID != nil {
+		var err error
+		subcategory, err = c.subcategoriesRepo.FindByID(ctx, *subcategoryID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to get subcategory", err)
+			return err
+		}
+
+		if !subcategory.IsVisibleTo(transaction.UserID) {
+			return inerr.NewErrValidation("subcategory_id", "subcategory not found")
+		}
+	}
+
+	err := transaction.Categorise(category, subcategory)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to categorise transaction", err)
+		return err
+	}
+
+	return nil
+}
+
+// Save applies the transaction to its account and stores both. It must run
+// inside a database transaction, the account stays locked until it ends.
+func (c *TransactionCreator) Save(ctx context.Context, transaction *entities.Transaction) error {
+	account, err := c.accountsRepo.GetByIDForUpdate(ctx, transaction.AccountID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to get account", err)
+		return err
+	}
+
+	if account.UserID != transaction.UserID {
+		return inerr.NewErrValidation("account_id", "account not found")
+	}
+
+	err = account.ApplyTransaction(transaction)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to apply transaction", err)
+		return err
+	}
+
+	err = c.accountsRepo.Save(ctx, account)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to save account", err)
+		return err
+	}
+
+	err = c.transactionsRepo.Save(ctx, transaction)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to create transaction", err)
+		return err
+	}
+
+	c.statsCache.Invalidate(ctx, transaction.UserID)
+
+	return nil
+}
+
+// Created runs the follow-ups of a committed transaction and returns the
+// entries it likely duplicates. Both are best effort, the transaction is
+// already saved.
+func (c *TransactionCreator) Created(ctx context.Context, transaction *entities.Transaction) []entities.DuplicateMatch {
+	if transaction.Type == entities.Withdrawal {
+		c.enqueueAnomalyDetect(ctx, transaction)
+	}
+
+	return c.findDuplicates(ctx, transaction)
+}
+
+func (c *TransactionCreator) findDuplicates(ctx context.Context, transaction *entities.Transaction) []entities.DuplicateMatch {
+	occurredAt := transaction.OccurredAt()
+	candidates, err := c.transactionsRepo.GetPerformedBetween(ctx, transaction.UserID,
+		occurredAt.Add(-entities.DuplicateMaxGap),
+		occurredAt.Add(entities.DuplicateMaxGap+time.Second),
+	)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to find duplicate transactions", err)
+		return nil
+	}
+
+	return entities.FindDuplicates(transaction, candidates)
+}
+
+// enqueueAnomalyDetect must not fail the transaction on a missed alert.
+func (c *TransactionCreator) enqueueAnomalyDetect(ctx context.Context, transaction *entities.Transaction) {
+	task, err := tasks.NewAnomalyDetectTask(transaction.UserID.String(), transaction.ID.String())
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to create anomaly detect task", err)
+		return
+	}
+
+	if _, err := c.taskQueue.EnqueueContext(ctx, task); err != nil {
+		c.logger.ErrorContext(ctx, "failed to enqueue anomaly detect task", err)
+	}
+}
@@ -15,14 +15,11 @@ import (
 )
 
 type CreateTransactionUsecase struct {
-	contextTimeout    time.Duration
-	logger            *logger.Logger
-	txManager         postgres.TxManager
-	usersRepo         entities.UserRepository
-	accountsRepo      entities.AccountRepository
-	transactionsRepo  entities.TransactionRepository
-	categoryRepo      entities.CategoryRepository
-	subcategoriesRepo entities.SubcategoryRepository
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	txManager      postgres.TxManager
+	usersRepo      entities.UserRepository
+	creator        *TransactionCreator
 }
 
 func NewCreateTransactionUsecase(
@@ -30,20 +27,14 @@ func NewCreateTransactionUsecase(
 	logger *logger.Logger,
 	txManager postgres.TxManager,
 	usersRepo entities.UserRepository,
-	accountsRepo entities.AccountRepository,
-	transactionsRepo entities.TransactionRepository,
-	categoriesRepo entities.CategoryRepository,
-	subcategoriesRepo entities.SubcategoryRepository,
+	creator *TransactionCreator,
 ) *CreateTransactionUsecase {
 	return &CreateTransactionUsecase{
-		contextTimeout:    timeout,
-		usersRepo:         usersRepo,
-		accountsRepo:      accountsRepo,
-		transactionsRepo:  transactionsRepo,
-		categoryRepo:      categoriesRepo,
-		subcategoriesRepo: subcategoriesRepo,
-		logger:            logger,
-		txManager:         txManager,
+		contextTimeout: timeout,
+		usersRepo:      usersRepo,
+		logger:         logger,
+		txManager:      txManager,
+		creator:        creator,
 	}
 }
 
@@ -59,10 +50,18 @@ type CreateTransactionCommand struct {
 	CategoryID           *int
 	SubcategoryID        *int
 	Note                 string
+	Merchant             string
 	PerformedAt          *time.Time
 }
 
-func (c *CreateTransactionUsecase) CreateTransaction(ctx context.Context, cmd *CreateTransactionCommand) (_ *entities.Transaction, err error) {
+// CreateTransactionResult flags entries that likely record the same money
+// movement, e.g. an expense told by voice and then sent as a receipt.
+type CreateTransactionResult struct {
+	Transaction *entities.Transaction
+	Duplicates  []entities.DuplicateMatch
+}
+
+func (c *CreateTransactionUsecase) CreateTransaction(ctx context.Context, cmd *CreateTransactionCommand) (_ *CreateTransactionResult, err error) {
 	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
 	defer cancel()
 
@@ -73,11 +72,9 @@ func (c *CreateTransactionUsecase) CreateTransaction(ctx context.Context, cmd *C
 	defer func() { end(err) }()
 
 	var input struct {
-		userID      uuid.UUID
-		accountID   uuid.UUID
-		category    *entities.Category
-		subcategory *entities.Subcategory
-		trnType     entities.TrnType
+		userID    uuid.UUID
+		accountID uuid.UUID
+		trnType   entities.TrnType
 	}
 	{
 		var err error
@@ -93,26 +90,6 @@ func (c *CreateTransactionUsecase) CreateTransaction(ctx context.Context, cmd *C
 			return nil, inerr.NewErrValidation("account_id", "invalud uuid type")
 		}
 
-		if cmd.CategoryID != nil {
-			category, err := c.categoryRepo.FindByID(ctx, *cmd.CategoryID)
-			if err != nil {
-				c.logger.ErrorContext(ctx, "failed to get category", err)
-				return nil, err
-			}
-
-			input.category = category
-		}
-
-		if cmd.SubcategoryID != nil {
-			subcategory, err := c.subcategoriesRepo.FindByID(ctx, *cmd.SubcategoryID)
-			if err != nil {
-				c.logger.ErrorContext(ctx, "failed to get subcategory", err)
-				return nil, err
-			}
-
-			input.subcategory = subcategory
-		}
-
 		if cmd.Type == "deposit" {
 			input.trnType = entities.Deposit
 		} else {
@@ -126,82 +103,61 @@ func (c *CreateTransactionUsecase) CreateTransaction(ctx context.Context, cmd *C
 		return nil, err
 	}
 
-	var transaction *entities.Transaction
-	err = c.txManager.WithTx(ctx, func(ctx context.Context) error {
-		account, err := c.accountsRepo.GetByIDForUpdate(ctx, input.accountID)
-		if err != nil {
-			c.logger.ErrorContext(ctx, "failed to get account", err)
-			return err
-		}
+	transaction, err := entities.NewTransaction(
+		user.ID,
+		input.accountID,
+		input.trnType,
+		cmd.Note,
+	)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to create transaction", err)
+		return nil, err
+	}
 
-		transaction, err = entities.NewTransaction(
-			user.ID,
-			account.ID,
-			input.trnType,
-			cmd.Note,
-		)
-		if err != nil {
-			c.logger.ErrorContext(ctx, "failed to create transaction", err)
-			return err
-		}
+	err = c.creator.Categorise(ctx, transaction, cmd.CategoryID, cmd.SubcategoryID)
+	if err != nil {
+		return nil, err
+	}
 
-		err = transaction.Categorise(input.category, input.subcategory)
-		if err != nil {
-			c.logger.ErrorContext(ctx, "failed to categorise transaction", err)
-			return err
-		}
+	transaction.SetMerchant(cmd.Merchant)
+
+	err = transaction.SetAmountMajor(cmd.Amount, user.CurrencyCode)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to set amount major", err)
+		return nil, err
+	}
 
-		err = transaction.SetAmountMajor(cmd.Amount, user.CurrencyCode)
+	if cmd.OriginalAmount != nil {
+		err = transaction.SetOriginalAmountMajor(*cmd.OriginalAmount, entities.Currency(*cmd.OriginalCurrencyCode))
 		if err != nil {
-			c.logger.ErrorContext(ctx, "failed to set amount major", err)
-			return err
+			c.logger.ErrorContext(ctx, "failed to set original amount major", err)
+			return nil, err
 		}
 
-		if cmd.OriginalAmount != nil {
-			err = transaction.SetOriginalAmountMajor(*cmd.OriginalAmount, entities.Currency(*cmd.OriginalCurrencyCode))
+		if cmd.FxRate != nil {
+			err = transaction.SetFxRate(*cmd.FxRate)
 			if err != nil {
-				c.logger.ErrorContext(ctx, "failed to set original amount major", err)
-				return err
-			}
-
-			if cmd.FxRate != nil {
-				err = transaction.SetFxRate(*cmd.FxRate)
-				if err != nil {
-					c.logger.ErrorContext(ctx, "failed to set fx rate", err)
-					return err
-				}
+				c.logger.ErrorContext(ctx, "failed to set fx rate", err)
+				return nil, err
 			}
 		}
+	}
 
-		if cmd.PerformedAt != nil {
-			transaction.Performed(*cmd.PerformedAt)
-		} else {
-			transaction.Performed(time.Now())
-		}
-
-		err = account.ApplyTransaction(transaction)
-		if err != nil {
-			c.logger.ErrorContext(ctx, "failed to apply transaction", err)
-			return err
-		}
-
-		err = c.accountsRepo.Save(ctx, account)
-		if err != nil {
-			c.logger.ErrorContext(ctx, "failed to create transaction", err)
-			return err
-		}
-
-		err = c.transactionsRepo.Save(ctx, transaction)
-		if err != nil {
-			c.logger.ErrorContext(ctx, "failed to create transaction", err)
-			return err
-		}
+	if cmd.PerformedAt != nil {
+		transaction.Performed(*cmd.PerformedAt)
+	} else {
+		transaction.Performed(time.Now())
+	}
 
-		return nil
+	err = c.txManager.WithTx(ctx, func(ctx context.Context) error {
+		return c.creator.Save(ctx, transaction)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return transaction, nil
+	return &CreateTransactionResult{
+		Transaction: transaction,
+		Duplicates:  c.creator.Created(ctx, transaction),
+	}, nil
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
@@ -20,6 +21,7 @@ type DeleteTransactionUsecase struct {
 	txManager        postgres.TxManager
 	accountsRepo     entities.AccountRepository
 	transactionsRepo entities.TransactionRepository
+	statsCache       ports.StatsCache
 }
 
 func NewDeleteTransactionUsecase(
@@ -28,6 +30,7 @@ func NewDeleteTransactionUsecase(
 	txManager postgres.TxManager,
 	accountsRepo entities.AccountRepository,
 	transactionsRepo entities.TransactionRepository,
+	statsCache ports.StatsCache,
 ) *DeleteTransactionUsecase {
 	return &DeleteTransactionUsecase{
 		contextTimeout:   timeout,
@@ -35,6 +38,7 @@ func NewDeleteTransactionUsecase(
 		txManager:        txManager,
 		accountsRepo:     accountsRepo,
 		transactionsRepo: transactionsRepo,
+		statsCache:       statsCache,
 	}
 }
 
@@ -104,6 +108,8 @@ func (c *DeleteTransactionUsecase) DeleteTransaction(ctx context.Context, cmd *D
 			return err
 		}
 
+		c.statsCache.Invalidate(ctx, userID)
+
 		return nil
 	})
 
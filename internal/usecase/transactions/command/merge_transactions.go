@@ -0,0 +1,175 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
+	"github.com/AsaHero/e-wallet/pkg/database/postgres"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type MergeTransactionsUsecase struct {
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	txManager        postgres.TxManager
+	accountsRepo     entities.AccountRepository
+	transactionsRepo entities.TransactionRepository
+	statsCache       ports.StatsCache
+}
+
+func NewMergeTransactionsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	txManager postgres.TxManager,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+	statsCache ports.StatsCache,
+) *MergeTransactionsUsecase {
+	return &MergeTransactionsUsecase{
+		contextTimeout:   timeout,
+		logger:           logger,
+		txManager:        txManager,
+		accountsRepo:     accountsRepo,
+		transactionsRepo: transactionsRepo,
+		statsCache:       statsCache,
+	}
+}
+
+// MergeTransactionsCommand keeps TransactionID and removes DuplicateID.
+type MergeTransactionsCommand struct {
+	UserID        string
+	TransactionID string
+	DuplicateID   string
+}
+
+// MergeTransactions removes the duplicate as if it was deleted, reverting
+// its effect on its account balance, and fills the kept transaction's blank
+// merchant, note and category from it.
+func (c *MergeTransactionsUsecase) MergeTransactions(ctx context.Context, cmd *MergeTransactionsCommand) (_ *entities.Transaction, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "MergeTransactions",
+		attribute.String("user_id", cmd.UserID),
+		attribute.String("transaction_id", cmd.TransactionID),
+		attribute.String("duplicate_id", cmd.DuplicateID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID        uuid.UUID
+		transactionID uuid.UUID
+		duplicateID   uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.transactionID, err = uuid.Parse(cmd.TransactionID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to parse transaction id", err)
+			return nil, inerr.NewErrValidation("transaction_id", "invalid uuid type")
+		}
+
+		input.duplicateID, err = uuid.Parse(cmd.DuplicateID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to parse duplicate id", err)
+			return nil, inerr.NewErrValidation("duplicate_id", "invalid uuid type")
+		}
+
+		if input.transactionID == input.duplicateID {
+			return nil, inerr.NewErrValidation("duplicate_id", "a transaction cannot be merged with itself")
+		}
+	}
+
+	var transaction *entities.Transaction
+	err = c.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// read once to find the account, it is locked before the
+		// transactions like everywhere else
+		duplicate, err := c.transactionsRepo.GetByID(ctx, input.duplicateID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to get duplicate transaction", err)
+			return err
+		}
+
+		if duplicate.UserID != input.userID {
+			return inerr.NewErrNotFound("transaction")
+		}
+
+		account, err := c.accountsRepo.GetByIDForUpdate(ctx, duplicate.AccountID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to get account", err)
+			return err
+		}
+
+		// read again under lock, a concurrent merge or delete may have
+		// removed the duplicate and reverted it from the balance already
+		duplicate, err = c.transactionsRepo.GetByIDForUpdate(ctx, input.duplicateID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to get duplicate transaction", err)
+			return err
+		}
+
+		if duplicate.AccountID != account.ID {
+			return inerr.NewErrConflict("transaction")
+		}
+
+		transaction, err = c.transactionsRepo.GetByIDForUpdate(ctx, input.transactionID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to get transaction", err)
+			return err
+		}
+
+		if transaction.UserID != input.userID {
+			return inerr.NewErrNotFound("transaction")
+		}
+
+		err = account.RevertTransaction(duplicate)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to revert transaction", err)
+			return err
+		}
+
+		err = c.accountsRepo.Save(ctx, account)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to save account", err)
+			return err
+		}
+
+		// Deleted first, the kept transaction may take over its bank
+		// reference which is unique per account.
+		err = c.transactionsRepo.Delete(ctx, duplicate.ID)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to delete transaction", err)
+			return err
+		}
+
+		transaction.Absorb(duplicate)
+
+		err = c.transactionsRepo.Save(ctx, transaction)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "failed to save transaction", err)
+			return err
+		}
+
+		c.statsCache.Invalidate(ctx, input.userID)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
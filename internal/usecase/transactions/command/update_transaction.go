@@ -6,6 +6,7 @@ import (
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
@@ -23,6 +24,7 @@ type UpdateTransactionUsecase struct {
 	transactionsRepo  entities.TransactionRepository
 	categoryRepo      entities.CategoryRepository
 	subcategoriesRepo entities.SubcategoryRepository
+	statsCache        ports.StatsCache
 }
 
 func NewUpdateTransactionUsecase(
@@ -34,6 +36,7 @@ func NewUpdateTransactionUsecase(
 	transactionsRepo entities.TransactionRepository,
 	categoriesRepo entities.CategoryRepository,
 	subcategoriesRepo entities.SubcategoryRepository,
+	statsCache ports.StatsCache,
 ) *UpdateTransactionUsecase {
 	return &UpdateTransactionUsecase{
 		contextTimeout:    timeout,
@@ -44,6 +47,7 @@ func NewUpdateTransactionUsecase(
 		subcategoriesRepo: subcategoriesRepo,
 		logger:            logger,
 		txManager:         txManager,
+		statsCache:        statsCache,
 	}
 }
 
@@ -60,6 +64,7 @@ type UpdateTransactionCommand struct {
 	OriginalCurrencyCode *string
 	FxRate               *float64
 	Note                 string
+	Merchant             string
 	PerformedAt          *time.Time
 }
 
@@ -181,6 +186,7 @@ func (c *UpdateTransactionUsecase) UpdateTransaction(ctx context.Context, cmd *U
 			cmd.OriginalCurrencyCode,
 			cmd.FxRate,
 			cmd.Note,
+			cmd.Merchant,
 			cmd.PerformedAt,
 		)
 		if err != nil {
@@ -217,6 +223,8 @@ func (c *UpdateTransactionUsecase) UpdateTransaction(ctx context.Context, cmd *U
 			return err
 		}
 
+		c.statsCache.Invalidate(ctx, input.userID)
+
 		return nil
 	})
 	if err != nil {
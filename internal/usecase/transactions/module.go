@@ -4,23 +4,31 @@ import (
 	"time"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/internal/usecase/transactions/command"
 	"github.com/AsaHero/e-wallet/internal/usecase/transactions/query"
 
 	"github.com/AsaHero/e-wallet/pkg/database/postgres"
 	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/hibiken/asynq"
 )
 
 type Commands struct {
 	*command.CreateTransactionUsecase
 	*command.DeleteTransactionUsecase
 	*command.UpdateTransactionUsecase
+	*command.MergeTransactionsUsecase
 }
 
 type Query struct {
 	*query.GetByIDUsecase
 	*query.GetByFilterUsecase
 	*query.GetStatsUsecase
+	*query.SearchUsecase
+	*query.GetTimeseriesUsecase
+	*query.GetStatsComparisonUsecase
+	*query.ExportTransactionsUsecase
+	*query.GetDuplicatesUsecase
 }
 
 type Module struct {
@@ -37,7 +45,19 @@ func NewModule(
 	transactionsRepo entities.TransactionRepository,
 	categortiesRepo entities.CategoryRepository,
 	subcategoriesRepo entities.SubcategoryRepository,
+	taskQueue *asynq.Client,
+	statsCache ports.StatsCache,
 ) *Module {
+	creator := command.NewTransactionCreator(
+		logger,
+		accountsRepo,
+		transactionsRepo,
+		categortiesRepo,
+		subcategoriesRepo,
+		taskQueue,
+		statsCache,
+	)
+
 	m := &Module{
 		Command: Commands{
 			CreateTransactionUsecase: command.NewCreateTransactionUsecase(
@@ -45,10 +65,7 @@ func NewModule(
 				logger,
 				txManager,
 				usersRepo,
-				accountsRepo,
-				transactionsRepo,
-				categortiesRepo,
-				subcategoriesRepo,
+				creator,
 			),
 			DeleteTransactionUsecase: command.NewDeleteTransactionUsecase(
 				timeout,
@@ -56,6 +73,7 @@ func NewModule(
 				txManager,
 				accountsRepo,
 				transactionsRepo,
+				statsCache,
 			),
 			UpdateTransactionUsecase: command.NewUpdateTransactionUsecase(
 				timeout,
@@ -66,12 +84,26 @@ func NewModule(
 				transactionsRepo,
 				categortiesRepo,
 				subcategoriesRepo,
+				statsCache,
+			),
+			MergeTransactionsUsecase: command.NewMergeTransactionsUsecase(
+				timeout,
+				logger,
+				txManager,
+				accountsRepo,
+				transactionsRepo,
+				statsCache,
 			),
 		},
 		Query: Query{
-			GetByIDUsecase:     query.NewGetByIDUsecase(timeout, logger, transactionsRepo),
-			GetByFilterUsecase: query.NewGetByFilterUsecase(timeout, logger, transactionsRepo),
-			GetStatsUsecase:    query.NewGetStatsUsecase(timeout, logger, usersRepo, accountsRepo, transactionsRepo, categortiesRepo),
+			GetByIDUsecase:            query.NewGetByIDUsecase(timeout, logger, transactionsRepo),
+			GetByFilterUsecase:        query.NewGetByFilterUsecase(timeout, logger, transactionsRepo),
+			GetStatsUsecase:           query.NewGetStatsUsecase(timeout, logger, usersRepo, accountsRepo, transactionsRepo, categortiesRepo, subcategoriesRepo, statsCache),
+			SearchUsecase:             query.NewSearchUsecase(timeout, logger, usersRepo, transactionsRepo),
+			GetTimeseriesUsecase:      query.NewGetTimeseriesUsecase(timeout, logger, usersRepo, transactionsRepo, categortiesRepo),
+			GetStatsComparisonUsecase: query.NewGetStatsComparisonUsecase(timeout, logger, usersRepo, transactionsRepo, categortiesRepo),
+			ExportTransactionsUsecase: query.NewExportTransactionsUsecase(timeout, logger, usersRepo, accountsRepo, transactionsRepo),
+			GetDuplicatesUsecase:      query.NewGetDuplicatesUsecase(timeout, logger, usersRepo, transactionsRepo),
 		},
 	}
 
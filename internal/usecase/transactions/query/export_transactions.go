@@ -0,0 +1,361 @@
+package query
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/AsaHero/e-wallet/pkg/xlsx"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// exportBatchSize is how many rows are read per query while streaming.
+const exportBatchSize = 500
+
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// exportColumns lists every column in the default order. The header is the
+// column key so files stay stable across user languages.
+var exportColumns = []string{
+	"date",
+	"type",
+	"status",
+	"account",
+	"category",
+	"subcategory",
+	"amount",
+	"currency",
+	"original_amount",
+	"original_currency",
+	"fx_rate",
+	"merchant",
+	"note",
+}
+
+type ExportTransactionsUsecase struct {
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	usersRepo        entities.UserRepository
+	accountsRepo     entities.AccountRepository
+	transactionsRepo entities.TransactionRepository
+}
+
+func NewExportTransactionsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	accountsRepo entities.AccountRepository,
+	transactionsRepo entities.TransactionRepository,
+) *ExportTransactionsUsecase {
+	return &ExportTransactionsUsecase{
+		contextTimeout:   timeout,
+		logger:           logger,
+		usersRepo:        usersRepo,
+		accountsRepo:     accountsRepo,
+		transactionsRepo: transactionsRepo,
+	}
+}
+
+type ExportTransactionsQuery struct {
+	UserID    string
+	Format    string
+	Columns   []string
+	AccountID string
+	Type      string
+	Period    string
+	From      string
+	To        string
+}
+
+// TransactionsExport is a validated export ready to be streamed. Nothing is
+// read from the database before Write, so the caller can still send headers.
+type TransactionsExport struct {
+	Filename    string
+	ContentType string
+
+	format       string
+	columns      []string
+	filter       entities.TransactionFilter
+	user         *entities.User
+	accounts     map[uuid.UUID]string
+	transactions entities.TransactionRepository
+}
+
+func (u *ExportTransactionsUsecase) ExportTransactions(ctx context.Context, query *ExportTransactionsQuery) (_ *TransactionsExport, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "ExportTransactions",
+		attribute.String("user_id", query.UserID),
+		attribute.String("format", query.Format),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		format    string
+		columns   []string
+		accountID *uuid.UUID
+		types     []entities.TrnType
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(query.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.format = strings.ToLower(query.Format)
+		if input.format == "" {
+			input.format = ExportCSV
+		}
+		if input.format != ExportCSV && input.format != ExportXLSX {
+			return nil, inerr.NewErrValidation("format", "format must be csv or xlsx")
+		}
+
+		input.columns = exportColumns
+		if len(query.Columns) > 0 {
+			input.columns = make([]string, 0, len(query.Columns))
+			for _, column := range query.Columns {
+				column = strings.ToLower(strings.TrimSpace(column))
+				if !isExportColumn(column) {
+					return nil, inerr.NewErrValidation("columns", "unknown column "+column)
+				}
+				input.columns = append(input.columns, column)
+			}
+		}
+
+		if query.AccountID != "" {
+			accountID, err := uuid.Parse(query.AccountID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to parse account id", err)
+				return nil, inerr.NewErrValidation("account_id", "invalid uuid type")
+			}
+			input.accountID = &accountID
+		}
+
+		if query.Type != "" {
+			switch t := entities.TrnType(query.Type); t {
+			case entities.Deposit, entities.Withdrawal, entities.Transfer, entities.Adjustment:
+				input.types = []entities.TrnType{t}
+			default:
+				return nil, inerr.NewErrValidation("type", "unknown transaction type")
+			}
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	periods := user.Periods()
+	filter := entities.TransactionFilter{
+		UserID:    user.ID,
+		AccountID: input.accountID,
+		Types:     input.types,
+	}
+	if query.Period != "" || query.From != "" || query.To != "" {
+		period, err := periods.Resolve(query.Period, query.From, query.To, nil)
+		if err != nil {
+			return nil, inerr.AsValidation(err)
+		}
+		if !period.From.IsZero() {
+			filter.From = &period.From
+		}
+		// Only an explicit end, an open one keeps future-dated entries.
+		if query.Period != "" || query.To != "" {
+			filter.To = &period.To
+		}
+	}
+
+	accounts, err := u.accountsRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get accounts", err)
+		return nil, err
+	}
+
+	export := &TransactionsExport{
+		Filename:     fmt.Sprintf("transactions-%s.%s", periods.Now().Format("2006-01-02"), input.format),
+		ContentType:  "text/csv; charset=utf-8",
+		format:       input.format,
+		columns:      input.columns,
+		filter:       filter,
+		user:         user,
+		accounts:     make(map[uuid.UUID]string, len(accounts)),
+		transactions: u.transactionsRepo,
+	}
+	if input.format == ExportXLSX {
+		export.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	for _, account := range accounts {
+		export.accounts[account.ID] = account.Name
+	}
+
+	return export, nil
+}
+
+// Write streams the rows to w batch by batch.
+func (e *TransactionsExport) Write(ctx context.Context, w io.Writer) (err error) {
+	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "WriteTransactionsExport",
+		attribute.String("user_id", e.user.ID.String()),
+		attribute.String("format", e.format),
+	)
+	defer func() { end(err) }()
+
+	var (
+		writeRow func(values []string, header bool) error
+		flush    func() error
+		closeFn  func() error
+	)
+
+	switch e.format {
+	case ExportXLSX:
+		xw, err := xlsx.NewWriter(w, "Transactions")
+		if err != nil {
+			return err
+		}
+		writeRow = func(values []string, header bool) error {
+			cells := make([]xlsx.Cell, len(values))
+			for i, value := range values {
+				switch {
+				case header:
+					cells[i] = xlsx.Header(value)
+				case isNumericColumn(e.columns[i]):
+					cells[i] = xlsx.Number(value)
+				default:
+					cells[i] = xlsx.String(value)
+				}
+			}
+			return xw.WriteRow(cells...)
+		}
+		flush = xw.Flush
+		closeFn = xw.Close
+	default:
+		// The byte order mark makes Excel open the file as UTF-8.
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		writeRow = func(values []string, _ bool) error {
+			return cw.Write(values)
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+		closeFn = flush
+	}
+
+	if err := writeRow(e.columns, true); err != nil {
+		return err
+	}
+
+	var cursor *entities.TransactionCursor
+	for {
+		batch, err := e.transactions.GetBatch(ctx, e.filter, cursor, exportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range batch {
+			if err := writeRow(e.row(t), false); err != nil {
+				return err
+			}
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+
+		if len(batch) < exportBatchSize {
+			break
+		}
+
+		last := batch[len(batch)-1]
+		cursor = &entities.TransactionCursor{OccurredAt: last.OccurredAt(), ID: last.ID}
+	}
+
+	return closeFn()
+}
+
+func (e *TransactionsExport) row(t *entities.Transaction) []string {
+	lang := e.user.LanguageCode
+
+	values := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		switch column {
+		case "date":
+			values[i] = t.OccurredAt().In(e.user.Location()).Format(time.DateTime)
+		case "type":
+			values[i] = t.Type.String()
+		case "status":
+			values[i] = t.Status.String()
+		case "account":
+			values[i] = e.accounts[t.AccountID]
+		case "category":
+			if t.Category != nil {
+				values[i] = t.Category.GetName(lang)
+			}
+		case "subcategory":
+			if t.Subcategory != nil {
+				values[i] = t.Subcategory.GetName(lang)
+			}
+		case "amount":
+			values[i] = formatMinor(t.AmountMinor(), t.CurrencyCode)
+		case "currency":
+			values[i] = t.CurrencyCode.String()
+		case "original_amount":
+			if t.OriginalCurrencyCode != "" {
+				values[i] = formatMinor(t.OriginalAmountMinor(), t.OriginalCurrencyCode)
+			}
+		case "original_currency":
+			values[i] = t.OriginalCurrencyCode.String()
+		case "fx_rate":
+			if t.FxRate != 0 {
+				values[i] = strconv.FormatFloat(t.FxRate, 'f', -1, 64)
+			}
+		case "merchant":
+			values[i] = t.Merchant
+		case "note":
+			values[i] = t.RowText
+		}
+	}
+
+	return values
+}
+
+// formatMinor renders an amount with exactly the currency's scale, so 1050
+// tiyin is "10.50" and 1050 yen stays "1050".
+func formatMinor(minor int64, currency entities.Currency) string {
+	scale := currency.Scale()
+	return strconv.FormatFloat(entities.MajorFromMinor(minor, scale), 'f', scale, 64)
+}
+
+func isExportColumn(column string) bool {
+	for _, c := range exportColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumericColumn(column string) bool {
+	return column == "amount" || column == "original_amount" || column == "fx_rate"
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// duplicatesDefaultDays is how far back possible duplicates are looked for
+// when no period is given.
+const duplicatesDefaultDays = 30
+
+type GetDuplicatesUsecase struct {
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	usersRepo        entities.UserRepository
+	transactionsRepo entities.TransactionRepository
+}
+
+func NewGetDuplicatesUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+) *GetDuplicatesUsecase {
+	return &GetDuplicatesUsecase{
+		contextTimeout:   timeout,
+		logger:           logger,
+		usersRepo:        usersRepo,
+		transactionsRepo: transactionsRepo,
+	}
+}
+
+type GetDuplicatesQuery struct {
+	UserID    string
+	AccountID string
+	Period    string
+	From      string
+	To        string
+}
+
+// GetDuplicates lists pairs of transactions that likely record the same
+// money movement, performed in the named Period or between From and To
+// inclusive in the user's time zone. It defaults to the last 30 days.
+func (u *GetDuplicatesUsecase) GetDuplicates(ctx context.Context, query *GetDuplicatesQuery) (_ []entities.DuplicatePair, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "GetDuplicates",
+		attribute.String("user_id", query.UserID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		accountID *uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(query.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		if query.AccountID != "" {
+			accountID, err := uuid.Parse(query.AccountID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to parse account id", err)
+				return nil, inerr.NewErrValidation("account_id", "invalid uuid type")
+			}
+			input.accountID = &accountID
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	period, err := user.Periods().Resolve(query.Period, query.From, query.To, func(to time.Time) time.Time {
+		return to.AddDate(0, 0, -duplicatesDefaultDays)
+	})
+	if err != nil {
+		return nil, inerr.AsValidation(err)
+	}
+
+	transactions, err := u.transactionsRepo.GetPerformedBetween(ctx, user.ID, period.From, period.To)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get transactions", err)
+		return nil, err
+	}
+
+	if input.accountID != nil {
+		filtered := transactions[:0]
+		for _, transaction := range transactions {
+			if transaction.AccountID == *input.accountID {
+				filtered = append(filtered, transaction)
+			}
+		}
+		transactions = filtered
+	}
+
+	return entities.FindDuplicatePairs(transactions), nil
+}
@@ -2,25 +2,28 @@ package query
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
-	"github.com/AsaHero/e-wallet/pkg/utils"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 )
 
 type GetStatsUsecase struct {
-	contextTimeout   time.Duration
-	logger           *logger.Logger
-	usersRepo        entities.UserRepository
-	accountsRepo     entities.AccountRepository
-	transactionsRepo entities.TransactionRepository
-	categoriesRepo   entities.CategoryRepository
+	contextTimeout    time.Duration
+	logger            *logger.Logger
+	usersRepo         entities.UserRepository
+	accountsRepo      entities.AccountRepository
+	transactionsRepo  entities.TransactionRepository
+	categoriesRepo    entities.CategoryRepository
+	subcategoriesRepo entities.SubcategoryRepository
+	statsCache        ports.StatsCache
 }
 
 func NewGetStatsUsecase(
@@ -30,14 +33,18 @@ func NewGetStatsUsecase(
 	accountsRepo entities.AccountRepository,
 	transactionsRepo entities.TransactionRepository,
 	categoriesRepo entities.CategoryRepository,
+	subcategoriesRepo entities.SubcategoryRepository,
+	statsCache ports.StatsCache,
 ) *GetStatsUsecase {
 	return &GetStatsUsecase{
-		contextTimeout:   timeout,
-		transactionsRepo: transactionsRepo,
-		usersRepo:        usersRepo,
-		accountsRepo:     accountsRepo,
-		categoriesRepo:   categoriesRepo,
-		logger:           logger,
+		contextTimeout:    timeout,
+		transactionsRepo:  transactionsRepo,
+		usersRepo:         usersRepo,
+		accountsRepo:      accountsRepo,
+		categoriesRepo:    categoriesRepo,
+		subcategoriesRepo: subcategoriesRepo,
+		logger:            logger,
+		statsCache:        statsCache,
 	}
 }
 
@@ -49,20 +56,38 @@ type GetStatsView struct {
 	ExpenseByCategory []CategoryStat `json:"expense_by_category"`
 }
 
+// CategoryStat is the total of one category. Transactions without a category
+// are reported under category 0 with Uncategorized set.
 type CategoryStat struct {
-	CategoryID    int     `json:"category_id"`
-	CategoryName  string  `json:"category_name"`
-	CategoryEmoji string  `json:"category_emoji"`
-	Total         float64 `json:"total"`
+	CategoryID    int               `json:"category_id"`
+	CategoryName  string            `json:"category_name"`
+	CategoryEmoji string            `json:"category_emoji"`
+	Uncategorized bool              `json:"uncategorized"`
+	Total         float64           `json:"total"`
+	Count         int               `json:"count"`
+	Subcategories []SubcategoryStat `json:"subcategories"`
 }
 
-func (u *GetStatsUsecase) GetStats(ctx context.Context, userID string, accountID string, from string, to string) (_ *GetStatsView, err error) {
+// SubcategoryStat is the total of one subcategory within its category.
+// Transactions without a subcategory are reported under subcategory 0.
+type SubcategoryStat struct {
+	SubcategoryID    int     `json:"subcategory_id"`
+	SubcategoryName  string  `json:"subcategory_name"`
+	SubcategoryEmoji string  `json:"subcategory_emoji"`
+	Total            float64 `json:"total"`
+	Count            int     `json:"count"`
+}
+
+// GetStats sums up the transactions of a named period, or between from and
+// to inclusive, in the user's time zone. Without either the range is open.
+func (u *GetStatsUsecase) GetStats(ctx context.Context, userID string, accountID string, period string, from string, to string) (_ *GetStatsView, err error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
 	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "GetStats",
 		attribute.String("user_id", userID),
 		attribute.String("account_id", accountID),
+		attribute.String("period", period),
 		attribute.String("from", from),
 		attribute.String("to", to),
 	)
@@ -90,25 +115,6 @@ func (u *GetStatsUsecase) GetStats(ctx context.Context, userID string, accountID
 			}
 			input.accountID = &accountUUID
 		}
-
-		if from != "" {
-			from, err := time.Parse(time.DateOnly, from)
-			if err != nil {
-				u.logger.ErrorContext(ctx, "failed to parse from", err)
-				return nil, inerr.NewErrValidation("from", "invalud date format")
-			}
-			input.from = &from
-		}
-
-		if to != "" {
-			to, err := time.Parse(time.DateOnly, to)
-			if err != nil {
-				u.logger.ErrorContext(ctx, "failed to parse to", err)
-				return nil, inerr.NewErrValidation("to", "invalud date format")
-			}
-			to = utils.EndOfDate(to)
-			input.to = &to
-		}
 	}
 
 	user, err := u.usersRepo.FindByID(ctx, input.userID)
@@ -117,34 +123,79 @@ func (u *GetStatsUsecase) GetStats(ctx context.Context, userID string, accountID
 		return nil, err
 	}
 
-	totalIncome, err := u.transactionsRepo.GetTotalByTypeAndAccount(ctx, user.ID, input.accountID, entities.Deposit, input.from, input.to)
-	if err != nil {
-		u.logger.ErrorContext(ctx, "failed to get total income", err)
-		return nil, err
+	if period != "" || from != "" || to != "" {
+		resolved, err := user.Periods().Resolve(period, from, to, nil)
+		if err != nil {
+			return nil, inerr.AsValidation(err)
+		}
+		if !resolved.From.IsZero() {
+			input.from = &resolved.From
+		}
+		input.to = &resolved.To
+	}
+
+	filter := entities.CategoryBreakdownFilter{
+		UserID:    user.ID,
+		AccountID: input.accountID,
+		From:      input.from,
+		To:        input.to,
+	}
+	key := statsCacheKey(filter, user.Location())
+
+	// The generation is read before computing, a write landing meanwhile
+	// bumps it and the stored view is never served.
+	generation, err := u.statsCache.Generation(ctx, user.ID)
+	cacheable := err == nil
+	if !cacheable {
+		u.logger.ErrorContext(ctx, "failed to get stats cache generation", err)
+	} else {
+		var cached GetStatsView
+		found, err := u.statsCache.Get(ctx, user.ID, generation, key, &cached)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get cached stats", err)
+		}
+		if found {
+			return &cached, nil
+		}
 	}
 
-	totalExpense, err := u.transactionsRepo.GetTotalByTypeAndAccount(ctx, user.ID, input.accountID, entities.Withdrawal, input.from, input.to)
+	response, err := u.stats(ctx, user, filter)
 	if err != nil {
-		u.logger.ErrorContext(ctx, "failed to get total expense", err)
 		return nil, err
 	}
 
+	if cacheable {
+		if err := u.statsCache.Set(ctx, user.ID, generation, key, response); err != nil {
+			u.logger.ErrorContext(ctx, "failed to cache stats", err)
+		}
+	}
+
+	return response, nil
+}
+
+// stats computes the view from the daily rollups.
+func (u *GetStatsUsecase) stats(ctx context.Context, user *entities.User, filter entities.CategoryBreakdownFilter) (*GetStatsView, error) {
 	balance, err := u.accountsRepo.GetTotalBalance(ctx, user.ID)
 	if err != nil {
 		u.logger.ErrorContext(ctx, "failed to get balance", err)
 		return nil, err
 	}
 
-	incomeByCategory, incomeCategories, err := u.transactionsRepo.GetTotalsByCategoriesAndAccount(ctx, user.ID, input.accountID, entities.Deposit, input.from, input.to)
+	// One pass over the daily rollups, the totals are the sums of the rows.
+	breakdown, err := u.transactionsRepo.GetCategoryBreakdown(ctx, filter)
 	if err != nil {
 		u.logger.ErrorContext(ctx, "failed to get stats by category", err)
 		return nil, err
 	}
 
-	expenseByCategory, expenseCategories, err := u.transactionsRepo.GetTotalsByCategoriesAndAccount(ctx, user.ID, input.accountID, entities.Withdrawal, input.from, input.to)
-	if err != nil {
-		u.logger.ErrorContext(ctx, "failed to get stats by category", err)
-		return nil, err
+	var totalIncome, totalExpense int64
+	for _, row := range breakdown {
+		switch row.Type {
+		case entities.Deposit:
+			totalIncome += row.Total
+		case entities.Withdrawal:
+			totalExpense += row.Total
+		}
 	}
 
 	response := &GetStatsView{
@@ -153,45 +204,135 @@ func (u *GetStatsUsecase) GetStats(ctx context.Context, userID string, accountID
 		Balance:      entities.MajorFromMinor(balance, user.CurrencyCode.Scale()),
 	}
 
-	for _, categoryID := range incomeCategories {
-		category, err := u.categoriesRepo.FindByID(ctx, categoryID)
-		if err != nil {
-			u.logger.ErrorContext(ctx, "failed to get catzegory", err)
-			return nil, err
-		}
+	categories, err := categoriesByID(ctx, u.categoriesRepo, user.ID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get categories", err)
+		return nil, err
+	}
 
-		total, ok := incomeByCategory[categoryID]
-		if !ok {
-			continue
-		}
+	subcategories, err := subcategoriesByID(ctx, u.subcategoriesRepo, user.ID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get subcategories", err)
+		return nil, err
+	}
 
-		response.IncomeByCategory = append(response.IncomeByCategory, CategoryStat{
-			CategoryID:    category.ID.Int(),
-			CategoryName:  category.GetName(user.LanguageCode),
-			CategoryEmoji: category.Emoji,
-			Total:         entities.MajorFromMinor(total, user.CurrencyCode.Scale()),
-		})
+	names := statNames{
+		categories:    categories,
+		subcategories: subcategories,
+		lang:          user.LanguageCode,
+		scale:         user.CurrencyCode.Scale(),
 	}
+	response.IncomeByCategory = names.categoryStats(breakdown, entities.Deposit)
+	response.ExpenseByCategory = names.categoryStats(breakdown, entities.Withdrawal)
 
-	for _, categoryID := range expenseCategories {
-		category, err := u.categoriesRepo.FindByID(ctx, categoryID)
-		if err != nil {
-			u.logger.ErrorContext(ctx, "failed to get catzegory", err)
-			return nil, err
+	return response, nil
+}
+
+// statsCacheKey identifies a view within the user's cache generation, by
+// account and the local days the period spans.
+func statsCacheKey(filter entities.CategoryBreakdownFilter, loc *time.Location) string {
+	account, from, to := "all", "open", "open"
+	if filter.AccountID != nil {
+		account = filter.AccountID.String()
+	}
+	if filter.From != nil {
+		from = filter.From.In(loc).Format(time.DateOnly)
+	}
+	if filter.To != nil {
+		to = filter.To.In(loc).Format(time.DateOnly)
+	}
+	return "summary:" + account + ":" + from + ":" + to
+}
+
+type statNames struct {
+	categories    map[int]*entities.Category
+	subcategories map[int]*entities.Subcategory
+	lang          entities.Language
+	scale         int
+}
+
+// categoryStats folds the breakdown rows of one type into category stats,
+// keeping the order of the rows, which come sorted by total.
+func (n statNames) categoryStats(rows []*entities.CategoryBreakdownRow, trnType entities.TrnType) []CategoryStat {
+	var (
+		totals = make(map[int]int64)
+		stats  []CategoryStat
+		index  = make(map[int]int)
+	)
+	for _, row := range rows {
+		if row.Type != trnType {
+			continue
 		}
 
-		total, ok := expenseByCategory[categoryID]
+		i, ok := index[row.CategoryID]
 		if !ok {
-			continue
+			stat := CategoryStat{
+				CategoryID:    row.CategoryID,
+				Uncategorized: row.CategoryID == 0,
+				Subcategories: []SubcategoryStat{},
+			}
+			if category, ok := n.categories[row.CategoryID]; ok {
+				stat.CategoryName = category.GetName(n.lang)
+				stat.CategoryEmoji = category.Emoji
+			}
+
+			i = len(stats)
+			index[row.CategoryID] = i
+			stats = append(stats, stat)
 		}
 
-		response.ExpenseByCategory = append(response.ExpenseByCategory, CategoryStat{
-			CategoryID:    category.ID.Int(),
-			CategoryName:  category.GetName(user.LanguageCode),
-			CategoryEmoji: category.Emoji,
-			Total:         entities.MajorFromMinor(total, user.CurrencyCode.Scale()),
-		})
+		totals[row.CategoryID] += row.Total
+		stats[i].Count += row.Count
+
+		subStat := SubcategoryStat{
+			SubcategoryID: row.SubcategoryID,
+			Total:         entities.MajorFromMinor(row.Total, n.scale),
+			Count:         row.Count,
+		}
+		if subcategory, ok := n.subcategories[row.SubcategoryID]; ok {
+			subStat.SubcategoryName = subcategory.GetName(n.lang)
+			subStat.SubcategoryEmoji = subcategory.Emoji
+		}
+		stats[i].Subcategories = append(stats[i].Subcategories, subStat)
 	}
 
-	return response, nil
+	for i := range stats {
+		stats[i].Total = entities.MajorFromMinor(totals[stats[i].CategoryID], n.scale)
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return totals[stats[i].CategoryID] > totals[stats[j].CategoryID]
+	})
+
+	return stats
+}
+
+// categoriesByID loads every category visible to the user once, so stats can
+// resolve names without a lookup per row.
+func categoriesByID(ctx context.Context, categoriesRepo entities.CategoryRepository, userID uuid.UUID) (map[int]*entities.Category, error) {
+	categories, err := categoriesRepo.FindAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]*entities.Category, len(categories))
+	for _, category := range categories {
+		result[category.ID.Int()] = category
+	}
+
+	return result, nil
+}
+
+func subcategoriesByID(ctx context.Context, subcategoriesRepo entities.SubcategoryRepository, userID uuid.UUID) (map[int]*entities.Subcategory, error) {
+	subcategories, err := subcategoriesRepo.FindAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]*entities.Subcategory, len(subcategories))
+	for _, subcategory := range subcategories {
+		result[subcategory.ID] = subcategory
+	}
+
+	return result, nil
 }
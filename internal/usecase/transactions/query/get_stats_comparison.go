@@ -0,0 +1,228 @@
+package query
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type GetStatsComparisonUsecase struct {
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	usersRepo        entities.UserRepository
+	transactionsRepo entities.TransactionRepository
+	categoriesRepo   entities.CategoryRepository
+}
+
+func NewGetStatsComparisonUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+	categoriesRepo entities.CategoryRepository,
+) *GetStatsComparisonUsecase {
+	return &GetStatsComparisonUsecase{
+		contextTimeout:   timeout,
+		logger:           logger,
+		usersRepo:        usersRepo,
+		transactionsRepo: transactionsRepo,
+		categoriesRepo:   categoriesRepo,
+	}
+}
+
+type GetStatsComparisonQuery struct {
+	UserID    string
+	AccountID string
+	Period    string
+	From      string
+	To        string
+}
+
+type StatsComparisonView struct {
+	Timezone          string               `json:"timezone"`
+	Currency          string               `json:"currency"`
+	Current           PeriodRange          `json:"current"`
+	Previous          PeriodRange          `json:"previous"`
+	Income            ComparisonValue      `json:"income"`
+	Expense           ComparisonValue      `json:"expense"`
+	Net               ComparisonValue      `json:"net"`
+	IncomeByCategory  []CategoryComparison `json:"income_by_category"`
+	ExpenseByCategory []CategoryComparison `json:"expense_by_category"`
+}
+
+// PeriodRange is an inclusive date range in the user's timezone.
+type PeriodRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type ComparisonValue struct {
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	Delta    float64 `json:"delta"`
+	// DeltaPercent is nil when there is nothing to compare against.
+	DeltaPercent *float64 `json:"delta_percent"`
+}
+
+type CategoryComparison struct {
+	CategoryID    int    `json:"category_id"`
+	CategoryName  string `json:"category_name"`
+	CategoryEmoji string `json:"category_emoji"`
+	ComparisonValue
+}
+
+func (u *GetStatsComparisonUsecase) GetStatsComparison(ctx context.Context, query *GetStatsComparisonQuery) (_ *StatsComparisonView, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "GetStatsComparison",
+		attribute.String("user_id", query.UserID),
+		attribute.String("account_id", query.AccountID),
+		attribute.String("from", query.From),
+		attribute.String("to", query.To),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID    uuid.UUID
+		accountID *uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(query.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		if query.AccountID != "" {
+			accountID, err := uuid.Parse(query.AccountID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to parse account id", err)
+				return nil, inerr.NewErrValidation("account_id", "invalid uuid type")
+			}
+			input.accountID = &accountID
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	periods := user.Periods()
+	loc := periods.Location()
+
+	// Without dates the current month up to and including today.
+	period, err := periods.Resolve(query.Period, query.From, query.To, func(time.Time) time.Time {
+		thisMonth, _ := periods.Named(entities.PeriodThisMonth)
+		return thisMonth.From
+	})
+	if err != nil {
+		return nil, inerr.AsValidation(err)
+	}
+	from, to := period.From, period.To
+	prevFrom, prevTo := entities.PreviousPeriod(from, to)
+
+	rows, err := u.transactionsRepo.GetCategoryTotalsComparison(ctx, entities.PeriodComparisonFilter{
+		UserID:       user.ID,
+		AccountID:    input.accountID,
+		CurrentFrom:  from,
+		CurrentTo:    to,
+		PreviousFrom: prevFrom,
+		PreviousTo:   prevTo,
+	})
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get category totals comparison", err)
+		return nil, err
+	}
+
+	categories, err := categoriesByID(ctx, u.categoriesRepo, user.ID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get categories", err)
+		return nil, err
+	}
+
+	scale := user.CurrencyCode.Scale()
+
+	var income, expense entities.CategoryComparisonRow
+	view := &StatsComparisonView{
+		Timezone: loc.String(),
+		Currency: user.CurrencyCode.String(),
+		Current: PeriodRange{
+			From: from.Format(time.DateOnly),
+			To:   to.AddDate(0, 0, -1).Format(time.DateOnly),
+		},
+		Previous: PeriodRange{
+			From: prevFrom.Format(time.DateOnly),
+			To:   prevTo.AddDate(0, 0, -1).Format(time.DateOnly),
+		},
+		IncomeByCategory:  []CategoryComparison{},
+		ExpenseByCategory: []CategoryComparison{},
+	}
+
+	for _, row := range rows {
+		item := CategoryComparison{
+			CategoryID:      row.CategoryID,
+			ComparisonValue: newComparisonValue(row.Current, row.Previous, scale),
+		}
+		if category, ok := categories[row.CategoryID]; ok {
+			item.CategoryName = category.GetName(user.LanguageCode)
+			item.CategoryEmoji = category.Emoji
+		}
+
+		switch row.Type {
+		case entities.Deposit:
+			income.Current += row.Current
+			income.Previous += row.Previous
+			view.IncomeByCategory = append(view.IncomeByCategory, item)
+		case entities.Withdrawal:
+			expense.Current += row.Current
+			expense.Previous += row.Previous
+			view.ExpenseByCategory = append(view.ExpenseByCategory, item)
+		}
+	}
+
+	view.Income = newComparisonValue(income.Current, income.Previous, scale)
+	view.Expense = newComparisonValue(expense.Current, expense.Previous, scale)
+	view.Net = newComparisonValue(income.Current-expense.Current, income.Previous-expense.Previous, scale)
+
+	sortCategoryComparisons(view.IncomeByCategory)
+	sortCategoryComparisons(view.ExpenseByCategory)
+
+	return view, nil
+}
+
+func newComparisonValue(current, previous int64, scale int) ComparisonValue {
+	value := ComparisonValue{
+		Current:  entities.MajorFromMinor(current, scale),
+		Previous: entities.MajorFromMinor(previous, scale),
+		Delta:    entities.MajorFromMinor(current-previous, scale),
+	}
+
+	if previous != 0 {
+		percent := math.Round(float64(current-previous)/math.Abs(float64(previous))*10000) / 100
+		value.DeltaPercent = &percent
+	}
+
+	return value
+}
+
+func sortCategoryComparisons(items []CategoryComparison) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Current != items[j].Current {
+			return items[i].Current > items[j].Current
+		}
+		return items[i].Previous > items[j].Previous
+	})
+}
@@ -0,0 +1,237 @@
+package query
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxTimeseriesBuckets keeps zero-filled responses bounded, a daily series
+// over several years is not something a chart can render anyway.
+const maxTimeseriesBuckets = 400
+
+type GetTimeseriesUsecase struct {
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	usersRepo        entities.UserRepository
+	transactionsRepo entities.TransactionRepository
+	categoriesRepo   entities.CategoryRepository
+}
+
+func NewGetTimeseriesUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+	categoriesRepo entities.CategoryRepository,
+) *GetTimeseriesUsecase {
+	return &GetTimeseriesUsecase{
+		contextTimeout:   timeout,
+		logger:           logger,
+		usersRepo:        usersRepo,
+		transactionsRepo: transactionsRepo,
+		categoriesRepo:   categoriesRepo,
+	}
+}
+
+type GetTimeseriesQuery struct {
+	UserID     string
+	AccountID  string
+	Bucket     string
+	Period     string
+	From       string
+	To         string
+	ByCategory bool
+}
+
+type TimeseriesView struct {
+	Bucket     string               `json:"bucket"`
+	Timezone   string               `json:"timezone"`
+	Currency   string               `json:"currency"`
+	From       string               `json:"from"`
+	To         string               `json:"to"`
+	Points     []TimeseriesPoint    `json:"points"`
+	Categories []CategoryTimeseries `json:"categories,omitempty"`
+}
+
+type TimeseriesPoint struct {
+	Start   string  `json:"start"`
+	Income  float64 `json:"income"`
+	Expense float64 `json:"expense"`
+	Net     float64 `json:"net"`
+}
+
+type CategoryTimeseries struct {
+	CategoryID    int               `json:"category_id"`
+	CategoryName  string            `json:"category_name"`
+	CategoryEmoji string            `json:"category_emoji"`
+	Points        []TimeseriesPoint `json:"points"`
+}
+
+func (u *GetTimeseriesUsecase) GetTimeseries(ctx context.Context, query *GetTimeseriesQuery) (_ *TimeseriesView, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "GetTimeseries",
+		attribute.String("user_id", query.UserID),
+		attribute.String("bucket", query.Bucket),
+		attribute.String("from", query.From),
+		attribute.String("to", query.To),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID      uuid.UUID
+		accountID   *uuid.UUID
+		granularity entities.Granularity
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(query.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		if query.AccountID != "" {
+			accountID, err := uuid.Parse(query.AccountID)
+			if err != nil {
+				u.logger.ErrorContext(ctx, "failed to parse account id", err)
+				return nil, inerr.NewErrValidation("account_id", "invalid uuid type")
+			}
+			input.accountID = &accountID
+		}
+
+		input.granularity = entities.Day
+		if query.Bucket != "" {
+			input.granularity, err = entities.ParseGranularity(query.Bucket)
+			if err != nil {
+				return nil, inerr.NewErrValidation("bucket", "bucket must be one of day, week, month")
+			}
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	periods := user.Periods()
+	loc := periods.Location()
+
+	period, err := periods.Resolve(query.Period, query.From, query.To, input.granularity.DefaultFrom)
+	if err != nil {
+		return nil, inerr.AsValidation(err)
+	}
+	from, to := period.From, period.To
+
+	buckets := input.granularity.Buckets(from, to)
+	if len(buckets) > maxTimeseriesBuckets {
+		return nil, inerr.NewErrValidation("from", "range is too long for the selected bucket")
+	}
+
+	rows, err := u.transactionsRepo.GetTimeseries(ctx, entities.TimeseriesFilter{
+		UserID:      user.ID,
+		AccountID:   input.accountID,
+		Granularity: input.granularity,
+		Location:    loc,
+		From:        from,
+		To:          to,
+		ByCategory:  query.ByCategory,
+	})
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get timeseries", err)
+		return nil, err
+	}
+
+	scale := user.CurrencyCode.Scale()
+	view := &TimeseriesView{
+		Bucket:   input.granularity.String(),
+		Timezone: loc.String(),
+		Currency: user.CurrencyCode.String(),
+		From:     from.Format(time.DateOnly),
+		To:       to.AddDate(0, 0, -1).Format(time.DateOnly),
+	}
+
+	totals := make(map[string]*entities.TimeseriesRow, len(buckets))
+	byCategory := make(map[int]map[string]*entities.TimeseriesRow)
+	for _, row := range rows {
+		key := row.Bucket.Format(time.DateOnly)
+		total, ok := totals[key]
+		if !ok {
+			total = &entities.TimeseriesRow{Bucket: row.Bucket}
+			totals[key] = total
+		}
+		total.Income += row.Income
+		total.Expense += row.Expense
+
+		if query.ByCategory {
+			if byCategory[row.CategoryID] == nil {
+				byCategory[row.CategoryID] = make(map[string]*entities.TimeseriesRow)
+			}
+			byCategory[row.CategoryID][key] = row
+		}
+	}
+
+	view.Points = fillTimeseries(buckets, totals, scale)
+
+	if query.ByCategory {
+		categories, err := categoriesByID(ctx, u.categoriesRepo, user.ID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to get categories", err)
+			return nil, err
+		}
+
+		categoryIDs := make([]int, 0, len(byCategory))
+		for categoryID := range byCategory {
+			categoryIDs = append(categoryIDs, categoryID)
+		}
+		sort.Ints(categoryIDs)
+
+		for _, categoryID := range categoryIDs {
+			series := CategoryTimeseries{
+				CategoryID: categoryID,
+				Points:     fillTimeseries(buckets, byCategory[categoryID], scale),
+			}
+
+			if category, ok := categories[categoryID]; ok {
+				series.CategoryName = category.GetName(user.LanguageCode)
+				series.CategoryEmoji = category.Emoji
+			}
+
+			view.Categories = append(view.Categories, series)
+		}
+	}
+
+	return view, nil
+}
+
+func fillTimeseries(buckets []time.Time, rows map[string]*entities.TimeseriesRow, scale int) []TimeseriesPoint {
+	points := make([]TimeseriesPoint, 0, len(buckets))
+	for _, bucket := range buckets {
+		key := bucket.Format(time.DateOnly)
+
+		var income, expense int64
+		if row, ok := rows[key]; ok {
+			income, expense = row.Income, row.Expense
+		}
+
+		points = append(points, TimeseriesPoint{
+			Start:   key,
+			Income:  entities.MajorFromMinor(income, scale),
+			Expense: entities.MajorFromMinor(expense, scale),
+			Net:     entities.MajorFromMinor(income-expense, scale),
+		})
+	}
+
+	return points
+}
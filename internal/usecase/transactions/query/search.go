@@ -0,0 +1,86 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type SearchUsecase struct {
+	contextTimeout   time.Duration
+	logger           *logger.Logger
+	usersRepo        entities.UserRepository
+	transactionsRepo entities.TransactionRepository
+}
+
+func NewSearchUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	transactionsRepo entities.TransactionRepository,
+) *SearchUsecase {
+	return &SearchUsecase{
+		contextTimeout:   timeout,
+		logger:           logger,
+		usersRepo:        usersRepo,
+		transactionsRepo: transactionsRepo,
+	}
+}
+
+type SearchQuery struct {
+	UserID string
+	Text   string
+	Limit  int
+	Offset int
+}
+
+func (u *SearchUsecase) Search(ctx context.Context, query *SearchQuery) (_ []*entities.TransactionSearchHit, _ int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("transactions"), "Search",
+		attribute.String("user_id", query.UserID),
+		attribute.String("text", query.Text),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+		text   string
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(query.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, 0, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.text = strings.TrimSpace(query.Text)
+		if input.text == "" {
+			return nil, 0, inerr.NewErrValidation("q", "search text is empty")
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, 0, err
+	}
+
+	hits, total, err := u.transactionsRepo.Search(ctx, user.ID, user.LanguageCode, input.text, query.Limit, query.Offset)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to search transactions", err)
+		return nil, 0, err
+	}
+
+	return hits, total, nil
+}
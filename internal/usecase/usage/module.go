@@ -0,0 +1,33 @@
+package usage
+
+import (
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/usage/query"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+)
+
+type Query struct {
+	*query.GetUsageUsecase
+	*query.GetUsageReportUsecase
+}
+
+type Module struct {
+	Query Query
+}
+
+func NewModule(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	usageRepo entities.LLMUsageRepository,
+	quotas map[entities.Plan]entities.PlanQuota,
+) *Module {
+	return &Module{
+		Query: Query{
+			GetUsageUsecase:       query.NewGetUsageUsecase(timeout, logger, usersRepo, usageRepo, quotas),
+			GetUsageReportUsecase: query.NewGetUsageReportUsecase(timeout, logger, usageRepo),
+		},
+	}
+}
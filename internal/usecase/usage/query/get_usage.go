@@ -0,0 +1,111 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PeriodUsage is what the user spent in [From, To) against the limits of
+// their plan.
+type PeriodUsage struct {
+	From   time.Time
+	To     time.Time
+	Totals entities.UsageTotals
+	Limits entities.UsageLimits
+}
+
+type UsageView struct {
+	Plan  entities.Plan
+	Day   PeriodUsage
+	Month PeriodUsage
+}
+
+type GetUsageUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usersRepo      entities.UserRepository
+	usageRepo      entities.LLMUsageRepository
+	quotas         map[entities.Plan]entities.PlanQuota
+}
+
+func NewGetUsageUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usersRepo entities.UserRepository,
+	usageRepo entities.LLMUsageRepository,
+	quotas map[entities.Plan]entities.PlanQuota,
+) *GetUsageUsecase {
+	return &GetUsageUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usersRepo:      usersRepo,
+		usageRepo:      usageRepo,
+		quotas:         quotas,
+	}
+}
+
+// GetUsage shows the user's LLM usage of their current day and month.
+func (u *GetUsageUsecase) GetUsage(ctx context.Context, userID string) (_ *UsageView, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("usage"), "GetUsage",
+		attribute.String("user_id", userID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+	}
+
+	user, err := u.usersRepo.FindByID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get user", err)
+		return nil, err
+	}
+
+	quota := u.quotas[user.Plan]
+	now := time.Now().In(user.Location())
+
+	view := &UsageView{
+		Plan: user.Plan,
+		Day: PeriodUsage{
+			Limits: quota.Daily,
+		},
+		Month: PeriodUsage{
+			Limits: quota.Monthly,
+		},
+	}
+	view.Day.From, view.Day.To = entities.UsageDay(now)
+	view.Month.From, view.Month.To = entities.UsageMonth(now)
+
+	view.Day.Totals, err = u.usageRepo.GetTotals(ctx, user.ID, view.Day.From, view.Day.To)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get daily usage", err)
+		return nil, err
+	}
+
+	view.Month.Totals, err = u.usageRepo.GetTotals(ctx, user.ID, view.Month.From, view.Month.To)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get monthly usage", err)
+		return nil, err
+	}
+
+	return view, nil
+}
@@ -0,0 +1,69 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultReportLimit = 50
+	maxReportLimit     = 500
+)
+
+type GetUsageReportUsecase struct {
+	contextTimeout time.Duration
+	logger         *logger.Logger
+	usageRepo      entities.LLMUsageRepository
+}
+
+func NewGetUsageReportUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	usageRepo entities.LLMUsageRepository,
+) *GetUsageReportUsecase {
+	return &GetUsageReportUsecase{
+		contextTimeout: timeout,
+		logger:         logger,
+		usageRepo:      usageRepo,
+	}
+}
+
+// GetUsageReport sums LLM usage per user in [from, to), the heaviest users
+// first. It is meant for admins watching the spend.
+func (u *GetUsageReportUsecase) GetUsageReport(ctx context.Context, from, to time.Time, limit int) (_ []entities.UserUsageRow, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("usage"), "GetUsageReport",
+		attribute.String("from", from.String()),
+		attribute.String("to", to.String()),
+		attribute.Int("limit", limit),
+	)
+	defer func() { end(err) }()
+
+	if !from.Before(to) {
+		return nil, inerr.NewErrValidation("from", "must be before to")
+	}
+
+	if limit <= 0 {
+		limit = defaultReportLimit
+	}
+	if limit > maxReportLimit {
+		limit = maxReportLimit
+	}
+
+	rows, err := u.usageRepo.GetReport(ctx, from, to, limit)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get usage report", err)
+		return nil, err
+	}
+
+	return rows, nil
+}
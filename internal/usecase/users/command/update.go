@@ -2,13 +2,17 @@ package command
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
 	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/internal/tasks"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/pkg/logger"
 	"github.com/AsaHero/e-wallet/pkg/otlp"
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -17,17 +21,23 @@ type UpdateUsecase struct {
 	contextTimeout time.Duration
 	logger         *logger.Logger
 	usersRepo      entities.UserRepository
+	taskQueue      *asynq.Client
+	statsCache     ports.StatsCache
 }
 
 func NewUpdateUsecase(
 	timeout time.Duration,
 	logger *logger.Logger,
 	usersRepo entities.UserRepository,
+	taskQueue *asynq.Client,
+	statsCache ports.StatsCache,
 ) *UpdateUsecase {
 	return &UpdateUsecase{
 		contextTimeout: timeout,
 		usersRepo:      usersRepo,
 		logger:         logger,
+		taskQueue:      taskQueue,
+		statsCache:     statsCache,
 	}
 }
 
@@ -66,14 +76,24 @@ func (u *UpdateUsecase) Update(ctx context.Context, cmd *UpdateCommand) (_ *enti
 	}
 
 	if cmd.LanguageCode != nil {
-		user.UpdateLanguageCode(entities.Language(*cmd.LanguageCode))
+		lang, err := entities.ParseLanguage(*cmd.LanguageCode)
+		if err != nil {
+			return nil, inerr.NewErrValidation("language_code", err.Error())
+		}
+		user.UpdateLanguageCode(lang)
 	}
 
 	if cmd.CurrencyCode != nil {
 		user.UpdateCurrencyCode(entities.Currency(*cmd.CurrencyCode))
 	}
 
+	timezoneChanged := false
 	if cmd.Timezone != nil {
+		// An unknown zone would silently fall back to UTC in every period.
+		if _, err := time.LoadLocation(*cmd.Timezone); err != nil {
+			return nil, inerr.NewErrValidation("timezone", "unknown time zone")
+		}
+		timezoneChanged = *cmd.Timezone != user.Timezone
 		user.UpdateTimezone(*cmd.Timezone)
 	}
 
@@ -83,5 +103,26 @@ func (u *UpdateUsecase) Update(ctx context.Context, cmd *UpdateCommand) (_ *enti
 		return nil, err
 	}
 
+	// names and amounts in cached stats follow the language and currency
+	u.statsCache.Invalidate(ctx, user.ID)
+
+	if timezoneChanged {
+		u.rebuildRollups(ctx, user)
+	}
+
 	return user, nil
 }
+
+// rebuildRollups recounts the daily rollups on the user's new local days.
+// Best effort, the nightly verification catches a missed rebuild.
+func (u *UpdateUsecase) rebuildRollups(ctx context.Context, user *entities.User) {
+	task, err := tasks.NewRollupsRebuildTask(user.ID.String())
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to create rollups rebuild task", err)
+		return
+	}
+
+	if _, err := u.taskQueue.EnqueueContext(ctx, task); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		u.logger.ErrorContext(ctx, "failed to enqueue rollups rebuild task", err)
+	}
+}
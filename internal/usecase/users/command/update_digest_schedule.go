@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type UpdateDigestScheduleUsecase struct {
+	contextTimeout     time.Duration
+	logger             *logger.Logger
+	digestScheduleRepo entities.DigestScheduleRepository
+}
+
+func NewUpdateDigestScheduleUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	digestScheduleRepo entities.DigestScheduleRepository,
+) *UpdateDigestScheduleUsecase {
+	return &UpdateDigestScheduleUsecase{
+		contextTimeout:     timeout,
+		logger:             logger,
+		digestScheduleRepo: digestScheduleRepo,
+	}
+}
+
+// UpdateDigestScheduleCommand changes only the fields that are set.
+type UpdateDigestScheduleCommand struct {
+	UserID  string
+	Weekday *string
+	Hour    *int
+}
+
+func (u *UpdateDigestScheduleUsecase) UpdateDigestSchedule(ctx context.Context, cmd *UpdateDigestScheduleCommand) (_ *entities.DigestSchedule, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("users"), "UpdateDigestSchedule",
+		attribute.String("user_id", cmd.UserID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID  uuid.UUID
+		weekday *time.Weekday
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		if cmd.Weekday != nil {
+			weekday, err := entities.ParseWeekday(*cmd.Weekday)
+			if err != nil {
+				return nil, inerr.NewErrValidation("weekday", err.Error())
+			}
+			input.weekday = &weekday
+		}
+	}
+
+	schedule, err := u.digestScheduleRepo.FindByUserID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get digest schedule", err)
+		return nil, err
+	}
+
+	weekday, hour := schedule.Weekday, schedule.Hour
+	if input.weekday != nil {
+		weekday = *input.weekday
+	}
+	if cmd.Hour != nil {
+		hour = *cmd.Hour
+	}
+
+	if err := schedule.Update(weekday, hour); err != nil {
+		return nil, inerr.NewErrValidation("hour", err.Error())
+	}
+
+	err = u.digestScheduleRepo.Save(ctx, schedule)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to save digest schedule", err)
+		return nil, err
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type UpdateNotificationSettingsUsecase struct {
+	contextTimeout           time.Duration
+	logger                   *logger.Logger
+	notificationSettingsRepo entities.NotificationSettingsRepository
+}
+
+func NewUpdateNotificationSettingsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	notificationSettingsRepo entities.NotificationSettingsRepository,
+) *UpdateNotificationSettingsUsecase {
+	return &UpdateNotificationSettingsUsecase{
+		contextTimeout:           timeout,
+		logger:                   logger,
+		notificationSettingsRepo: notificationSettingsRepo,
+	}
+}
+
+// UpdateNotificationSettingsCommand only changes the listed types, the rest
+// keep their current value.
+type UpdateNotificationSettingsCommand struct {
+	UserID  string
+	Enabled map[string]bool
+}
+
+func (u *UpdateNotificationSettingsUsecase) UpdateNotificationSettings(ctx context.Context, cmd *UpdateNotificationSettingsCommand) (_ *entities.NotificationSettings, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("users"), "UpdateNotificationSettings",
+		attribute.String("user_id", cmd.UserID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID  uuid.UUID
+		enabled map[entities.NotificationType]bool
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(cmd.UserID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+
+		input.enabled = make(map[entities.NotificationType]bool, len(cmd.Enabled))
+		for key, enabled := range cmd.Enabled {
+			t, err := entities.ParseNotificationType(key)
+			if err != nil {
+				return nil, inerr.NewErrValidation("settings", "unknown notification type "+key)
+			}
+			input.enabled[t] = enabled
+		}
+	}
+
+	settings, err := u.notificationSettingsRepo.FindByUserID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get notification settings", err)
+		return nil, err
+	}
+
+	for t, enabled := range input.enabled {
+		settings.Set(t, enabled)
+	}
+
+	err = u.notificationSettingsRepo.Save(ctx, settings)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to save notification settings", err)
+		return nil, err
+	}
+
+	return settings, nil
+}
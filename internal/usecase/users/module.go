@@ -4,19 +4,25 @@ import (
 	"time"
 
 	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/usecase/ports"
 	"github.com/AsaHero/e-wallet/internal/usecase/users/command"
 	"github.com/AsaHero/e-wallet/internal/usecase/users/query"
 	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/hibiken/asynq"
 )
 
 type Commands struct {
 	*command.AuthTelegramUsecase
 	*command.UpdateUsecase
+	*command.UpdateNotificationSettingsUsecase
+	*command.UpdateDigestScheduleUsecase
 }
 
 type Query struct {
 	*query.GetByTGUserIDUsecase
 	*query.GetByIDUsecase
+	*query.GetNotificationSettingsUsecase
+	*query.GetDigestScheduleUsecase
 }
 
 type Module struct {
@@ -28,15 +34,23 @@ func NewModule(
 	timeout time.Duration,
 	logger *logger.Logger,
 	usersRepo entities.UserRepository,
+	notificationSettingsRepo entities.NotificationSettingsRepository,
+	digestScheduleRepo entities.DigestScheduleRepository,
+	taskQueue *asynq.Client,
+	statsCache ports.StatsCache,
 ) *Module {
 	m := &Module{
 		Command: Commands{
-			AuthTelegramUsecase: command.NewAuthTelegramUsecase(timeout, logger, usersRepo),
-			UpdateUsecase:       command.NewUpdateUsecase(timeout, logger, usersRepo),
+			AuthTelegramUsecase:               command.NewAuthTelegramUsecase(timeout, logger, usersRepo),
+			UpdateUsecase:                     command.NewUpdateUsecase(timeout, logger, usersRepo, taskQueue, statsCache),
+			UpdateNotificationSettingsUsecase: command.NewUpdateNotificationSettingsUsecase(timeout, logger, notificationSettingsRepo),
+			UpdateDigestScheduleUsecase:       command.NewUpdateDigestScheduleUsecase(timeout, logger, digestScheduleRepo),
 		},
 		Query: Query{
-			GetByIDUsecase:       query.NewGetByUserIDUsecase(timeout, logger, usersRepo),
-			GetByTGUserIDUsecase: query.NewGetByTGUserIDUsecase(timeout, logger, usersRepo),
+			GetByIDUsecase:                 query.NewGetByUserIDUsecase(timeout, logger, usersRepo),
+			GetByTGUserIDUsecase:           query.NewGetByTGUserIDUsecase(timeout, logger, usersRepo),
+			GetNotificationSettingsUsecase: query.NewGetNotificationSettingsUsecase(timeout, logger, notificationSettingsRepo),
+			GetDigestScheduleUsecase:       query.NewGetDigestScheduleUsecase(timeout, logger, digestScheduleRepo),
 		},
 	}
 
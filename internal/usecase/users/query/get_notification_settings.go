@@ -0,0 +1,62 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/AsaHero/e-wallet/internal/entities"
+	"github.com/AsaHero/e-wallet/internal/inerr"
+	"github.com/AsaHero/e-wallet/pkg/logger"
+	"github.com/AsaHero/e-wallet/pkg/otlp"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type GetNotificationSettingsUsecase struct {
+	contextTimeout           time.Duration
+	logger                   *logger.Logger
+	notificationSettingsRepo entities.NotificationSettingsRepository
+}
+
+func NewGetNotificationSettingsUsecase(
+	timeout time.Duration,
+	logger *logger.Logger,
+	notificationSettingsRepo entities.NotificationSettingsRepository,
+) *GetNotificationSettingsUsecase {
+	return &GetNotificationSettingsUsecase{
+		contextTimeout:           timeout,
+		logger:                   logger,
+		notificationSettingsRepo: notificationSettingsRepo,
+	}
+}
+
+func (u *GetNotificationSettingsUsecase) GetNotificationSettings(ctx context.Context, userID string) (_ *entities.NotificationSettings, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	ctx, end := otlp.Start(ctx, otel.Tracer("users"), "GetNotificationSettings",
+		attribute.String("user_id", userID),
+	)
+	defer func() { end(err) }()
+
+	var input struct {
+		userID uuid.UUID
+	}
+	{
+		var err error
+		input.userID, err = uuid.Parse(userID)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "failed to parse user id", err)
+			return nil, inerr.NewErrValidation("user_id", "invalid uuid type")
+		}
+	}
+
+	settings, err := u.notificationSettingsRepo.FindByUserID(ctx, input.userID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "failed to get notification settings", err)
+		return nil, err
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS subcategories_name_uz_trgm_idx;
+
+DROP INDEX IF EXISTS categories_name_uz_trgm_idx;
+
+DROP INDEX IF EXISTS transactions_merchant_trgm_idx;
+
+DROP INDEX IF EXISTS transactions_row_text_trgm_idx;
+
+DROP INDEX IF EXISTS transactions_search_simple_idx;
+
+DROP INDEX IF EXISTS transactions_search_english_idx;
+
+DROP INDEX IF EXISTS transactions_search_russian_idx;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS merchant;
@@ -0,0 +1,17 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS merchant varchar(255);
+
+CREATE INDEX IF NOT EXISTS transactions_search_russian_idx ON transactions USING GIN (to_tsvector('russian', coalesce(row_text, '') || ' ' || coalesce(merchant, '')));
+
+CREATE INDEX IF NOT EXISTS transactions_search_english_idx ON transactions USING GIN (to_tsvector('english', coalesce(row_text, '') || ' ' || coalesce(merchant, '')));
+
+CREATE INDEX IF NOT EXISTS transactions_search_simple_idx ON transactions USING GIN (to_tsvector('simple', coalesce(row_text, '') || ' ' || coalesce(merchant, '')));
+
+CREATE INDEX IF NOT EXISTS transactions_row_text_trgm_idx ON transactions USING GIN (lower(row_text) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS transactions_merchant_trgm_idx ON transactions USING GIN (lower(merchant) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS categories_name_uz_trgm_idx ON categories USING GIN (lower(name_uz) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS subcategories_name_uz_trgm_idx ON subcategories USING GIN (lower(name_uz) gin_trgm_ops);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS subcategory_preferences;
+
+DROP TABLE IF EXISTS category_preferences;
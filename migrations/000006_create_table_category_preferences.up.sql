@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS category_preferences(
+    user_id uuid NOT NULL,
+    category_id integer NOT NULL,
+    position integer,
+    hidden boolean NOT NULL DEFAULT false,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, category_id),
+    CONSTRAINT category_preferences_user_id_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT category_preferences_category_id_fk FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS subcategory_preferences(
+    user_id uuid NOT NULL,
+    subcategory_id integer NOT NULL,
+    position integer,
+    hidden boolean NOT NULL DEFAULT false,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, subcategory_id),
+    CONSTRAINT subcategory_preferences_user_id_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT subcategory_preferences_subcategory_id_fk FOREIGN KEY (subcategory_id) REFERENCES subcategories(id) ON DELETE CASCADE
+);
@@ -0,0 +1,41 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS name_en varchar(255);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS name_ru varchar(255);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS name_uz varchar(255);
+
+ALTER TABLE subcategories ADD COLUMN IF NOT EXISTS name_en varchar(255);
+ALTER TABLE subcategories ADD COLUMN IF NOT EXISTS name_ru varchar(255);
+ALTER TABLE subcategories ADD COLUMN IF NOT EXISTS name_uz varchar(255);
+
+UPDATE categories c SET
+    name_en = (SELECT value FROM translations WHERE entity = 'category' AND entity_id = c.id AND locale = 'en'),
+    name_ru = (SELECT value FROM translations WHERE entity = 'category' AND entity_id = c.id AND locale = 'ru'),
+    name_uz = (SELECT value FROM translations WHERE entity = 'category' AND entity_id = c.id AND locale = 'uz');
+
+UPDATE categories SET
+    name_ru = coalesce(name_ru, name_en),
+    name_uz = coalesce(name_uz, name_ru, name_en);
+
+UPDATE subcategories s SET
+    name_en = (SELECT value FROM translations WHERE entity = 'subcategory' AND entity_id = s.id AND locale = 'en'),
+    name_ru = (SELECT value FROM translations WHERE entity = 'subcategory' AND entity_id = s.id AND locale = 'ru'),
+    name_uz = (SELECT value FROM translations WHERE entity = 'subcategory' AND entity_id = s.id AND locale = 'uz');
+
+UPDATE subcategories SET
+    name_ru = coalesce(name_ru, name_en),
+    name_uz = coalesce(name_uz, name_ru, name_en);
+
+ALTER TABLE categories ALTER COLUMN name_en SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN name_ru SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN name_uz SET NOT NULL;
+
+ALTER TABLE subcategories ALTER COLUMN name_en SET NOT NULL;
+ALTER TABLE subcategories ALTER COLUMN name_ru SET NOT NULL;
+ALTER TABLE subcategories ALTER COLUMN name_uz SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS categories_name_uz_trgm_idx ON categories USING GIN (lower(name_uz) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS subcategories_name_uz_trgm_idx ON subcategories USING GIN (lower(name_uz) gin_trgm_ops);
+
+DROP TABLE IF EXISTS translations;
+
+DROP TABLE IF EXISTS languages;
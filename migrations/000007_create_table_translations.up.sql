@@ -0,0 +1,68 @@
+CREATE TABLE IF NOT EXISTS languages(
+    code varchar(8) NOT NULL,
+    name varchar(64) NOT NULL,
+    fallback varchar(8),
+    search_config varchar(32) NOT NULL DEFAULT 'simple',
+    position integer,
+    enabled boolean NOT NULL DEFAULT true,
+    PRIMARY KEY (code),
+    CONSTRAINT languages_fallback_fk FOREIGN KEY (fallback) REFERENCES languages(code)
+);
+
+INSERT INTO languages
+(code, name, fallback, search_config, position)
+VALUES
+('en', 'English',   NULL, 'english', 1),
+('ru', 'Русский',   'en', 'russian', 2),
+('uz', 'O‘zbekcha', 'ru', 'simple',  3),
+('kk', 'Қазақша',   'ru', 'simple',  4),
+('tg', 'Тоҷикӣ',    'ru', 'simple',  5),
+('tr', 'Türkçe',    'en', 'turkish', 6)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS translations(
+    entity varchar(32) NOT NULL,
+    entity_id integer NOT NULL,
+    locale varchar(8) NOT NULL,
+    value varchar(255) NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (entity, entity_id, locale),
+    CONSTRAINT translations_locale_fk FOREIGN KEY (locale) REFERENCES languages(code)
+);
+
+CREATE INDEX IF NOT EXISTS translations_value_trgm_idx ON translations USING GIN (lower(value) gin_trgm_ops);
+
+INSERT INTO translations (entity, entity_id, locale, value)
+SELECT 'category', id, 'en', name_en FROM categories
+UNION ALL
+SELECT 'category', id, 'ru', name_ru FROM categories
+UNION ALL
+SELECT 'category', id, 'uz', name_uz FROM categories
+UNION ALL
+SELECT 'subcategory', id, 'en', name_en FROM subcategories
+UNION ALL
+SELECT 'subcategory', id, 'ru', name_ru FROM subcategories
+UNION ALL
+SELECT 'subcategory', id, 'uz', name_uz FROM subcategories
+ON CONFLICT DO NOTHING;
+
+-- user categories used to get the same name copied into every column,
+-- keep only the base one so other locales fall back to it
+DELETE FROM translations t
+USING categories c
+WHERE t.entity = 'category' AND t.entity_id = c.id AND c.user_id IS NOT NULL
+  AND t.locale <> 'en' AND c.name_en = c.name_ru AND c.name_en = c.name_uz;
+
+DELETE FROM translations t
+USING subcategories s
+WHERE t.entity = 'subcategory' AND t.entity_id = s.id AND s.user_id IS NOT NULL
+  AND t.locale <> 'en' AND s.name_en = s.name_ru AND s.name_en = s.name_uz;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS name_en;
+ALTER TABLE categories DROP COLUMN IF EXISTS name_ru;
+ALTER TABLE categories DROP COLUMN IF EXISTS name_uz;
+
+ALTER TABLE subcategories DROP COLUMN IF EXISTS name_en;
+ALTER TABLE subcategories DROP COLUMN IF EXISTS name_ru;
+ALTER TABLE subcategories DROP COLUMN IF EXISTS name_uz;
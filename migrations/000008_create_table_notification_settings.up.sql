@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS notification_settings(
+    user_id uuid NOT NULL,
+    type varchar(32) NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type),
+    CONSTRAINT notification_settings_user_id_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS transactions_import_session_id_idx;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_import_session_id_fkey;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS import_session_id;
+
+DROP TABLE IF EXISTS import_rows;
+
+DROP TABLE IF EXISTS import_sessions;
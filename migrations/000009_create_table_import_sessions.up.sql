@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS import_sessions(
+    id uuid,
+    user_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    source varchar(16) NOT NULL,
+    filename varchar(255),
+    status varchar(16) NOT NULL,
+    encoding varchar(32),
+    delimiter varchar(4),
+    date_format varchar(32),
+    decimal_separator varchar(1),
+    header jsonb,
+    mapping jsonb,
+    row_count integer NOT NULL DEFAULT 0,
+    imported_count integer NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone,
+    committed_at timestamp with time zone,
+    PRIMARY KEY (id),
+    CONSTRAINT import_sessions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT import_sessions_account_id_fkey FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS import_sessions_user_id_idx ON import_sessions(user_id);
+
+CREATE TABLE IF NOT EXISTS import_rows(
+    session_id uuid NOT NULL,
+    line integer NOT NULL,
+    data jsonb NOT NULL,
+    PRIMARY KEY (session_id, line),
+    CONSTRAINT import_rows_session_id_fkey FOREIGN KEY (session_id) REFERENCES import_sessions(id) ON DELETE CASCADE
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS import_session_id uuid;
+
+ALTER TABLE transactions ADD CONSTRAINT transactions_import_session_id_fkey FOREIGN KEY (import_session_id) REFERENCES import_sessions(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS transactions_import_session_id_idx ON transactions(import_session_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_account_id_external_id_idx;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS external_id;
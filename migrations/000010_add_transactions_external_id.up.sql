@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_id varchar(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_account_id_external_id_idx ON transactions(account_id, external_id) WHERE external_id IS NOT NULL;
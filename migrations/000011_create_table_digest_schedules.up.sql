@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS digest_schedules(
+    user_id uuid PRIMARY KEY,
+    weekday smallint NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6),
+    hour smallint NOT NULL DEFAULT 9 CHECK (hour BETWEEN 0 AND 23),
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT digest_schedules_user_id_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS transaction_rollups(
+    user_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    category_id integer NOT NULL DEFAULT 0,
+    subcategory_id integer NOT NULL DEFAULT 0,
+    type varchar(255) NOT NULL,
+    day date NOT NULL,
+    total bigint NOT NULL DEFAULT 0,
+    count integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day, account_id, category_id, subcategory_id, type),
+    CONSTRAINT transaction_rollups_user_id_fk FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT transaction_rollups_account_id_fk FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Days are local to the user, unknown zones fall back to UTC like the app does.
+INSERT INTO transaction_rollups (user_id, account_id, category_id, subcategory_id, type, day, total, count)
+SELECT
+    t.user_id,
+    t.account_id,
+    COALESCE(t.category_id, 0),
+    COALESCE(t.subcategory_id, 0),
+    t.type,
+    (COALESCE(t.performed_at, t.created_at) AT TIME ZONE COALESCE(tz.name, 'UTC'))::date AS day,
+    SUM(t.amount),
+    COUNT(*)
+FROM transactions t
+JOIN users u ON u.id = t.user_id
+LEFT JOIN pg_timezone_names tz ON tz.name = u.timezone
+WHERE t.status = 'success' AND t.type IN ('deposit', 'withdrawal')
+GROUP BY 1, 2, 3, 4, 5, 6
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS transaction_drafts(
+    id uuid,
+    user_id uuid NOT NULL,
+    account_id uuid,
+    category_id integer,
+    subcategory_id integer,
+    type varchar(255) NOT NULL,
+    status varchar(16) NOT NULL,
+    source varchar(16) NOT NULL,
+    amount bigint NOT NULL,
+    currency_code char(3) NOT NULL,
+    original_amount bigint,
+    original_currency_code char(3),
+    fx_rate numeric(18, 6),
+    note text,
+    merchant varchar(255),
+    confidence double precision NOT NULL DEFAULT 0,
+    performed_at timestamp with time zone,
+    transaction_id uuid,
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone,
+    PRIMARY KEY (id),
+    CONSTRAINT transaction_drafts_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT transaction_drafts_account_id_fkey FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT transaction_drafts_category_id_fkey FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT transaction_drafts_subcategory_id_fkey FOREIGN KEY (subcategory_id) REFERENCES subcategories(id) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT transaction_drafts_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS transaction_drafts_user_id_idx ON transaction_drafts(user_id, created_at);
+
+CREATE INDEX IF NOT EXISTS transaction_drafts_expires_at_idx ON transaction_drafts(expires_at);
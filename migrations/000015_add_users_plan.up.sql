@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS plan varchar(32) NOT NULL DEFAULT 'free';
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS llm_usage(
+    id uuid,
+    user_id uuid NOT NULL,
+    operation varchar(16) NOT NULL,
+    prompt_tokens integer NOT NULL DEFAULT 0,
+    completion_tokens integer NOT NULL DEFAULT 0,
+    audio_seconds double precision NOT NULL DEFAULT 0,
+    ocr_calls integer NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CONSTRAINT llm_usage_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS llm_usage_user_id_idx ON llm_usage(user_id, created_at);
+
+CREATE INDEX IF NOT EXISTS llm_usage_created_at_idx ON llm_usage(created_at);
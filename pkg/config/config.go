@@ -2,12 +2,34 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AsaHero/e-wallet/pkg/app"
 )
 
+// QuotaLimits caps LLM usage over a period, zero leaves a metric unlimited.
+type QuotaLimits struct {
+	Tokens       int64
+	AudioSeconds int64
+	OCRCalls     int64
+}
+
+// Quota holds the LLM limits of a plan.
+type Quota struct {
+	Daily   QuotaLimits
+	Monthly QuotaLimits
+}
+
+// RateLimit allows Limit requests per Window.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
 type Config struct {
 	APP         string
 	Environment app.Environment
@@ -19,6 +41,10 @@ type Config struct {
 		ReadTimeout  time.Duration
 		WriteTimeout time.Duration
 		IdleTimeout  time.Duration
+		// TrustedProxies may set the client address in X-Forwarded-For,
+		// addresses or CIDRs. None by default, clients are told apart by
+		// their remote address.
+		TrustedProxies []string
 	}
 
 	Context struct {
@@ -67,6 +93,28 @@ type Config struct {
 		APIKey string
 	}
 
+	// LLM picks the providers and the model of every task. Providers are
+	// tried in order, the next one on an error or timeout.
+	LLM struct {
+		Providers []string
+		Timeout   time.Duration
+		Models    struct {
+			Classification string
+			Details        string
+			OCRCleanup     string
+			Transcription  string
+		}
+		// Compatible is any server speaking the OpenAI API, e.g. Ollama or
+		// vLLM. Model replaces the task models when set, such servers
+		// usually serve one.
+		Compatible struct {
+			BaseURL            string
+			APIKey             string
+			Model              string
+			TranscriptionModel string
+		}
+	}
+
 	TelegramBotService struct {
 		BaseURL string
 		Timeout time.Duration
@@ -76,6 +124,44 @@ type Config struct {
 		BaseURL string
 		Timeout time.Duration
 	}
+
+	Report struct {
+		FontPath string
+	}
+
+	StatsCache struct {
+		Enabled bool
+		TTL     time.Duration
+	}
+
+	// ParseCache keeps LLM results of repeated inputs. Results below
+	// MinConfidence are not stored.
+	ParseCache struct {
+		Enabled       bool
+		TTL           time.Duration
+		MinConfidence float64
+	}
+
+	// Quotas holds the daily and monthly LLM limits of each plan, a plan
+	// missing here is unlimited.
+	Quotas map[string]Quota
+
+	// RateLimit limits requests per route group. Auth and Admin are
+	// counted per client address, the rest per user.
+	RateLimit struct {
+		Enabled bool
+		Auth    RateLimit
+		Admin   RateLimit
+		Parse   RateLimit
+		API     RateLimit
+	}
+
+	// Admin guards the admin endpoints with basic auth, they are not
+	// served while Password is empty.
+	Admin struct {
+		Username string
+		Password string
+	}
 }
 
 func New() (*Config, error) {
@@ -107,6 +193,13 @@ func New() (*Config, error) {
 		return nil, fmt.Errorf("SERVER_IDLE_TIMEOUT: %w", err)
 	}
 
+	c.Server.TrustedProxies = getEnvList("SERVER_TRUSTED_PROXIES", "")
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("SERVER_TRUSTED_PROXIES: invalid address %q", proxy)
+		}
+	}
+
 	// Context
 	if c.Context.Timeout, err = getEnvDuration("CONTEXT_TIMEOUT", "30s"); err != nil {
 		return nil, fmt.Errorf("CONTEXT_TIMEOUT: %w", err)
@@ -149,6 +242,20 @@ func New() (*Config, error) {
 	// OpenAI
 	c.OpenAI.APIKey = getEnv("OPENAI_API_KEY", "")
 
+	// LLM
+	c.LLM.Providers = getEnvList("LLM_PROVIDERS", "openai")
+	if c.LLM.Timeout, err = getEnvDuration("LLM_TIMEOUT", "60s"); err != nil {
+		return nil, fmt.Errorf("LLM_TIMEOUT: %w", err)
+	}
+	c.LLM.Models.Classification = getEnv("LLM_MODEL_CLASSIFICATION", "gpt-4o")
+	c.LLM.Models.Details = getEnv("LLM_MODEL_DETAILS", "gpt-4o")
+	c.LLM.Models.OCRCleanup = getEnv("LLM_MODEL_OCR_CLEANUP", "gpt-4o")
+	c.LLM.Models.Transcription = getEnv("LLM_MODEL_TRANSCRIPTION", "gpt-4o-transcribe")
+	c.LLM.Compatible.BaseURL = getEnv("LLM_COMPATIBLE_BASE_URL", "")
+	c.LLM.Compatible.APIKey = getEnv("LLM_COMPATIBLE_API_KEY", "")
+	c.LLM.Compatible.Model = getEnv("LLM_COMPATIBLE_MODEL", "")
+	c.LLM.Compatible.TranscriptionModel = getEnv("LLM_COMPATIBLE_TRANSCRIPTION_MODEL", "")
+
 	// Telegram Bot Service
 	c.TelegramBotService.BaseURL = getEnv("TELEGRAM_BOT_SERVICE_BASE_URL", "")
 	if c.TelegramBotService.Timeout, err = getEnvDuration("TELEGRAM_BOT_SERVICE_TIMEOUT", "30s"); err != nil {
@@ -161,6 +268,57 @@ func New() (*Config, error) {
 		return nil, fmt.Errorf("OCR_SERVICE_TIMEOUT: %w", err)
 	}
 
+	// Reports
+	c.Report.FontPath = getEnv("REPORT_FONT_PATH", "/usr/share/fonts/dejavu/DejaVuSans.ttf")
+
+	// Stats Cache
+	c.StatsCache.Enabled = getEnv("STATS_CACHE_ENABLED", "false") == "true"
+	if c.StatsCache.TTL, err = getEnvDuration("STATS_CACHE_TTL", "10m"); err != nil {
+		return nil, fmt.Errorf("STATS_CACHE_TTL: %w", err)
+	}
+
+	// Parse Cache
+	c.ParseCache.Enabled = getEnv("PARSE_CACHE_ENABLED", "false") == "true"
+	if c.ParseCache.TTL, err = getEnvDuration("PARSE_CACHE_TTL", "24h"); err != nil {
+		return nil, fmt.Errorf("PARSE_CACHE_TTL: %w", err)
+	}
+	if c.ParseCache.MinConfidence, err = getEnvFloat("PARSE_CACHE_MIN_CONFIDENCE", "0.8"); err != nil {
+		return nil, fmt.Errorf("PARSE_CACHE_MIN_CONFIDENCE: %w", err)
+	}
+
+	// Quotas
+	c.Quotas = make(map[string]Quota)
+	for _, plan := range getEnvList("LLM_QUOTA_PLANS", "free,premium") {
+		prefix := "LLM_QUOTA_" + strings.ToUpper(plan)
+		var quota Quota
+		if quota.Daily, err = getEnvQuotaLimits(prefix+"_DAILY", quotaDefaults[plan+"_daily"]); err != nil {
+			return nil, err
+		}
+		if quota.Monthly, err = getEnvQuotaLimits(prefix+"_MONTHLY", quotaDefaults[plan+"_monthly"]); err != nil {
+			return nil, err
+		}
+		c.Quotas[plan] = quota
+	}
+
+	// Rate Limit
+	c.RateLimit.Enabled = getEnv("RATE_LIMIT_ENABLED", "true") == "true"
+	if c.RateLimit.Auth, err = getEnvRateLimit("RATE_LIMIT_AUTH", "10/1m"); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_AUTH: %w", err)
+	}
+	if c.RateLimit.Admin, err = getEnvRateLimit("RATE_LIMIT_ADMIN", "30/1m"); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_ADMIN: %w", err)
+	}
+	if c.RateLimit.Parse, err = getEnvRateLimit("RATE_LIMIT_PARSE", "20/1m"); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_PARSE: %w", err)
+	}
+	if c.RateLimit.API, err = getEnvRateLimit("RATE_LIMIT_API", "300/1m"); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_API: %w", err)
+	}
+
+	// Admin
+	c.Admin.Username = getEnv("ADMIN_USERNAME", "admin")
+	c.Admin.Password = getEnv("ADMIN_PASSWORD", "")
+
 	return c, nil
 }
 
@@ -172,6 +330,17 @@ func getEnv(key string, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList splits a comma separated value, skipping empty items.
+func getEnvList(key string, defaultValue string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // func getEnvInt(key string, defaultValue int) int {
 // 	value, err := strconv.Atoi(os.Getenv(key))
 // 	if err != nil {
@@ -180,6 +349,61 @@ func getEnv(key string, defaultValue string) string {
 // 	return value
 // }
 
+// quotaDefaults are the limits of a plan and period when not configured,
+// premium is unlimited.
+var quotaDefaults = map[string]QuotaLimits{
+	"free_daily":   {Tokens: 50_000, AudioSeconds: 300, OCRCalls: 20},
+	"free_monthly": {Tokens: 1_000_000, AudioSeconds: 3600, OCRCalls: 300},
+}
+
+// getEnvQuotaLimits reads <prefix>_TOKENS, <prefix>_AUDIO_SECONDS and
+// <prefix>_OCR_CALLS.
+func getEnvQuotaLimits(prefix string, defaults QuotaLimits) (QuotaLimits, error) {
+	var limits QuotaLimits
+	var err error
+	if limits.Tokens, err = getEnvInt64(prefix+"_TOKENS", defaults.Tokens); err != nil {
+		return limits, fmt.Errorf("%s_TOKENS: %w", prefix, err)
+	}
+	if limits.AudioSeconds, err = getEnvInt64(prefix+"_AUDIO_SECONDS", defaults.AudioSeconds); err != nil {
+		return limits, fmt.Errorf("%s_AUDIO_SECONDS: %w", prefix, err)
+	}
+	if limits.OCRCalls, err = getEnvInt64(prefix+"_OCR_CALLS", defaults.OCRCalls); err != nil {
+		return limits, fmt.Errorf("%s_OCR_CALLS: %w", prefix, err)
+	}
+	return limits, nil
+}
+
+// getEnvRateLimit reads a limit written as <requests>/<window>, e.g.
+// 20/1m.
+func getEnvRateLimit(key string, defaultValue string) (RateLimit, error) {
+	limit, window, ok := strings.Cut(getEnv(key, defaultValue), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("expected <requests>/<window>")
+	}
+
+	var rateLimit RateLimit
+	var err error
+	if rateLimit.Limit, err = strconv.Atoi(strings.TrimSpace(limit)); err != nil || rateLimit.Limit <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid requests: %q", limit)
+	}
+	if rateLimit.Window, err = time.ParseDuration(strings.TrimSpace(window)); err != nil || rateLimit.Window <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid window: %q", window)
+	}
+	return rateLimit, nil
+}
+
+func getEnvInt64(key string, defaultValue int64) (int64, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func getEnvFloat(key string, defaultValue string) (float64, error) {
+	return strconv.ParseFloat(getEnv(key, defaultValue), 64)
+}
+
 func getEnvDuration(key string, defaultValue string) (time.Duration, error) {
 	value, err := time.ParseDuration(getEnv(key, defaultValue))
 	if err != nil {
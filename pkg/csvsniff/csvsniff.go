@@ -0,0 +1,157 @@
+// Package csvsniff reads CSV files of unknown origin: it guesses the text
+// encoding and the delimiter before handing the data to encoding/csv.
+package csvsniff
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const (
+	UTF8        = "utf-8"
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Windows1251 = "windows-1251"
+)
+
+// delimiters are tried in order, the first one wins a tie.
+var delimiters = []rune{';', ',', '\t', '|'}
+
+// sampleLines is how many lines are looked at to pick the delimiter.
+const sampleLines = 20
+
+var ErrEmpty = errors.New("csvsniff: file is empty")
+
+type Result struct {
+	Encoding  string
+	Delimiter rune
+	Records   [][]string
+}
+
+// Sniff decodes data and splits it into records. Blank lines are dropped and
+// every value is trimmed.
+func Sniff(data []byte) (*Result, error) {
+	text, encoding, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmpty
+	}
+
+	delimiter := DetectDelimiter(text)
+
+	records, err := Read(text, delimiter)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return &Result{
+		Encoding:  encoding,
+		Delimiter: delimiter,
+		Records:   records,
+	}, nil
+}
+
+// Decode converts data to UTF-8. Byte order marks decide UTF-8 and UTF-16,
+// anything that is not valid UTF-8 is read as Windows-1251, which is what
+// most CIS banks export.
+func Decode(data []byte) (string, string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:]), UTF8, nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		text, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		return string(text), UTF16LE, err
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		text, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		return string(text), UTF16BE, err
+	case utf8.Valid(data):
+		return string(data), UTF8, nil
+	}
+
+	text, err := charmap.Windows1251.NewDecoder().Bytes(data)
+	return string(text), Windows1251, err
+}
+
+// DetectDelimiter picks the delimiter that splits the first lines into the
+// same, largest number of fields.
+func DetectDelimiter(text string) rune {
+	lines := make([]string, 0, sampleLines)
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == sampleLines {
+			break
+		}
+	}
+
+	best, bestScore := delimiters[0], 0
+	for _, delimiter := range delimiters {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			if n := strings.Count(line, string(delimiter)); n > 0 {
+				counts[n]++
+			}
+		}
+
+		// The most frequent field count, weighted by how many fields it
+		// gives, so a stray comma in a note does not beat real columns.
+		score := 0
+		for fields, lines := range counts {
+			if s := lines * (fields + 1); s > score {
+				score = s
+			}
+		}
+
+		if score > bestScore {
+			best, bestScore = delimiter, score
+		}
+	}
+
+	return best
+}
+
+func Read(text string, delimiter rune) ([][]string, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		empty := true
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+			if record[i] != "" {
+				empty = false
+			}
+		}
+		if !empty {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
@@ -114,18 +114,23 @@ func (d *BaseDictionary[K, M]) Load(ctx context.Context) error {
 		}
 	}
 
-	d.mu.Lock()
-	d.Items = models
-
+	itemsByKey := make(map[K]M, len(models))
+	itemsBySlug := make(map[string]M, len(models))
 	for _, m := range models {
-		d.ItemsByKey[m.Key()] = m
+		itemsByKey[m.Key()] = m
 
 		if m.Code() == "" {
 			continue
 		}
 
-		d.ItemsBySlug[m.Code()] = m
+		itemsBySlug[m.Code()] = m
 	}
+
+	// swap the maps instead of patching them so deleted rows disappear too
+	d.mu.Lock()
+	d.Items = models
+	d.ItemsByKey = itemsByKey
+	d.ItemsBySlug = itemsBySlug
 	d.mu.Unlock()
 
 	d.lastLoad = time.Now()
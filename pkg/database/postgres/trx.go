@@ -3,12 +3,20 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/uptrace/bun"
 )
 
 type txCtx struct{}
 
+type txHooksCtx struct{}
+
+type txHooks struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
 func FromContext(ctx context.Context, defautDB bun.IDB) bun.IDB {
 	if db, ok := ctx.Value(txCtx{}).(bun.IDB); ok {
 		return db
@@ -17,6 +25,20 @@ func FromContext(ctx context.Context, defautDB bun.IDB) bun.IDB {
 	return defautDB
 }
 
+// AfterCommit defers fn until the transaction carried by ctx is committed.
+// Outside of a transaction fn runs right away. Hooks are dropped on rollback.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	hooks, ok := ctx.Value(txHooksCtx{}).(*txHooks)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	hooks.mu.Lock()
+	hooks.fns = append(hooks.fns, fn)
+	hooks.mu.Unlock()
+}
+
 type TxManager interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -42,13 +64,22 @@ func (tm *txManager) WithTx(ctx context.Context, fn func(ctx context.Context) er
 		}
 	}()
 
-	ctx = context.WithValue(ctx, txCtx{}, tx)
-	if err := fn(ctx); err != nil {
+	hooks := &txHooks{}
+	tctx := context.WithValue(context.WithValue(ctx, txCtx{}, tx), txHooksCtx{}, hooks)
+	if err := fn(tctx); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, hook := range hooks.fns {
+		hook(ctx)
+	}
+
+	return nil
 }
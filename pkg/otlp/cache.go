@@ -0,0 +1,38 @@
+package otlp
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CacheLookups counts the hits and misses of a cache.
+type CacheLookups struct {
+	name   string
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCacheLookups counts the lookups of the cache, name prefixes the span
+// attributes, e.g. "parse_cache".
+func NewCacheLookups(name string) *CacheLookups {
+	return &CacheLookups{name: name}
+}
+
+// Record annotates the current span with the lookup along with the running
+// totals of this process.
+func (c *CacheLookups) Record(ctx context.Context, hit bool) {
+	var hits, misses int64
+	if hit {
+		hits, misses = c.hits.Add(1), c.misses.Load()
+	} else {
+		hits, misses = c.hits.Load(), c.misses.Add(1)
+	}
+
+	Annotate(ctx,
+		attribute.Bool(c.name+".hit", hit),
+		attribute.Int64(c.name+".hits", hits),
+		attribute.Int64(c.name+".misses", misses),
+	)
+}
@@ -0,0 +1,258 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrUnsupportedFont = errors.New("pdf: only TrueType outlines are supported")
+
+// Font is a TrueType font embedded whole into the document. Text is written
+// as glyph ids, so any script the font covers can be shown.
+type Font struct {
+	name       string
+	data       []byte
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	widths     []uint16
+	glyphs     map[rune]uint16
+}
+
+// LoadFont reads a .ttf file.
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParseFont(name, data)
+}
+
+// ParseFont reads the metrics and the character map of a TrueType font.
+func ParseFont(name string, data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, errors.New("pdf: font is too short")
+	}
+	if string(data[:4]) == "OTTO" {
+		return nil, ErrUnsupportedFont
+	}
+
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + i*16
+		if record+16 > len(data) {
+			return nil, errors.New("pdf: font table directory is truncated")
+		}
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("pdf: font table %q is truncated", data[record:record+4])
+		}
+		tables[string(data[record:record+4])] = data[offset : offset+length]
+	}
+
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("pdf: font has no %s table", tag)
+		}
+	}
+
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errors.New("pdf: font header is truncated")
+	}
+
+	f := &Font{
+		name:       pdfName(name),
+		data:       data,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		bbox: [4]int{
+			int(int16(binary.BigEndian.Uint16(head[36:]))),
+			int(int16(binary.BigEndian.Uint16(head[38:]))),
+			int(int16(binary.BigEndian.Uint16(head[40:]))),
+			int(int16(binary.BigEndian.Uint16(head[42:]))),
+		},
+		ascent:  int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent: int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("pdf: font has no units per em")
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if numMetrics == 0 || len(hmtx) < numMetrics*4 {
+		return nil, errors.New("pdf: font metrics are truncated")
+	}
+
+	// Glyphs past the last metric share its advance.
+	f.widths = make([]uint16, max(numGlyphs, numMetrics))
+	for i := range f.widths {
+		if i < numMetrics {
+			f.widths[i] = binary.BigEndian.Uint16(hmtx[i*4:])
+		} else {
+			f.widths[i] = f.widths[numMetrics-1]
+		}
+	}
+
+	glyphs, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.glyphs = glyphs
+
+	return f, nil
+}
+
+// parseCmap prefers the full Unicode subtable and falls back to the BMP one.
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("pdf: font cmap is truncated")
+	}
+
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := 4 + i*8
+		if record+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if offset+2 > len(cmap) || (platform != 0 && (platform != 3 || (encoding != 1 && encoding != 10))) {
+			continue
+		}
+
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		return parseCmap12(format12)
+	case format4 != nil:
+		return parseCmap4(format4)
+	}
+	return nil, errors.New("pdf: font has no unicode cmap")
+}
+
+func parseCmap4(t []byte) (map[rune]uint16, error) {
+	if len(t) < 14 {
+		return nil, errors.New("pdf: font cmap is truncated")
+	}
+
+	segCount := int(binary.BigEndian.Uint16(t[6:])) / 2
+	ends, starts := 14, 16+segCount*2
+	deltas, rangeOffsets := starts+segCount*2, starts+segCount*4
+	if rangeOffsets+segCount*2 > len(t) {
+		return nil, errors.New("pdf: font cmap is truncated")
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < segCount; i++ {
+		end := int(binary.BigEndian.Uint16(t[ends+i*2:]))
+		start := int(binary.BigEndian.Uint16(t[starts+i*2:]))
+		delta := binary.BigEndian.Uint16(t[deltas+i*2:])
+		rangeOffset := int(binary.BigEndian.Uint16(t[rangeOffsets+i*2:]))
+
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var gid uint16
+			if rangeOffset == 0 {
+				gid = uint16(c) + delta
+			} else {
+				at := rangeOffsets + i*2 + rangeOffset + (c-start)*2
+				if at+2 > len(t) {
+					continue
+				}
+				if gid = binary.BigEndian.Uint16(t[at:]); gid != 0 {
+					gid += delta
+				}
+			}
+			if gid != 0 {
+				glyphs[rune(c)] = gid
+			}
+		}
+	}
+
+	return glyphs, nil
+}
+
+func parseCmap12(t []byte) (map[rune]uint16, error) {
+	if len(t) < 16 {
+		return nil, errors.New("pdf: font cmap is truncated")
+	}
+
+	groups := int(binary.BigEndian.Uint32(t[12:]))
+	if 16+groups*12 > len(t) {
+		return nil, errors.New("pdf: font cmap is truncated")
+	}
+
+	glyphs := make(map[rune]uint16)
+	for i := 0; i < groups; i++ {
+		group := t[16+i*12:]
+		start := binary.BigEndian.Uint32(group)
+		end := binary.BigEndian.Uint32(group[4:])
+		gid := binary.BigEndian.Uint32(group[8:])
+		for c := start; c <= end && c <= 0x10FFFF; c++ {
+			glyphs[rune(c)] = uint16(gid + c - start)
+		}
+	}
+
+	return glyphs, nil
+}
+
+// scale converts font units into thousandths of an em, the unit of PDF
+// font metrics.
+func (f *Font) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+func (f *Font) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+func (f *Font) advance(gid uint16) int {
+	if int(gid) >= len(f.widths) {
+		return 0
+	}
+	return f.scale(int(f.widths[gid]))
+}
+
+// pdfName keeps the characters a PDF name can hold without escaping.
+func pdfName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > ' ' && r < 0x7F && !strings.ContainsRune("()<>[]{}/%#", r) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "Font"
+	}
+	return b.String()
+}
+
+// helveticaWidths are the advances of printable ASCII in Helvetica, used to
+// measure text when no font is embedded.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
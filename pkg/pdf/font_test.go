@@ -0,0 +1,229 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"sort"
+	"testing"
+)
+
+// testFont builds a minimal TrueType font: 2048 units per em, five glyphs
+// with three metrics, and the cmap subtables given.
+func testFont(t *testing.T, cmaps ...[]byte) []byte {
+	t.Helper()
+
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 2048)
+	for i, v := range []int16{-100, -400, 1800, 1900} {
+		binary.BigEndian.PutUint16(head[36+i*2:], uint16(v))
+	}
+
+	hhea := make([]byte, 36)
+	ascent, descent := int16(1556), int16(-492)
+	binary.BigEndian.PutUint16(hhea[4:], uint16(ascent))
+	binary.BigEndian.PutUint16(hhea[6:], uint16(descent))
+	binary.BigEndian.PutUint16(hhea[34:], 3)
+
+	maxp := make([]byte, 6)
+	binary.BigEndian.PutUint16(maxp[4:], 5)
+
+	var hmtx []byte
+	for _, advance := range []uint16{1024, 1229, 2048} {
+		hmtx = binary.BigEndian.AppendUint16(hmtx, advance)
+		hmtx = binary.BigEndian.AppendUint16(hmtx, 0)
+	}
+
+	cmap := binary.BigEndian.AppendUint16(nil, 0)
+	cmap = binary.BigEndian.AppendUint16(cmap, uint16(len(cmaps)))
+	offset := 4 + 8*len(cmaps)
+	for i, sub := range cmaps {
+		encoding := uint16(1)
+		if binary.BigEndian.Uint16(sub) == 12 {
+			encoding = 10
+		}
+		cmap = binary.BigEndian.AppendUint16(cmap, 3)
+		cmap = binary.BigEndian.AppendUint16(cmap, encoding)
+		cmap = binary.BigEndian.AppendUint32(cmap, uint32(offset))
+		offset += len(cmaps[i])
+	}
+	for _, sub := range cmaps {
+		cmap = append(cmap, sub...)
+	}
+
+	tables := map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"maxp": maxp,
+		"hmtx": hmtx,
+		"cmap": cmap,
+		"glyf": {},
+	}
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	data := binary.BigEndian.AppendUint32(nil, 0x00010000)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(tags)))
+	data = append(data, make([]byte, 6)...)
+
+	offset = 12 + 16*len(tags)
+	for _, tag := range tags {
+		data = append(data, tag...)
+		data = binary.BigEndian.AppendUint32(data, 0)
+		data = binary.BigEndian.AppendUint32(data, uint32(offset))
+		data = binary.BigEndian.AppendUint32(data, uint32(len(tables[tag])))
+		offset += len(tables[tag])
+	}
+	for _, tag := range tags {
+		data = append(data, tables[tag]...)
+	}
+
+	return data
+}
+
+// cmap4 maps 'A' and 'B' to glyphs 1 and 2 by delta and 'Я' to glyph 4
+// through the glyph id array.
+func cmap4() []byte {
+	// deltas are added modulo 65536
+	first := uint16('A')
+	segments := []struct {
+		start, end, delta, rangeOffset uint16
+	}{
+		{start: 'A', end: 'B', delta: 1 - first},
+		{start: 'Я', end: 'Я', rangeOffset: 4},
+		{start: 0xFFFF, end: 0xFFFF, delta: 1},
+	}
+
+	t := binary.BigEndian.AppendUint16(nil, 4)
+	t = binary.BigEndian.AppendUint16(t, 0) // length, not read
+	t = binary.BigEndian.AppendUint16(t, 0)
+	t = binary.BigEndian.AppendUint16(t, uint16(len(segments)*2))
+	t = append(t, make([]byte, 6)...)
+	for _, s := range segments {
+		t = binary.BigEndian.AppendUint16(t, s.end)
+	}
+	t = binary.BigEndian.AppendUint16(t, 0)
+	for _, s := range segments {
+		t = binary.BigEndian.AppendUint16(t, s.start)
+	}
+	for _, s := range segments {
+		t = binary.BigEndian.AppendUint16(t, s.delta)
+	}
+	for _, s := range segments {
+		t = binary.BigEndian.AppendUint16(t, s.rangeOffset)
+	}
+	// the glyph id array, the second segment's offset points right here
+	return binary.BigEndian.AppendUint16(t, 4)
+}
+
+// cmap12 maps '0'..'2' to glyphs 1..3 and an emoji outside the BMP to 4.
+func cmap12() []byte {
+	groups := [][3]uint32{
+		{'0', '2', 1},
+		{0x1F600, 0x1F600, 4},
+	}
+
+	t := binary.BigEndian.AppendUint16(nil, 12)
+	t = append(t, make([]byte, 10)...)
+	t = binary.BigEndian.AppendUint32(t, uint32(len(groups)))
+	for _, g := range groups {
+		t = binary.BigEndian.AppendUint32(t, g[0])
+		t = binary.BigEndian.AppendUint32(t, g[1])
+		t = binary.BigEndian.AppendUint32(t, g[2])
+	}
+	return t
+}
+
+func TestParseFont(t *testing.T) {
+	font, err := ParseFont("Test Font", testFont(t, cmap4()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if font.name != "TestFont" {
+		t.Errorf("name = %q, want TestFont", font.name)
+	}
+	if font.unitsPerEm != 2048 || font.ascent != 1556 || font.descent != -492 {
+		t.Errorf("metrics = %d/%d/%d, want 2048/1556/-492", font.unitsPerEm, font.ascent, font.descent)
+	}
+	if font.bbox != [4]int{-100, -400, 1800, 1900} {
+		t.Errorf("bbox = %v", font.bbox)
+	}
+
+	glyphs := map[rune]uint16{'A': 1, 'B': 2, 'Я': 4, 'C': 0, 'a': 0}
+	for r, want := range glyphs {
+		if got := font.glyph(r); got != want {
+			t.Errorf("glyph(%q) = %d, want %d", r, got, want)
+		}
+	}
+
+	// thousandths of an em, glyphs past the last metric share it
+	advances := map[uint16]int{0: 500, 1: 600, 2: 1000, 4: 1000, 9: 0}
+	for gid, want := range advances {
+		if got := font.advance(gid); got != want {
+			t.Errorf("advance(%d) = %d, want %d", gid, got, want)
+		}
+	}
+}
+
+func TestParseFontPrefersFullUnicodeCmap(t *testing.T) {
+	font, err := ParseFont("Test", testFont(t, cmap4(), cmap12()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glyphs := map[rune]uint16{'0': 1, '2': 3, 0x1F600: 4, 'A': 0}
+	for r, want := range glyphs {
+		if got := font.glyph(r); got != want {
+			t.Errorf("glyph(%q) = %d, want %d", r, got, want)
+		}
+	}
+}
+
+func TestParseFontErrors(t *testing.T) {
+	valid := testFont(t, cmap4())
+
+	if _, err := ParseFont("Test", append([]byte("OTTO"), valid[4:]...)); !errors.Is(err, ErrUnsupportedFont) {
+		t.Errorf("CFF font: got %v, want ErrUnsupportedFont", err)
+	}
+	if _, err := ParseFont("Test", valid[:8]); err == nil {
+		t.Error("short font: got no error")
+	}
+	if _, err := ParseFont("Test", valid[:len(valid)-10]); err == nil {
+		t.Error("truncated table: got no error")
+	}
+	if _, err := ParseFont("Test", testFont(t)); err == nil {
+		t.Error("font without a unicode cmap: got no error")
+	}
+}
+
+// TestLoadFont reads the font reports are rendered with, when it is
+// installed.
+func TestLoadFont(t *testing.T) {
+	path := os.Getenv("REPORT_FONT_PATH")
+	if path == "" {
+		path = "/usr/share/fonts/dejavu/DejaVuSans.ttf"
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("report font is not installed: %v", err)
+	}
+
+	font, err := LoadFont(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range "Aя₽" {
+		gid := font.glyph(r)
+		if gid == 0 {
+			t.Errorf("glyph(%q) is missing", r)
+			continue
+		}
+		if font.advance(gid) <= 0 {
+			t.Errorf("advance of %q = %d, want it positive", r, font.advance(gid))
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Package pdf writes simple A4 documents: text in a single font, filled
+// rectangles and lines, which is enough for tables and bar charts. Positions
+// are in points from the top left corner of the page.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+var ErrNoPages = errors.New("pdf: document has no pages")
+
+type Color struct {
+	R, G, B uint8
+}
+
+var (
+	Black = Color{0, 0, 0}
+	White = Color{255, 255, 255}
+)
+
+// Document collects pages in memory. Without a font the standard Helvetica
+// is used, which only covers ASCII, other characters are shown as '?'.
+type Document struct {
+	font    *Font
+	title   string
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	used    map[uint16]rune
+}
+
+func New(font *Font) *Document {
+	return &Document{
+		font: font,
+		used: make(map[uint16]rune),
+	}
+}
+
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+func (d *Document) AddPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+}
+
+// PageCount is the number of pages added so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) SetFillColor(c Color) {
+	d.op("%s %s %s rg", colorComponent(c.R), colorComponent(c.G), colorComponent(c.B))
+}
+
+func (d *Document) SetStrokeColor(c Color) {
+	d.op("%s %s %s RG", colorComponent(c.R), colorComponent(c.G), colorComponent(c.B))
+}
+
+// Text draws s with its baseline at y, in the current fill color.
+func (d *Document) Text(x, y, size float64, s string) {
+	if s == "" {
+		return
+	}
+	d.op("BT /F1 %s Tf %s %s Td %s Tj ET", num(size), num(x), num(PageHeight-y), d.encode(s))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y, size float64, s string) {
+	d.Text(x-d.TextWidth(s, size), y, size, s)
+}
+
+// TextWidth measures s in points.
+func (d *Document) TextWidth(s string, size float64) float64 {
+	var width int
+	for _, r := range s {
+		if d.font != nil {
+			width += d.font.advance(d.font.glyph(r))
+		} else {
+			width += helveticaWidths[helveticaCode(r)-32]
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// Truncate shortens s with an ellipsis until it fits into width.
+func (d *Document) Truncate(s string, size, width float64) string {
+	if d.TextWidth(s, size) <= width {
+		return s
+	}
+
+	ellipsis := "…"
+	if d.font == nil || d.font.glyph('…') == 0 {
+		ellipsis = "..."
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if t := strings.TrimSpace(string(runes)) + ellipsis; d.TextWidth(t, size) <= width {
+			return t
+		}
+	}
+	return ""
+}
+
+// Rect fills a rectangle whose top left corner is at x, y.
+func (d *Document) Rect(x, y, w, h float64) {
+	d.op("%s %s %s %s re f", num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	d.op("%s w %s %s m %s %s l S", num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+func (d *Document) op(format string, args ...any) {
+	if d.current == nil {
+		d.AddPage()
+	}
+	fmt.Fprintf(d.current, format, args...)
+	d.current.WriteByte('\n')
+}
+
+// encode writes glyph ids for an embedded font and WinAnsi bytes for
+// Helvetica.
+func (d *Document) encode(s string) string {
+	var b strings.Builder
+
+	if d.font == nil {
+		b.WriteByte('(')
+		for _, r := range s {
+			switch c := helveticaCode(r); c {
+			case '(', ')', '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			default:
+				b.WriteByte(c)
+			}
+		}
+		b.WriteByte(')')
+		return b.String()
+	}
+
+	b.WriteByte('<')
+	for _, r := range s {
+		gid := d.font.glyph(r)
+		if _, ok := d.used[gid]; !ok {
+			d.used[gid] = r
+		}
+		fmt.Fprintf(&b, "%04X", gid)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+func helveticaCode(r rune) byte {
+	if r < 32 || r > 126 {
+		return '?'
+	}
+	return byte(r)
+}
+
+// Bytes renders the document.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		return 0, ErrNoPages
+	}
+
+	out := &objectWriter{}
+	out.buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	const (
+		catalogID = 1
+		pagesID   = 2
+		infoID    = 3
+		fontID    = 4
+	)
+	firstPageID := fontID + 1
+	if d.font != nil {
+		firstPageID = fontID + 5
+	}
+
+	out.object(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+
+	kids := make([]string, 0, len(d.pages))
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageID+i*2))
+	}
+	out.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	out.object(infoID, fmt.Sprintf("<< /Title %s /Producer (e-wallet) >>", textString(d.title)))
+
+	if d.font == nil {
+		out.object(fontID, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	} else if err := d.writeFont(out, fontID); err != nil {
+		return 0, err
+	}
+
+	for i, page := range d.pages {
+		pageID := firstPageID + i*2
+		out.object(pageID, fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesID, num(PageWidth), num(PageHeight), fontID, pageID+1,
+		))
+		if err := out.stream(pageID+1, "", page.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+
+	out.finish(catalogID, infoID)
+
+	n, err := w.Write(out.buf.Bytes())
+	return int64(n), err
+}
+
+// writeFont embeds the font as a CID font with identity encoding, so the
+// glyph ids written by encode are used as they are.
+func (d *Document) writeFont(out *objectWriter, fontID int) error {
+	f := d.font
+	cidFontID, descriptorID, fileID, toUnicodeID := fontID+1, fontID+2, fontID+3, fontID+4
+
+	out.object(fontID, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cidFontID, toUnicodeID,
+	))
+
+	gids := make([]int, 0, len(d.used))
+	for gid := range d.used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var widths strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%d] ", gid, f.advance(uint16(gid)))
+	}
+
+	out.object(cidFontID, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW 1000 /W [%s] >>",
+		f.name, descriptorID, strings.TrimSpace(widths.String()),
+	))
+
+	out.object(descriptorID, fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.name, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), fileID,
+	))
+
+	if err := out.stream(fileID, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+		return err
+	}
+
+	return out.stream(toUnicodeID, "", toUnicodeCMap(gids, d.used))
+}
+
+// toUnicodeCMap maps glyph ids back to text, so it can be searched and
+// copied.
+func toUnicodeCMap(gids []int, used map[uint16]rune) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// A bfchar block holds at most 100 entries.
+	for start := 0; start < len(gids); start += 100 {
+		chunk := gids[start:min(start+100, len(gids))]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for _, gid := range chunk {
+			fmt.Fprintf(&b, "<%04X> <", gid)
+			for _, unit := range utf16.Encode([]rune{used[uint16(gid)]}) {
+				fmt.Fprintf(&b, "%04X", unit)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// objectWriter numbers objects by hand, so the cross-reference table only
+// needs their offsets.
+type objectWriter struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+}
+
+func (o *objectWriter) object(id int, body string) {
+	o.begin(id)
+	o.buf.WriteString(body)
+	o.buf.WriteString("\nendobj\n")
+}
+
+func (o *objectWriter) stream(id int, extra string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	o.begin(id)
+	fmt.Fprintf(&o.buf, "<< /Length %d /Filter /FlateDecode %s>>\nstream\n", compressed.Len(), extra+" ")
+	o.buf.Write(compressed.Bytes())
+	o.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+func (o *objectWriter) begin(id int) {
+	if o.offsets == nil {
+		o.offsets = make(map[int]int)
+	}
+	o.offsets[id] = o.buf.Len()
+	fmt.Fprintf(&o.buf, "%d 0 obj\n", id)
+}
+
+func (o *objectWriter) finish(rootID, infoID int) {
+	size := len(o.offsets) + 1
+	start := o.buf.Len()
+
+	fmt.Fprintf(&o.buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for id := 1; id < size; id++ {
+		fmt.Fprintf(&o.buf, "%010d 00000 n \n", o.offsets[id])
+	}
+	fmt.Fprintf(&o.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, rootID, infoID, start)
+}
+
+// textString writes s as UTF-16 with a byte order mark, which PDF readers
+// accept in document metadata.
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+func colorComponent(v uint8) string {
+	return num(float64(v) / 255)
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
@@ -0,0 +1,136 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	trailerPattern   = regexp.MustCompile(`trailer\n<< /Size (\d+) /Root (\d+) 0 R /Info (\d+) 0 R >>\n`)
+)
+
+// checkStructure walks the document from its trailer: the cross-reference
+// table must be where startxref says and list every object at its offset.
+func checkStructure(t *testing.T, data []byte) {
+	t.Helper()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n%")) {
+		t.Fatalf("header = %q", data[:min(len(data), 16)])
+	}
+	// the comment after the version marks the file as binary
+	for _, c := range data[10:14] {
+		if c < 0x80 {
+			t.Fatalf("binary marker = %q, want four bytes above 127", data[10:14])
+		}
+	}
+
+	m := startxrefPattern.FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("no startxref at the end of the file: %q", data[max(0, len(data)-64):])
+	}
+	start, _ := strconv.Atoi(string(m[1]))
+	if start >= len(data) || !bytes.HasPrefix(data[start:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", start)
+	}
+
+	xref := data[start+len("xref\n"):]
+	var first, size int
+	if _, err := fmt.Sscanf(string(xref), "%d %d\n", &first, &size); err != nil {
+		t.Fatalf("xref subsection header: %v", err)
+	}
+	if first != 0 {
+		t.Fatalf("xref starts at object %d, want 0", first)
+	}
+	xref = xref[bytes.IndexByte(xref, '\n')+1:]
+
+	// every entry is exactly 20 bytes, the free head of the list first
+	const entryLen = 20
+	if len(xref) < size*entryLen {
+		t.Fatalf("xref table holds fewer than %d entries", size)
+	}
+	if entry := string(xref[:entryLen]); entry != "0000000000 65535 f \n" {
+		t.Errorf("entry 0 = %q", entry)
+	}
+	for id := 1; id < size; id++ {
+		entry := string(xref[id*entryLen : (id+1)*entryLen])
+		var offset, generation int
+		var kind string
+		if _, err := fmt.Sscanf(entry, "%010d %05d %1s", &offset, &generation, &kind); err != nil || kind != "n" || generation != 0 {
+			t.Errorf("entry %d = %q", id, entry)
+			continue
+		}
+
+		if want := fmt.Sprintf("%d 0 obj\n", id); offset >= start || !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("entry %d points at %q, want %q", id, data[offset:min(offset+len(want), len(data))], want)
+		}
+	}
+
+	trailer := trailerPattern.FindSubmatch(xref[size*entryLen:])
+	if trailer == nil {
+		t.Fatalf("no trailer after the xref table")
+	}
+	if string(trailer[1]) != strconv.Itoa(size) {
+		t.Errorf("trailer /Size = %s, want %d", trailer[1], size)
+	}
+	if string(trailer[2]) != "1" || string(trailer[3]) != "3" {
+		t.Errorf("trailer /Root %s /Info %s, want 1 and 3", trailer[2], trailer[3])
+	}
+	if !bytes.Contains(data, []byte("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>")) {
+		t.Error("object 1 is not the catalog")
+	}
+}
+
+func TestDocumentStructure(t *testing.T) {
+	font, err := ParseFont("Test", testFont(t, cmap4()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		font    *Font
+		objects int
+	}{
+		// catalog, pages, info, font, two objects per page
+		{name: "helvetica", font: nil, objects: 4 + 2*2},
+		// the font adds its descendant, descriptor, file and ToUnicode map
+		{name: "embedded font", font: font, objects: 8 + 2*2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New(tt.font)
+			doc.SetTitle("Отчёт (май)")
+			doc.AddPage()
+			doc.Text(40, 800, 12, "AB Я")
+			doc.Rect(40, 700, 100, 20)
+			doc.AddPage()
+			doc.Line(40, 600, 200, 600, 1)
+			doc.TextRight(550, 800, 10, "BA")
+
+			data, err := doc.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkStructure(t, data)
+
+			if got := bytes.Count(data, []byte(" 0 obj\n")); got != tt.objects {
+				t.Errorf("%d objects, want %d", got, tt.objects)
+			}
+			if !bytes.Contains(data, []byte("/Count 2")) {
+				t.Error("page tree does not count two pages")
+			}
+		})
+	}
+}
+
+func TestDocumentWithoutPages(t *testing.T) {
+	if _, err := New(nil).Bytes(); !errors.Is(err, ErrNoPages) {
+		t.Errorf("got %v, want ErrNoPages", err)
+	}
+}
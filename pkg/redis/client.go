@@ -129,3 +129,40 @@ func (c *RedisClient) SRem(ctx context.Context, key string, members ...string) e
 func (c *RedisClient) SIsMember(ctx context.Context, key string, member string) (bool, error) {
 	return c.client.SIsMember(ctx, c.prefixer(key), member).Result()
 }
+
+// GetInt64 reads a counter, 0 when the key does not exist.
+func (c *RedisClient) GetInt64(ctx context.Context, key string) (int64, error) {
+	value, err := c.client.Get(ctx, c.prefixer(key)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return value, err
+}
+
+// Incr increments a counter without expiry and returns its new value.
+func (c *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, c.prefixer(key)).Result()
+}
+
+// Script is a Lua script, sent by hash and loaded on first use.
+type Script = redis.Script
+
+func NewScript(src string) *Script {
+	return redis.NewScript(src)
+}
+
+// RunScript runs script atomically on the prefixed keys and reads its
+// answer as a list of integers.
+func (c *RedisClient) RunScript(ctx context.Context, script *Script, keys []string, args ...any) ([]int64, error) {
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, c.prefixer(key))
+	}
+
+	return script.Run(ctx, c.client, prefixed, args...).Int64Slice()
+}
+
+// IsNil reports whether err means the key does not exist.
+func IsNil(err error) bool {
+	return err == redis.Nil
+}
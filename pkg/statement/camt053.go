@@ -0,0 +1,173 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// camtDocument covers the parts of camt.053 that are read. Tags carry no
+// namespace so every camt.053 version matches.
+type camtDocument struct {
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Ref    string `xml:"NtryRef"`
+	Amount struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	Direction string `xml:"CdtDbtInd"`
+	// Status is plain text in older versions and a code since version 8.
+	Status struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookingDate camtDate     `xml:"BookgDt"`
+	ValueDate   camtDate     `xml:"ValDt"`
+	ServicerRef string       `xml:"AcctSvcrRef"`
+	Info        string       `xml:"AddtlNtryInf"`
+	Details     []camtTxDtls `xml:"NtryDtls>TxDtls"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtTxDtls struct {
+	EndToEndID   string   `xml:"Refs>EndToEndId"`
+	TxID         string   `xml:"Refs>TxId"`
+	ServicerRef  string   `xml:"Refs>AcctSvcrRef"`
+	Creditor     string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty  string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Debtor       string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty    string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+	Info         string   `xml:"AddtlTxInf"`
+}
+
+// ParseCAMT053 reads booked entries. The id is the end-to-end id of the
+// payment, falling back to the bank's references.
+func ParseCAMT053(text string) ([]Entry, error) {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	// The text is already UTF-8 whatever the declaration says.
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var doc camtDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("statement: invalid camt.053: %w", err)
+	}
+
+	var entries []Entry
+	for _, stmt := range doc.Statements {
+		for _, ntry := range stmt.Entries {
+			status := strings.ToUpper(strings.TrimSpace(ntry.Status.Code + ntry.Status.Value))
+			if status != "" && status != "BOOK" {
+				continue
+			}
+
+			entry, err := ntry.entry()
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	hashIDs(CAMT053, entries)
+	return entries, nil
+}
+
+func (n camtEntry) entry() (Entry, error) {
+	amount, err := parseDecimal(n.Amount.Value)
+	if err != nil {
+		return Entry{}, fmt.Errorf("statement: invalid camt.053 amount %q", n.Amount.Value)
+	}
+
+	debit := strings.EqualFold(strings.TrimSpace(n.Direction), "DBIT")
+	if debit {
+		amount = -amount
+	}
+
+	date := n.BookingDate
+	if date.Date == "" && date.DateTime == "" {
+		date = n.ValueDate
+	}
+	posted, dateOnly, err := date.parse()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		PostedAt: posted,
+		DateOnly: dateOnly,
+		Amount:   amount,
+		Currency: strings.ToUpper(strings.TrimSpace(n.Amount.Currency)),
+		Memo:     strings.TrimSpace(n.Info),
+	}
+
+	var ids []string
+	if len(n.Details) > 0 {
+		tx := n.Details[0]
+		ids = append(ids, tx.EndToEndID, tx.TxID, tx.ServicerRef)
+
+		// The counterparty is the creditor of money going out and the
+		// debtor of money coming in.
+		if debit {
+			entry.Payee = firstNonEmpty(tx.Creditor, tx.CreditorPty)
+		} else {
+			entry.Payee = firstNonEmpty(tx.Debtor, tx.DebtorPty)
+		}
+
+		if memo := strings.TrimSpace(strings.Join(tx.Unstructured, " ")); memo != "" {
+			entry.Memo = memo
+		} else if entry.Memo == "" {
+			entry.Memo = strings.TrimSpace(tx.Info)
+		}
+	}
+	ids = append(ids, n.ServicerRef, n.Ref)
+
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !strings.EqualFold(id, "NOTPROVIDED") {
+			entry.ID = id
+			break
+		}
+	}
+
+	return entry, nil
+}
+
+func (d camtDate) parse() (time.Time, bool, error) {
+	if d.DateTime != "" {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05"} {
+			if t, err := time.Parse(layout, strings.TrimSpace(d.DateTime)); err == nil {
+				return t, false, nil
+			}
+		}
+		return time.Time{}, false, fmt.Errorf("statement: invalid camt.053 date %q", d.DateTime)
+	}
+
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(d.Date))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("statement: invalid camt.053 date %q", d.Date)
+	}
+	return t, true, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package statement
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ofxTag matches both SGML (OFX 1.x, closing tags optional) and XML
+// (OFX 2.x) elements, capturing the name and the text up to the next tag.
+var ofxTag = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<]*)`)
+
+// ParseOFX reads the STMTTRN records of bank and credit card statements.
+// FITID is the entry id.
+func ParseOFX(text string) ([]Entry, error) {
+	var (
+		entries  []Entry
+		current  *Entry
+		currency string
+		name     string
+		inPayee  bool
+	)
+
+	for _, m := range ofxTag.FindAllStringSubmatch(text, -1) {
+		closing, tag, value := m[1] == "/", strings.ToUpper(m[2]), strings.TrimSpace(m[3])
+
+		switch {
+		case tag == "STMTTRN" && !closing:
+			current = &Entry{Currency: currency}
+			name = ""
+		case tag == "STMTTRN" && closing:
+			if current == nil {
+				continue
+			}
+			if current.Payee == "" {
+				current.Payee = name
+			}
+			if current.PostedAt.IsZero() {
+				return nil, fmt.Errorf("statement: ofx entry %q has no date", current.ID)
+			}
+			entries = append(entries, *current)
+			current = nil
+		case tag == "PAYEE":
+			inPayee = !closing
+		case closing || value == "":
+			continue
+		case tag == "CURDEF":
+			currency = strings.ToUpper(value)
+		case current == nil:
+			continue
+		case tag == "FITID":
+			current.ID = value
+		case tag == "DTPOSTED":
+			posted, dateOnly, err := parseOFXDate(value)
+			if err != nil {
+				return nil, err
+			}
+			current.PostedAt, current.DateOnly = posted, dateOnly
+		case tag == "TRNAMT":
+			amount, err := parseDecimal(value)
+			if err != nil {
+				return nil, fmt.Errorf("statement: invalid ofx amount %q", value)
+			}
+			current.Amount = amount
+		case tag == "NAME" && inPayee:
+			current.Payee = unescapeOFX(value)
+		case tag == "NAME":
+			name = unescapeOFX(value)
+		case tag == "MEMO":
+			current.Memo = unescapeOFX(value)
+		case tag == "CURSYM":
+			current.Currency = strings.ToUpper(value)
+		}
+	}
+
+	hashIDs(OFX, entries)
+	return entries, nil
+}
+
+// parseOFXDate reads YYYYMMDD[HHMMSS[.XXX]][[+-]H[:TZ]], the offset in
+// brackets being hours from UTC. Without it the time is taken as UTC, and a
+// bare date is reported as date only.
+func parseOFXDate(value string) (time.Time, bool, error) {
+	loc, zoned := time.UTC, false
+	if i := strings.Index(value, "["); i >= 0 {
+		zoned = true
+		offset := strings.TrimSuffix(value[i+1:], "]")
+		if j := strings.Index(offset, ":"); j >= 0 {
+			offset = offset[:j]
+		}
+		if hours, err := strconv.ParseFloat(offset, 64); err == nil {
+			loc = time.FixedZone("", int(hours*3600))
+		}
+		value = value[:i]
+	}
+	if i := strings.Index(value, "."); i >= 0 {
+		value = value[:i]
+	}
+
+	layout := "20060102150405"
+	switch {
+	case len(value) == 8:
+		layout = "20060102"
+	case len(value) == 12:
+		layout = "200601021504"
+	}
+
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("statement: invalid ofx date %q", value)
+	}
+	return t, len(value) == 8 && !zoned, nil
+}
+
+var ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'")
+
+func unescapeOFX(s string) string {
+	return ofxEntities.Replace(s)
+}
@@ -0,0 +1,163 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseQIF reads bank and card account sections. QIF has no entry ids, the
+// check number is used when present and a hash otherwise. Dates are taken as
+// month first, as Quicken writes them, unless a day above 12 in the first
+// position or dots as separators show they are day first.
+func ParseQIF(text string) ([]Entry, error) {
+	type rawEntry struct {
+		entry Entry
+		date  string
+	}
+
+	var (
+		raws    []rawEntry
+		current rawEntry
+		skip    bool
+		started bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if line[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(line))
+			if strings.HasPrefix(header, "!type:") {
+				// Only money movements, not the category or memorized lists.
+				kind := strings.TrimPrefix(header, "!type:")
+				skip = kind != "bank" && kind != "cash" && kind != "ccard" && kind != "oth a" && kind != "oth l"
+			}
+			continue
+		}
+		if skip {
+			continue
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+		switch code {
+		case '^':
+			if started {
+				raws = append(raws, current)
+			}
+			current, started = rawEntry{}, false
+		case 'D':
+			current.date, started = value, true
+		case 'T', 'U':
+			amount, err := parseDecimal(value)
+			if err != nil {
+				return nil, fmt.Errorf("statement: invalid qif amount %q", value)
+			}
+			current.entry.Amount, started = amount, true
+		case 'P':
+			current.entry.Payee = value
+		case 'M':
+			current.entry.Memo = value
+		case 'N':
+			// Check numbers only identify an entry when they are numbers,
+			// N also carries words such as ATM or DEP.
+			if _, err := strconv.Atoi(value); err == nil {
+				current.entry.ID = "qif:" + value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if started {
+		raws = append(raws, current)
+	}
+
+	dates := make([]string, len(raws))
+	for i, raw := range raws {
+		dates[i] = raw.date
+	}
+	dayFirst := qifDayFirst(dates)
+
+	entries := make([]Entry, 0, len(raws))
+	for _, raw := range raws {
+		posted, err := parseQIFDate(raw.date, dayFirst)
+		if err != nil {
+			return nil, err
+		}
+		raw.entry.PostedAt = posted
+		raw.entry.DateOnly = true
+		entries = append(entries, raw.entry)
+	}
+
+	hashIDs(QIF, entries)
+	return entries, nil
+}
+
+// qifDateParts splits "1/2'25", "01/02/2025", "01.02.2025" and
+// "2025-01-02" into year, first and second numbers.
+func qifDateParts(value string) (parts [3]int, yearFirst bool, dotted bool, err error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	dotted = strings.Contains(value, ".")
+
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '/' || r == '.' || r == '-' || r == '\''
+	})
+	if len(fields) != 3 {
+		return parts, false, dotted, fmt.Errorf("statement: invalid qif date %q", value)
+	}
+
+	for i, f := range fields {
+		if parts[i], err = strconv.Atoi(f); err != nil {
+			return parts, false, dotted, fmt.Errorf("statement: invalid qif date %q", value)
+		}
+	}
+
+	return parts, len(fields[0]) == 4, dotted, nil
+}
+
+func qifDayFirst(dates []string) bool {
+	for _, date := range dates {
+		parts, yearFirst, dotted, err := qifDateParts(date)
+		if err != nil || yearFirst {
+			continue
+		}
+		if dotted || parts[0] > 12 {
+			return true
+		}
+	}
+	return false
+}
+
+func parseQIFDate(value string, dayFirst bool) (time.Time, error) {
+	parts, yearFirst, _, err := qifDateParts(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var year, month, day int
+	switch {
+	case yearFirst:
+		year, month, day = parts[0], parts[1], parts[2]
+	case dayFirst:
+		day, month, year = parts[0], parts[1], parts[2]
+	default:
+		month, day, year = parts[0], parts[1], parts[2]
+	}
+	if year < 100 {
+		year += 2000
+	}
+
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, fmt.Errorf("statement: invalid qif date %q", value)
+	}
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), nil
+}
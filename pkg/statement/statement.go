@@ -0,0 +1,137 @@
+// Package statement reads bank statements in OFX, QIF and ISO 20022
+// CAMT.053 into a flat list of entries.
+package statement
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV     Format = "csv"
+	OFX     Format = "ofx"
+	QIF     Format = "qif"
+	CAMT053 Format = "camt053"
+)
+
+var ErrNoEntries = errors.New("statement: no entries found")
+
+// Entry is a single booked transaction. Amount is negative for money going
+// out. ID is the bank's own reference when the format has one, otherwise a
+// hash of the entry that stays the same when the file is exported again.
+type Entry struct {
+	ID       string
+	PostedAt time.Time
+	// DateOnly is set when the statement gives no time, PostedAt is then
+	// midnight UTC of the booking date and should not be converted.
+	DateOnly bool
+	Amount   float64
+	Currency string
+	Payee    string
+	Memo     string
+}
+
+// Detect looks at the content first and falls back to the file extension.
+// Anything unrecognised is CSV.
+func Detect(filename string, data []byte) Format {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = bytes.ToUpper(bytes.TrimLeft(head, "\uFEFF \t\r\n"))
+
+	switch {
+	case bytes.HasPrefix(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")):
+		return OFX
+	case bytes.HasPrefix(head, []byte("!TYPE:")) || bytes.HasPrefix(head, []byte("!ACCOUNT")):
+		return QIF
+	case bytes.Contains(head, []byte("CAMT.053")) || bytes.Contains(head, []byte("<BKTOCSTMRSTMT")):
+		return CAMT053
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ofx", ".qfx":
+		return OFX
+	case ".qif":
+		return QIF
+	}
+
+	return CSV
+}
+
+// Parse reads UTF-8 text in the given format.
+func Parse(format Format, text string) ([]Entry, error) {
+	var (
+		entries []Entry
+		err     error
+	)
+
+	switch format {
+	case OFX:
+		entries, err = ParseOFX(text)
+	case QIF:
+		entries, err = ParseQIF(text)
+	case CAMT053:
+		entries, err = ParseCAMT053(text)
+	default:
+		return nil, fmt.Errorf("statement: unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+
+	return entries, nil
+}
+
+// parseDecimal reads "1234.56", "1,234.56" and "1234,56".
+func parseDecimal(s string) (float64, error) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "'", "").Replace(strings.TrimSpace(s))
+
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && comma > dot:
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	case comma >= 0 && dot < 0 && strings.Count(s, ",") == 1 && len(s)-comma-1 != 3:
+		s = strings.Replace(s, ",", ".", 1)
+	default:
+		s = strings.ReplaceAll(s, ",", "")
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+// hashIDs gives entries without a bank reference a stable id. Identical
+// entries on the same statement are told apart by their order.
+func hashIDs(format Format, entries []Entry) {
+	seen := make(map[string]int)
+	for i := range entries {
+		if entries[i].ID != "" {
+			continue
+		}
+
+		key := strings.Join([]string{
+			entries[i].PostedAt.Format("2006-01-02"),
+			strconv.FormatFloat(entries[i].Amount, 'f', -1, 64),
+			strings.ToLower(entries[i].Payee),
+			strings.ToLower(entries[i].Memo),
+		}, "|")
+		seen[key]++
+
+		sum := sha1.Sum([]byte(key + "|" + strconv.Itoa(seen[key])))
+		entries[i].ID = string(format) + ":" + hex.EncodeToString(sum[:])
+	}
+}
@@ -9,6 +9,10 @@ import (
 	"strings"
 )
 
+// mp3Bitrate is the constant bitrate ConvertOggToMp3 encodes at, in bits
+// per second.
+const mp3Bitrate = 128_000
+
 func ConvertOggToMp3(ctx context.Context, oggPath string) (string, error) {
 	outPath := strings.TrimSuffix(oggPath, filepath.Ext(oggPath)) + ".mp3"
 
@@ -20,7 +24,7 @@ func ConvertOggToMp3(ctx context.Context, oggPath string) (string, error) {
 		"-loglevel", "error",
 		"-i", oggPath,
 		"-ac", "1",
-		"-b:a", "128k",
+		"-b:a", fmt.Sprintf("%d", mp3Bitrate),
 		outPath,
 	)
 
@@ -36,3 +40,14 @@ func ConvertOggToMp3(ctx context.Context, oggPath string) (string, error) {
 
 	return outPath, nil
 }
+
+// Mp3Seconds estimates the duration of a file made by ConvertOggToMp3 from
+// its size, exact enough for a constant bitrate.
+func Mp3Seconds(mp3Path string) (float64, error) {
+	info, err := os.Stat(mp3Path)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(info.Size()*8) / mp3Bitrate, nil
+}
@@ -0,0 +1,206 @@
+// Package xlsx writes single-sheet Office Open XML workbooks row by row, so
+// large tables can be streamed without holding them in memory.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+	relsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+	// stylesXML defines the cell formats referenced by Cell.style: 0 is the
+	// default and 1 a bold header.
+	stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+</styleSheet>`
+
+	sheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	sheetFooter = `</sheetData></worksheet>`
+)
+
+var ErrClosed = errors.New("xlsx: writer is closed")
+
+// Cell is a single value. Numbers are written as given, so the caller
+// decides the decimal scale.
+type Cell struct {
+	value  string
+	number bool
+	style  int
+}
+
+func String(value string) Cell {
+	return Cell{value: value}
+}
+
+func Number(value string) Cell {
+	return Cell{value: value, number: true}
+}
+
+// Header is a bold string cell.
+func Header(value string) Cell {
+	return Cell{value: value, style: 1}
+}
+
+type Writer struct {
+	zw     *zip.Writer
+	sheet  *bufio.Writer
+	closed bool
+}
+
+// NewWriter writes the workbook skeleton to w and opens the only sheet, which
+// is named sheetName.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+
+	var name bytes.Buffer
+	if err := xml.EscapeText(&name, []byte(sheetName)); err != nil {
+		return nil, err
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", relsXML},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", stylesXML},
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet goes last since zip entries are written one after another
+	// and it stays open until Close.
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := bufio.NewWriter(f)
+	if _, err := sheet.WriteString(sheetHeader); err != nil {
+		return nil, err
+	}
+
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+func (w *Writer) WriteRow(cells ...Cell) error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	if _, err := w.sheet.WriteString("<row>"); err != nil {
+		return err
+	}
+
+	for _, cell := range cells {
+		if err := w.writeCell(cell); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+func (w *Writer) writeCell(cell Cell) error {
+	style := ""
+	if cell.style != 0 {
+		style = ` s="1"`
+	}
+
+	if cell.value == "" {
+		_, err := w.sheet.WriteString("<c" + style + "/>")
+		return err
+	}
+
+	if cell.number {
+		if _, err := w.sheet.WriteString("<c" + style + "><v>"); err != nil {
+			return err
+		}
+		if err := xml.EscapeText(w.sheet, []byte(cell.value)); err != nil {
+			return err
+		}
+		_, err := w.sheet.WriteString("</v></c>")
+		return err
+	}
+
+	if _, err := w.sheet.WriteString(`<c t="inlineStr"` + style + `><is><t xml:space="preserve">`); err != nil {
+		return err
+	}
+	if err := xml.EscapeText(w.sheet, []byte(cell.value)); err != nil {
+		return err
+	}
+	_, err := w.sheet.WriteString("</t></is></c>")
+	return err
+}
+
+// Flush pushes buffered rows to the underlying writer.
+func (w *Writer) Flush() error {
+	if w.closed {
+		return ErrClosed
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Flush()
+}
+
+// Close finishes the sheet and the archive. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if _, err := w.sheet.WriteString(sheetFooter); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
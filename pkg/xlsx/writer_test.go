@@ -0,0 +1,164 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+)
+
+type testSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Type   string `xml:"t,attr"`
+			Style  string `xml:"s,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type testWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// readPart finds the part in the archive and decodes it into v, which fails
+// on anything that is not well-formed XML.
+func readPart(t *testing.T, archive *zip.Reader, name string, v any) {
+	t.Helper()
+
+	f, err := archive.Open(name)
+	if err != nil {
+		t.Fatalf("part %s: %v", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("part %s: %v", name, err)
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		t.Fatalf("part %s is not well-formed: %v", name, err)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, `P&L <"2025">`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := [][]Cell{
+		{Header("Date"), Header("Amount"), Header("Note")},
+		{String("2025-05-10"), Number("-125000.50"), String(`a < b && "c"`)},
+		{String("2025-05-11"), Number("42"), String("Обед в кафе — 50 000 сум 🍜")},
+		{String(""), Number("0"), String("  padded  ")},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(String("late")); !errors.Is(err, ErrClosed) {
+		t.Errorf("WriteRow after Close = %v, want ErrClosed", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the parts the content types and relationships point at
+	for _, name := range []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/_rels/workbook.xml.rels",
+		"xl/styles.xml",
+	} {
+		var part struct{}
+		readPart(t, archive, name, &part)
+	}
+
+	var workbook testWorkbook
+	readPart(t, archive, "xl/workbook.xml", &workbook)
+	if len(workbook.Sheets) != 1 || workbook.Sheets[0].Name != `P&L <"2025">` {
+		t.Errorf("sheets = %+v, want the one named P&L <\"2025\">", workbook.Sheets)
+	}
+
+	var sheet testSheet
+	readPart(t, archive, "xl/worksheets/sheet1.xml", &sheet)
+	if len(sheet.Rows) != len(rows) {
+		t.Fatalf("%d rows, want %d", len(sheet.Rows), len(rows))
+	}
+
+	for i, row := range rows {
+		got := sheet.Rows[i].Cells
+		if len(got) != len(row) {
+			t.Errorf("row %d: %d cells, want %d", i, len(got), len(row))
+			continue
+		}
+
+		for j, cell := range row {
+			c := got[j]
+			switch {
+			case cell.value == "":
+				if c.Type != "" || c.Value != "" || c.Inline != "" {
+					t.Errorf("cell %d/%d = %+v, want it empty", i, j, c)
+				}
+			case cell.number:
+				if c.Type != "" || c.Value != cell.value {
+					t.Errorf("cell %d/%d = %+v, want the number %s", i, j, c, cell.value)
+				}
+			default:
+				if c.Type != "inlineStr" || c.Inline != cell.value {
+					t.Errorf("cell %d/%d = %+v, want the string %q", i, j, c, cell.value)
+				}
+			}
+
+			wantStyle := ""
+			if cell.style != 0 {
+				wantStyle = "1"
+			}
+			if c.Style != wantStyle {
+				t.Errorf("cell %d/%d style = %q, want %q", i, j, c.Style, wantStyle)
+			}
+		}
+	}
+}
+
+func TestWriterCloseIsIdempotent(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Sheet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	size := buf.Len()
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close = %v", err)
+	}
+	if buf.Len() != size {
+		t.Error("second Close wrote to the output")
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet testSheet
+	readPart(t, archive, "xl/worksheets/sheet1.xml", &sheet)
+	if len(sheet.Rows) != 0 {
+		t.Errorf("%d rows in an empty sheet", len(sheet.Rows))
+	}
+}